@@ -3,11 +3,17 @@ package main
 import (
 	"alcyxob/fitness-app/internal/api" // Import API package
 	"alcyxob/fitness-app/internal/config"
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/mail"
+	"alcyxob/fitness-app/internal/push"
+	"alcyxob/fitness-app/internal/realtime"
 	"alcyxob/fitness-app/internal/repository/mongo"
+	"alcyxob/fitness-app/internal/scheduler"
 	"alcyxob/fitness-app/internal/service"
 	"alcyxob/fitness-app/internal/storage"
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -17,6 +23,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // @title Fitness Trainer API
@@ -65,6 +72,15 @@ func main() {
 	appDB := dbClient.Database(cfg.Database.Name)
 	log.Println("Database connection established.")
 
+	// Exercise edits, batch edits, reorders and plan publishing run in transactions,
+	// which a standalone mongod cannot provide.
+	txCheckCtx, txCheckCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	err = mongo.RequireTransactions(txCheckCtx, dbClient)
+	txCheckCancel()
+	if err != nil {
+		log.Fatalf("FATAL: MongoDB must run as a replica set or sharded cluster (e.g. mongod --replSet rs0, then rs.initiate()): %v", err)
+	}
+
 	// --- Ensure Indexes ---
 	log.Println("Ensuring database indexes...")
 	go func() { // Run index creation concurrently/in background
@@ -72,46 +88,246 @@ func main() {
 		defer cancel()
 		mongo.EnsureUserIndexes(ctx, appDB.Collection("users"))
 		mongo.EnsureExerciseIndexes(ctx, appDB.Collection("exercises"))
+		mongo.EnsureExerciseMediaIndexes(ctx, appDB.Collection("exercise_media"))
+		mongo.EnsureExerciseRevisionIndexes(ctx, appDB.Collection("exercise_revisions"))
 		mongo.EnsureAssignmentIndexes(ctx, appDB.Collection("assignments"))
+		if n, err := mongo.NewMongoAssignmentRepository(appDB).BackfillOwnerIDs(ctx); err != nil {
+			log.Printf("WARN: Failed to backfill assignment owner IDs: %v", err)
+		} else if n > 0 {
+			log.Printf("Backfilled trainer/client IDs on %d assignments", n)
+		}
 		mongo.EnsureUploadIndexes(ctx, appDB.Collection("uploads"))
 		mongo.EnsureTrainingPlanIndexes(ctx, appDB.Collection("training_plans"))
+		if n, err := mongo.NewMongoTrainingPlanRepository(appDB).BackfillStatuses(ctx); err != nil {
+			log.Printf("WARN: Failed to backfill training plan statuses: %v", err)
+		} else if n > 0 {
+			log.Printf("Backfilled status on %d training plans", n)
+		}
+		if n, err := mongo.NewMongoTrainingPlanRepository(appDB).BackfillPublishedAt(ctx); err != nil {
+			log.Printf("WARN: Failed to backfill training plan publish dates: %v", err)
+		} else if n > 0 {
+			log.Printf("Backfilled publishedAt on %d training plans", n)
+		}
 		mongo.EnsureWorkoutIndexes(ctx, appDB.Collection("workouts"))
+		mongo.EnsureWorkoutBlockIndexes(ctx, appDB.Collection("workout_blocks"))
+		mongo.EnsurePlanDraftIndexes(ctx, appDB.Collection("plan_drafts"))
+		mongo.EnsureFeedbackCommentIndexes(ctx, appDB.Collection("feedback_comments"))
+		mongo.EnsureConversationIndexes(ctx, appDB.Collection("conversations"))
+		mongo.EnsureMessageIndexes(ctx, appDB.Collection("messages"))
+		mongo.EnsureNotificationIndexes(ctx, appDB.Collection("notifications"))
+		mongo.EnsureDeviceIndexes(ctx, appDB.Collection("devices"))
+		mongo.EnsureWebhookEndpointIndexes(ctx, appDB.Collection("webhook_endpoints"))
+		mongo.EnsureWebhookDeliveryIndexes(ctx, appDB.Collection("webhook_deliveries"))
 		log.Println("Index creation process completed.")
 	}()
 
 	// --- Initialize Storage ---
 	log.Println("Initializing file storage service...")
-	fileStorage, err := storage.NewS3Storage(cfg.S3)
+	signingKey := cfg.Storage.SigningKey
+	if signingKey == "" {
+		signingKey = cfg.JWT.Secret
+	}
+	var fileStorage storage.FileStorage
+	switch cfg.Storage.Driver {
+	case "", "s3":
+		fileStorage, err = storage.NewS3Storage(cfg.S3)
+	case "local":
+		fileStorage, err = storage.NewLocalStorage(cfg.Storage.LocalPath, cfg.Storage.PublicBaseURL, signingKey)
+	case "memory":
+		log.Println("WARN: Using in-memory file storage; uploads are lost on restart.")
+		fileStorage, err = storage.NewMemoryStorage(cfg.Storage.PublicBaseURL, signingKey)
+	default:
+		log.Fatalf("FATAL: Unknown storage driver %q (expected s3, local or memory)", cfg.Storage.Driver)
+	}
 	if err != nil {
-		log.Fatalf("FATAL: Failed to initialize S3 storage: %v", err)
+		log.Fatalf("FATAL: Failed to initialize %s storage: %v", cfg.Storage.Driver, err)
 	}
 
 	// --- Initialize Repositories ---
 	log.Println("Initializing repositories...")
 	userRepo := mongo.NewMongoUserRepository(appDB)
 	exerciseRepo := mongo.NewMongoExerciseRepository(appDB)
+	exerciseMediaRepo := mongo.NewMongoExerciseMediaRepository(appDB)
+	exerciseRevisionRepo := mongo.NewMongoExerciseRevisionRepository(appDB)
 	assignmentRepo := mongo.NewMongoAssignmentRepository(appDB)
 	uploadRepo := mongo.NewMongoUploadRepository(appDB)
 	trainingPlanRepo := mongo.NewMongoTrainingPlanRepository(appDB) // ADDED
 	workoutRepo := mongo.NewMongoWorkoutRepository(appDB)
+	workoutBlockRepo := mongo.NewMongoWorkoutBlockRepository(appDB)
+	feedbackCommentRepo := mongo.NewMongoFeedbackCommentRepository(appDB)
+	conversationRepo := mongo.NewMongoConversationRepository(appDB)
+	messageRepo := mongo.NewMongoMessageRepository(appDB)
+	notificationRepo := mongo.NewMongoNotificationRepository(appDB)
+	notificationPreferencesRepo := mongo.NewMongoNotificationPreferencesRepository(appDB)
+	deviceRepo := mongo.NewMongoDeviceRepository(appDB)
+	webhookEndpointRepo := mongo.NewMongoWebhookEndpointRepository(appDB)
+	webhookDeliveryRepo := mongo.NewMongoWebhookDeliveryRepository(appDB)
+	reminderSettingsRepo := mongo.NewMongoReminderSettingsRepository(appDB)
+	leaseRepo := mongo.NewMongoLeaseRepository(appDB)
+	digestSettingsRepo := mongo.NewMongoDigestSettingsRepository(appDB)
+	planDraftRepo := mongo.NewMongoPlanDraftRepository(appDB)
+	transactor := mongo.NewMongoTransactor(dbClient)
   // workoutRepo := mongo.NewMongoWorkoutRepository(appDB) // Add later
 
+	// --- Realtime event hub (in-process; swap for a shared broker when running several instances) ---
+	eventHub := realtime.NewHub(32)
+	// --- Push notifications ---
+	pushSenders := map[domain.DevicePlatform]push.PushSender{
+		domain.PlatformIOS:     push.NewLogSender("ios"),
+		domain.PlatformAndroid: push.NewLogSender("android"),
+	}
+	switch cfg.Push.Driver {
+	case "", "log":
+		log.Println("Push notifications are logged, not sent (push.driver=log).")
+	case "live":
+		if cfg.Push.APNs.KeyFile != "" {
+			apns, err := push.NewAPNsSender(push.APNsConfig{
+				KeyID:      cfg.Push.APNs.KeyID,
+				TeamID:     cfg.Push.APNs.TeamID,
+				Topic:      cfg.Push.APNs.Topic,
+				KeyFile:    cfg.Push.APNs.KeyFile,
+				Production: cfg.Push.APNs.Production,
+			})
+			if err != nil {
+				log.Fatalf("FATAL: Failed to initialize APNs: %v", err)
+			}
+			pushSenders[domain.PlatformIOS] = apns
+		} else {
+			log.Println("WARN: push.apns.key_file not set; iOS pushes are only logged.")
+		}
+		if cfg.Push.FCM.CredentialsFile != "" {
+			fcm, err := push.NewFCMSender(push.FCMConfig{
+				ProjectID:       cfg.Push.FCM.ProjectID,
+				CredentialsFile: cfg.Push.FCM.CredentialsFile,
+			})
+			if err != nil {
+				log.Fatalf("FATAL: Failed to initialize FCM: %v", err)
+			}
+			pushSenders[domain.PlatformAndroid] = fcm
+		} else {
+			log.Println("WARN: push.fcm.credentials_file not set; Android pushes are only logged.")
+		}
+	default:
+		log.Fatalf("FATAL: Unknown push driver %q (expected log or live)", cfg.Push.Driver)
+	}
+	pushDispatcher := push.NewDispatcher(deviceRepo, pushSenders, push.DispatcherConfig{
+		Workers:     cfg.Push.Workers,
+		MaxAttempts: cfg.Push.MaxAttempts,
+	})
+
+	// --- Outbound webhooks ---
+	webhookService := service.NewWebhookService(webhookEndpointRepo, webhookDeliveryRepo, cfg.Webhooks.AllowPrivateTargets)
+
+	// Services publish through the notification center, which forwards to the hub and
+	// webhooks, stores notifications and hands them to the push dispatcher.
+	notificationService := service.NewNotificationService(notificationRepo, notificationPreferencesRepo, realtime.Publishers{eventHub, webhookService}, pushDispatcher)
+
 	// --- Initialize Services ---
 	log.Println("Initializing services...")
 	// Pass JWT config directly
 	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.Expiration)
-	exerciseService := service.NewExerciseService(exerciseRepo)
-	trainerService := service.NewTrainerService(userRepo, assignmentRepo, exerciseRepo, trainingPlanRepo, workoutRepo, uploadRepo, fileStorage)
-	clientService := service.NewClientService(userRepo, assignmentRepo, uploadRepo, exerciseRepo, workoutRepo, trainingPlanRepo, fileStorage)
+	exerciseService := service.NewExerciseService(exerciseRepo, exerciseMediaRepo, exerciseRevisionRepo, assignmentRepo, workoutRepo, trainingPlanRepo, userRepo, uploadRepo, feedbackCommentRepo, planDraftRepo, transactor, fileStorage)
+	uploadLimits := service.UploadLimits{
+		MaxFileSize:  cfg.Quotas.MaxFileSize,
+		TrainerQuota: cfg.Quotas.TrainerBytes,
+		ClientQuota:  cfg.Quotas.ClientBytes,
+	}
+	trainerService := service.NewTrainerService(userRepo, assignmentRepo, exerciseRepo, trainingPlanRepo, workoutRepo, uploadRepo, workoutBlockRepo, planDraftRepo, transactor, fileStorage, uploadLimits, notificationService)
+	clientService := service.NewClientService(userRepo, assignmentRepo, uploadRepo, exerciseRepo, workoutRepo, trainingPlanRepo, exerciseMediaRepo, exerciseRevisionRepo, workoutBlockRepo, feedbackCommentRepo, fileStorage, uploadLimits, notificationService)
+	feedbackService := service.NewFeedbackService(uploadRepo, feedbackCommentRepo, notificationService)
+	messagingService := service.NewMessagingService(conversationRepo, messageRepo, userRepo, trainingPlanRepo, workoutRepo, assignmentRepo, notificationService)
+	reconciliationService := service.NewReconciliationService(uploadRepo, exerciseMediaRepo, assignmentRepo, fileStorage, cfg.Storage.GCGracePeriod)
+	deviceService := service.NewDeviceService(deviceRepo)
+	reminderService := service.NewReminderService(reminderSettingsRepo, trainingPlanRepo, assignmentRepo, clientService, notificationService)
+
+	// --- Email ---
+	var mailSender mail.Sender
+	switch cfg.Mail.Driver {
+	case "", "log":
+		log.Println("Email is logged, not sent (mail.driver=log).")
+		mailSender = mail.NewLogSender()
+	case "smtp":
+		smtpSender, err := mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+		})
+		if err != nil {
+			log.Fatalf("FATAL: Failed to initialize SMTP mail: %v", err)
+		}
+		mailSender = smtpSender
+	default:
+		log.Fatalf("FATAL: Unknown mail driver %q (expected log or smtp)", cfg.Mail.Driver)
+	}
+	planPublishingService := service.NewPlanPublishingService(trainingPlanRepo, workoutRepo, assignmentRepo, workoutBlockRepo, exerciseRepo, planDraftRepo, transactor, notificationService)
+	planLifecycleService := service.NewPlanLifecycleService(trainingPlanRepo, userRepo, transactor, notificationService, cfg.Scheduler.PlanEndingNotice)
+	digestService := service.NewDigestService(digestSettingsRepo, userRepo, assignmentRepo, exerciseRepo, clientService, mailSender)
 
 	// --- Initialize Gin Engine ---
 	// gin.SetMode(gin.ReleaseMode) // Uncomment for production
-	router := gin.Default() // Includes Logger and Recovery middleware
+	router := gin.New()
+	router.Use(api.RequestLogger(), gin.Recovery()) // Like gin.Default(), but keeps access_token out of the logs
 
 	// --- Setup Routes ---
 	log.Println("Setting up API routes...")
 	// Pass services to the route setup function
-	api.SetupRoutes(router, cfg.JWT.Secret, authService, trainerService, clientService, exerciseService)
+	api.SetupRoutes(router, cfg.JWT.Secret, authService, trainerService, clientService, exerciseService, fileStorage, cfg.Admin.APIKey, reconciliationService, feedbackService, messagingService, eventHub, notificationService, deviceService, webhookService, reminderService, digestService, planPublishingService)
+
+	// --- Background Jobs ---
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go pushDispatcher.Run(jobsCtx)
+	go webhookService.RunDeliveries(jobsCtx, cfg.Webhooks.PollInterval)
+
+	// Periodic jobs run on whichever instance holds the "scheduler" lease.
+	if cfg.Scheduler.Enabled {
+		hostname, _ := os.Hostname()
+		holder := fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+		sched := scheduler.New(leaseRepo, "scheduler", holder, scheduler.DefaultLeaseTTL)
+		sched.Every("workout-reminders", cfg.Scheduler.ReminderInterval, func(ctx context.Context) error {
+			sent, err := reminderService.SendDueReminders(ctx, time.Now())
+			if sent > 0 {
+				log.Printf("Scheduler: sent %d workout reminders", sent)
+			}
+			return err
+		})
+		sched.Every("missed-workout-nudges", cfg.Scheduler.ReminderInterval, func(ctx context.Context) error {
+			sent, err := reminderService.SendDueNudges(ctx, time.Now())
+			if sent > 0 {
+				log.Printf("Scheduler: sent %d missed-workout nudges", sent)
+			}
+			return err
+		})
+		sched.Every("plan-lifecycle", cfg.Scheduler.PlanLifecycleInterval, func(ctx context.Context) error {
+			result, err := planLifecycleService.Run(ctx, time.Now())
+			if result.Activated+result.Completed+result.EndingNotified > 0 {
+				log.Printf("Scheduler: plans activated %d, completed %d, ending notices %d", result.Activated, result.Completed, result.EndingNotified)
+			}
+			return err
+		})
+		sched.Every("trainer-digests", cfg.Scheduler.DigestInterval, func(ctx context.Context) error {
+			sent, err := digestService.SendDue(ctx, time.Now())
+			if sent > 0 {
+				log.Printf("Scheduler: sent %d weekly trainer digests", sent)
+			}
+			return err
+		})
+		if cfg.Storage.GCInterval > 0 {
+			log.Printf("Storage reconciliation every %s (grace period %s)", cfg.Storage.GCInterval, cfg.Storage.GCGracePeriod)
+			sched.Every("storage-reconciliation", cfg.Storage.GCInterval, func(ctx context.Context) error {
+				report, err := reconciliationService.Reconcile(ctx, false)
+				if err != nil {
+					return err
+				}
+				log.Printf("Scheduler: storage reconciliation scanned %d objects, %d records; %d orphans (%d bytes) removed, %d stale multipart uploads aborted, %d uploads missing objects, %d errors",
+					report.ObjectsScanned, report.RecordsScanned, len(report.OrphanedObjects), report.OrphanedBytes, len(report.StaleMultipart), len(report.MissingObjects), len(report.Errors))
+				return nil
+			})
+		}
+		go sched.Run(jobsCtx)
+	}
 
 	// --- Start HTTP Server ---
 	server := &http.Server{
@@ -136,6 +352,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the requests it is currently handling
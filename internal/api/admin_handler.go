@@ -0,0 +1,64 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves operator endpoints, authorized by AdminKeyMiddleware.
+type AdminHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+func NewAdminHandler(reconciliationService service.ReconciliationService) *AdminHandler {
+	return &AdminHandler{reconciliationService: reconciliationService}
+}
+
+// runReconciliation runs a reconciliation and writes its report.
+func (h *AdminHandler) runReconciliation(c *gin.Context, dryRun bool) {
+	report, err := h.reconciliationService.Reconcile(c.Request.Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrReconciliationInProgress) {
+			abortWithError(c, http.StatusConflict, err.Error())
+			return
+		}
+		log.Printf("Admin: storage reconciliation failed: %v", err)
+		abortWithError(c, http.StatusInternalServerError, "Storage reconciliation failed.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetStorageReconciliationReport godoc
+// @Summary Dry-run storage reconciliation
+// @Description Compares objects under uploads/, exercises/ and feedback/ with upload, exercise media and feedback attachment records, and reports orphaned objects and multipart uploads (no record, older than the grace period) and upload records whose object is missing. Changes nothing.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Operator key"
+// @Success 200 {object} service.ReconciliationReport "Dry-run report"
+// @Failure 401 {object} gin.H "Invalid or missing admin key"
+// @Failure 409 {object} gin.H "A reconciliation is already running"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/storage/reconciliation [get]
+func (h *AdminHandler) GetStorageReconciliationReport(c *gin.Context) {
+	h.runReconciliation(c, true)
+}
+
+// RunStorageReconciliation godoc
+// @Summary Run storage reconciliation now
+// @Description Same as the dry run, but deletes orphaned objects, aborts stale multipart uploads and flags upload records whose object is missing.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Operator key"
+// @Success 200 {object} service.ReconciliationReport "Report of the changes made"
+// @Failure 401 {object} gin.H "Invalid or missing admin key"
+// @Failure 409 {object} gin.H "A reconciliation is already running"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/storage/reconciliation [post]
+func (h *AdminHandler) RunStorageReconciliation(c *gin.Context) {
+	h.runReconciliation(c, false)
+}
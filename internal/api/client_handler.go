@@ -115,9 +115,32 @@ func (h *ClientHandler) GetWorkoutsForMyPlan(c *gin.Context) {
 }
 
 
+// MapPinnedAssignmentsToResponse converts assignments with their pinned exercise content to DTOs.
+func MapPinnedAssignmentsToResponse(assignments []service.PinnedAssignment) []AssignmentResponse {
+	responses := make([]AssignmentResponse, len(assignments))
+	for i, a := range assignments {
+		responses[i] = MapAssignmentToResponse(&a.Assignment)
+		if a.Exercise != nil {
+			exercise := MapExerciseRevisionToResponse(a.Exercise)
+			responses[i].Exercise = &exercise
+		}
+	}
+	return responses
+}
+
+// MapPinnedWorkoutStructureToResponse converts a client's nested workout blocks to DTOs.
+func MapPinnedWorkoutStructureToResponse(blocks []service.PinnedWorkoutBlock) []WorkoutBlockResponse {
+	responses := make([]WorkoutBlockResponse, len(blocks))
+	for i, b := range blocks {
+		responses[i] = MapWorkoutBlockToResponse(&b.WorkoutBlock)
+		responses[i].Assignments = MapPinnedAssignmentsToResponse(b.Assignments)
+	}
+	return responses
+}
+
 // GetAssignmentsForMyWorkout godoc
 // @Summary Get assignments for one of my workouts
-// @Description Retrieves exercise assignments for a specific workout within a plan assigned to the authenticated client.
+// @Description Retrieves exercise assignments for a specific workout within a plan assigned to the authenticated client. Each includes the exercise content as pinned on the assignment, not the exercise's latest edit.
 // @Tags Client
 // @Produce json
 // @Security BearerAuth
@@ -159,7 +182,7 @@ func (h *ClientHandler) GetAssignmentsForMyWorkout(c *gin.Context) {
         c.JSON(http.StatusOK, []AssignmentResponse{})
         return
     }
-	c.JSON(http.StatusOK, MapAssignmentsToResponse(assignments)) // Reuse mapper
+	c.JSON(http.StatusOK, MapPinnedAssignmentsToResponse(assignments))
 }
 
 // --- DTO for Updating Assignment Status ---
@@ -239,6 +262,7 @@ func (h *ClientHandler) UpdateMyAssignmentStatus(c *gin.Context) {
 
 type RequestUploadURLRequest struct {
 	ContentType string `json:"contentType" binding:"required"`
+	FileSize    int64  `json:"fileSize" binding:"omitempty,min=0"` // Optional; lets size and quota limits be checked up front
 }
 
 // UploadURLResponse is already defined (or should be in a shared DTO place)
@@ -262,12 +286,13 @@ type ConfirmUploadRequest struct {
 // @Produce json
 // @Security BearerAuth
 // @Param assignmentId path string true "Assignment's ObjectID Hex"
-// @Param uploadRequest body RequestUploadURLRequest true "Upload content type"
+// @Param uploadRequest body RequestUploadURLRequest true "Upload content type and (optionally) file size"
 // @Success 200 {object} UploadURLResponse "Pre-signed URL and object key"
 // @Failure 400 {object} gin.H "Invalid input"
 // @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 403 {object} gin.H "Forbidden (assignment not for this client, or upload not allowed for status)"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client, upload not allowed for status, or storage quota used up)"
 // @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 413 {object} gin.H "File exceeds the maximum file size"
 // @Failure 500 {object} gin.H "Internal Server Error (e.g., S3 error)"
 // @Router /client/assignments/{assignmentId}/upload-url [post]
 func (h *ClientHandler) RequestUploadURLForAssignment(c *gin.Context) {
@@ -288,15 +313,21 @@ func (h *ClientHandler) RequestUploadURLForAssignment(c *gin.Context) {
 	// Call clientService method (which we created earlier)
 	// Make sure the service.UploadURLResponse matches the DTO api.UploadURLResponse
 	// Or map it here.
-	serviceResponse, err := h.clientService.RequestUploadURL(c.Request.Context(), clientID, assignmentID, req.ContentType)
+	serviceResponse, err := h.clientService.RequestUploadURL(c.Request.Context(), clientID, assignmentID, req.ContentType, req.FileSize)
 	if err != nil {
 		log.Printf("Service Error in RequestUploadURLForAssignment: %v", err)
 		// Map service errors (ErrAssignmentNotFound, ErrAssignmentNotBelongToClient, ErrUploadNotAllowed, ErrUploadURLError)
-		if errors.Is(err, service.ErrAssignmentNotFound) {
+		if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) { // Also unpublished plans
 			abortWithError(c, http.StatusNotFound, err.Error())
         } else if errors.Is(err, service.ErrAssignmentNotBelongToClient) || errors.Is(err, service.ErrUploadNotAllowed) {
             abortWithError(c, http.StatusForbidden, err.Error())
-        } else if errors.Is(err, service.ErrUploadURLError) || errors.Is(err, service.ErrWorkoutNotFound) { // Workout check is now in service
+        } else if isStorageQuotaError(err) {
+            abortWithError(c, http.StatusForbidden, err.Error())
+        } else if errors.Is(err, service.ErrUploadTooLarge) {
+            abortWithError(c, http.StatusRequestEntityTooLarge, err.Error())
+        } else if errors.Is(err, service.ErrInvalidUploadContentType) {
+            abortWithError(c, http.StatusBadRequest, err.Error())
+        } else if errors.Is(err, service.ErrUploadURLError) {
              abortWithError(c, http.StatusInternalServerError, err.Error())
 		} else {
 			abortWithError(c, http.StatusInternalServerError, "Failed to get upload URL.")
@@ -310,7 +341,7 @@ func (h *ClientHandler) RequestUploadURLForAssignment(c *gin.Context) {
 
 // ConfirmUploadForAssignment godoc
 // @Summary Confirm video upload for an assignment
-// @Description Client informs the backend that the S3 upload is complete. Backend updates assignment status.
+// @Description Client informs the backend that the S3 upload is complete. The backend checks that the object exists under this assignment's upload prefix with the reported size and content type, then updates the assignment status.
 // @Tags Client Assignments
 // @Accept json
 // @Produce json
@@ -318,10 +349,11 @@ func (h *ClientHandler) RequestUploadURLForAssignment(c *gin.Context) {
 // @Param assignmentId path string true "Assignment's ObjectID Hex"
 // @Param confirmRequest body ConfirmUploadRequest true "Upload confirmation details"
 // @Success 200 {object} AssignmentResponse "Assignment updated successfully"
-// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 400 {object} gin.H "Invalid input, object not uploaded, or size/content type mismatch"
 // @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client, object key outside its upload prefix, or storage quota used up; the object is then deleted)"
 // @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 409 {object} gin.H "Object already confirmed"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /client/assignments/{assignmentId}/upload-confirm [post]
 func (h *ClientHandler) ConfirmUploadForAssignment(c *gin.Context) {
@@ -350,11 +382,15 @@ func (h *ClientHandler) ConfirmUploadForAssignment(c *gin.Context) {
 	)
 	if err != nil {
 		// Map service errors
-        if errors.Is(err, service.ErrAssignmentNotFound) {
+        if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) {
 			abortWithError(c, http.StatusNotFound, err.Error())
-        } else if errors.Is(err, service.ErrAssignmentNotBelongToClient) {
+        } else if errors.Is(err, service.ErrAssignmentNotBelongToClient) || errors.Is(err, service.ErrUploadObjectKeyInvalid) || isStorageQuotaError(err) {
             abortWithError(c, http.StatusForbidden, err.Error())
-        } else if errors.Is(err, service.ErrUploadConfirmationFailed) || errors.Is(err, service.ErrWorkoutNotFound) {
+        } else if errors.Is(err, service.ErrUploadObjectNotFound) || errors.Is(err, service.ErrUploadMetadataMismatch) || errors.Is(err, service.ErrUploadTooLarge) {
+            abortWithError(c, http.StatusBadRequest, err.Error())
+        } else if errors.Is(err, service.ErrUploadAlreadyConfirmed) {
+            abortWithError(c, http.StatusConflict, err.Error())
+        } else if errors.Is(err, service.ErrUploadConfirmationFailed) {
              abortWithError(c, http.StatusInternalServerError, err.Error())
 		} else {
 			abortWithError(c, http.StatusInternalServerError, "Failed to confirm upload.")
@@ -463,4 +499,604 @@ func (h *ClientHandler) GetMyCurrentWorkouts(c *gin.Context) {
 			 return
 	}
 	c.JSON(http.StatusOK, MapWorkoutsToResponse(workouts)) // Reuse existing mapper
-}
\ No newline at end of file
+}
+// GetExerciseMediaForMyAssignment godoc
+// @Summary Get demonstration media for the exercise in one of my assignments
+// @Description Retrieves the trainer's demonstration videos and images for the assigned exercise, with short-lived download URLs.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {array} ExerciseMediaResponse "List of media items (can be empty)"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/exercise-media [get]
+func (h *ClientHandler) GetExerciseMediaForMyAssignment(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	media, err := h.clientService.GetExerciseMediaForMyAssignment(c.Request.Context(), clientID, assignmentID)
+	if err != nil {
+		if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrAssignmentNotBelongToClient) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to retrieve exercise media.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapExerciseMediaDetailsToResponse(media))
+}
+
+// GetExerciseForMyAssignment godoc
+// @Summary Get the exercise instructions for one of my assignments
+// @Description Returns the exercise revision pinned on the assignment, so later edits by the trainer don't change past instructions.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {object} ExerciseRevisionResponse "Pinned exercise revision"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment or exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/exercise [get]
+func (h *ClientHandler) GetExerciseForMyAssignment(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	rev, err := h.clientService.GetExerciseForMyAssignment(c.Request.Context(), clientID, assignmentID)
+	if err != nil {
+		if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrExerciseNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrAssignmentNotBelongToClient) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to retrieve exercise for assignment.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapExerciseRevisionToResponse(rev))
+}
+
+// GetWorkoutStructureForMyWorkout godoc
+// @Summary Get one of my workouts as nested blocks
+// @Description Returns the workout's blocks (supersets, circuits, EMOM, AMRAP) in order, each with its assignments and their pinned exercise content. Ungrouped exercises appear as straight-set blocks without an ID.
+// @Tags Client
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Success 200 {array} WorkoutBlockResponse "Blocks with nested assignments"
+// @Failure 400 {object} gin.H "Invalid workout ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (workout not assigned to this client)"
+// @Failure 404 {object} gin.H "Workout not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/workouts/{workoutId}/blocks [get]
+func (h *ClientHandler) GetWorkoutStructureForMyWorkout(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID format."); return }
+
+	blocks, err := h.clientService.GetWorkoutStructureForMyWorkout(c.Request.Context(), clientID, workoutID)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkoutNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrWorkoutNotBelongToPlan) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to retrieve workout structure.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapPinnedWorkoutStructureToResponse(blocks))
+}
+
+// LogSetRequest defines the payload for logging one round (or set) of an assignment.
+type LogSetRequest struct {
+	Round    int     `json:"round" binding:"required,min=1"` // Block round, or set number for straight sets
+	Reps     *string `json:"reps" binding:"omitempty"`
+	Weight   *string `json:"weight" binding:"omitempty"`
+	Duration *string `json:"duration" binding:"omitempty"`
+	Notes    *string `json:"notes" binding:"omitempty"`
+}
+
+// LogSetForMyAssignment godoc
+// @Summary Log one round or set of an assignment
+// @Description Records the result of a single round. The round must be within the block's rounds (or the exercise's sets when ungrouped); logging a round again replaces it.
+// @Tags Client Assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param setRequest body LogSetRequest true "Round result"
+// @Success 200 {object} AssignmentResponse "Set logged"
+// @Failure 400 {object} gin.H "Invalid input or round out of range"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/sets [post]
+func (h *ClientHandler) LogSetForMyAssignment(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req LogSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	setLog := domain.SetLog{
+		Round:    req.Round,
+		Reps:     req.Reps,
+		Weight:   req.Weight,
+		Duration: req.Duration,
+		Notes:    req.Notes,
+	}
+	updatedAssignment, err := h.clientService.LogSetForMyAssignment(c.Request.Context(), clientID, assignmentID, setLog)
+	if err != nil {
+		if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrAssignmentNotBelongToClient) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else if errors.Is(err, service.ErrInvalidSetRound) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to log set.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentToResponse(updatedAssignment))
+}
+
+// --- DTOs for Upload History ---
+
+// UploadResponse is one upload of an assignment, shared by client and trainer endpoints.
+type UploadResponse struct {
+	ID           string    `json:"id"`
+	AssignmentID string    `json:"assignmentId"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Attempt      int       `json:"attempt,omitempty"`
+	IsPrimary    bool      `json:"isPrimary"`
+	UploadedAt   time.Time `json:"uploadedAt"`
+	DownloadURL  string    `json:"downloadUrl"` // Short-lived URL
+}
+
+// MapUploadDetailsToResponse converts a slice of service.UploadDetails to UploadResponse DTOs.
+func MapUploadDetailsToResponse(uploads []service.UploadDetails) []UploadResponse {
+	responses := make([]UploadResponse, len(uploads))
+	for i, u := range uploads {
+		responses[i] = UploadResponse{
+			ID:           u.ID.Hex(),
+			AssignmentID: u.AssignmentID.Hex(),
+			FileName:     u.FileName,
+			ContentType:  u.ContentType,
+			Size:         u.Size,
+			Attempt:      u.Attempt,
+			IsPrimary:    u.IsPrimary,
+			UploadedAt:   u.UploadedAt,
+			DownloadURL:  u.DownloadURL,
+		}
+	}
+	return responses
+}
+
+// abortWithUploadError maps upload history service errors to HTTP responses.
+func abortWithUploadError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrUploadNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrAssignmentNotBelongToClient) || errors.Is(err, service.ErrAssignmentAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Upload History ---
+
+// GetMyUploadsForAssignment godoc
+// @Summary List all my uploads for an assignment
+// @Description Returns every video uploaded for the assignment (retries, other angles), oldest first, with short-lived download URLs. The primary upload is the one the trainer reviews.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {array} UploadResponse "List of uploads (can be empty)"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/uploads [get]
+func (h *ClientHandler) GetMyUploadsForAssignment(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	uploads, err := h.clientService.GetMyUploadsForAssignment(c.Request.Context(), clientID, assignmentID)
+	if err != nil {
+		abortWithUploadError(c, err, "Failed to retrieve uploads.")
+		return
+	}
+	c.JSON(http.StatusOK, MapUploadDetailsToResponse(uploads))
+}
+
+// SetMyPrimaryUpload godoc
+// @Summary Mark one of my uploads as primary
+// @Description Chooses which upload of the assignment the trainer reviews.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param uploadId path string true "Upload's ObjectID Hex"
+// @Success 200 {object} AssignmentResponse "Assignment with the new primary upload"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment or upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/uploads/{uploadId}/primary [put]
+func (h *ClientHandler) SetMyPrimaryUpload(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+	uploadID, err := primitive.ObjectIDFromHex(c.Param("uploadId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid upload ID."); return }
+
+	assignment, err := h.clientService.SetMyPrimaryUpload(c.Request.Context(), clientID, assignmentID, uploadID)
+	if err != nil {
+		abortWithUploadError(c, err, "Failed to set primary upload.")
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentToResponse(assignment))
+}
+
+// DeleteMyUpload godoc
+// @Summary Delete one of my uploads
+// @Description Deletes the video from storage and its record. If it was the primary upload, the newest remaining upload becomes primary; if none remain, a submitted assignment returns to assigned.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param uploadId path string true "Upload's ObjectID Hex"
+// @Success 200 {object} AssignmentResponse "Assignment after the deletion"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment or upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error (e.g., storage error)"
+// @Router /client/assignments/{assignmentId}/uploads/{uploadId} [delete]
+func (h *ClientHandler) DeleteMyUpload(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+	uploadID, err := primitive.ObjectIDFromHex(c.Param("uploadId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid upload ID."); return }
+
+	assignment, err := h.clientService.DeleteMyUpload(c.Request.Context(), clientID, assignmentID, uploadID)
+	if err != nil {
+		abortWithUploadError(c, err, "Failed to delete upload.")
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentToResponse(assignment))
+}
+
+// GetMyFeedbackAttachments godoc
+// @Summary List my trainer's video and voice replies on an assignment
+// @Description Returns the demonstration videos and voice notes the trainer attached to their feedback, oldest first, with short-lived download URLs.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {array} FeedbackAttachmentResponse "Attachments (can be empty)"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/feedback-attachments [get]
+func (h *ClientHandler) GetMyFeedbackAttachments(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	attachments, err := h.clientService.GetMyFeedbackAttachments(c.Request.Context(), clientID, assignmentID)
+	if err != nil {
+		abortWithFeedbackAttachmentError(c, err, "Failed to retrieve feedback attachments.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackAttachmentDetailsToResponse(attachments))
+}
+
+// --- DTOs for Multipart / Resumable Uploads ---
+
+// InitiateMultipartUploadRequest starts a multipart upload for a large video.
+type InitiateMultipartUploadRequest struct {
+	ContentType string `json:"contentType" binding:"required"`
+	FileSize    int64  `json:"fileSize" binding:"required,min=1"`
+}
+
+// MultipartUploadRef identifies an in-progress multipart upload.
+type MultipartUploadRef struct {
+	ObjectKey string `json:"objectKey" form:"objectKey" binding:"required"`
+	UploadID  string `json:"uploadId" form:"uploadId" binding:"required"`
+}
+
+// PresignPartsRequest asks for upload URLs for specific parts.
+type PresignPartsRequest struct {
+	MultipartUploadRef
+	PartNumbers []int `json:"partNumbers" binding:"required,min=1,dive,min=1"`
+}
+
+// CompleteMultipartUploadRequest finishes a multipart upload; the fields mirror ConfirmUploadRequest.
+type CompleteMultipartUploadRequest struct {
+	MultipartUploadRef
+	FileName    string `json:"fileName" binding:"required"`
+	FileSize    int64  `json:"fileSize" binding:"required,min=1"`
+	ContentType string `json:"contentType" binding:"required"`
+}
+
+// UploadedPartResponse is one part storage has already received.
+type UploadedPartResponse struct {
+	PartNumber int32     `json:"partNumber"`
+	ETag       string    `json:"etag"`
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// isStorageQuotaError reports whether err is a client or trainer storage quota error.
+func isStorageQuotaError(err error) bool {
+	return errors.Is(err, service.ErrClientStorageQuotaExceeded) || errors.Is(err, service.ErrTrainerStorageQuotaExceeded)
+}
+
+// abortWithMultipartUploadError maps multipart upload service errors to HTTP responses.
+func abortWithMultipartUploadError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrMultipartUploadNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrAssignmentNotBelongToClient) || errors.Is(err, service.ErrUploadNotAllowed) || errors.Is(err, service.ErrUploadObjectKeyInvalid) || isStorageQuotaError(err) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrInvalidUploadContentType) || errors.Is(err, service.ErrInvalidUploadPart) ||
+		errors.Is(err, service.ErrMultipartUploadIncomplete) || errors.Is(err, service.ErrUploadObjectNotFound) || errors.Is(err, service.ErrUploadMetadataMismatch) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, service.ErrUploadTooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, err.Error())
+	} else if errors.Is(err, service.ErrUploadAlreadyConfirmed) {
+		abortWithError(c, http.StatusConflict, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Multipart / Resumable Uploads ---
+
+// InitiateMultipartUpload godoc
+// @Summary Start a multipart upload for a large video
+// @Description Starts a resumable upload. The response gives the part size and count; request part URLs, PUT each part, then complete. Rejects files over the maximum file size or the remaining storage quota.
+// @Tags Client Assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param uploadRequest body InitiateMultipartUploadRequest true "Content type and total size"
+// @Success 201 {object} service.MultipartUploadSession "Upload ID, object key and part layout"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (assignment not for this client, or upload not allowed for status)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 413 {object} gin.H "File exceeds the size limit for its content type"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/multipart-uploads [post]
+func (h *ClientHandler) InitiateMultipartUpload(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req InitiateMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	session, err := h.clientService.InitiateMultipartUpload(c.Request.Context(), clientID, assignmentID, req.ContentType, req.FileSize)
+	if err != nil {
+		abortWithMultipartUploadError(c, err, "Failed to start multipart upload.")
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+// PresignMultipartParts godoc
+// @Summary Get upload URLs for parts of a multipart upload
+// @Description Returns a pre-signed PUT URL for each requested part number (at most 100 per call). Clients need not track part ETags; the server reads the parts back from storage on completion.
+// @Tags Client Assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param partsRequest body PresignPartsRequest true "Upload reference and part numbers"
+// @Success 200 {array} service.PresignedPart "Part URLs"
+// @Failure 400 {object} gin.H "Invalid input or part number"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden"
+// @Failure 404 {object} gin.H "Assignment or multipart upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/multipart-uploads/parts [post]
+func (h *ClientHandler) PresignMultipartParts(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req PresignPartsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	parts, err := h.clientService.PresignMultipartParts(c.Request.Context(), clientID, assignmentID, req.ObjectKey, req.UploadID, req.PartNumbers)
+	if err != nil {
+		abortWithMultipartUploadError(c, err, "Failed to generate part upload URLs.")
+		return
+	}
+	c.JSON(http.StatusOK, parts)
+}
+
+// ListMultipartParts godoc
+// @Summary List the parts already uploaded (resume)
+// @Description Returns the parts storage has received so an interrupted upload can continue with the missing ones.
+// @Tags Client Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param objectKey query string true "Object key from the initiate response"
+// @Param uploadId query string true "Upload ID from the initiate response"
+// @Success 200 {array} UploadedPartResponse "Uploaded parts, by part number"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden"
+// @Failure 404 {object} gin.H "Assignment or multipart upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/multipart-uploads/parts [get]
+func (h *ClientHandler) ListMultipartParts(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var ref MultipartUploadRef
+	if err := c.ShouldBindQuery(&ref); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	parts, err := h.clientService.ListMultipartParts(c.Request.Context(), clientID, assignmentID, ref.ObjectKey, ref.UploadID)
+	if err != nil {
+		abortWithMultipartUploadError(c, err, "Failed to list uploaded parts.")
+		return
+	}
+
+	responses := make([]UploadedPartResponse, len(parts))
+	for i, p := range parts {
+		responses[i] = UploadedPartResponse{PartNumber: p.PartNumber, ETag: p.ETag, Size: p.Size, UploadedAt: p.LastModified}
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// CompleteMultipartUpload godoc
+// @Summary Complete a multipart upload
+// @Description Assembles the uploaded parts (which must run 1..N and add up to fileSize), then confirms the upload like upload-confirm.
+// @Tags Client Assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param completeRequest body CompleteMultipartUploadRequest true "Upload reference and file details"
+// @Success 200 {object} AssignmentResponse "Assignment updated successfully"
+// @Failure 400 {object} gin.H "Invalid input or missing parts"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden"
+// @Failure 404 {object} gin.H "Assignment or multipart upload not found"
+// @Failure 409 {object} gin.H "Object already confirmed"
+// @Failure 413 {object} gin.H "File exceeds the size limit for its content type"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/multipart-uploads/complete [post]
+func (h *ClientHandler) CompleteMultipartUpload(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req CompleteMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	assignment, err := h.clientService.CompleteMultipartUpload(c.Request.Context(), clientID, assignmentID,
+		req.ObjectKey, req.UploadID, req.FileName, req.FileSize, req.ContentType)
+	if err != nil {
+		abortWithMultipartUploadError(c, err, "Failed to complete multipart upload.")
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentToResponse(assignment))
+}
+
+// AbortMultipartUpload godoc
+// @Summary Abort a multipart upload
+// @Description Discards an unfinished multipart upload and any parts already stored.
+// @Tags Client Assignments
+// @Accept json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param abortRequest body MultipartUploadRef true "Upload reference"
+// @Success 204 "Aborted"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden"
+// @Failure 404 {object} gin.H "Assignment or multipart upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/assignments/{assignmentId}/multipart-uploads/abort [post]
+func (h *ClientHandler) AbortMultipartUpload(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req MultipartUploadRef
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.clientService.AbortMultipartUpload(c.Request.Context(), clientID, assignmentID, req.ObjectKey, req.UploadID); err != nil {
+		abortWithMultipartUploadError(c, err, "Failed to abort multipart upload.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceHandler registers the signed-in user's phones and tablets for push notifications.
+type DeviceHandler struct {
+	deviceService service.DeviceService
+}
+
+// NewDeviceHandler creates a new DeviceHandler.
+func NewDeviceHandler(deviceService service.DeviceService) *DeviceHandler {
+	return &DeviceHandler{deviceService: deviceService}
+}
+
+// --- DTOs for Devices ---
+
+// RegisterDeviceRequest registers a push token.
+type RegisterDeviceRequest struct {
+	Platform   string `json:"platform" binding:"required,oneof=ios android"`
+	Token      string `json:"token" binding:"required"` // APNs device token (hex) or FCM registration token
+	AppVersion string `json:"appVersion,omitempty"`
+}
+
+// DeviceResponse is a registered device.
+type DeviceResponse struct {
+	ID         string    `json:"id"`
+	Platform   string    `json:"platform"`
+	Token      string    `json:"token"`
+	AppVersion string    `json:"appVersion,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// MapDeviceToResponse converts a domain.Device to a DeviceResponse DTO.
+func MapDeviceToResponse(d *domain.Device) DeviceResponse {
+	return DeviceResponse{
+		ID:         d.ID.Hex(),
+		Platform:   string(d.Platform),
+		Token:      d.Token,
+		AppVersion: d.AppVersion,
+		CreatedAt:  d.CreatedAt,
+		LastSeenAt: d.LastSeenAt,
+	}
+}
+
+// --- Handler Methods ---
+
+// RegisterDevice godoc
+// @Summary Register a device for push notifications
+// @Description Adds the device or refreshes its registration. Call on every app launch and whenever APNs/FCM issues a new token. A token registered by another account moves to the caller.
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param device body RegisterDeviceRequest true "Platform and push token"
+// @Success 200 {object} DeviceResponse "Registered device"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /devices [post]
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	device, err := h.deviceService.RegisterDevice(c.Request.Context(), userID, service.DeviceInput{
+		Platform:   domain.DevicePlatform(req.Platform),
+		Token:      req.Token,
+		AppVersion: req.AppVersion,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDevice) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to register device.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapDeviceToResponse(device))
+}
+
+// ListDevices godoc
+// @Summary List my devices
+// @Description Lists the caller's devices registered for push notifications, most recently seen first.
+// @Tags Devices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} DeviceResponse "Registered devices"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /devices [get]
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	devices, err := h.deviceService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to retrieve devices.")
+		return
+	}
+	resp := make([]DeviceResponse, len(devices))
+	for i := range devices {
+		resp[i] = MapDeviceToResponse(&devices[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister a device
+// @Description Stops push notifications to the device, e.g. when the user signs out.
+// @Tags Devices
+// @Security BearerAuth
+// @Param token path string true "Push token"
+// @Success 204 "Device unregistered"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 404 {object} gin.H "Device not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /devices/{token} [delete]
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	if err := h.deviceService.UnregisterDevice(c.Request.Context(), userID, c.Param("token")); err != nil {
+		if errors.Is(err, service.ErrDeviceNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to unregister device.")
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
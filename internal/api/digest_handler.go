@@ -0,0 +1,178 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigestHandler serves the signed-in trainer's weekly email digest settings and preview.
+type DigestHandler struct {
+	digestService service.DigestService
+}
+
+// NewDigestHandler creates a new DigestHandler.
+func NewDigestHandler(digestService service.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+// --- DTOs for the Digest ---
+
+// DigestSettingsRequest changes digest settings. Omitted fields are left unchanged; an
+// empty email or time zone restores the default.
+type DigestSettingsRequest struct {
+	Enabled  *bool   `json:"enabled,omitempty"`
+	Email    *string `json:"email,omitempty"`    // Empty = the account email
+	TimeZone *string `json:"timeZone,omitempty"` // IANA name, e.g. "Europe/Berlin"
+}
+
+// DigestSettingsResponse is the trainer's digest settings.
+type DigestSettingsResponse struct {
+	Enabled   bool       `json:"enabled"`
+	Email     string     `json:"email,omitempty"` // Empty = the account email
+	TimeZone  string     `json:"timeZone"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// DigestPreviewResponse is a digest with the email it would be sent as.
+type DigestPreviewResponse struct {
+	To      string                 `json:"to"`
+	Subject string                 `json:"subject"`
+	Digest  *service.TrainerDigest `json:"digest"`
+	HTML    string                 `json:"html"`
+	Text    string                 `json:"text"`
+}
+
+// MapDigestSettingsToResponse converts domain.DigestSettings to a DigestSettingsResponse DTO.
+func MapDigestSettingsToResponse(s *domain.DigestSettings) DigestSettingsResponse {
+	resp := DigestSettingsResponse{
+		Enabled:  s.Enabled,
+		Email:    s.Email,
+		TimeZone: s.Location().String(),
+	}
+	if !s.UpdatedAt.IsZero() {
+		updatedAt := s.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+// --- Handler Methods ---
+
+// GetDigestSettings godoc
+// @Summary Get my weekly digest settings
+// @Description The weekly digest is off until the trainer turns it on.
+// @Tags Trainer-Digest
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} DigestSettingsResponse "Digest settings"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (User is not a trainer)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/digest/settings [get]
+func (h *DigestHandler) GetDigestSettings(c *gin.Context) {
+	trainerID, ok := currentUserID(c)
+	if !ok { return }
+
+	settings, err := h.digestService.GetSettings(c.Request.Context(), trainerID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to load digest settings.")
+		return
+	}
+	c.JSON(http.StatusOK, MapDigestSettingsToResponse(settings))
+}
+
+// UpdateDigestSettings godoc
+// @Summary Update my weekly digest settings
+// @Description Turns the Monday email summary of the previous week on or off and sets where it goes and which time zone weeks run in.
+// @Tags Trainer-Digest
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body DigestSettingsRequest true "Settings to change"
+// @Success 200 {object} DigestSettingsResponse "Updated digest settings"
+// @Failure 400 {object} gin.H "Invalid email or time zone"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (User is not a trainer)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/digest/settings [put]
+func (h *DigestHandler) UpdateDigestSettings(c *gin.Context) {
+	trainerID, ok := currentUserID(c)
+	if !ok { return }
+
+	var req DigestSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.digestService.UpdateSettings(c.Request.Context(), trainerID, service.DigestSettingsInput{
+		Enabled:  req.Enabled,
+		Email:    req.Email,
+		TimeZone: req.TimeZone,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDigestSettings) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to save digest settings.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapDigestSettingsToResponse(settings))
+}
+
+// PreviewDigest godoc
+// @Summary Preview my weekly digest
+// @Description Builds the digest without sending it: for the last full week by default, or for the week (Monday to Sunday) containing weekOf. format=html or format=text returns the email body itself.
+// @Tags Trainer-Digest
+// @Produce json,html,plain
+// @Security BearerAuth
+// @Param weekOf query string false "Any date in the week to preview (YYYY-MM-DD)"
+// @Param format query string false "json (default), html or text"
+// @Success 200 {object} DigestPreviewResponse "Digest and rendered email"
+// @Failure 400 {object} gin.H "Invalid weekOf or format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (User is not a trainer)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/digest/preview [get]
+func (h *DigestHandler) PreviewDigest(c *gin.Context) {
+	trainerID, ok := currentUserID(c)
+	if !ok { return }
+
+	var weekOf *time.Time
+	if raw := c.Query("weekOf"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid weekOf, expected YYYY-MM-DD."); return }
+		weekOf = &parsed
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "html" && format != "text" {
+		abortWithError(c, http.StatusBadRequest, "Invalid format, expected json, html or text.")
+		return
+	}
+
+	rendered, err := h.digestService.Preview(c.Request.Context(), trainerID, weekOf)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to build digest.")
+		return
+	}
+	switch format {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(rendered.Text))
+	default:
+		c.JSON(http.StatusOK, DigestPreviewResponse{
+			To:      rendered.To,
+			Subject: rendered.Subject,
+			Digest:  rendered.Digest,
+			HTML:    rendered.HTML,
+			Text:    rendered.Text,
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/realtime"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// eventStreamKeepAlive is how often an idle stream sends a comment line, so proxies
+// and mobile networks don't close it.
+const eventStreamKeepAlive = 25 * time.Second
+
+// EventsHandler streams realtime events to the signed-in trainer or client.
+type EventsHandler struct {
+	broker realtime.Broker
+}
+
+// NewEventsHandler creates a new EventsHandler.
+func NewEventsHandler(broker realtime.Broker) *EventsHandler {
+	return &EventsHandler{broker: broker}
+}
+
+// StreamEvents godoc
+// @Summary Stream realtime events
+// @Description Server-Sent Events stream of the caller's events: assignment.feedback, assignment.status_changed, upload.created, plan.created, plan.updated, message.created, client.linked, workout.reminder, workout.missed and plan.ending. Each event's data is JSON with the IDs to refetch. Authenticate with the Bearer header or, for EventSource, the access_token query parameter. Events are not replayed; refetch after reconnecting.
+// @Tags Realtime
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param access_token query string false "JWT, when the Authorization header cannot be set"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Router /events [get]
+func (h *EventsHandler) StreamEvents(c *gin.Context) {
+	userIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+
+	// The stream outlives the server's WriteTimeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	sub := h.broker.Subscribe(userID)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
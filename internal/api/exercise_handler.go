@@ -5,6 +5,7 @@ import (
 	"alcyxob/fitness-app/internal/service"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -35,6 +36,14 @@ type CreateExerciseRequest struct {
 	VideoURL         string `json:"videoUrl" binding:"omitempty,url"` // Optional, validated as URL if provided
 }
 
+// UpdateExerciseRequest extends the create DTO with revision handling options.
+type UpdateExerciseRequest struct {
+	CreateExerciseRequest
+	// UpgradeOpenAssignments moves not-yet-started assignments to the new revision.
+	// Started or completed assignments always keep the revision they were created with.
+	UpgradeOpenAssignments bool `json:"upgradeOpenAssignments"`
+}
+
 // ExerciseResponse is the DTO for returning exercise details.
 // Matches the Swift Exercise struct.
 type ExerciseResponse struct {
@@ -47,6 +56,7 @@ type ExerciseResponse struct {
 	Applicability    string    `json:"applicability,omitempty"`
 	Difficulty       string    `json:"difficulty,omitempty"`
 	VideoURL         string    `json:"videoUrl,omitempty"`
+	CurrentRevision  int       `json:"currentRevision"`
 	CreatedAt        time.Time `json:"createdAt"`
 	UpdatedAt        time.Time `json:"updatedAt"`
 }
@@ -66,6 +76,7 @@ func MapExerciseToResponse(ex *domain.Exercise) ExerciseResponse {
 		Applicability:    ex.Applicability,
 		Difficulty:       ex.Difficulty,
 		VideoURL:         ex.VideoURL,
+		CurrentRevision:  ex.CurrentRevision,
 		CreatedAt:        ex.CreatedAt,
 		UpdatedAt:        ex.UpdatedAt,
 	}
@@ -184,7 +195,7 @@ func (h *ExerciseHandler) GetTrainerExercises(c *gin.Context) {
 
 // GetExerciseByID godoc
 // @Summary Get a specific exercise by ID
-// @Description Retrieves details for a single exercise.
+// @Description Retrieves the current details of a single exercise (trainers only). Clients get the exercise content pinned on their assignments instead.
 // @Tags Exercises
 // @Produce json
 // @Security BearerAuth
@@ -192,6 +203,7 @@ func (h *ExerciseHandler) GetTrainerExercises(c *gin.Context) {
 // @Success 200 {object} ExerciseResponse
 // @Failure 400 {object} gin.H "Invalid ID format"
 // @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
 // @Failure 404 {object} gin.H "Exercise not found"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /exercises/{id} [get]
@@ -227,7 +239,7 @@ func (h *ExerciseHandler) GetExerciseByID(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Exercise ObjectID Hex"
-// @Param exercise body CreateExerciseRequest true "Updated exercise details"
+// @Param exercise body UpdateExerciseRequest true "Updated exercise details"
 // @Success 200 {object} ExerciseResponse "Exercise updated successfully"
 // @Failure 400 {object} gin.H "Invalid input (validation error, invalid ID)"
 // @Failure 401 {object} gin.H "Unauthorized"
@@ -243,7 +255,7 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 		return
 	}
 
-	var req CreateExerciseRequest // Reusing Create DTO
+	var req UpdateExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
 		return
@@ -267,6 +279,7 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 		req.Applicability,
 		req.Difficulty,
 		req.VideoURL,
+		req.UpgradeOpenAssignments,
 	)
 	if err != nil {
 		if errors.Is(err, service.ErrExerciseNotFound) {
@@ -287,16 +300,18 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 
 // DeleteExercise godoc
 // @Summary Delete an exercise
-// @Description Deletes an exercise owned by the authenticated trainer.
+// @Description Deletes an exercise owned by the authenticated trainer. Exercises still used by assignments are rejected with 409 unless force=true, which also deletes those assignments with their uploads and feedback.
 // @Tags Exercises
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Exercise ObjectID Hex"
-// @Success 200 {object} gin.H "message: Exercise deleted successfully" // Or 204 No Content
+// @Param force query bool false "Also delete assignments that use the exercise, with their uploads and feedback"
+// @Success 200 {object} gin.H "message: Exercise deleted successfully, deletedAssignments: count"
 // @Failure 400 {object} gin.H "Invalid ID format"
 // @Failure 401 {object} gin.H "Unauthorized"
 // @Failure 403 {object} gin.H "Forbidden (not a trainer, or does not own the exercise)"
 // @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 409 {object} gin.H "Exercise is in use (see GET /exercises/{id}/usage)"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /exercises/{id} [delete]
 func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
@@ -307,6 +322,14 @@ func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
 		return
 	}
 
+	force := false
+	if forceStr := c.Query("force"); forceStr != "" {
+		if force, err = strconv.ParseBool(forceStr); err != nil {
+			abortWithError(c, http.StatusBadRequest, "Invalid 'force' value; expected true or false.")
+			return
+		}
+	}
+
 	trainerIDStr, err := getUserIDFromContext(c)
 	if err != nil {
 		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
@@ -314,19 +337,618 @@ func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
 	}
 	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr) // Assume valid
 
-	err = h.exerciseService.DeleteExercise(c.Request.Context(), trainerID, exerciseID)
+	deletedAssignments, err := h.exerciseService.DeleteExercise(c.Request.Context(), trainerID, exerciseID, force)
 	if err != nil {
 		if errors.Is(err, service.ErrExerciseNotFound) { // Service maps repo's ErrNotFound
 			abortWithError(c, http.StatusNotFound, "Exercise not found or access denied.")
 		} else if errors.Is(err, service.ErrExerciseAccessDenied) { // If service distinguishes
             abortWithError(c, http.StatusForbidden, err.Error())
-        } else {
+        } else if errors.Is(err, service.ErrExerciseInUse) {
+			abortWithError(c, http.StatusConflict, "Exercise is used by existing assignments. Merge it into another exercise or delete with force=true.")
+		} else {
 			// log.Printf("Error deleting exercise %s: %v", exerciseIDHex, err)
 			abortWithError(c, http.StatusInternalServerError, "Failed to delete exercise.")
 		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Exercise deleted successfully"})
-	// Alternatively, return http.StatusNoContent with no body:
-	// c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, gin.H{"message": "Exercise deleted successfully", "deletedAssignments": deletedAssignments})
+}
+
+// MergeExercisesRequest defines the payload for merging a duplicate exercise into another.
+type MergeExercisesRequest struct {
+	TargetExerciseID string `json:"targetExerciseId" binding:"required"` // Exercise that keeps all assignments
+}
+
+// abortWithExerciseUsageError maps usage and merge service errors to HTTP responses.
+func abortWithExerciseUsageError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrExerciseNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrExerciseAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrMergeSameExercise) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// GetExerciseUsage godoc
+// @Summary Show where an exercise is used
+// @Description Lists the plans, workouts and clients that reference the exercise through assignments.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Success 200 {object} service.ExerciseUsage "Usage report"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/usage [get]
+func (h *ExerciseHandler) GetExerciseUsage(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	usage, err := h.exerciseService.GetExerciseUsage(c.Request.Context(), trainerID, exerciseID)
+	if err != nil {
+		abortWithExerciseUsageError(c, err, "Failed to retrieve exercise usage.")
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// MergeExercises godoc
+// @Summary Merge a duplicate exercise into another
+// @Description Re-points all assignments of this exercise to the target exercise (pinned to its current revision), then deletes this exercise.
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Duplicate (source) exercise ObjectID Hex"
+// @Param merge body MergeExercisesRequest true "Target exercise"
+// @Success 200 {object} gin.H "movedAssignments: count"
+// @Failure 400 {object} gin.H "Invalid ID format or same exercise"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own one of the exercises)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/merge [post]
+func (h *ExerciseHandler) MergeExercises(c *gin.Context) {
+	sourceID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	var req MergeExercisesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	targetID, err := primitive.ObjectIDFromHex(req.TargetExerciseID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid target exercise ID format.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	moved, err := h.exerciseService.MergeExercises(c.Request.Context(), trainerID, sourceID, targetID)
+	if err != nil {
+		abortWithExerciseUsageError(c, err, "Failed to merge exercises.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exercises merged successfully", "movedAssignments": moved})
+}
+
+// --- DTOs for Exercise Media ---
+
+// RequestExerciseMediaUploadURLRequest defines the payload for requesting a media upload URL.
+type RequestExerciseMediaUploadURLRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=video image thumbnail"`
+	ContentType string `json:"contentType" binding:"required"`
+}
+
+// ConfirmExerciseMediaUploadRequest defines the payload for confirming a media upload.
+type ConfirmExerciseMediaUploadRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=video image thumbnail"`
+	ObjectKey   string `json:"objectKey" binding:"required"`
+	FileName    string `json:"fileName" binding:"required"`
+	FileSize    int64  `json:"fileSize" binding:"required,min=1"`
+	ContentType string `json:"contentType" binding:"required"`
+	Sequence    int    `json:"sequence" binding:"omitempty,min=0"` // Display order, defaults to 0
+}
+
+// ExerciseMediaResponse is the DTO for returning exercise media details.
+type ExerciseMediaResponse struct {
+	ID          string    `json:"id"`
+	ExerciseID  string    `json:"exerciseId"`
+	Kind        string    `json:"kind"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Sequence    int       `json:"sequence"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+	DownloadURL string    `json:"downloadUrl,omitempty"` // Short-lived URL, only set on list endpoints
+}
+
+// MapExerciseMediaToResponse converts domain.ExerciseMedia to ExerciseMediaResponse DTO.
+func MapExerciseMediaToResponse(m *domain.ExerciseMedia) ExerciseMediaResponse {
+	if m == nil {
+		return ExerciseMediaResponse{}
+	}
+	return ExerciseMediaResponse{
+		ID:          m.ID.Hex(),
+		ExerciseID:  m.ExerciseID.Hex(),
+		Kind:        string(m.Kind),
+		FileName:    m.FileName,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Sequence:    m.Sequence,
+		UploadedAt:  m.UploadedAt,
+	}
+}
+
+// MapExerciseMediaDetailsToResponse converts media with download URLs to DTOs.
+func MapExerciseMediaDetailsToResponse(details []service.ExerciseMediaDetails) []ExerciseMediaResponse {
+	responses := make([]ExerciseMediaResponse, len(details))
+	for i, d := range details {
+		responses[i] = MapExerciseMediaToResponse(&d.ExerciseMedia)
+		responses[i].DownloadURL = d.DownloadURL
+	}
+	return responses
+}
+
+// abortWithExerciseMediaError maps exercise media service errors to HTTP responses.
+func abortWithExerciseMediaError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrExerciseNotFound) || errors.Is(err, service.ErrExerciseMediaNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrExerciseAccessDenied) || errors.Is(err, service.ErrMediaObjectKeyMismatch) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrInvalidMediaKind) || errors.Is(err, service.ErrInvalidMediaContentType) ||
+		errors.Is(err, service.ErrUploadObjectNotFound) || errors.Is(err, service.ErrUploadMetadataMismatch) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, service.ErrUploadAlreadyConfirmed) {
+		abortWithError(c, http.StatusConflict, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Exercise Media ---
+
+// RequestExerciseMediaUploadURL godoc
+// @Summary Request a pre-signed URL to upload demonstration media for an exercise
+// @Description Trainer requests a temporary URL to upload a video, image or thumbnail directly to S3.
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Param uploadRequest body RequestExerciseMediaUploadURLRequest true "Media kind and content type"
+// @Success 200 {object} service.UploadURLResponse "Pre-signed URL and object key"
+// @Failure 400 {object} gin.H "Invalid input (bad kind or content type)"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error (e.g., S3 error)"
+// @Router /exercises/{id}/media/upload-url [post]
+func (h *ExerciseHandler) RequestExerciseMediaUploadURL(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+
+	var req RequestExerciseMediaUploadURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr) // Assume valid if token good
+
+	resp, err := h.exerciseService.RequestMediaUploadURL(c.Request.Context(), trainerID, exerciseID, domain.MediaKind(req.Kind), req.ContentType)
+	if err != nil {
+		abortWithExerciseMediaError(c, err, "Failed to get media upload URL.")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ConfirmExerciseMediaUpload godoc
+// @Summary Confirm a demonstration media upload for an exercise
+// @Description Trainer informs the backend that the S3 upload is complete so the media is attached to the exercise.
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Param confirmRequest body ConfirmExerciseMediaUploadRequest true "Upload confirmation details"
+// @Success 201 {object} ExerciseMediaResponse "Media attached to exercise"
+// @Failure 400 {object} gin.H "Invalid input, object not uploaded, or size/content type not matching the stored object"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise, or foreign object key)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 409 {object} gin.H "Object already confirmed"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/media/upload-confirm [post]
+func (h *ExerciseHandler) ConfirmExerciseMediaUpload(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+
+	var req ConfirmExerciseMediaUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	media, err := h.exerciseService.ConfirmMediaUpload(
+		c.Request.Context(),
+		trainerID,
+		exerciseID,
+		domain.MediaKind(req.Kind),
+		req.ObjectKey,
+		req.FileName,
+		req.FileSize,
+		req.ContentType,
+		req.Sequence,
+	)
+	if err != nil {
+		abortWithExerciseMediaError(c, err, "Failed to confirm media upload.")
+		return
+	}
+	c.JSON(http.StatusCreated, MapExerciseMediaToResponse(media))
+}
+
+// GetExerciseMedia godoc
+// @Summary List demonstration media for an exercise
+// @Description Retrieves all media attached to an exercise owned by the trainer, with short-lived download URLs.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Success 200 {array} ExerciseMediaResponse "List of media items"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/media [get]
+func (h *ExerciseHandler) GetExerciseMedia(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	media, err := h.exerciseService.GetExerciseMedia(c.Request.Context(), trainerID, exerciseID)
+	if err != nil {
+		abortWithExerciseMediaError(c, err, "Failed to retrieve exercise media.")
+		return
+	}
+	c.JSON(http.StatusOK, MapExerciseMediaDetailsToResponse(media))
+}
+
+// DeleteExerciseMedia godoc
+// @Summary Delete a demonstration media item
+// @Description Removes a media item from an exercise and deletes the file from storage.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Param mediaId path string true "Media ObjectID Hex"
+// @Success 200 {object} gin.H "message: Media deleted successfully"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise or media not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/media/{mediaId} [delete]
+func (h *ExerciseHandler) DeleteExerciseMedia(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	mediaID, err := primitive.ObjectIDFromHex(c.Param("mediaId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid media ID format.")
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	if err := h.exerciseService.DeleteExerciseMedia(c.Request.Context(), trainerID, exerciseID, mediaID); err != nil {
+		abortWithExerciseMediaError(c, err, "Failed to delete exercise media.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
+}
+
+// --- DTOs for Exercise Revisions ---
+
+// ExerciseRevisionResponse is the DTO for one immutable revision of an exercise.
+type ExerciseRevisionResponse struct {
+	ExerciseID       string    `json:"exerciseId"`
+	Revision         int       `json:"revision"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description,omitempty"`
+	MuscleGroup      string    `json:"muscleGroup,omitempty"`
+	ExecutionTechnic string    `json:"executionTechnic,omitempty"`
+	Applicability    string    `json:"applicability,omitempty"`
+	Difficulty       string    `json:"difficulty,omitempty"`
+	VideoURL         string    `json:"videoUrl,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// ExerciseRevisionDiffResponse lists the fields changed between two revisions.
+type ExerciseRevisionDiffResponse struct {
+	ExerciseID string                        `json:"exerciseId"`
+	From       int                           `json:"from"`
+	To         int                           `json:"to"`
+	Changes    []service.ExerciseFieldChange `json:"changes"`
+}
+
+// MapExerciseRevisionToResponse converts a domain.ExerciseRevision to ExerciseRevisionResponse DTO.
+func MapExerciseRevisionToResponse(rev *domain.ExerciseRevision) ExerciseRevisionResponse {
+	if rev == nil {
+		return ExerciseRevisionResponse{}
+	}
+	return ExerciseRevisionResponse{
+		ExerciseID:       rev.ExerciseID.Hex(),
+		Revision:         rev.Revision,
+		Name:             rev.Name,
+		Description:      rev.Description,
+		MuscleGroup:      rev.MuscleGroup,
+		ExecutionTechnic: rev.ExecutionTechnic,
+		Applicability:    rev.Applicability,
+		Difficulty:       rev.Difficulty,
+		VideoURL:         rev.VideoURL,
+		CreatedAt:        rev.CreatedAt,
+	}
+}
+
+// MapExerciseRevisionsToResponse converts a slice of domain.ExerciseRevision to DTOs.
+func MapExerciseRevisionsToResponse(revs []domain.ExerciseRevision) []ExerciseRevisionResponse {
+	responses := make([]ExerciseRevisionResponse, len(revs))
+	for i, rev := range revs {
+		responses[i] = MapExerciseRevisionToResponse(&rev)
+	}
+	return responses
+}
+
+// abortWithExerciseRevisionError maps exercise revision service errors to HTTP responses.
+func abortWithExerciseRevisionError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrExerciseNotFound) || errors.Is(err, service.ErrExerciseRevisionNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrExerciseAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Exercise Revisions ---
+
+// GetExerciseRevisions godoc
+// @Summary List revisions of an exercise
+// @Description Lists all immutable revisions of an exercise owned by the authenticated trainer, newest first.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Success 200 {array} ExerciseRevisionResponse "List of revisions"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/revisions [get]
+func (h *ExerciseHandler) GetExerciseRevisions(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	revisions, err := h.exerciseService.GetExerciseRevisions(c.Request.Context(), trainerID, exerciseID)
+	if err != nil {
+		abortWithExerciseRevisionError(c, err, "Failed to retrieve exercise revisions.")
+		return
+	}
+	c.JSON(http.StatusOK, MapExerciseRevisionsToResponse(revisions))
+}
+
+// GetExerciseRevision godoc
+// @Summary Get one revision of an exercise
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} ExerciseRevisionResponse "Exercise revision"
+// @Failure 400 {object} gin.H "Invalid ID or revision number"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise or revision not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/revisions/{revision} [get]
+func (h *ExerciseHandler) GetExerciseRevision(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid revision number.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	rev, err := h.exerciseService.GetExerciseRevision(c.Request.Context(), trainerID, exerciseID, revision)
+	if err != nil {
+		abortWithExerciseRevisionError(c, err, "Failed to retrieve exercise revision.")
+		return
+	}
+	c.JSON(http.StatusOK, MapExerciseRevisionToResponse(rev))
+}
+
+// DiffExerciseRevisions godoc
+// @Summary Diff two revisions of an exercise
+// @Description Lists the fields that changed between two revisions. Defaults to the previous and current revision.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Param from query int false "Older revision number"
+// @Param to query int false "Newer revision number"
+// @Success 200 {object} ExerciseRevisionDiffResponse "Changed fields"
+// @Failure 400 {object} gin.H "Invalid ID or revision number"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise or revision not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/revisions-diff [get]
+func (h *ExerciseHandler) DiffExerciseRevisions(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	// Default to comparing the current revision with the one before it.
+	to, from := 0, 0
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = strconv.Atoi(toStr); err != nil {
+			abortWithError(c, http.StatusBadRequest, "Invalid 'to' revision number.")
+			return
+		}
+	} else {
+		exercise, err := h.exerciseService.GetExerciseByID(c.Request.Context(), exerciseID)
+		if err != nil {
+			abortWithExerciseRevisionError(c, err, "Failed to retrieve exercise.")
+			return
+		}
+		to = exercise.CurrentRevision
+		if to == 0 {
+			to = 1
+		}
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = strconv.Atoi(fromStr); err != nil {
+			abortWithError(c, http.StatusBadRequest, "Invalid 'from' revision number.")
+			return
+		}
+	} else {
+		from = to - 1
+		if from < 1 {
+			from = 1
+		}
+	}
+
+	changes, err := h.exerciseService.DiffExerciseRevisions(c.Request.Context(), trainerID, exerciseID, from, to)
+	if err != nil {
+		abortWithExerciseRevisionError(c, err, "Failed to diff exercise revisions.")
+		return
+	}
+	c.JSON(http.StatusOK, ExerciseRevisionDiffResponse{
+		ExerciseID: exerciseID.Hex(),
+		From:       from,
+		To:         to,
+		Changes:    changes,
+	})
+}
+
+// UpgradeOpenAssignments godoc
+// @Summary Upgrade open assignments to the latest exercise revision
+// @Description Moves assignments of this exercise that the client hasn't started yet to the current revision. Started and completed assignments keep their original instructions.
+// @Tags Exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ObjectID Hex"
+// @Success 200 {object} gin.H "upgraded: number of assignments moved to the current revision"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the exercise)"
+// @Failure 404 {object} gin.H "Exercise not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exercises/{id}/upgrade-assignments [post]
+func (h *ExerciseHandler) UpgradeOpenAssignments(c *gin.Context) {
+	exerciseID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid exercise ID format.")
+		return
+	}
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unable to identify trainer.")
+		return
+	}
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	count, err := h.exerciseService.UpgradeOpenAssignments(c.Request.Context(), trainerID, exerciseID)
+	if err != nil {
+		abortWithExerciseRevisionError(c, err, "Failed to upgrade assignments.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"upgraded": count})
 }
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedbackHandler serves timestamped feedback comments on uploads, for trainers
+// (full control) and clients (read and resolve).
+type FeedbackHandler struct {
+	feedbackService service.FeedbackService
+}
+
+// NewFeedbackHandler creates a new FeedbackHandler.
+func NewFeedbackHandler(feedbackService service.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{feedbackService: feedbackService}
+}
+
+// --- DTOs for Feedback Comments ---
+
+// FeedbackCommentRequest creates or replaces a comment. Omit startMs for a comment on
+// the whole video; endMs turns a moment into a range.
+type FeedbackCommentRequest struct {
+	StartMs *int64 `json:"startMs" binding:"omitempty,min=0"`
+	EndMs   *int64 `json:"endMs" binding:"omitempty,min=0"`
+	Text    string `json:"text" binding:"required"`
+}
+
+// ResolveFeedbackCommentRequest resolves (true) or reopens (false) a comment.
+type ResolveFeedbackCommentRequest struct {
+	Resolved *bool `json:"resolved" binding:"required"`
+}
+
+// FeedbackCommentResponse is one comment on an upload.
+type FeedbackCommentResponse struct {
+	ID           string     `json:"id"`
+	UploadID     string     `json:"uploadId"`
+	AssignmentID string     `json:"assignmentId"`
+	TrainerID    string     `json:"trainerId"`
+	StartMs      *int64     `json:"startMs,omitempty"`
+	EndMs        *int64     `json:"endMs,omitempty"`
+	Text         string     `json:"text"`
+	Resolved     bool       `json:"resolved"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// MapFeedbackCommentToResponse converts a domain.FeedbackComment to a FeedbackCommentResponse DTO.
+func MapFeedbackCommentToResponse(fc *domain.FeedbackComment) FeedbackCommentResponse {
+	return FeedbackCommentResponse{
+		ID:           fc.ID.Hex(),
+		UploadID:     fc.UploadID.Hex(),
+		AssignmentID: fc.AssignmentID.Hex(),
+		TrainerID:    fc.TrainerID.Hex(),
+		StartMs:      fc.StartMs,
+		EndMs:        fc.EndMs,
+		Text:         fc.Text,
+		Resolved:     fc.IsResolved(),
+		ResolvedAt:   fc.ResolvedAt,
+		CreatedAt:    fc.CreatedAt,
+		UpdatedAt:    fc.UpdatedAt,
+	}
+}
+
+// MapFeedbackCommentsToResponse converts a slice of domain.FeedbackComment to DTOs.
+func MapFeedbackCommentsToResponse(comments []domain.FeedbackComment) []FeedbackCommentResponse {
+	responses := make([]FeedbackCommentResponse, len(comments))
+	for i, fc := range comments {
+		responses[i] = MapFeedbackCommentToResponse(&fc)
+	}
+	return responses
+}
+
+// abortWithFeedbackCommentError maps feedback service errors to HTTP responses.
+func abortWithFeedbackCommentError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrUploadNotFound) || errors.Is(err, service.ErrFeedbackCommentNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrUploadAccessDenied) || errors.Is(err, service.ErrFeedbackCommentAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrInvalidFeedbackComment) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// userAndPathID reads the caller's ID and one ObjectID path parameter, aborting on failure.
+func userAndPathID(c *gin.Context, param, label string) (primitive.ObjectID, primitive.ObjectID, bool) {
+	userIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unauthorized.")
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+	userID, _ := primitive.ObjectIDFromHex(userIDStr)
+
+	id, err := primitive.ObjectIDFromHex(c.Param(param))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid "+label+" ID.")
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+	return userID, id, true
+}
+
+// --- Trainer Handler Methods ---
+
+// CreateFeedbackComment godoc
+// @Summary Comment on a client's upload
+// @Description Adds a feedback comment to an upload, optionally at a moment (startMs) or range (startMs-endMs) of the video, in milliseconds.
+// @Tags Trainer Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uploadId path string true "Upload's ObjectID Hex"
+// @Param comment body FeedbackCommentRequest true "Comment text and optional timestamp range"
+// @Success 201 {object} FeedbackCommentResponse "Comment created"
+// @Failure 400 {object} gin.H "Invalid input or timestamp range"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Upload is not from one of the trainer's clients"
+// @Failure 404 {object} gin.H "Upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/uploads/{uploadId}/comments [post]
+func (h *FeedbackHandler) CreateFeedbackComment(c *gin.Context) {
+	trainerID, uploadID, ok := userAndPathID(c, "uploadId", "upload")
+	if !ok { return }
+
+	var req FeedbackCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	input := service.FeedbackCommentInput{StartMs: req.StartMs, EndMs: req.EndMs, Text: req.Text}
+	comment, err := h.feedbackService.CreateComment(c.Request.Context(), trainerID, uploadID, input)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to create feedback comment.")
+		return
+	}
+	c.JSON(http.StatusCreated, MapFeedbackCommentToResponse(comment))
+}
+
+// GetFeedbackCommentsForUpload godoc
+// @Summary List comments on a client's upload
+// @Description Returns the upload's comments in playback order; comments on the whole video come first.
+// @Tags Trainer Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param uploadId path string true "Upload's ObjectID Hex"
+// @Success 200 {array} FeedbackCommentResponse "Comments (can be empty)"
+// @Failure 400 {object} gin.H "Invalid upload ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Upload is not from one of the trainer's clients"
+// @Failure 404 {object} gin.H "Upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/uploads/{uploadId}/comments [get]
+func (h *FeedbackHandler) GetFeedbackCommentsForUpload(c *gin.Context) {
+	trainerID, uploadID, ok := userAndPathID(c, "uploadId", "upload")
+	if !ok { return }
+
+	comments, err := h.feedbackService.GetCommentsForUpload(c.Request.Context(), trainerID, uploadID)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to retrieve feedback comments.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackCommentsToResponse(comments))
+}
+
+// UpdateFeedbackComment godoc
+// @Summary Edit a feedback comment
+// @Description Replaces the text and timestamp range of a comment. Omitting startMs makes it a comment on the whole video.
+// @Tags Trainer Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param commentId path string true "Comment's ObjectID Hex"
+// @Param comment body FeedbackCommentRequest true "New text and timestamp range"
+// @Success 200 {object} FeedbackCommentResponse "Comment updated"
+// @Failure 400 {object} gin.H "Invalid input or timestamp range"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Comment belongs to another trainer"
+// @Failure 404 {object} gin.H "Comment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/comments/{commentId} [put]
+func (h *FeedbackHandler) UpdateFeedbackComment(c *gin.Context) {
+	trainerID, commentID, ok := userAndPathID(c, "commentId", "comment")
+	if !ok { return }
+
+	var req FeedbackCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	input := service.FeedbackCommentInput{StartMs: req.StartMs, EndMs: req.EndMs, Text: req.Text}
+	comment, err := h.feedbackService.UpdateComment(c.Request.Context(), trainerID, commentID, input)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to update feedback comment.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackCommentToResponse(comment))
+}
+
+// DeleteFeedbackComment godoc
+// @Summary Delete a feedback comment
+// @Tags Trainer Feedback
+// @Security BearerAuth
+// @Param commentId path string true "Comment's ObjectID Hex"
+// @Success 204 "Comment deleted"
+// @Failure 400 {object} gin.H "Invalid comment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Comment belongs to another trainer"
+// @Failure 404 {object} gin.H "Comment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/comments/{commentId} [delete]
+func (h *FeedbackHandler) DeleteFeedbackComment(c *gin.Context) {
+	trainerID, commentID, ok := userAndPathID(c, "commentId", "comment")
+	if !ok { return }
+
+	if err := h.feedbackService.DeleteComment(c.Request.Context(), trainerID, commentID); err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to delete feedback comment.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResolveFeedbackComment godoc
+// @Summary Resolve or reopen a feedback comment
+// @Tags Trainer Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param commentId path string true "Comment's ObjectID Hex"
+// @Param resolve body ResolveFeedbackCommentRequest true "true to resolve, false to reopen"
+// @Success 200 {object} FeedbackCommentResponse "Comment updated"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Comment belongs to another trainer"
+// @Failure 404 {object} gin.H "Comment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/comments/{commentId}/resolved [put]
+func (h *FeedbackHandler) ResolveFeedbackComment(c *gin.Context) {
+	trainerID, commentID, ok := userAndPathID(c, "commentId", "comment")
+	if !ok { return }
+
+	var req ResolveFeedbackCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	comment, err := h.feedbackService.SetCommentResolved(c.Request.Context(), trainerID, commentID, *req.Resolved)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to update feedback comment.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackCommentToResponse(comment))
+}
+
+// --- Client Handler Methods ---
+
+// GetMyFeedbackCommentsForUpload godoc
+// @Summary List my trainer's comments on one of my uploads
+// @Description Returns the upload's comments in playback order; comments on the whole video come first.
+// @Tags Client Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param uploadId path string true "Upload's ObjectID Hex"
+// @Success 200 {array} FeedbackCommentResponse "Comments (can be empty)"
+// @Failure 400 {object} gin.H "Invalid upload ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Upload belongs to another client"
+// @Failure 404 {object} gin.H "Upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/uploads/{uploadId}/comments [get]
+func (h *FeedbackHandler) GetMyFeedbackCommentsForUpload(c *gin.Context) {
+	clientID, uploadID, ok := userAndPathID(c, "uploadId", "upload")
+	if !ok { return }
+
+	comments, err := h.feedbackService.GetMyCommentsForUpload(c.Request.Context(), clientID, uploadID)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to retrieve feedback comments.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackCommentsToResponse(comments))
+}
+
+// ResolveMyFeedbackComment godoc
+// @Summary Mark a comment on my upload as resolved
+// @Description Lets the client acknowledge a correction (true) or reopen it (false).
+// @Tags Client Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param commentId path string true "Comment's ObjectID Hex"
+// @Param resolve body ResolveFeedbackCommentRequest true "true to resolve, false to reopen"
+// @Success 200 {object} FeedbackCommentResponse "Comment updated"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Comment is on another client's upload"
+// @Failure 404 {object} gin.H "Comment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/comments/{commentId}/resolved [put]
+func (h *FeedbackHandler) ResolveMyFeedbackComment(c *gin.Context) {
+	clientID, commentID, ok := userAndPathID(c, "commentId", "comment")
+	if !ok { return }
+
+	var req ResolveFeedbackCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	comment, err := h.feedbackService.SetMyCommentResolved(c.Request.Context(), clientID, commentID, *req.Resolved)
+	if err != nil {
+		abortWithFeedbackCommentError(c, err, "Failed to update feedback comment.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackCommentToResponse(comment))
+}
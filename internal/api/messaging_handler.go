@@ -0,0 +1,426 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MessagingHandler serves trainer–client conversations. The same handlers are mounted
+// under /trainer and /client; the service checks the caller takes part in the conversation.
+type MessagingHandler struct {
+	messagingService service.MessagingService
+}
+
+// NewMessagingHandler creates a new MessagingHandler.
+func NewMessagingHandler(messagingService service.MessagingService) *MessagingHandler {
+	return &MessagingHandler{messagingService: messagingService}
+}
+
+// --- DTOs for Messaging ---
+
+// MessageAnchorRequest names the plan, workout or assignment a thread is about.
+type MessageAnchorRequest struct {
+	Type string `json:"type" binding:"required,oneof=plan workout assignment"`
+	ID   string `json:"id" binding:"required"`
+}
+
+// SendMessageRequest posts a message, optionally into a thread.
+type SendMessageRequest struct {
+	Body   string                `json:"body" binding:"required"`
+	Anchor *MessageAnchorRequest `json:"anchor" binding:"omitempty"`
+}
+
+// MarkReadRequest marks the whole conversation read, or only one thread.
+type MarkReadRequest struct {
+	Anchor *MessageAnchorRequest `json:"anchor" binding:"omitempty"`
+}
+
+// MessageAnchorResponse identifies a thread.
+type MessageAnchorResponse struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// ConversationResponse is one entry of a user's inbox.
+type ConversationResponse struct {
+	ID                 string     `json:"id"`
+	TrainerID          string     `json:"trainerId"`
+	ClientID           string     `json:"clientId"`
+	ParticipantName    string     `json:"participantName"` // The other participant
+	ParticipantEmail   string     `json:"participantEmail"`
+	LastMessageAt      *time.Time `json:"lastMessageAt,omitempty"`
+	LastMessagePreview string     `json:"lastMessagePreview,omitempty"`
+	LastSenderID       *string    `json:"lastSenderId,omitempty"`
+	UnreadCount        int64      `json:"unreadCount"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// MessageResponse is one message; readAt is the recipient's read receipt.
+type MessageResponse struct {
+	ID             string                 `json:"id"`
+	ConversationID string                 `json:"conversationId"`
+	SenderID       string                 `json:"senderId"`
+	RecipientID    string                 `json:"recipientId"`
+	Anchor         *MessageAnchorResponse `json:"anchor,omitempty"`
+	Body           string                 `json:"body"`
+	ReadAt         *time.Time             `json:"readAt,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt"`
+}
+
+// MessagePageResponse is one page of history, newest first.
+type MessagePageResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	NextBefore *string           `json:"nextBefore,omitempty"` // Pass as ?before= for older messages; absent on the last page
+}
+
+// MessageThreadResponse summarizes one thread of a conversation.
+type MessageThreadResponse struct {
+	Anchor        MessageAnchorResponse `json:"anchor"`
+	MessageCount  int64                 `json:"messageCount"`
+	UnreadCount   int64                 `json:"unreadCount"`
+	LastMessageAt time.Time             `json:"lastMessageAt"`
+}
+
+// MapConversationSummaryToResponse converts a service.ConversationSummary to a ConversationResponse DTO.
+func MapConversationSummaryToResponse(s *service.ConversationSummary) ConversationResponse {
+	var lastSenderHex *string
+	if s.Conversation.LastSenderID != nil {
+		hex := s.Conversation.LastSenderID.Hex()
+		lastSenderHex = &hex
+	}
+	return ConversationResponse{
+		ID:                 s.Conversation.ID.Hex(),
+		TrainerID:          s.Conversation.TrainerID.Hex(),
+		ClientID:           s.Conversation.ClientID.Hex(),
+		ParticipantName:    s.ParticipantName,
+		ParticipantEmail:   s.ParticipantEmail,
+		LastMessageAt:      s.Conversation.LastMessageAt,
+		LastMessagePreview: s.Conversation.LastMessagePreview,
+		LastSenderID:       lastSenderHex,
+		UnreadCount:        s.UnreadCount,
+		CreatedAt:          s.Conversation.CreatedAt,
+	}
+}
+
+// MapMessageToResponse converts a domain.Message to a MessageResponse DTO.
+func MapMessageToResponse(m *domain.Message) MessageResponse {
+	resp := MessageResponse{
+		ID:             m.ID.Hex(),
+		ConversationID: m.ConversationID.Hex(),
+		SenderID:       m.SenderID.Hex(),
+		RecipientID:    m.RecipientID.Hex(),
+		Body:           m.Body,
+		ReadAt:         m.ReadAt,
+		CreatedAt:      m.CreatedAt,
+	}
+	if m.Anchor != nil {
+		resp.Anchor = &MessageAnchorResponse{Type: string(m.Anchor.Type), ID: m.Anchor.ID.Hex()}
+	}
+	return resp
+}
+
+// parseMessageAnchor converts an anchor DTO; nil stays nil.
+func parseMessageAnchor(req *MessageAnchorRequest) (*domain.MessageAnchor, error) {
+	if req == nil {
+		return nil, nil
+	}
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return nil, errors.New("invalid anchor ID")
+	}
+	return &domain.MessageAnchor{Type: domain.MessageAnchorType(req.Type), ID: id}, nil
+}
+
+// abortWithMessagingError maps messaging service errors to HTTP responses.
+func abortWithMessagingError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrConversationNotFound) || errors.Is(err, service.ErrClientNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrConversationAccessDenied) || errors.Is(err, service.ErrClientNotManaged) || errors.Is(err, service.ErrConversationClosed) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrNoTrainerAssigned) {
+		abortWithError(c, http.StatusConflict, err.Error())
+	} else if errors.Is(err, service.ErrInvalidMessage) || errors.Is(err, service.ErrInvalidMessageAnchor) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods ---
+
+// StartTrainerConversation godoc
+// @Summary Open the conversation with a client
+// @Description Returns the trainer's conversation with the client, creating it on first use.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Param clientId path string true "Client's ObjectID Hex"
+// @Success 200 {object} ConversationResponse "The conversation"
+// @Failure 400 {object} gin.H "Invalid client ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Client not managed by this trainer"
+// @Failure 404 {object} gin.H "Client not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/clients/{clientId}/conversation [post]
+func (h *MessagingHandler) StartTrainerConversation(c *gin.Context) {
+	trainerID, clientID, ok := userAndPathID(c, "clientId", "client")
+	if !ok { return }
+
+	summary, err := h.messagingService.StartTrainerConversation(c.Request.Context(), trainerID, clientID)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to open conversation.")
+		return
+	}
+	c.JSON(http.StatusOK, MapConversationSummaryToResponse(summary))
+}
+
+// StartClientConversation godoc
+// @Summary Open the conversation with my trainer
+// @Description Returns the client's conversation with their current trainer, creating it on first use.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ConversationResponse "The conversation"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 409 {object} gin.H "Client has no trainer"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/conversation [post]
+func (h *MessagingHandler) StartClientConversation(c *gin.Context) {
+	clientIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	clientID, _ := primitive.ObjectIDFromHex(clientIDStr)
+
+	summary, err := h.messagingService.StartClientConversation(c.Request.Context(), clientID)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to open conversation.")
+		return
+	}
+	c.JSON(http.StatusOK, MapConversationSummaryToResponse(summary))
+}
+
+// ListConversations godoc
+// @Summary List my conversations
+// @Description Returns the caller's conversations, most recently active first, with unread counts.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} ConversationResponse "Conversations (can be empty)"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/conversations [get]
+// @Router /client/conversations [get]
+func (h *MessagingHandler) ListConversations(c *gin.Context) {
+	userIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	userID, _ := primitive.ObjectIDFromHex(userIDStr)
+
+	summaries, err := h.messagingService.ListConversations(c.Request.Context(), userID)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to retrieve conversations.")
+		return
+	}
+	responses := make([]ConversationResponse, len(summaries))
+	for i := range summaries {
+		responses[i] = MapConversationSummaryToResponse(&summaries[i])
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetMessages godoc
+// @Summary Get conversation history
+// @Description Returns one page of messages, newest first. Without anchorType/anchorId the whole conversation is listed, threads included; with them, only that thread.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Param conversationId path string true "Conversation's ObjectID Hex"
+// @Param before query string false "Return messages older than this message ID (nextBefore of the previous page)"
+// @Param limit query int false "Page size (default 30, max 100)"
+// @Param anchorType query string false "Thread anchor type: plan, workout or assignment"
+// @Param anchorId query string false "Thread anchor ObjectID Hex"
+// @Success 200 {object} MessagePageResponse "One page of messages"
+// @Failure 400 {object} gin.H "Invalid ID, cursor or anchor"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Not a participant of this conversation"
+// @Failure 404 {object} gin.H "Conversation not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/conversations/{conversationId}/messages [get]
+// @Router /client/conversations/{conversationId}/messages [get]
+func (h *MessagingHandler) GetMessages(c *gin.Context) {
+	userID, conversationID, ok := userAndPathID(c, "conversationId", "conversation")
+	if !ok { return }
+
+	var query service.MessageQuery
+	if raw := c.Query("before"); raw != "" {
+		before, err := primitive.ObjectIDFromHex(raw)
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid before cursor."); return }
+		query.BeforeID = &before
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 1 { abortWithError(c, http.StatusBadRequest, "Invalid limit."); return }
+		query.Limit = limit
+	}
+	if anchorType, anchorID := c.Query("anchorType"), c.Query("anchorId"); anchorType != "" || anchorID != "" {
+		anchor, err := parseMessageAnchor(&MessageAnchorRequest{Type: anchorType, ID: anchorID})
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid anchor."); return }
+		query.Anchor = anchor
+	}
+
+	page, err := h.messagingService.GetMessages(c.Request.Context(), userID, conversationID, query)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to retrieve messages.")
+		return
+	}
+	resp := MessagePageResponse{Messages: make([]MessageResponse, len(page.Messages))}
+	for i := range page.Messages {
+		resp.Messages[i] = MapMessageToResponse(&page.Messages[i])
+	}
+	if page.NextBeforeID != nil {
+		hex := page.NextBeforeID.Hex()
+		resp.NextBefore = &hex
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetThreads godoc
+// @Summary List the threads of a conversation
+// @Description Returns one entry per plan, workout or assignment discussed in the conversation, most recently active first, with the caller's unread count.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Param conversationId path string true "Conversation's ObjectID Hex"
+// @Success 200 {array} MessageThreadResponse "Threads (can be empty)"
+// @Failure 400 {object} gin.H "Invalid conversation ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Not a participant of this conversation"
+// @Failure 404 {object} gin.H "Conversation not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/conversations/{conversationId}/threads [get]
+// @Router /client/conversations/{conversationId}/threads [get]
+func (h *MessagingHandler) GetThreads(c *gin.Context) {
+	userID, conversationID, ok := userAndPathID(c, "conversationId", "conversation")
+	if !ok { return }
+
+	threads, err := h.messagingService.GetThreads(c.Request.Context(), userID, conversationID)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to retrieve threads.")
+		return
+	}
+	responses := make([]MessageThreadResponse, len(threads))
+	for i, t := range threads {
+		responses[i] = MessageThreadResponse{
+			Anchor:        MessageAnchorResponse{Type: string(t.Anchor.Type), ID: t.Anchor.ID.Hex()},
+			MessageCount:  t.MessageCount,
+			UnreadCount:   t.UnreadCount,
+			LastMessageAt: t.LastMessageAt,
+		}
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// SendMessage godoc
+// @Summary Send a message
+// @Description Posts a message to the conversation, or to a thread when an anchor (plan, workout or assignment of this client) is given.
+// @Tags Messaging
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param conversationId path string true "Conversation's ObjectID Hex"
+// @Param message body SendMessageRequest true "Message body and optional thread anchor"
+// @Success 201 {object} MessageResponse "Message sent"
+// @Failure 400 {object} gin.H "Invalid input or anchor"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Not a participant, or the client has left this trainer"
+// @Failure 404 {object} gin.H "Conversation not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/conversations/{conversationId}/messages [post]
+// @Router /client/conversations/{conversationId}/messages [post]
+func (h *MessagingHandler) SendMessage(c *gin.Context) {
+	userID, conversationID, ok := userAndPathID(c, "conversationId", "conversation")
+	if !ok { return }
+
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	anchor, err := parseMessageAnchor(req.Anchor)
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid anchor."); return }
+
+	msg, err := h.messagingService.SendMessage(c.Request.Context(), userID, conversationID, req.Body, anchor)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to send message.")
+		return
+	}
+	c.JSON(http.StatusCreated, MapMessageToResponse(msg))
+}
+
+// MarkConversationRead godoc
+// @Summary Mark messages as read
+// @Description Sets read receipts on all unread messages to the caller in the conversation, or only in one thread when an anchor is given. The body is optional.
+// @Tags Messaging
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param conversationId path string true "Conversation's ObjectID Hex"
+// @Param read body MarkReadRequest false "Optional thread anchor"
+// @Success 200 {object} gin.H "marked: number of messages marked read"
+// @Failure 400 {object} gin.H "Invalid input or anchor"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Not a participant of this conversation"
+// @Failure 404 {object} gin.H "Conversation not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/conversations/{conversationId}/read [post]
+// @Router /client/conversations/{conversationId}/read [post]
+func (h *MessagingHandler) MarkConversationRead(c *gin.Context) {
+	userID, conversationID, ok := userAndPathID(c, "conversationId", "conversation")
+	if !ok { return }
+
+	var req MarkReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	anchor, err := parseMessageAnchor(req.Anchor)
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid anchor."); return }
+
+	marked, err := h.messagingService.MarkRead(c.Request.Context(), userID, conversationID, anchor)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to mark messages read.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetUnreadMessageCount godoc
+// @Summary Count my unread messages
+// @Description Returns the number of messages to the caller not yet read, across all conversations.
+// @Tags Messaging
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} gin.H "unread: number of unread messages"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/messages/unread-count [get]
+// @Router /client/messages/unread-count [get]
+func (h *MessagingHandler) GetUnreadMessageCount(c *gin.Context) {
+	userIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	userID, _ := primitive.ObjectIDFromHex(userIDStr)
+
+	count, err := h.messagingService.GetUnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		abortWithMessagingError(c, err, "Failed to count unread messages.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/subtle"
 	"errors"
 	"alcyxob/fitness-app/internal/domain" // For domain.Role
 	"fmt"
@@ -43,47 +44,121 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		}
 		tokenString := parts[1]
 
-		// Parse and validate the token
-		claims := &jwtClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate the alg is what we expect:
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			// Return the secret key
-			return []byte(jwtSecret), nil
-		})
-
-		// Handle errors during parsing/validation
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				abortWithError(c, http.StatusUnauthorized, "Token has expired")
-			} else {
-				abortWithError(c, http.StatusUnauthorized, fmt.Sprintf("Invalid token: %v", err))
-			}
+		if !authenticateToken(c, jwtSecret, tokenString) {
 			return
 		}
 
-		if !token.Valid || claims.UserID == "" || claims.Role == "" {
-			abortWithError(c, http.StatusUnauthorized, "Invalid token or missing claims")
+		// Continue to the next handler
+		c.Next()
+	}
+}
+
+// EventStreamAuthMiddleware authenticates like AuthMiddleware, but also accepts the
+// token in the access_token query parameter, since browser EventSource cannot set headers.
+func EventStreamAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.Query("access_token")
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+				abortWithError(c, http.StatusUnauthorized, "Authorization header format must be Bearer {token}")
+				return
+			}
+			tokenString = parts[1]
+		}
+		if tokenString == "" {
+			abortWithError(c, http.StatusUnauthorized, "Authorization header or access_token is missing")
 			return
 		}
 
-		// Check if token expiry is reasonable (within configured lifetime, though ParseWithClaims checks this)
-		// Redundant check usually, but doesn't hurt
-		if claims.ExpiresAt == nil || claims.ExpiresAt.Time.Before(time.Now()) {
-			abortWithError(c, http.StatusUnauthorized, "Token has expired (claim check)")
+		if !authenticateToken(c, jwtSecret, tokenString) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// --- Token is valid ---
-		// Set user information in the context for downstream handlers
-		c.Set(ContextUserIDKey, claims.UserID) // Store UserID as string (Hex representation)
-		c.Set(ContextUserRoleKey, claims.Role)
+// RequestLogger is gin's default request logger, except that the value of the access_token
+// query parameter (see EventStreamAuthMiddleware) is redacted so JWTs never reach the logs.
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactAccessToken(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
 
-		// Continue to the next handler
-		c.Next()
+// redactAccessToken replaces the value of every access_token parameter in a logged path.
+func redactAccessToken(path string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found {
+		return path
 	}
+	params := strings.Split(query, "&")
+	for i, p := range params {
+		if name, _, _ := strings.Cut(p, "="); name == "access_token" {
+			params[i] = "access_token=REDACTED"
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// authenticateToken validates a JWT and stores the user's ID and role in the context.
+// On failure it aborts the request and returns false.
+func authenticateToken(c *gin.Context, jwtSecret, tokenString string) bool {
+	// Parse and validate the token
+	claims := &jwtClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate the alg is what we expect:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		// Return the secret key
+		return []byte(jwtSecret), nil
+	})
+
+	// Handle errors during parsing/validation
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			abortWithError(c, http.StatusUnauthorized, "Token has expired")
+		} else {
+			abortWithError(c, http.StatusUnauthorized, fmt.Sprintf("Invalid token: %v", err))
+		}
+		return false
+	}
+
+	if !token.Valid || claims.UserID == "" || claims.Role == "" {
+		abortWithError(c, http.StatusUnauthorized, "Invalid token or missing claims")
+		return false
+	}
+
+	// Check if token expiry is reasonable (within configured lifetime, though ParseWithClaims checks this)
+	// Redundant check usually, but doesn't hurt
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Time.Before(time.Now()) {
+		abortWithError(c, http.StatusUnauthorized, "Token has expired (claim check)")
+		return false
+	}
+
+	// --- Token is valid ---
+	// Set user information in the context for downstream handlers
+	c.Set(ContextUserIDKey, claims.UserID) // Store UserID as string (Hex representation)
+	c.Set(ContextUserRoleKey, claims.Role)
+	return true
 }
 
 // Helper to return JSON error response and abort request
@@ -91,6 +166,18 @@ func abortWithError(c *gin.Context, code int, message string) {
 	c.AbortWithStatusJSON(code, gin.H{"error": message})
 }
 
+// AdminKeyMiddleware protects operator endpoints with a static key sent in the X-Admin-Key header.
+func AdminKeyMiddleware(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Key")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			abortWithError(c, http.StatusUnauthorized, "Invalid or missing admin key")
+			return
+		}
+		c.Next()
+	}
+}
+
 // RoleMiddleware creates middleware to check if user has the required role(s).
 // Must run AFTER AuthMiddleware.
 func RoleMiddleware(allowedRoles ...domain.Role) gin.HandlerFunc {
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationHandler serves the notification center of the signed-in trainer or client.
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// --- DTOs for Notifications ---
+
+// NotificationResponse is one entry of the notification center.
+type NotificationResponse struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	ActorID   *string        `json:"actorId,omitempty"`
+	Data      map[string]any `json:"data,omitempty"` // IDs of the affected resources
+	Read      bool           `json:"read"`
+	ReadAt    *time.Time     `json:"readAt,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// NotificationPageResponse is one page of notifications, newest first.
+type NotificationPageResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextBefore    *string                `json:"nextBefore,omitempty"` // Pass as ?before= for older notifications
+}
+
+// NotificationPreferencesRequest turns notification types on (true) or off (false).
+// Types not mentioned are left unchanged.
+type NotificationPreferencesRequest struct {
+	Types map[string]bool `json:"types" binding:"required"`
+}
+
+// NotificationPreferencesResponse lists every notification type with whether it is on.
+type NotificationPreferencesResponse struct {
+	Types map[string]bool `json:"types"`
+}
+
+// MapNotificationToResponse converts a domain.Notification to a NotificationResponse DTO.
+func MapNotificationToResponse(n *domain.Notification) NotificationResponse {
+	var actorHex *string
+	if n.ActorID != nil {
+		hex := n.ActorID.Hex()
+		actorHex = &hex
+	}
+	return NotificationResponse{
+		ID:        n.ID.Hex(),
+		Type:      n.Type,
+		Title:     n.Title,
+		ActorID:   actorHex,
+		Data:      n.Data,
+		Read:      n.IsRead(),
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// mapPreferencesToResponse converts the service's preference map to the DTO.
+func mapPreferencesToResponse(prefs map[realtime.EventType]bool) NotificationPreferencesResponse {
+	resp := NotificationPreferencesResponse{Types: make(map[string]bool, len(prefs))}
+	for eventType, wanted := range prefs {
+		resp.Types[string(eventType)] = wanted
+	}
+	return resp
+}
+
+// currentUserID reads the caller's ID, aborting on failure.
+func currentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userIDStr, err := getUserIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unauthorized.")
+		return primitive.NilObjectID, false
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Unauthorized.")
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
+
+// --- Handler Methods ---
+
+// ListNotifications godoc
+// @Summary List my notifications
+// @Description Returns one page of the caller's notifications, newest first.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param before query string false "Return notifications older than this ID (nextBefore of the previous page)"
+// @Param limit query int false "Page size (default 30, max 100)"
+// @Success 200 {object} NotificationPageResponse "One page of notifications"
+// @Failure 400 {object} gin.H "Invalid cursor or limit"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	var query service.NotificationQuery
+	query.UnreadOnly = c.Query("unread") == "true"
+	if raw := c.Query("before"); raw != "" {
+		before, err := primitive.ObjectIDFromHex(raw)
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid before cursor."); return }
+		query.BeforeID = &before
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 1 { abortWithError(c, http.StatusBadRequest, "Invalid limit."); return }
+		query.Limit = limit
+	}
+
+	page, err := h.notificationService.List(c.Request.Context(), userID, query)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to retrieve notifications.")
+		return
+	}
+	resp := NotificationPageResponse{Notifications: make([]NotificationResponse, len(page.Notifications))}
+	for i := range page.Notifications {
+		resp.Notifications[i] = MapNotificationToResponse(&page.Notifications[i])
+	}
+	if page.NextBeforeID != nil {
+		hex := page.NextBeforeID.Hex()
+		resp.NextBefore = &hex
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetUnreadNotificationCount godoc
+// @Summary Count my unread notifications
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} gin.H "unread: number of unread notifications"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadNotificationCount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	count, err := h.notificationService.GetUnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to count unread notifications.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark a notification as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Param notificationId path string true "Notification's ObjectID Hex"
+// @Success 204 "Notification marked read"
+// @Failure 400 {object} gin.H "Invalid notification ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 404 {object} gin.H "Notification not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications/{notificationId}/read [post]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID, notificationID, ok := userAndPathID(c, "notificationId", "notification")
+	if !ok { return }
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), userID, notificationID); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to mark notification read.")
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all my notifications as read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} gin.H "marked: number of notifications marked read"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to mark notifications read.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetNotificationPreferences godoc
+// @Summary Get my notification preferences
+// @Description Lists every notification type (e.g. plan.created, assignment.feedback, upload.created, message.created) with whether the caller receives it.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} NotificationPreferencesResponse "Preferences"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	prefs, err := h.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to load notification preferences.")
+		return
+	}
+	c.JSON(http.StatusOK, mapPreferencesToResponse(prefs))
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary Update my notification preferences
+// @Description Turns notification types on or off. Types not in the request keep their setting. Realtime events are still delivered; this affects the notification center and push notifications.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body NotificationPreferencesRequest true "Types to turn on (true) or off (false)"
+// @Success 200 {object} NotificationPreferencesResponse "Updated preferences"
+// @Failure 400 {object} gin.H "Invalid input or unknown type"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok { return }
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	changes := make(map[realtime.EventType]bool, len(req.Types))
+	for eventType, wanted := range req.Types {
+		changes[realtime.EventType(eventType)] = wanted
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(c.Request.Context(), userID, changes)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidNotificationType) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to save notification preferences.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, mapPreferencesToResponse(prefs))
+}
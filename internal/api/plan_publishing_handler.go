@@ -0,0 +1,159 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlanPublishingHandler validates the trainer's plans and publishes them to clients.
+type PlanPublishingHandler struct {
+	planPublishingService service.PlanPublishingService
+}
+
+// NewPlanPublishingHandler creates a new PlanPublishingHandler.
+func NewPlanPublishingHandler(planPublishingService service.PlanPublishingService) *PlanPublishingHandler {
+	return &PlanPublishingHandler{planPublishingService: planPublishingService}
+}
+
+// --- DTOs for Publishing ---
+
+// PublishPlanResponse is a published plan with the warnings found while validating it.
+type PublishPlanResponse struct {
+	Plan     TrainingPlanResponse `json:"plan"`
+	Warnings []service.PlanIssue  `json:"warnings"`
+}
+
+// PendingChangesResponse lists the changes to a published plan the client has not seen yet.
+type PendingChangesResponse struct {
+	PlanID  string               `json:"planId"`
+	Changes []service.PlanChange `json:"changes"`
+}
+
+// PublishPlanErrorResponse is returned when validation stops a plan from being published.
+type PublishPlanErrorResponse struct {
+	Error      string                        `json:"error"`
+	Validation *service.PlanValidationReport `json:"validation"`
+}
+
+// --- Handler Methods ---
+
+// abortWithPlanError maps errors shared by the publishing endpoints.
+func abortWithPlanError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrTrainingPlanNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrTrainingPlanAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// ValidatePlan godoc
+// @Summary Validate a training plan
+// @Description Checks a plan, including edits not yet published, without changing it. Errors (end date before start date, dates overlapping another scheduled or active plan of the client, no workouts, workouts without exercises, two workouts on the same day of week or at the same position) block publishing; warnings (plan already ended, exercises sharing a position) do not.
+// @Tags Trainer Plans
+// @Produce json
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Success 200 {object} service.PlanValidationReport "Validation report"
+// @Failure 400 {object} gin.H "Invalid plan ID"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or plan not owned)"
+// @Failure 404 {object} gin.H "Training plan not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/validation [get]
+func (h *PlanPublishingHandler) ValidatePlan(c *gin.Context) {
+	trainerID, planID, ok := userAndPathID(c, "planId", "training plan")
+	if !ok { return }
+
+	report, err := h.planPublishingService.ValidatePlan(c.Request.Context(), trainerID, planID)
+	if err != nil {
+		abortWithPlanError(c, err, "Failed to validate training plan.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// PublishPlan godoc
+// @Summary Publish a training plan to the client
+// @Description Validates the plan (including unpublished edits) and, if it has no errors, makes it visible to the client and notifies them. A draft plan is activated (replacing the client's current plan) or, if it starts in the future, scheduled. Publishing again swaps in the edits staged since the last publish in one step and sends the client a summary of what changed (also returned in plan.changeSummary).
+// @Tags Trainer Plans
+// @Produce json
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Success 200 {object} PublishPlanResponse "Published plan and validation warnings"
+// @Failure 400 {object} gin.H "Invalid plan ID"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or plan not owned)"
+// @Failure 404 {object} gin.H "Training plan not found"
+// @Failure 422 {object} PublishPlanErrorResponse "Plan has validation errors"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/publish [post]
+func (h *PlanPublishingHandler) PublishPlan(c *gin.Context) {
+	trainerID, planID, ok := userAndPathID(c, "planId", "training plan")
+	if !ok { return }
+
+	plan, report, err := h.planPublishingService.PublishPlan(c.Request.Context(), trainerID, planID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlanValidationFailed) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, PublishPlanErrorResponse{Error: err.Error(), Validation: report})
+			return
+		}
+		abortWithPlanError(c, err, "Failed to publish training plan.")
+		return
+	}
+	c.JSON(http.StatusOK, PublishPlanResponse{Plan: MapTrainingPlanToResponse(plan), Warnings: report.Warnings})
+}
+
+// GetPendingChanges godoc
+// @Summary List unpublished changes of a training plan
+// @Description Lists the changes staged on a published plan (workouts, blocks and exercises added, removed, edited or reordered), as the client will see them summarized on the next publish. Empty if there are none.
+// @Tags Trainer Plans
+// @Produce json
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Success 200 {object} PendingChangesResponse "Unpublished changes"
+// @Failure 400 {object} gin.H "Invalid plan ID"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or plan not owned)"
+// @Failure 404 {object} gin.H "Training plan not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/draft [get]
+func (h *PlanPublishingHandler) GetPendingChanges(c *gin.Context) {
+	trainerID, planID, ok := userAndPathID(c, "planId", "training plan")
+	if !ok { return }
+
+	changes, err := h.planPublishingService.GetPendingChanges(c.Request.Context(), trainerID, planID)
+	if err != nil {
+		abortWithPlanError(c, err, "Failed to load unpublished changes.")
+		return
+	}
+	c.JSON(http.StatusOK, PendingChangesResponse{PlanID: planID.Hex(), Changes: changes})
+}
+
+// DiscardPendingChanges godoc
+// @Summary Discard unpublished changes of a training plan
+// @Description Drops the changes staged on a published plan, going back to the version the client sees.
+// @Tags Trainer Plans
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Success 204 "Changes discarded"
+// @Failure 400 {object} gin.H "Invalid plan ID"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or plan not owned)"
+// @Failure 404 {object} gin.H "Training plan not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/draft [delete]
+func (h *PlanPublishingHandler) DiscardPendingChanges(c *gin.Context) {
+	trainerID, planID, ok := userAndPathID(c, "planId", "training plan")
+	if !ok { return }
+
+	if err := h.planPublishingService.DiscardPendingChanges(c.Request.Context(), trainerID, planID); err != nil {
+		abortWithPlanError(c, err, "Failed to discard unpublished changes.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
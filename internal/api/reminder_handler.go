@@ -0,0 +1,126 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReminderHandler serves the signed-in client's workout reminder settings.
+type ReminderHandler struct {
+	reminderService service.ReminderService
+}
+
+// NewReminderHandler creates a new ReminderHandler.
+func NewReminderHandler(reminderService service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminderService: reminderService}
+}
+
+// --- DTOs for Reminders ---
+
+// ReminderSettingsRequest changes reminder settings. Omitted fields are left unchanged;
+// an empty time or time zone restores the default.
+type ReminderSettingsRequest struct {
+	RemindersEnabled *bool   `json:"remindersEnabled,omitempty"`
+	ReminderTime     *string `json:"reminderTime,omitempty"` // "HH:MM", 24-hour
+	NudgesEnabled    *bool   `json:"nudgesEnabled,omitempty"`
+	NudgeTime        *string `json:"nudgeTime,omitempty"` // "HH:MM", 24-hour
+	TimeZone         *string `json:"timeZone,omitempty"`  // IANA name, e.g. "Europe/Berlin"
+}
+
+// ReminderSettingsResponse is the client's effective reminder settings.
+type ReminderSettingsResponse struct {
+	RemindersEnabled bool       `json:"remindersEnabled"`
+	ReminderTime     string     `json:"reminderTime"` // Reminder of the day's workouts
+	NudgesEnabled    bool       `json:"nudgesEnabled"`
+	NudgeTime        string     `json:"nudgeTime"` // Nudge about yesterday's unfinished exercises
+	TimeZone         string     `json:"timeZone"`
+	UpdatedAt        *time.Time `json:"updatedAt,omitempty"`
+}
+
+// MapReminderSettingsToResponse converts domain.ReminderSettings to a ReminderSettingsResponse DTO.
+func MapReminderSettingsToResponse(s *domain.ReminderSettings) ReminderSettingsResponse {
+	resp := ReminderSettingsResponse{
+		RemindersEnabled: !s.RemindersDisabled,
+		ReminderTime:     s.EffectiveReminderTime(),
+		NudgesEnabled:    !s.NudgesDisabled,
+		NudgeTime:        s.EffectiveNudgeTime(),
+		TimeZone:         s.Location().String(),
+	}
+	if !s.UpdatedAt.IsZero() {
+		updatedAt := s.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+// --- Handler Methods ---
+
+// GetMyReminderSettings godoc
+// @Summary Get my workout reminder settings
+// @Description Returns when the caller is reminded of the day's workouts and nudged about exercises left unfinished the day before. Defaults apply until the settings are changed.
+// @Tags Client-Reminders
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ReminderSettingsResponse "Reminder settings"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (User is not a client)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/reminders [get]
+func (h *ReminderHandler) GetMyReminderSettings(c *gin.Context) {
+	clientID, ok := currentUserID(c)
+	if !ok { return }
+
+	settings, err := h.reminderService.GetSettings(c.Request.Context(), clientID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to load reminder settings.")
+		return
+	}
+	c.JSON(http.StatusOK, MapReminderSettingsToResponse(settings))
+}
+
+// UpdateMyReminderSettings godoc
+// @Summary Update my workout reminder settings
+// @Description Turns the daily workout reminder and the missed-workout nudge on or off and sets their local times. Fields not in the request keep their value.
+// @Tags Client-Reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body ReminderSettingsRequest true "Settings to change"
+// @Success 200 {object} ReminderSettingsResponse "Updated reminder settings"
+// @Failure 400 {object} gin.H "Invalid time or time zone"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (User is not a client)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /client/reminders [put]
+func (h *ReminderHandler) UpdateMyReminderSettings(c *gin.Context) {
+	clientID, ok := currentUserID(c)
+	if !ok { return }
+
+	var req ReminderSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.reminderService.UpdateSettings(c.Request.Context(), clientID, service.ReminderSettingsInput{
+		RemindersEnabled: req.RemindersEnabled,
+		ReminderTime:     req.ReminderTime,
+		NudgesEnabled:    req.NudgesEnabled,
+		NudgeTime:        req.NudgeTime,
+		TimeZone:         req.TimeZone,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReminderSettings) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to save reminder settings.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapReminderSettingsToResponse(settings))
+}
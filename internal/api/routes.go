@@ -2,7 +2,9 @@ package api
 
 import (
 	"alcyxob/fitness-app/internal/domain" // Needed for RoleMiddleware
+	"alcyxob/fitness-app/internal/realtime"
 	"alcyxob/fitness-app/internal/service"
+	"alcyxob/fitness-app/internal/storage"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +19,18 @@ func SetupRoutes(
 	trainerService service.TrainerService,
 	clientService service.ClientService,
 	exerciseService service.ExerciseService, // Make sure this is passed in
+	fileStorage storage.FileStorage,
+	adminAPIKey string,
+	reconciliationService service.ReconciliationService,
+	feedbackService service.FeedbackService,
+	messagingService service.MessagingService,
+	broker realtime.Broker,
+	notificationService service.NotificationService,
+	deviceService service.DeviceService,
+	webhookService service.WebhookService,
+	reminderService service.ReminderService,
+	digestService service.DigestService,
+	planPublishingService service.PlanPublishingService,
 ) {
 
 	authHandler := NewAuthHandler(authService)
@@ -24,6 +38,15 @@ func SetupRoutes(
 	exerciseHandler := NewExerciseHandler(exerciseService)
 	trainerHandler := NewTrainerHandler(trainerService)
 	clientHandler := NewClientHandler(clientService)
+	feedbackHandler := NewFeedbackHandler(feedbackService)
+	messagingHandler := NewMessagingHandler(messagingService)
+	eventsHandler := NewEventsHandler(broker)
+	notificationHandler := NewNotificationHandler(notificationService)
+	deviceHandler := NewDeviceHandler(deviceService)
+	webhookHandler := NewWebhookHandler(webhookService)
+	reminderHandler := NewReminderHandler(reminderService)
+	digestHandler := NewDigestHandler(digestService)
+	planPublishingHandler := NewPlanPublishingHandler(planPublishingService)
 
 	authMiddleware := AuthMiddleware(jwtSecret) // Using the jwtSecret parameter
 
@@ -38,8 +61,30 @@ func SetupRoutes(
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
 		}
+
+		// --- Signed object URLs (local/memory storage drivers only) ---
+		// No JWT: the URL's HMAC signature is the authorization, as with S3 presigned URLs.
+		if backend, ok := fileStorage.(storage.SignedURLBackend); ok {
+			storageHandler := NewStorageHandler(backend)
+			apiV1.PUT("/storage/objects/*objectKey", storageHandler.PutObject)
+			apiV1.GET("/storage/objects/*objectKey", storageHandler.GetObject)
+		}
+
+		// --- Operator endpoints (X-Admin-Key), only when a key is configured ---
+		if adminAPIKey != "" {
+			adminHandler := NewAdminHandler(reconciliationService)
+			adminGroup := apiV1.Group("/admin")
+			adminGroup.Use(AdminKeyMiddleware(adminAPIKey))
+			{
+				adminGroup.GET("/storage/reconciliation", adminHandler.GetStorageReconciliationReport) // Dry run
+				adminGroup.POST("/storage/reconciliation", adminHandler.RunStorageReconciliation)
+			}
+		}
 	}
 
+	// --- Realtime events (SSE); also accepts ?access_token= for EventSource ---
+	apiV1.GET("/events", EventStreamAuthMiddleware(jwtSecret), eventsHandler.StreamEvents)
+
 	protected := apiV1.Group("")
 	protected.Use(authMiddleware)
 	{
@@ -53,6 +98,25 @@ func SetupRoutes(
 			c.JSON(http.StatusOK, gin.H{"userId": userIDStr, "role": role})
 		})
 
+		// --- Notification center (trainers and clients) ---
+		notificationGroup := protected.Group("/notifications")
+		{
+			notificationGroup.GET("", notificationHandler.ListNotifications) // ?unread=true&before=&limit=
+			notificationGroup.GET("/unread-count", notificationHandler.GetUnreadNotificationCount)
+			notificationGroup.POST("/read-all", notificationHandler.MarkAllNotificationsRead)
+			notificationGroup.POST("/:notificationId/read", notificationHandler.MarkNotificationRead)
+			notificationGroup.GET("/preferences", notificationHandler.GetNotificationPreferences)
+			notificationGroup.PUT("/preferences", notificationHandler.UpdateNotificationPreferences)
+		}
+
+		// --- Push notification devices (trainers and clients) ---
+		deviceGroup := protected.Group("/devices")
+		{
+			deviceGroup.POST("", deviceHandler.RegisterDevice)
+			deviceGroup.GET("", deviceHandler.ListDevices)
+			deviceGroup.DELETE("/:token", deviceHandler.UnregisterDevice)
+		}
+
 		// --- Exercise Routes ---
 		exerciseGroup := protected.Group("/exercises")
 		{
@@ -67,9 +131,24 @@ func SetupRoutes(
 			// If clients need access, they'd likely use it via their assignments.
 			exerciseGroup.GET("", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetTrainerExercises)
 
-			exerciseGroup.GET("/:id", exerciseHandler.GetExerciseByID) // For fetching a single exercise
+			// Clients read exercises through their assignments, pinned to the revision they were assigned
+			exerciseGroup.GET("/:id", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetExerciseByID)
 			exerciseGroup.PUT("/:id", RoleMiddleware(domain.RoleTrainer), exerciseHandler.UpdateExercise)
-			exerciseGroup.DELETE("/:id", RoleMiddleware(domain.RoleTrainer), exerciseHandler.DeleteExercise)
+			exerciseGroup.DELETE("/:id", RoleMiddleware(domain.RoleTrainer), exerciseHandler.DeleteExercise) // ?force=true also deletes assignments
+			exerciseGroup.GET("/:id/usage", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetExerciseUsage)
+			exerciseGroup.POST("/:id/merge", RoleMiddleware(domain.RoleTrainer), exerciseHandler.MergeExercises)
+
+			// --- Demonstration Media (trainer uploads via pre-signed URLs) ---
+			exerciseGroup.POST("/:id/media/upload-url", RoleMiddleware(domain.RoleTrainer), exerciseHandler.RequestExerciseMediaUploadURL)
+			exerciseGroup.POST("/:id/media/upload-confirm", RoleMiddleware(domain.RoleTrainer), exerciseHandler.ConfirmExerciseMediaUpload)
+			exerciseGroup.GET("/:id/media", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetExerciseMedia)
+			exerciseGroup.DELETE("/:id/media/:mediaId", RoleMiddleware(domain.RoleTrainer), exerciseHandler.DeleteExerciseMedia)
+
+			// --- Revision History (assignments pin the revision they were created with) ---
+			exerciseGroup.GET("/:id/revisions", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetExerciseRevisions)
+			exerciseGroup.GET("/:id/revisions/:revision", RoleMiddleware(domain.RoleTrainer), exerciseHandler.GetExerciseRevision)
+			exerciseGroup.GET("/:id/revisions-diff", RoleMiddleware(domain.RoleTrainer), exerciseHandler.DiffExerciseRevisions) // ?from=&to=
+			exerciseGroup.POST("/:id/upgrade-assignments", RoleMiddleware(domain.RoleTrainer), exerciseHandler.UpgradeOpenAssignments)
 
 			// TODO: Add routes for specific exercise actions
 			// exerciseGroup.GET("/:id", exerciseHandler.GetExerciseByID)
@@ -109,19 +188,83 @@ func SetupRoutes(
 
 			// GET /api/v1/trainer/assignments/{assignmentId}/video-download-url
 			trainerApiGroup.GET("/assignments/:assignmentId/video-download-url", trainerHandler.GetAssignmentVideoDownloadURL)
+			// GET /api/v1/trainer/assignments/{assignmentId}/uploads (all attempts, primary marked)
+			trainerApiGroup.GET("/assignments/:assignmentId/uploads", trainerHandler.GetUploadsForAssignment)
+			// GET /api/v1/trainer/storage/usage (video storage per client, with quotas)
+			trainerApiGroup.GET("/storage/usage", trainerHandler.GetStorageUsage)
+			// GET /api/v1/trainer/review-queue?clientId= (submitted assignments, oldest first)
+			trainerApiGroup.GET("/review-queue", trainerHandler.GetReviewQueue)
 
 			// PATCH /api/v1/trainer/assignments/{assignmentId}/feedback
 			trainerApiGroup.PATCH("/assignments/:assignmentId/feedback", trainerHandler.SubmitFeedbackForAssignment)
+			// PATCH /api/v1/trainer/assignments/feedback (many assignments; per-item results)
+			trainerApiGroup.PATCH("/assignments/feedback", trainerHandler.BulkSubmitFeedback)
+
+			// --- Video / voice replies attached to feedback ---
+			trainerApiGroup.POST("/assignments/:assignmentId/feedback-attachments/upload-url", trainerHandler.RequestFeedbackAttachmentUploadURL)
+			trainerApiGroup.POST("/assignments/:assignmentId/feedback-attachments", trainerHandler.ConfirmFeedbackAttachment)
+			trainerApiGroup.GET("/assignments/:assignmentId/feedback-attachments", trainerHandler.GetFeedbackAttachments)
+			trainerApiGroup.DELETE("/assignments/:assignmentId/feedback-attachments/:attachmentId", trainerHandler.DeleteFeedbackAttachment)
+
+			// --- Timestamped feedback comments on uploads ---
+			trainerApiGroup.POST("/uploads/:uploadId/comments", feedbackHandler.CreateFeedbackComment)
+			trainerApiGroup.GET("/uploads/:uploadId/comments", feedbackHandler.GetFeedbackCommentsForUpload)
+			trainerApiGroup.PUT("/comments/:commentId", feedbackHandler.UpdateFeedbackComment)
+			trainerApiGroup.DELETE("/comments/:commentId", feedbackHandler.DeleteFeedbackComment)
+			trainerApiGroup.PUT("/comments/:commentId/resolved", feedbackHandler.ResolveFeedbackComment)
 
 			trainerApiGroup.PUT("/clients/:clientId/plans/:planId", trainerHandler.UpdateTrainingPlan)
 
 			trainerApiGroup.DELETE("/clients/:clientId/plans/:planId", trainerHandler.DeleteTrainingPlan)
+			// Lifecycle: draft / scheduled / active / archived (completed is set after EndDate)
+			trainerApiGroup.PUT("/plans/:planId/status", trainerHandler.UpdateTrainingPlanStatus)
+			// Publishing: clients only see plans that passed validation
+			trainerApiGroup.GET("/plans/:planId/validation", planPublishingHandler.ValidatePlan)
+			trainerApiGroup.POST("/plans/:planId/publish", planPublishingHandler.PublishPlan)
+			// Workout/exercise edits of published plans wait here until the next publish
+			trainerApiGroup.GET("/plans/:planId/draft", planPublishingHandler.GetPendingChanges)
+			trainerApiGroup.DELETE("/plans/:planId/draft", planPublishingHandler.DiscardPendingChanges)
 
 			trainerApiGroup.PUT("/plans/:planId/workouts/:workoutId", trainerHandler.UpdateWorkout)
 			trainerApiGroup.DELETE("/plans/:planId/workouts/:workoutId", trainerHandler.DeleteWorkout)
 
 			trainerApiGroup.PUT("/workouts/:workoutId/assignments/:assignmentId", trainerHandler.UpdateAssignmentInWorkout)
 			trainerApiGroup.DELETE("/workouts/:workoutId/assignments/:assignmentId", trainerHandler.DeleteAssignmentFromWorkout)
+
+			// --- Bulk ordering & all-or-nothing batch edits ---
+			trainerApiGroup.PUT("/workouts/:workoutId/assignments/order", trainerHandler.ReorderAssignments)
+			trainerApiGroup.POST("/workouts/:workoutId/assignments/batch", trainerHandler.BatchEditAssignments)
+			trainerApiGroup.PUT("/plans/:planId/workouts/order", trainerHandler.ReorderWorkouts)
+
+			// --- Workout Blocks (supersets, circuits, EMOM, AMRAP) ---
+			trainerApiGroup.POST("/workouts/:workoutId/blocks", trainerHandler.CreateWorkoutBlock)
+			trainerApiGroup.GET("/workouts/:workoutId/blocks", trainerHandler.GetWorkoutStructure) // Nested blocks + assignments
+			trainerApiGroup.PUT("/workouts/:workoutId/blocks/:blockId", trainerHandler.UpdateWorkoutBlock)
+			trainerApiGroup.DELETE("/workouts/:workoutId/blocks/:blockId", trainerHandler.DeleteWorkoutBlock)
+
+			// --- Messaging (conversation per client, threads per plan/workout/assignment) ---
+			trainerApiGroup.POST("/clients/:clientId/conversation", messagingHandler.StartTrainerConversation)
+			trainerApiGroup.GET("/conversations", messagingHandler.ListConversations)
+			trainerApiGroup.GET("/conversations/:conversationId/messages", messagingHandler.GetMessages) // ?before=&limit=&anchorType=&anchorId=
+			trainerApiGroup.POST("/conversations/:conversationId/messages", messagingHandler.SendMessage)
+			trainerApiGroup.GET("/conversations/:conversationId/threads", messagingHandler.GetThreads)
+			trainerApiGroup.POST("/conversations/:conversationId/read", messagingHandler.MarkConversationRead)
+			trainerApiGroup.GET("/messages/unread-count", messagingHandler.GetUnreadMessageCount)
+
+			// --- Outbound webhooks for integrations (signed, retried, logged) ---
+			trainerApiGroup.POST("/webhooks", webhookHandler.CreateWebhook)
+			trainerApiGroup.GET("/webhooks", webhookHandler.ListWebhooks)
+			trainerApiGroup.GET("/webhooks/:webhookId", webhookHandler.GetWebhook)
+			trainerApiGroup.PUT("/webhooks/:webhookId", webhookHandler.UpdateWebhook)
+			trainerApiGroup.DELETE("/webhooks/:webhookId", webhookHandler.DeleteWebhook)
+			trainerApiGroup.POST("/webhooks/:webhookId/ping", webhookHandler.PingWebhook)
+			trainerApiGroup.GET("/webhooks/:webhookId/deliveries", webhookHandler.ListWebhookDeliveries) // ?before=&limit=
+			trainerApiGroup.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+			// --- Weekly email digest (opt-in) ---
+			trainerApiGroup.GET("/digest/settings", digestHandler.GetDigestSettings)
+			trainerApiGroup.PUT("/digest/settings", digestHandler.UpdateDigestSettings)
+			trainerApiGroup.GET("/digest/preview", digestHandler.PreviewDigest) // ?weekOf=YYYY-MM-DD&format=json|html|text
 		}
 
 		clientApiGroup := protected.Group("/client")
@@ -135,16 +278,52 @@ func SetupRoutes(
 
 			// Assignments (exercises) for a specific workout of the client
 			clientApiGroup.GET("/workouts/:workoutId/assignments", clientHandler.GetAssignmentsForMyWorkout)
+			// Same workout as nested blocks (supersets, circuits, ...) with their assignments
+			clientApiGroup.GET("/workouts/:workoutId/blocks", clientHandler.GetWorkoutStructureForMyWorkout)
 
 			clientApiGroup.PATCH("/assignments/:assignmentId/status", clientHandler.UpdateMyAssignmentStatus)
 			
 			// --- Routes for Upload Process ---
 			clientApiGroup.POST("/assignments/:assignmentId/upload-url", clientHandler.RequestUploadURLForAssignment)
 			clientApiGroup.POST("/assignments/:assignmentId/upload-confirm", clientHandler.ConfirmUploadForAssignment)
+			// Upload history: list, choose the primary upload, delete one (also removes it from storage)
+			clientApiGroup.GET("/assignments/:assignmentId/uploads", clientHandler.GetMyUploadsForAssignment)
+			clientApiGroup.PUT("/assignments/:assignmentId/uploads/:uploadId/primary", clientHandler.SetMyPrimaryUpload)
+			clientApiGroup.DELETE("/assignments/:assignmentId/uploads/:uploadId", clientHandler.DeleteMyUpload)
+			// Trainer's video / voice replies (download URLs)
+			clientApiGroup.GET("/assignments/:assignmentId/feedback-attachments", clientHandler.GetMyFeedbackAttachments)
+			// Trainer's timestamped comments on an upload (read, and mark resolved)
+			clientApiGroup.GET("/uploads/:uploadId/comments", feedbackHandler.GetMyFeedbackCommentsForUpload)
+			clientApiGroup.PUT("/comments/:commentId/resolved", feedbackHandler.ResolveMyFeedbackComment)
+
+			// --- Multipart / resumable uploads for large videos ---
+			clientApiGroup.POST("/assignments/:assignmentId/multipart-uploads", clientHandler.InitiateMultipartUpload)
+			clientApiGroup.POST("/assignments/:assignmentId/multipart-uploads/parts", clientHandler.PresignMultipartParts)
+			clientApiGroup.GET("/assignments/:assignmentId/multipart-uploads/parts", clientHandler.ListMultipartParts) // Resume: parts already received
+			clientApiGroup.POST("/assignments/:assignmentId/multipart-uploads/complete", clientHandler.CompleteMultipartUpload)
+			clientApiGroup.POST("/assignments/:assignmentId/multipart-uploads/abort", clientHandler.AbortMultipartUpload)
 
 			// --- NEW Route for Logging Performance ---
 			clientApiGroup.PATCH("/assignments/:assignmentId/performance", clientHandler.LogPerformanceForMyAssignment)
+			clientApiGroup.POST("/assignments/:assignmentId/sets", clientHandler.LogSetForMyAssignment) // Per-round logging
 			clientApiGroup.GET("/workouts/today", clientHandler.GetMyCurrentWorkouts)
+			// Daily workout reminder and missed-workout nudge (times, time zone, on/off)
+			clientApiGroup.GET("/reminders", reminderHandler.GetMyReminderSettings)
+			clientApiGroup.PUT("/reminders", reminderHandler.UpdateMyReminderSettings)
+
+			// Trainer's demonstration media for an assigned exercise (short-lived download URLs)
+			clientApiGroup.GET("/assignments/:assignmentId/exercise-media", clientHandler.GetExerciseMediaForMyAssignment)
+			// Exercise instructions as pinned on the assignment (not the latest edit)
+			clientApiGroup.GET("/assignments/:assignmentId/exercise", clientHandler.GetExerciseForMyAssignment)
+
+			// --- Messaging with my trainer ---
+			clientApiGroup.POST("/conversation", messagingHandler.StartClientConversation)
+			clientApiGroup.GET("/conversations", messagingHandler.ListConversations)
+			clientApiGroup.GET("/conversations/:conversationId/messages", messagingHandler.GetMessages)
+			clientApiGroup.POST("/conversations/:conversationId/messages", messagingHandler.SendMessage)
+			clientApiGroup.GET("/conversations/:conversationId/threads", messagingHandler.GetThreads)
+			clientApiGroup.POST("/conversations/:conversationId/read", messagingHandler.MarkConversationRead)
+			clientApiGroup.GET("/messages/unread-count", messagingHandler.GetUnreadMessageCount)
 		}
 	}
 }
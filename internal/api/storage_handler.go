@@ -0,0 +1,137 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/storage"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Largest object or part accepted through signed URLs (S3's single PUT limit).
+	maxSignedUploadSize = 5 << 30 // 5 GiB
+	// Transfers through signed URLs may outlast the server's default read/write timeouts.
+	signedTransferTimeout = time.Hour
+)
+
+// StorageHandler serves presigned URLs for storage backends that have no HTTP
+// endpoint of their own (local disk, in-memory). Requests are authorized by the
+// URL signature, not by a JWT. Routes live under storage.SignedObjectsRoute.
+type StorageHandler struct {
+	backend storage.SignedURLBackend
+}
+
+func NewStorageHandler(backend storage.SignedURLBackend) *StorageHandler {
+	return &StorageHandler{backend: backend}
+}
+
+// extendTransferDeadlines lifts the server's short read/write timeouts for one large transfer.
+func extendTransferDeadlines(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	deadline := time.Now().Add(signedTransferTimeout)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
+
+// abortWithSignedURLError maps signature and storage errors to HTTP responses.
+func abortWithSignedURLError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, storage.ErrInvalidSignature) || errors.Is(err, storage.ErrURLExpired) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrMultipartUploadNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// PutObject godoc
+// @Summary Upload an object or multipart part through a signed URL
+// @Description Target of upload URLs issued by the local and memory storage drivers. Whole-object uploads must send the Content-Type they were signed for.
+// @Tags Storage
+// @Accept octet-stream
+// @Param objectKey path string true "Object key"
+// @Param expires query int true "Expiry (Unix seconds)"
+// @Param signature query string true "HMAC-SHA256 signature"
+// @Success 200 "Stored; the ETag header identifies the content"
+// @Failure 400 {object} gin.H "Wrong Content-Type or body too large"
+// @Failure 403 {object} gin.H "Invalid or expired signature"
+// @Failure 404 {object} gin.H "Multipart upload not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /storage/objects/{objectKey} [put]
+func (h *StorageHandler) PutObject(c *gin.Context) {
+	objectKey := strings.TrimPrefix(c.Param("objectKey"), "/")
+	signed, err := h.backend.VerifySignedURL(http.MethodPut, objectKey, c.Request.URL.Query())
+	if err != nil {
+		abortWithSignedURLError(c, err, "Failed to verify URL.")
+		return
+	}
+
+	extendTransferDeadlines(c)
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedUploadSize)
+
+	var etag string
+	if signed.UploadID != "" {
+		part, err := h.backend.PutPart(c.Request.Context(), objectKey, signed.UploadID, signed.PartNumber, body)
+		if err != nil {
+			log.Printf("Storage: failed to store part %d of '%s': %v", signed.PartNumber, objectKey, err)
+			abortWithSignedURLError(c, err, "Failed to store part.")
+			return
+		}
+		etag = part.ETag
+	} else {
+		// Like S3, the Content-Type header must match the one the URL was signed for.
+		if c.GetHeader("Content-Type") != signed.ContentType {
+			abortWithError(c, http.StatusBadRequest, "Content-Type does not match the signed URL.")
+			return
+		}
+		meta, err := h.backend.PutObject(c.Request.Context(), objectKey, signed.ContentType, body)
+		if err != nil {
+			log.Printf("Storage: failed to store '%s': %v", objectKey, err)
+			abortWithSignedURLError(c, err, "Failed to store object.")
+			return
+		}
+		etag = meta.ETag
+	}
+
+	c.Header("ETag", etag)
+	c.Status(http.StatusOK)
+}
+
+// GetObject godoc
+// @Summary Download an object through a signed URL
+// @Description Target of download URLs issued by the local and memory storage drivers. Supports Range requests for video seeking.
+// @Tags Storage
+// @Produce octet-stream
+// @Param objectKey path string true "Object key"
+// @Param expires query int true "Expiry (Unix seconds)"
+// @Param signature query string true "HMAC-SHA256 signature"
+// @Success 200 "Object content"
+// @Failure 403 {object} gin.H "Invalid or expired signature"
+// @Failure 404 {object} gin.H "Object not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /storage/objects/{objectKey} [get]
+func (h *StorageHandler) GetObject(c *gin.Context) {
+	objectKey := strings.TrimPrefix(c.Param("objectKey"), "/")
+	if _, err := h.backend.VerifySignedURL(http.MethodGet, objectKey, c.Request.URL.Query()); err != nil {
+		abortWithSignedURLError(c, err, "Failed to verify URL.")
+		return
+	}
+
+	reader, meta, err := h.backend.OpenObject(c.Request.Context(), objectKey)
+	if err != nil {
+		abortWithSignedURLError(c, err, "Failed to read object.")
+		return
+	}
+	defer reader.Close()
+
+	extendTransferDeadlines(c)
+	if meta.ContentType != "" {
+		c.Header("Content-Type", meta.ContentType)
+	}
+	c.Header("ETag", meta.ETag)
+	http.ServeContent(c.Writer, c.Request, "", meta.LastModified, reader)
+}
@@ -5,6 +5,7 @@ import (
 	"alcyxob/fitness-app/internal/domain"
 	"alcyxob/fitness-app/internal/service"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -160,6 +161,7 @@ type AssignmentResponse struct {
 	ID         string    `json:"id"`
 	WorkoutID  string    `json:"workoutId"`  // Link to Workout
 	ExerciseID string    `json:"exerciseId"` // Link to Exercise
+	ExerciseRevision int `json:"exerciseRevision,omitempty"` // Pinned exercise revision (0 = predates versioning)
 	AssignedAt time.Time `json:"assignedAt"`
 	Status     string    `json:"status"`
 	// Execution details
@@ -170,12 +172,16 @@ type AssignmentResponse struct {
 	Weight       *string `json:"weight,omitempty"`
 	Duration     *string `json:"duration,omitempty"`
 	Sequence     int     `json:"sequence"`
+	BlockID      *string `json:"blockId,omitempty"` // Superset/circuit block, if grouped
 	TrainerNotes string  `json:"trainerNotes,omitempty"`
 	// Client tracking
 	ClientNotes string  `json:"clientNotes,omitempty"`
 	UploadID    *string `json:"uploadId,omitempty"`
 	Feedback    string  `json:"feedback,omitempty"`
+	FeedbackAttachments []FeedbackAttachmentResponse `json:"feedbackAttachments,omitempty"` // Trainer's video/voice replies; download URLs via the feedback-attachments endpoints
+	SetLogs     []domain.SetLog `json:"setLogs,omitempty"` // Per-round results logged by the client
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Exercise    *ExerciseRevisionResponse `json:"exercise,omitempty"` // Client views only: the pinned exercise content
     // REMOVED: ClientID, TrainerID, DueDate
 }
 
@@ -189,10 +195,16 @@ func MapAssignmentToResponse(a *domain.Assignment) AssignmentResponse {
 		hex := (*a.UploadID).Hex()
 		uploadIDHex = &hex
 	}
+	var blockIDHex *string
+	if a.BlockID != nil && *a.BlockID != primitive.NilObjectID {
+		hex := (*a.BlockID).Hex()
+		blockIDHex = &hex
+	}
 	return AssignmentResponse{
 		ID:         a.ID.Hex(),
 		WorkoutID:  a.WorkoutID.Hex(), // Use WorkoutID
 		ExerciseID: a.ExerciseID.Hex(),
+		ExerciseRevision: a.ExerciseRevision,
 		AssignedAt: a.AssignedAt,
 		Status:     string(a.Status),
 		Sets:       a.Sets,
@@ -202,10 +214,13 @@ func MapAssignmentToResponse(a *domain.Assignment) AssignmentResponse {
 		Weight:     a.Weight,
 		Duration:   a.Duration,
 		Sequence:   a.Sequence,
+		BlockID:    blockIDHex,
         TrainerNotes: a.TrainerNotes,
 		ClientNotes: a.ClientNotes,
 		UploadID:    uploadIDHex,
 		Feedback:    a.Feedback,
+		FeedbackAttachments: MapFeedbackAttachmentsToResponse(a.FeedbackAttachments),
+		SetLogs:     a.SetLogs,
 		UpdatedAt:   a.UpdatedAt,
         // REMOVED: ClientID, TrainerID, DueDate assignments
 	}
@@ -227,20 +242,28 @@ type CreateTrainingPlanRequest struct {
 	Description string     `json:"description"`
 	StartDate   *time.Time `json:"startDate"` // Expect ISO8601 format string e.g., "2024-05-10T00:00:00Z"
 	EndDate     *time.Time `json:"endDate"`
-	IsActive    bool       `json:"isActive"` // Defaults to false if omitted
+	IsActive    bool       `json:"isActive"` // On update: true schedules the plan if StartDate is in the future (published plans only). Ignored on create: publishing activates new plans
+}
+
+// UpdateTrainingPlanStatusRequest moves a plan through its lifecycle.
+type UpdateTrainingPlanStatusRequest struct {
+	Status string `json:"status" binding:"required"` // draft, scheduled, active or archived
 }
 
 type TrainingPlanResponse struct {
-	ID          string     `json:"id"`
-	TrainerID   string     `json:"trainerId"`
-	ClientID    string     `json:"clientId"`
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	StartDate   *time.Time `json:"startDate,omitempty"`
-	EndDate     *time.Time `json:"endDate,omitempty"`
-	IsActive    bool       `json:"isActive"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID            string     `json:"id"`
+	TrainerID     string     `json:"trainerId"`
+	ClientID      string     `json:"clientId"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	StartDate     *time.Time `json:"startDate,omitempty"`
+	EndDate       *time.Time `json:"endDate,omitempty"`
+	IsActive      bool       `json:"isActive"`
+	Status        string     `json:"status"`                  // draft, scheduled, active, completed or archived
+	PublishedAt   *time.Time `json:"publishedAt,omitempty"`   // Unset until published; the client cannot see the plan before
+	ChangeSummary []string   `json:"changeSummary,omitempty"` // What the last publish changed, one line per change
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 // MapTrainingPlanToResponse converts domain.TrainingPlan to DTO
@@ -249,16 +272,19 @@ func MapTrainingPlanToResponse(p *domain.TrainingPlan) TrainingPlanResponse {
 		return TrainingPlanResponse{}
 	}
 	return TrainingPlanResponse{
-		ID:          p.ID.Hex(),
-		TrainerID:   p.TrainerID.Hex(),
-		ClientID:    p.ClientID.Hex(),
-		Name:        p.Name,
-		Description: p.Description,
-		StartDate:   p.StartDate,
-		EndDate:     p.EndDate,
-		IsActive:    p.IsActive,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:            p.ID.Hex(),
+		TrainerID:     p.TrainerID.Hex(),
+		ClientID:      p.ClientID.Hex(),
+		Name:          p.Name,
+		Description:   p.Description,
+		StartDate:     p.StartDate,
+		EndDate:       p.EndDate,
+		IsActive:      p.IsActive,
+		Status:        string(p.EffectiveStatus()),
+		PublishedAt:   p.PublishedAt,
+		ChangeSummary: p.ChangeSummary,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
 	}
 }
 
@@ -275,7 +301,7 @@ func MapTrainingPlansToResponse(plans []domain.TrainingPlan) []TrainingPlanRespo
 
 // CreateTrainingPlan godoc
 // @Summary Create a new training plan for a client
-// @Description Creates a training plan for a specific client managed by the authenticated trainer.
+// @Description Creates a training plan for a specific client managed by the authenticated trainer. The plan starts as an unpublished draft the client cannot see; publish it with POST /trainer/plans/{planId}/publish.
 // @Tags Trainer Plans
 // @Accept json
 // @Produce json
@@ -452,7 +478,7 @@ func MapWorkoutsToResponse(workouts []domain.Workout) []WorkoutResponse {
 
 // CreateWorkout godoc
 // @Summary Create a new workout within a training plan
-// @Description Creates a workout session associated with a specific training plan owned by the trainer.
+// @Description Creates a workout session associated with a specific training plan owned by the trainer. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Accept json
 // @Produce json
@@ -544,7 +570,7 @@ func (h *TrainerHandler) CreateWorkout(c *gin.Context) {
 
 // GetWorkoutsForPlan godoc
 // @Summary Get workouts for a specific training plan
-// @Description Retrieves all workouts associated with a specific training plan owned by the trainer.
+// @Description Retrieves all workouts associated with a specific training plan owned by the trainer, including changes not yet published.
 // @Tags Trainer Workouts
 // @Produce json
 // @Security BearerAuth
@@ -610,7 +636,8 @@ type AssignExerciseToWorkoutRequest struct {
 	Tempo        *string `json:"tempo" binding:"omitempty"`                         // e.g., "2010"
 	Weight       *string `json:"weight" binding:"omitempty"`                        // e.g., "10kg", "BW", "RPE 8"
 	Duration     *string `json:"duration" binding:"omitempty"`                      // e.g., "30min", "5km"
-	Sequence     *int     `json:"sequence" binding:"required,min=0"` // Order within workout
+	Sequence     *int     `json:"sequence" binding:"required,min=0"` // Order within workout (or within its block)
+	BlockID      *string `json:"blockId" binding:"omitempty"`       // Optional WorkoutBlock ObjectID hex; omit for an ungrouped exercise
 	TrainerNotes string  `json:"trainerNotes" binding:"omitempty"`
 	// Note: We don't include WorkoutID in the *body* because it's in the URL path.
 }
@@ -619,7 +646,7 @@ type AssignExerciseToWorkoutRequest struct {
 
 // AssignExerciseToWorkout godoc
 // @Summary Assign an exercise to a specific workout
-// @Description Adds an exercise with specific parameters (sets, reps, etc.) to a workout within a plan owned by the trainer.
+// @Description Adds an exercise with specific parameters (sets, reps, etc.) to a workout within a plan owned by the trainer. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Accept json
 // @Produce json
@@ -627,7 +654,7 @@ type AssignExerciseToWorkoutRequest struct {
 // @Param workoutId path string true "Workout's ObjectID Hex"
 // @Param assignmentRequest body AssignExerciseToWorkoutRequest true "Exercise assignment details"
 // @Success 201 {object} AssignmentResponse "Exercise assigned successfully to workout"
-// @Failure 400 {object} gin.H "Invalid input (validation error, invalid IDs)"
+// @Failure 400 {object} gin.H "Invalid input (validation error, invalid IDs, sequence already used)"
 // @Failure 401 {object} gin.H "Unauthorized"
 // @Failure 403 {object} gin.H "Forbidden (not a trainer, or does not own the workout/exercise)"
 // @Failure 404 {object} gin.H "Workout or Exercise not found"
@@ -682,8 +709,15 @@ func (h *TrainerHandler) AssignExerciseToWorkout(c *gin.Context) {
     }
     sequenceVal = *req.Sequence
 
+    blockID, err := parseOptionalObjectID(req.BlockID)
+    if err != nil {
+        abortWithError(c, http.StatusBadRequest, "Invalid block ID format.")
+        return
+    }
+
     assignmentDetails := domain.Assignment{
         // WorkoutID and ExerciseID will be set/validated by the service
+        BlockID:        blockID,
         Sets:           req.Sets,
         Reps:           req.Reps,
         Rest:           req.Rest,
@@ -706,8 +740,10 @@ func (h *TrainerHandler) AssignExerciseToWorkout(c *gin.Context) {
 	)
 	if err != nil {
 		// Map service errors
-		if errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrExerciseNotFound) {
+		if errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrExerciseNotFound) || errors.Is(err, service.ErrWorkoutBlockNotFound) {
 			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrDuplicateSequence) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
 		} else if errors.Is(err, service.ErrTrainingPlanAccessDenied) || errors.Is(err, service.ErrExerciseAccessDenied) || errors.Is(err, errors.New("access denied: trainer does not own this workout")) { // Crude check for now
             abortWithError(c, http.StatusForbidden, err.Error())
         } else {
@@ -723,7 +759,7 @@ func (h *TrainerHandler) AssignExerciseToWorkout(c *gin.Context) {
 
 // GetAssignmentsForWorkout godoc
 // @Summary Get all assigned exercises for a specific workout
-// @Description Retrieves all exercises assigned to a particular workout owned by the trainer.
+// @Description Retrieves all exercises assigned to a particular workout owned by the trainer, including changes not yet published.
 // @Tags Trainer Workouts
 // @Produce json
 // @Security BearerAuth
@@ -907,6 +943,105 @@ func (h *TrainerHandler) SubmitFeedbackForAssignment(c *gin.Context) {
     c.JSON(http.StatusOK, MapAssignmentToResponse(updatedAssignment))
 }
 
+// BulkFeedbackItemRequest is the feedback and/or status for one assignment.
+// Omit feedback to keep the existing text; omit status to keep the status.
+type BulkFeedbackItemRequest struct {
+	AssignmentID string  `json:"assignmentId" binding:"required"`
+	Feedback     *string `json:"feedback"`
+	Status       string  `json:"status"` // e.g., "reviewed" or "completed"
+}
+
+// BulkFeedbackRequest defines the payload for feedback on many assignments at once.
+type BulkFeedbackRequest struct {
+	Items []BulkFeedbackItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// BulkFeedbackResultResponse reports the outcome of one item.
+type BulkFeedbackResultResponse struct {
+	Index        int                 `json:"index"`
+	AssignmentID string              `json:"assignmentId"`
+	Success      bool                `json:"success"`
+	Status       int                 `json:"status,omitempty"` // HTTP status the item would have had on its own, on failure
+	Error        string              `json:"error,omitempty"`
+	Assignment   *AssignmentResponse `json:"assignment,omitempty"` // On success
+}
+
+// bulkFeedbackErrorStatus maps one item's error to the status SubmitFeedbackForAssignment would return.
+func bulkFeedbackErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, service.ErrAssignmentAccessDenied):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, service.ErrInvalidBulkFeedback) || errors.Is(err, service.ErrInvalidFeedbackStatus):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, "Failed to submit feedback."
+	}
+}
+
+// BulkSubmitFeedback godoc
+// @Summary Submit feedback and/or status for many assignments
+// @Description Applies feedback text and/or a status change to each listed assignment. Items are independent: each result reports success or why that item failed, and the others are still applied. Up to 100 items.
+// @Tags Trainer Assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param feedbackRequest body BulkFeedbackRequest true "Per-assignment feedback and status"
+// @Success 200 {object} gin.H "results: per-item results in request order; succeeded/failed: counts"
+// @Failure 400 {object} gin.H "Invalid request (validation error, invalid ID, too many items)"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/assignments/feedback [patch]
+func (h *TrainerHandler) BulkSubmitFeedback(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	var req BulkFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	items := make([]service.BulkFeedbackItem, len(req.Items))
+	for i, item := range req.Items {
+		assignmentID, err := primitive.ObjectIDFromHex(item.AssignmentID)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Sprintf("Item %d: invalid assignment ID.", i))
+			return
+		}
+		items[i] = service.BulkFeedbackItem{
+			AssignmentID: assignmentID,
+			Feedback:     item.Feedback,
+			Status:       domain.AssignmentStatus(item.Status),
+		}
+	}
+
+	results, err := h.trainerService.BulkSubmitFeedback(c.Request.Context(), trainerID, items)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkFeedback) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to submit feedback.")
+		}
+		return
+	}
+
+	responses := make([]BulkFeedbackResultResponse, len(results))
+	succeeded := 0
+	for i, r := range results {
+		responses[i] = BulkFeedbackResultResponse{Index: r.Index, AssignmentID: r.AssignmentID.Hex(), Success: r.Err == nil}
+		if r.Err != nil {
+			responses[i].Status, responses[i].Error = bulkFeedbackErrorStatus(r.Err)
+			continue
+		}
+		succeeded++
+		resp := MapAssignmentToResponse(r.Assignment)
+		responses[i].Assignment = &resp
+	}
+	c.JSON(http.StatusOK, gin.H{"results": responses, "succeeded": succeeded, "failed": len(results) - succeeded})
+}
+
 // UpdateTrainingPlan godoc
 // @Summary Update an existing training plan
 // @Description Updates details of a training plan for a specific client owned by the trainer.
@@ -922,6 +1057,7 @@ func (h *TrainerHandler) SubmitFeedbackForAssignment(c *gin.Context) {
 // @Failure 401 {object} gin.H "Unauthorized"
 // @Failure 403 {object} gin.H "Forbidden (not a trainer, or client/plan not owned)"
 // @Failure 404 {object} gin.H "Training Plan or Client not found"
+// @Failure 409 {object} gin.H "isActive set on a plan that has not been published"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /trainer/clients/{clientId}/plans/{planId} [put]
 func (h *TrainerHandler) UpdateTrainingPlan(c *gin.Context) {
@@ -974,6 +1110,8 @@ func (h *TrainerHandler) UpdateTrainingPlan(c *gin.Context) {
             abortWithError(c, http.StatusNotFound, err.Error())
         } else if errors.Is(err, service.ErrTrainingPlanAccessDenied) || errors.Is(err, service.ErrClientNotManaged) || errors.Is(err, errors.New("cannot change the client associated with a training plan via update")) {
             abortWithError(c, http.StatusForbidden, err.Error())
+        } else if errors.Is(err, service.ErrPlanNotPublished) {
+            abortWithError(c, http.StatusConflict, err.Error())
         } else {
             // log.Printf("Error updating training plan %s: %v", planIDHex, err)
             abortWithError(c, http.StatusInternalServerError, "Failed to update training plan.")
@@ -984,6 +1122,51 @@ func (h *TrainerHandler) UpdateTrainingPlan(c *gin.Context) {
     c.JSON(http.StatusOK, MapTrainingPlanToResponse(updatedPlan))
 }
 
+// UpdateTrainingPlanStatus godoc
+// @Summary Change a training plan's lifecycle status
+// @Description Moves a plan to draft, scheduled (needs a future startDate; activates on it), active (replaces the client's current plan) or archived. Scheduling and activating need the plan to be published. Plans become completed automatically after their endDate.
+// @Tags Trainer Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Param statusRequest body UpdateTrainingPlanStatusRequest true "New status"
+// @Success 200 {object} TrainingPlanResponse "Updated training plan"
+// @Failure 400 {object} gin.H "Invalid ID or status not allowed for the plan's dates"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer, or plan not owned)"
+// @Failure 404 {object} gin.H "Training plan not found"
+// @Failure 409 {object} gin.H "Plan not published yet"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/status [put]
+func (h *TrainerHandler) UpdateTrainingPlanStatus(c *gin.Context) {
+	trainerID, planID, ok := userAndPathID(c, "planId", "training plan")
+	if !ok { return }
+
+	var req UpdateTrainingPlanStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	plan, err := h.trainerService.SetTrainingPlanStatus(c.Request.Context(), trainerID, planID, domain.PlanStatus(req.Status))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPlanStatus) {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+		} else if errors.Is(err, service.ErrTrainingPlanNotFound) {
+			abortWithError(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, service.ErrTrainingPlanAccessDenied) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else if errors.Is(err, service.ErrPlanNotPublished) {
+			abortWithError(c, http.StatusConflict, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to update training plan status.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapTrainingPlanToResponse(plan))
+}
+
 // DeleteTrainingPlan godoc
 // @Summary Delete a training plan
 // @Description Deletes a training plan (and potentially its associated workouts/assignments) for a client.
@@ -1037,7 +1220,7 @@ func (h *TrainerHandler) DeleteTrainingPlan(c *gin.Context) {
 
 // UpdateWorkout godoc
 // @Summary Update an existing workout within a plan
-// @Description Updates details of a workout in a specific plan owned by the trainer.
+// @Description Updates details of a workout in a specific plan owned by the trainer. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Accept json
 // @Produce json
@@ -1112,7 +1295,7 @@ func (h *TrainerHandler) UpdateWorkout(c *gin.Context) {
 
 // DeleteWorkout godoc
 // @Summary Delete a workout from a plan
-// @Description Deletes a workout (and potentially its assignments) from a specific plan.
+// @Description Deletes a workout (and potentially its assignments) from a specific plan. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Produce json
 // @Security BearerAuth
@@ -1156,7 +1339,7 @@ func (h *TrainerHandler) DeleteWorkout(c *gin.Context) {
 
 // UpdateAssignmentInWorkout godoc
 // @Summary Update an existing exercise assignment within a workout
-// @Description Updates parameters (sets, reps, notes, etc.) of an exercise assigned to a workout.
+// @Description Updates parameters (sets, reps, notes, etc.) of an exercise assigned to a workout. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Accept json
 // @Produce json
@@ -1165,7 +1348,7 @@ func (h *TrainerHandler) DeleteWorkout(c *gin.Context) {
 // @Param assignmentId path string true "Assignment's ObjectID Hex to update"
 // @Param assignmentRequest body AssignExerciseToWorkoutRequest true "Updated assignment details"
 // @Success 200 {object} AssignmentResponse "Assignment updated successfully"
-// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 400 {object} gin.H "Invalid input, or sequence already used by another exercise"
 // @Failure 401 {object} gin.H "Unauthorized"
 // @Failure 403 {object} gin.H "Forbidden"
 // @Failure 404 {object} gin.H "Workout, Assignment, or Exercise not found"
@@ -1211,9 +1394,13 @@ func (h *TrainerHandler) UpdateAssignmentInWorkout(c *gin.Context) {
 	// If req.ExerciseID is empty, it means we are not changing the exercise,
 	// the service layer will use the existing one from the fetched assignment.
 
+	blockID, err := parseOptionalObjectID(req.BlockID)
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid block ID format."); return }
+
 	updates := domain.Assignment{
 			// Service layer will use existingAssignment.ID and existingAssignment.WorkoutID
 			ExerciseID:   exerciseID, // Pass the new one if provided, or NilObjectID
+			BlockID:      blockID,    // nil removes the exercise from its block
 			Sets:         req.Sets,
 			Reps:         req.Reps,
 			Rest:         req.Rest,
@@ -1229,8 +1416,10 @@ func (h *TrainerHandler) UpdateAssignmentInWorkout(c *gin.Context) {
 	updatedAssignment, err := h.trainerService.UpdateAssignmentInWorkout(c.Request.Context(), trainerID, workoutID, assignmentID, updates)
 	if err != nil {
 			// Map service errors appropriately (NotFound, Forbidden, etc.)
-			if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrExerciseNotFound) {
+			if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrExerciseNotFound) || errors.Is(err, service.ErrWorkoutBlockNotFound) {
 					 abortWithError(c, http.StatusNotFound, err.Error())
+			} else if errors.Is(err, service.ErrDuplicateSequence) {
+					 abortWithError(c, http.StatusBadRequest, err.Error())
 			} else if errors.Is(err, service.ErrAssignmentAccessDenied) || errors.Is(err, service.ErrExerciseAccessDenied) || errors.Is(err, errors.New("access denied: trainer does not own this workout")) {
 					 abortWithError(c, http.StatusForbidden, err.Error())
 			} else {
@@ -1244,7 +1433,7 @@ func (h *TrainerHandler) UpdateAssignmentInWorkout(c *gin.Context) {
 
 // DeleteAssignmentFromWorkout godoc
 // @Summary Delete an exercise assignment from a workout
-// @Description Removes an exercise assignment from a specific workout.
+// @Description Removes an exercise assignment from a specific workout. On a published plan the change is staged: the client sees it after the plan is published again.
 // @Tags Trainer Workouts
 // @Produce json
 // @Security BearerAuth
@@ -1280,4 +1469,860 @@ func (h *TrainerHandler) DeleteAssignmentFromWorkout(c *gin.Context) {
 			return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Assignment deleted successfully"})
-}
\ No newline at end of file
+}
+
+// --- DTOs for Workout Blocks ---
+
+// WorkoutBlockRequest defines the payload for creating or replacing a workout block.
+type WorkoutBlockRequest struct {
+	Label             string  `json:"label" binding:"omitempty"`                                          // e.g., "A"
+	Type              string  `json:"type" binding:"required,oneof=straight_sets superset circuit emom amrap"` // Block type
+	Rounds            int     `json:"rounds" binding:"omitempty,min=0"`
+	RestBetweenRounds *string `json:"restBetweenRounds" binding:"omitempty"` // e.g., "90s"
+	TimeCap           *string `json:"timeCap" binding:"omitempty"`           // Required for AMRAP, e.g., "12min"
+	Sequence          *int    `json:"sequence" binding:"required,min=0"`     // Order within the workout
+	Notes             string  `json:"notes" binding:"omitempty"`
+}
+
+// WorkoutBlockResponse is the DTO for a block of a workout with its nested assignments.
+// Ungrouped exercises are returned as straight-set blocks without an ID.
+type WorkoutBlockResponse struct {
+	ID                string               `json:"id,omitempty"`
+	WorkoutID         string               `json:"workoutId"`
+	Label             string               `json:"label,omitempty"`
+	Type              string               `json:"type"`
+	Rounds            int                  `json:"rounds"`
+	RestBetweenRounds *string              `json:"restBetweenRounds,omitempty"`
+	TimeCap           *string              `json:"timeCap,omitempty"`
+	Sequence          int                  `json:"sequence"`
+	Notes             string               `json:"notes,omitempty"`
+	Assignments       []AssignmentResponse `json:"assignments"`
+}
+
+// parseOptionalObjectID converts an optional hex string into an ObjectID pointer; empty means nil.
+func parseOptionalObjectID(hex *string) (*primitive.ObjectID, error) {
+	if hex == nil || *hex == "" {
+		return nil, nil
+	}
+	id, err := primitive.ObjectIDFromHex(*hex)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// MapWorkoutBlockToResponse converts a domain.WorkoutBlock (without assignments) to its DTO.
+func MapWorkoutBlockToResponse(b *domain.WorkoutBlock) WorkoutBlockResponse {
+	resp := WorkoutBlockResponse{
+		WorkoutID:         b.WorkoutID.Hex(),
+		Label:             b.Label,
+		Type:              string(b.Type),
+		Rounds:            b.Rounds,
+		RestBetweenRounds: b.RestBetweenRounds,
+		TimeCap:           b.TimeCap,
+		Sequence:          b.Sequence,
+		Notes:             b.Notes,
+		Assignments:       []AssignmentResponse{},
+	}
+	if b.ID != primitive.NilObjectID {
+		resp.ID = b.ID.Hex()
+	}
+	return resp
+}
+
+// MapWorkoutStructureToResponse converts nested block details to DTOs.
+func MapWorkoutStructureToResponse(blocks []service.WorkoutBlockDetails) []WorkoutBlockResponse {
+	responses := make([]WorkoutBlockResponse, len(blocks))
+	for i, b := range blocks {
+		responses[i] = MapWorkoutBlockToResponse(&b.WorkoutBlock)
+		responses[i].Assignments = MapAssignmentsToResponse(b.Assignments)
+	}
+	return responses
+}
+
+// workoutBlockFromRequest builds the domain block from the request DTO.
+func workoutBlockFromRequest(req *WorkoutBlockRequest) domain.WorkoutBlock {
+	return domain.WorkoutBlock{
+		Label:             req.Label,
+		Type:              domain.BlockType(req.Type),
+		Rounds:            req.Rounds,
+		RestBetweenRounds: req.RestBetweenRounds,
+		TimeCap:           req.TimeCap,
+		Sequence:          *req.Sequence,
+		Notes:             req.Notes,
+	}
+}
+
+// abortWithWorkoutBlockError maps workout block service errors to HTTP responses.
+func abortWithWorkoutBlockError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrWorkoutBlockNotFound) || errors.Is(err, service.ErrTrainingPlanNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrInvalidWorkoutBlock) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, service.ErrWorkoutAccessDenied) || errors.Is(err, service.ErrTrainingPlanAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Workout Blocks ---
+
+// CreateWorkoutBlock godoc
+// @Summary Add a block (superset, circuit, EMOM, AMRAP) to a workout
+// @Description Creates a grouping block in a workout. Assign exercises to it by passing blockId when assigning or updating exercises. On a published plan the change is staged: the client sees it after the plan is published again.
+// @Tags Trainer Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Param block body WorkoutBlockRequest true "Block details"
+// @Success 201 {object} WorkoutBlockResponse "Block created"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout)"
+// @Failure 404 {object} gin.H "Workout not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/blocks [post]
+func (h *TrainerHandler) CreateWorkoutBlock(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+
+	var req WorkoutBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	block, err := h.trainerService.CreateWorkoutBlock(c.Request.Context(), trainerID, workoutID, workoutBlockFromRequest(&req))
+	if err != nil {
+		abortWithWorkoutBlockError(c, err, "Failed to create workout block.")
+		return
+	}
+	c.JSON(http.StatusCreated, MapWorkoutBlockToResponse(block))
+}
+
+// GetWorkoutStructure godoc
+// @Summary Get a workout as nested blocks
+// @Description Returns the workout's blocks in order, each with its assignments, including changes not yet published. Ungrouped exercises appear as straight-set blocks without an ID.
+// @Tags Trainer Workouts
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Success 200 {array} WorkoutBlockResponse "Blocks with nested assignments"
+// @Failure 400 {object} gin.H "Invalid workout ID"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout)"
+// @Failure 404 {object} gin.H "Workout not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/blocks [get]
+func (h *TrainerHandler) GetWorkoutStructure(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	blocks, err := h.trainerService.GetWorkoutStructure(c.Request.Context(), trainerID, workoutID)
+	if err != nil {
+		abortWithWorkoutBlockError(c, err, "Failed to retrieve workout structure.")
+		return
+	}
+	c.JSON(http.StatusOK, MapWorkoutStructureToResponse(blocks))
+}
+
+// UpdateWorkoutBlock godoc
+// @Summary Update a workout block
+// @Tags Trainer Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Param blockId path string true "Block's ObjectID Hex"
+// @Param block body WorkoutBlockRequest true "Updated block details"
+// @Success 200 {object} WorkoutBlockResponse "Block updated"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout)"
+// @Failure 404 {object} gin.H "Workout or block not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/blocks/{blockId} [put]
+func (h *TrainerHandler) UpdateWorkoutBlock(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+	blockID, err := primitive.ObjectIDFromHex(c.Param("blockId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid block ID."); return }
+
+	var req WorkoutBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	block, err := h.trainerService.UpdateWorkoutBlock(c.Request.Context(), trainerID, workoutID, blockID, workoutBlockFromRequest(&req))
+	if err != nil {
+		abortWithWorkoutBlockError(c, err, "Failed to update workout block.")
+		return
+	}
+	c.JSON(http.StatusOK, MapWorkoutBlockToResponse(block))
+}
+
+// DeleteWorkoutBlock godoc
+// @Summary Delete a workout block
+// @Description Removes the block; its exercises stay in the workout as ungrouped assignments. On a published plan the change is staged: the client sees it after the plan is published again.
+// @Tags Trainer Workouts
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Param blockId path string true "Block's ObjectID Hex"
+// @Success 200 {object} gin.H "message: Workout block deleted successfully"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout)"
+// @Failure 404 {object} gin.H "Workout or block not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/blocks/{blockId} [delete]
+func (h *TrainerHandler) DeleteWorkoutBlock(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+	blockID, err := primitive.ObjectIDFromHex(c.Param("blockId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid block ID."); return }
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	if err := h.trainerService.DeleteWorkoutBlock(c.Request.Context(), trainerID, workoutID, blockID); err != nil {
+		abortWithWorkoutBlockError(c, err, "Failed to delete workout block.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Workout block deleted successfully"})
+}
+
+// GetUploadsForAssignment godoc
+// @Summary List all uploads for a client's assignment
+// @Description Returns every video the client uploaded for the assignment (retries, other angles), oldest first, with short-lived download URLs and the primary upload marked.
+// @Tags Trainer Assignments
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {array} UploadResponse "List of uploads (can be empty)"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (trainer does not own this assignment/workout)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/assignments/{assignmentId}/uploads [get]
+func (h *TrainerHandler) GetUploadsForAssignment(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	uploads, err := h.trainerService.GetUploadsForAssignment(c.Request.Context(), trainerID, assignmentID)
+	if err != nil {
+		abortWithUploadError(c, err, "Failed to retrieve uploads.")
+		return
+	}
+	c.JSON(http.StatusOK, MapUploadDetailsToResponse(uploads))
+}
+
+// GetStorageUsage godoc
+// @Summary Get video storage usage
+// @Description Returns the bytes and number of uploads stored by each of the trainer's clients and in total, with the configured quotas (0 = unlimited).
+// @Tags Trainer
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.TrainerStorageUsage "Storage usage and quotas"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/storage/usage [get]
+func (h *TrainerHandler) GetStorageUsage(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	usage, err := h.trainerService.GetStorageUsage(c.Request.Context(), trainerID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "Failed to retrieve storage usage.")
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// --- DTOs for the Review Queue ---
+
+// ReviewQueueUploadResponse summarizes the upload the trainer will review.
+type ReviewQueueUploadResponse struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Attempt     int       `json:"attempt,omitempty"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
+
+// ReviewQueueItemResponse is one submitted assignment awaiting review.
+type ReviewQueueItemResponse struct {
+	Assignment    AssignmentResponse         `json:"assignment"`
+	ClientID      string                     `json:"clientId"`
+	ClientName    string                     `json:"clientName"`
+	ClientEmail   string                     `json:"clientEmail"`
+	ExerciseName  string                     `json:"exerciseName"`
+	WorkoutName   string                     `json:"workoutName"`
+	SubmittedAt   *time.Time                 `json:"submittedAt,omitempty"` // Missing for submissions made before it was recorded
+	PrimaryUpload *ReviewQueueUploadResponse `json:"primaryUpload,omitempty"`
+	UploadCount   int64                      `json:"uploadCount"`
+}
+
+// MapReviewQueueToResponse converts review queue items to DTOs.
+func MapReviewQueueToResponse(items []service.ReviewQueueItem) []ReviewQueueItemResponse {
+	responses := make([]ReviewQueueItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = ReviewQueueItemResponse{
+			Assignment:   MapAssignmentToResponse(&item.Assignment),
+			ClientID:     item.Assignment.ClientID.Hex(),
+			ClientName:   item.ClientName,
+			ClientEmail:  item.ClientEmail,
+			ExerciseName: item.ExerciseName,
+			WorkoutName:  item.WorkoutName,
+			SubmittedAt:  item.Assignment.SubmittedAt,
+			UploadCount:  item.UploadCount,
+		}
+		if u := item.PrimaryUpload; u != nil {
+			responses[i].PrimaryUpload = &ReviewQueueUploadResponse{
+				ID:          u.ID.Hex(),
+				FileName:    u.FileName,
+				ContentType: u.ContentType,
+				Size:        u.Size,
+				Attempt:     u.Attempt,
+				UploadedAt:  u.UploadedAt,
+			}
+		}
+	}
+	return responses
+}
+
+// GetReviewQueue godoc
+// @Summary Get the review queue
+// @Description Lists submitted assignments across all of the trainer's clients, oldest submission first, with client, exercise and primary upload details. Use the uploads endpoints for download URLs.
+// @Tags Trainer
+// @Produce json
+// @Security BearerAuth
+// @Param clientId query string false "Only this client's submissions (ObjectID Hex)"
+// @Success 200 {array} ReviewQueueItemResponse "Submitted assignments (can be empty)"
+// @Failure 400 {object} gin.H "Invalid client ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (client not managed by this trainer)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/review-queue [get]
+func (h *TrainerHandler) GetReviewQueue(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	var clientID *primitive.ObjectID
+	if raw := c.Query("clientId"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid client ID."); return }
+		clientID = &id
+	}
+
+	items, err := h.trainerService.GetReviewQueue(c.Request.Context(), trainerID, clientID)
+	if err != nil {
+		if errors.Is(err, service.ErrClientNotManaged) {
+			abortWithError(c, http.StatusForbidden, err.Error())
+		} else {
+			abortWithError(c, http.StatusInternalServerError, "Failed to retrieve review queue.")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, MapReviewQueueToResponse(items))
+}
+
+// --- DTOs for Feedback Attachments ---
+
+// RequestFeedbackAttachmentUploadURLRequest asks for a URL to upload a video or voice reply.
+type RequestFeedbackAttachmentUploadURLRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=video audio"`
+	ContentType string `json:"contentType" binding:"required"`
+}
+
+// ConfirmFeedbackAttachmentRequest attaches an uploaded reply to the assignment.
+type ConfirmFeedbackAttachmentRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=video audio"`
+	ObjectKey   string `json:"objectKey" binding:"required"`
+	FileName    string `json:"fileName" binding:"required"`
+	FileSize    int64  `json:"fileSize" binding:"required,min=1"`
+	ContentType string `json:"contentType" binding:"required"`
+	DurationMs  *int64 `json:"durationMs" binding:"omitempty,min=0"`
+}
+
+// FeedbackAttachmentResponse is one trainer reply attached to an assignment.
+type FeedbackAttachmentResponse struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	DurationMs  *int64    `json:"durationMs,omitempty"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+	DownloadURL string    `json:"downloadUrl,omitempty"` // Short-lived URL, only set on list endpoints
+}
+
+// MapFeedbackAttachmentToResponse converts a domain.FeedbackAttachment to a DTO (without download URL).
+func MapFeedbackAttachmentToResponse(a *domain.FeedbackAttachment) FeedbackAttachmentResponse {
+	return FeedbackAttachmentResponse{
+		ID:          a.ID.Hex(),
+		Kind:        string(a.Kind),
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		DurationMs:  a.DurationMs,
+		UploadedAt:  a.UploadedAt,
+	}
+}
+
+// MapFeedbackAttachmentsToResponse converts an assignment's embedded attachments to DTOs.
+func MapFeedbackAttachmentsToResponse(attachments []domain.FeedbackAttachment) []FeedbackAttachmentResponse {
+	if len(attachments) == 0 {
+		return nil
+	}
+	responses := make([]FeedbackAttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		responses[i] = MapFeedbackAttachmentToResponse(&a)
+	}
+	return responses
+}
+
+// MapFeedbackAttachmentDetailsToResponse converts attachments with download URLs to DTOs.
+func MapFeedbackAttachmentDetailsToResponse(details []service.FeedbackAttachmentDetails) []FeedbackAttachmentResponse {
+	responses := make([]FeedbackAttachmentResponse, len(details))
+	for i, d := range details {
+		responses[i] = MapFeedbackAttachmentToResponse(&d.FeedbackAttachment)
+		responses[i].DownloadURL = d.DownloadURL
+	}
+	return responses
+}
+
+// abortWithFeedbackAttachmentError maps feedback attachment service errors to HTTP responses.
+func abortWithFeedbackAttachmentError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrFeedbackAttachmentNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrAssignmentAccessDenied) || errors.Is(err, service.ErrAssignmentNotBelongToClient) || errors.Is(err, service.ErrUploadObjectKeyInvalid) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else if errors.Is(err, service.ErrInvalidFeedbackAttachment) || errors.Is(err, service.ErrUploadObjectNotFound) || errors.Is(err, service.ErrUploadMetadataMismatch) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, service.ErrUploadTooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, err.Error())
+	} else {
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Feedback Attachments ---
+
+// RequestFeedbackAttachmentUploadURL godoc
+// @Summary Request a pre-signed URL to upload a video or voice reply
+// @Description Trainer requests a temporary URL to upload a demonstration video or voice note in reply to a client's submission.
+// @Tags Trainer Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param uploadRequest body RequestFeedbackAttachmentUploadURLRequest true "Attachment kind and content type"
+// @Success 200 {object} service.UploadURLResponse "Pre-signed URL and object key"
+// @Failure 400 {object} gin.H "Invalid input (bad kind or content type)"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (trainer does not own this assignment/workout)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error (e.g., S3 error)"
+// @Router /trainer/assignments/{assignmentId}/feedback-attachments/upload-url [post]
+func (h *TrainerHandler) RequestFeedbackAttachmentUploadURL(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req RequestFeedbackAttachmentUploadURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	resp, err := h.trainerService.RequestFeedbackAttachmentUploadURL(c.Request.Context(), trainerID, assignmentID, domain.FeedbackAttachmentKind(req.Kind), req.ContentType)
+	if err != nil {
+		abortWithFeedbackAttachmentError(c, err, "Failed to get attachment upload URL.")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ConfirmFeedbackAttachment godoc
+// @Summary Attach an uploaded video or voice reply to an assignment
+// @Description Checks the uploaded object (key, size, content type; max 512 MiB) and attaches it to the assignment's feedback.
+// @Tags Trainer Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param confirmRequest body ConfirmFeedbackAttachmentRequest true "Upload confirmation details"
+// @Success 201 {object} AssignmentResponse "Assignment with its attachments"
+// @Failure 400 {object} gin.H "Invalid input, object not uploaded, or size/content type mismatch"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not the trainer's assignment, or foreign object key)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 413 {object} gin.H "Attachment too large"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/assignments/{assignmentId}/feedback-attachments [post]
+func (h *TrainerHandler) ConfirmFeedbackAttachment(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	var req ConfirmFeedbackAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	assignment, err := h.trainerService.ConfirmFeedbackAttachment(
+		c.Request.Context(),
+		trainerID,
+		assignmentID,
+		domain.FeedbackAttachmentKind(req.Kind),
+		req.ObjectKey,
+		req.FileName,
+		req.FileSize,
+		req.ContentType,
+		req.DurationMs,
+	)
+	if err != nil {
+		abortWithFeedbackAttachmentError(c, err, "Failed to attach feedback media.")
+		return
+	}
+	c.JSON(http.StatusCreated, MapAssignmentToResponse(assignment))
+}
+
+// GetFeedbackAttachments godoc
+// @Summary List video and voice replies on an assignment
+// @Description Returns the trainer's attachments on the assignment, oldest first, with short-lived download URLs.
+// @Tags Trainer Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Success 200 {array} FeedbackAttachmentResponse "Attachments (can be empty)"
+// @Failure 400 {object} gin.H "Invalid assignment ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (trainer does not own this assignment/workout)"
+// @Failure 404 {object} gin.H "Assignment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/assignments/{assignmentId}/feedback-attachments [get]
+func (h *TrainerHandler) GetFeedbackAttachments(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+
+	attachments, err := h.trainerService.GetFeedbackAttachments(c.Request.Context(), trainerID, assignmentID)
+	if err != nil {
+		abortWithFeedbackAttachmentError(c, err, "Failed to retrieve feedback attachments.")
+		return
+	}
+	c.JSON(http.StatusOK, MapFeedbackAttachmentDetailsToResponse(attachments))
+}
+
+// DeleteFeedbackAttachment godoc
+// @Summary Delete a video or voice reply
+// @Description Detaches the reply from the assignment and removes its file from storage.
+// @Tags Trainer Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param assignmentId path string true "Assignment's ObjectID Hex"
+// @Param attachmentId path string true "Attachment's ObjectID Hex"
+// @Success 200 {object} AssignmentResponse "Assignment with its remaining attachments"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (trainer does not own this assignment/workout)"
+// @Failure 404 {object} gin.H "Assignment or attachment not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/assignments/{assignmentId}/feedback-attachments/{attachmentId} [delete]
+func (h *TrainerHandler) DeleteFeedbackAttachment(c *gin.Context) {
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("assignmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID."); return }
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachmentId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid attachment ID."); return }
+
+	assignment, err := h.trainerService.DeleteFeedbackAttachment(c.Request.Context(), trainerID, assignmentID, attachmentID)
+	if err != nil {
+		abortWithFeedbackAttachmentError(c, err, "Failed to delete feedback attachment.")
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentToResponse(assignment))
+}
+
+// --- DTOs for Bulk Ordering & Editing ---
+
+// ReorderAssignmentsRequest lists every assignment of a workout in the desired order.
+type ReorderAssignmentsRequest struct {
+	AssignmentIDs []string `json:"assignmentIds" binding:"required,min=1"`
+}
+
+// ReorderWorkoutsRequest lists every workout of a plan in the desired order.
+type ReorderWorkoutsRequest struct {
+	WorkoutIDs []string `json:"workoutIds" binding:"required,min=1"`
+}
+
+// BatchAssignmentOperationRequest is one create, update or delete in a batch edit.
+type BatchAssignmentOperationRequest struct {
+	Op           string                          `json:"op" binding:"required,oneof=create update delete"`
+	AssignmentID string                          `json:"assignmentId"`                         // Required for update/delete
+	Assignment   *AssignExerciseToWorkoutRequest `json:"assignment" binding:"omitempty"` // Required for create/update
+}
+
+// BatchAssignmentsRequest defines the payload for an all-or-nothing batch edit.
+type BatchAssignmentsRequest struct {
+	Operations []BatchAssignmentOperationRequest `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BatchAssignmentResultResponse reports the outcome of one operation.
+type BatchAssignmentResultResponse struct {
+	Index        int                 `json:"index"`
+	Op           string              `json:"op"`
+	AssignmentID string              `json:"assignmentId"`
+	Assignment   *AssignmentResponse `json:"assignment,omitempty"` // Omitted for deletes
+}
+
+// parseObjectIDList converts a list of hex strings into ObjectIDs.
+func parseObjectIDList(hexes []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, len(hexes))
+	for i, h := range hexes {
+		id, err := primitive.ObjectIDFromHex(h)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// batchOperationFromRequest converts one batch operation DTO into the service type.
+func batchOperationFromRequest(req *BatchAssignmentOperationRequest) (service.AssignmentBatchOperation, error) {
+	op := service.AssignmentBatchOperation{Op: service.AssignmentBatchOp(req.Op)}
+	if req.Op != string(service.AssignmentBatchCreate) {
+		id, err := primitive.ObjectIDFromHex(req.AssignmentID)
+		if err != nil {
+			return op, errors.New("assignmentId is required for update and delete")
+		}
+		op.AssignmentID = id
+	}
+	if req.Op == string(service.AssignmentBatchDelete) {
+		return op, nil
+	}
+
+	if req.Assignment == nil || req.Assignment.Sequence == nil {
+		return op, errors.New("assignment with a sequence is required for create and update")
+	}
+	exerciseID, err := primitive.ObjectIDFromHex(req.Assignment.ExerciseID)
+	if err != nil {
+		return op, errors.New("invalid exerciseId")
+	}
+	blockID, err := parseOptionalObjectID(req.Assignment.BlockID)
+	if err != nil {
+		return op, errors.New("invalid blockId")
+	}
+	op.ExerciseID = exerciseID
+	op.Assignment = domain.Assignment{
+		BlockID:      blockID,
+		Sets:         req.Assignment.Sets,
+		Reps:         req.Assignment.Reps,
+		Rest:         req.Assignment.Rest,
+		Tempo:        req.Assignment.Tempo,
+		Weight:       req.Assignment.Weight,
+		Duration:     req.Assignment.Duration,
+		Sequence:     *req.Assignment.Sequence,
+		TrainerNotes: req.Assignment.TrainerNotes,
+	}
+	return op, nil
+}
+
+// abortWithBulkEditError maps reorder and batch service errors to HTTP responses.
+func abortWithBulkEditError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, service.ErrReorderMismatch) || errors.Is(err, service.ErrDuplicateSequence) || errors.Is(err, service.ErrInvalidBatchOperation) {
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, service.ErrWorkoutNotFound) || errors.Is(err, service.ErrTrainingPlanNotFound) ||
+		errors.Is(err, service.ErrAssignmentNotFound) || errors.Is(err, service.ErrExerciseNotFound) || errors.Is(err, service.ErrWorkoutBlockNotFound) {
+		abortWithError(c, http.StatusNotFound, err.Error())
+	} else if errors.Is(err, service.ErrWorkoutAccessDenied) || errors.Is(err, service.ErrTrainingPlanAccessDenied) || errors.Is(err, service.ErrExerciseAccessDenied) {
+		abortWithError(c, http.StatusForbidden, err.Error())
+	} else {
+		var batchErr *service.BatchOperationError
+		if errors.As(err, &batchErr) {
+			// Failed while writing; the whole batch was rolled back.
+			abortWithError(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods for Bulk Ordering & Editing ---
+
+// ReorderAssignments godoc
+// @Summary Reorder all exercises in a workout
+// @Description Atomically sets the order of a workout's assignments. The list must contain every assignment of the workout exactly once. On a published plan the change is staged: the client sees it after the plan is published again.
+// @Tags Trainer Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Param order body ReorderAssignmentsRequest true "Assignment IDs in the new order"
+// @Success 200 {array} AssignmentResponse "Assignments in their new order"
+// @Failure 400 {object} gin.H "Invalid input or list does not match the workout's assignments"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout)"
+// @Failure 404 {object} gin.H "Workout not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/assignments/order [put]
+func (h *TrainerHandler) ReorderAssignments(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+
+	var req ReorderAssignmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	orderedIDs, err := parseObjectIDList(req.AssignmentIDs)
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid assignment ID in list."); return }
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	assignments, err := h.trainerService.ReorderAssignments(c.Request.Context(), trainerID, workoutID, orderedIDs)
+	if err != nil {
+		abortWithBulkEditError(c, err, "Failed to reorder assignments.")
+		return
+	}
+	c.JSON(http.StatusOK, MapAssignmentsToResponse(assignments))
+}
+
+// BatchEditAssignments godoc
+// @Summary Create, update and delete many assignments at once
+// @Description Applies all operations to the workout or none of them. Operations run in order; the result leaves no duplicate sequence numbers. On a published plan the change is staged: the client sees it after the plan is published again.
+// @Tags Trainer Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workoutId path string true "Workout's ObjectID Hex"
+// @Param batch body BatchAssignmentsRequest true "Operations"
+// @Success 200 {object} gin.H "results: per-operation results in request order"
+// @Failure 400 {object} gin.H "Invalid operation or duplicate sequence"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the workout or an exercise)"
+// @Failure 404 {object} gin.H "Workout, assignment, exercise or block not found"
+// @Failure 422 {object} gin.H "An operation failed; nothing was applied"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/workouts/{workoutId}/assignments/batch [post]
+func (h *TrainerHandler) BatchEditAssignments(c *gin.Context) {
+	workoutID, err := primitive.ObjectIDFromHex(c.Param("workoutId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID."); return }
+
+	var req BatchAssignmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	ops := make([]service.AssignmentBatchOperation, len(req.Operations))
+	for i := range req.Operations {
+		op, err := batchOperationFromRequest(&req.Operations[i])
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Sprintf("Operation %d: %v", i, err))
+			return
+		}
+		ops[i] = op
+	}
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	results, err := h.trainerService.BatchEditAssignments(c.Request.Context(), trainerID, workoutID, ops)
+	if err != nil {
+		abortWithBulkEditError(c, err, "Failed to apply batch edit.")
+		return
+	}
+
+	responses := make([]BatchAssignmentResultResponse, len(results))
+	for i, r := range results {
+		responses[i] = BatchAssignmentResultResponse{Index: r.Index, Op: string(r.Op), AssignmentID: r.AssignmentID.Hex()}
+		if r.Assignment != nil {
+			resp := MapAssignmentToResponse(r.Assignment)
+			responses[i].Assignment = &resp
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"results": responses})
+}
+
+// ReorderWorkouts godoc
+// @Summary Reorder all workouts in a plan
+// @Description Atomically sets the order of a plan's workouts. The list must contain every workout of the plan exactly once. On a published plan the change is staged: the client sees it after the plan is published again.
+// @Tags Trainer Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param planId path string true "Training Plan's ObjectID Hex"
+// @Param order body ReorderWorkoutsRequest true "Workout IDs in the new order"
+// @Success 200 {array} WorkoutResponse "Workouts in their new order"
+// @Failure 400 {object} gin.H "Invalid input or list does not match the plan's workouts"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (does not own the plan)"
+// @Failure 404 {object} gin.H "Plan not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/plans/{planId}/workouts/order [put]
+func (h *TrainerHandler) ReorderWorkouts(c *gin.Context) {
+	planID, err := primitive.ObjectIDFromHex(c.Param("planId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid plan ID."); return }
+
+	var req ReorderWorkoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+	orderedIDs, err := parseObjectIDList(req.WorkoutIDs)
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid workout ID in list."); return }
+
+	trainerIDStr, err := getUserIDFromContext(c)
+	if err != nil { abortWithError(c, http.StatusUnauthorized, "Unauthorized."); return }
+	trainerID, _ := primitive.ObjectIDFromHex(trainerIDStr)
+
+	workouts, err := h.trainerService.ReorderWorkouts(c.Request.Context(), trainerID, planID, orderedIDs)
+	if err != nil {
+		abortWithBulkEditError(c, err, "Failed to reorder workouts.")
+		return
+	}
+	c.JSON(http.StatusOK, MapWorkoutsToResponse(workouts))
+}
@@ -0,0 +1,378 @@
+package api
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookHandler manages a trainer's outbound webhooks for integrations.
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// --- DTOs for Webhooks ---
+
+// WebhookEndpointRequest creates or replaces a webhook endpoint.
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	EventTypes  []string `json:"eventTypes" binding:"required,min=1"` // assignment.submitted, assignment.reviewed, plan.created, client.linked
+	Description string   `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"` // Defaults to true on create; unchanged on update when omitted
+}
+
+// WebhookEndpointResponse is a webhook endpoint. The secret is only included when the
+// endpoint is created or fetched individually.
+type WebhookEndpointResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"eventTypes"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // HMAC-SHA256 key for verifying X-Webhook-Signature
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookAttemptResponse is one POST of a delivery.
+type WebhookAttemptResponse struct {
+	At           time.Time `json:"at"`
+	StatusCode   int       `json:"statusCode,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+	DurationMs   int64     `json:"durationMs"`
+}
+
+// WebhookDeliveryResponse is one entry of the delivery log.
+type WebhookDeliveryResponse struct {
+	ID            string                   `json:"id"`
+	EventID       string                   `json:"eventId"`
+	EventType     string                   `json:"eventType"`
+	Payload       string                   `json:"payload"` // The exact JSON body sent
+	Status        string                   `json:"status"`  // pending, succeeded or failed
+	Attempts      []WebhookAttemptResponse `json:"attempts"`
+	NextAttemptAt *time.Time               `json:"nextAttemptAt,omitempty"`
+	RedeliveryOf  *string                  `json:"redeliveryOf,omitempty"`
+	CreatedAt     time.Time                `json:"createdAt"`
+}
+
+// WebhookDeliveryPageResponse is one page of the delivery log, newest first.
+type WebhookDeliveryPageResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	NextBefore *string                   `json:"nextBefore,omitempty"` // Pass as ?before= for older deliveries
+}
+
+// MapWebhookEndpointToResponse converts a domain.WebhookEndpoint to a WebhookEndpointResponse DTO.
+func MapWebhookEndpointToResponse(e *domain.WebhookEndpoint, withSecret bool) WebhookEndpointResponse {
+	resp := WebhookEndpointResponse{
+		ID:          e.ID.Hex(),
+		URL:         e.URL,
+		EventTypes:  make([]string, len(e.EventTypes)),
+		Description: e.Description,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+	for i, eventType := range e.EventTypes {
+		resp.EventTypes[i] = string(eventType)
+	}
+	if withSecret {
+		resp.Secret = e.Secret
+	}
+	return resp
+}
+
+// MapWebhookDeliveryToResponse converts a domain.WebhookDelivery to a WebhookDeliveryResponse DTO.
+func MapWebhookDeliveryToResponse(d *domain.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:            d.ID.Hex(),
+		EventID:       d.EventID,
+		EventType:     string(d.EventType),
+		Payload:       d.Payload,
+		Status:        string(d.Status),
+		Attempts:      make([]WebhookAttemptResponse, len(d.Attempts)),
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	}
+	for i, a := range d.Attempts {
+		resp.Attempts[i] = WebhookAttemptResponse{
+			At:           a.At,
+			StatusCode:   a.StatusCode,
+			Error:        a.Error,
+			ResponseBody: a.ResponseBody,
+			DurationMs:   a.DurationMs,
+		}
+	}
+	if d.RedeliveryOf != nil {
+		hex := d.RedeliveryOf.Hex()
+		resp.RedeliveryOf = &hex
+	}
+	return resp
+}
+
+// toWebhookInput converts the request DTO to service input.
+func (req *WebhookEndpointRequest) toWebhookInput() service.WebhookEndpointInput {
+	input := service.WebhookEndpointInput{
+		URL:         req.URL,
+		EventTypes:  make([]domain.WebhookEventType, len(req.EventTypes)),
+		Description: req.Description,
+		Active:      req.Active,
+	}
+	for i, eventType := range req.EventTypes {
+		input.EventTypes[i] = domain.WebhookEventType(eventType)
+	}
+	return input
+}
+
+// abortWithWebhookError maps webhook service errors to HTTP responses.
+func abortWithWebhookError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound), errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		abortWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidWebhook):
+		abortWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrWebhookLimitReached), errors.Is(err, service.ErrWebhookInactive):
+		abortWithError(c, http.StatusConflict, err.Error())
+	default:
+		abortWithError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// --- Handler Methods ---
+
+// CreateWebhook godoc
+// @Summary Register a webhook endpoint
+// @Description Subscribes a URL to events. Each delivery is a JSON POST signed with the returned secret: the X-Webhook-Signature header is "t=<unix>,v1=<hex HMAC-SHA256 of '<t>.<body>'>". Failed deliveries (non-2xx or no answer within 10s) are retried with exponential backoff for about an hour. Store the secret; it is only shown here and on GET /trainer/webhooks/{webhookId}.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body WebhookEndpointRequest true "URL and event types"
+// @Success 201 {object} WebhookEndpointResponse "Created endpoint, with its secret"
+// @Failure 400 {object} gin.H "Invalid URL or event type"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 409 {object} gin.H "Endpoint limit reached"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	trainerID, ok := currentUserID(c)
+	if !ok { return }
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	endpoint, err := h.webhookService.CreateEndpoint(c.Request.Context(), trainerID, req.toWebhookInput())
+	if err != nil { abortWithWebhookError(c, err, "Failed to create webhook."); return }
+	c.JSON(http.StatusCreated, MapWebhookEndpointToResponse(endpoint, true))
+}
+
+// ListWebhooks godoc
+// @Summary List my webhook endpoints
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} WebhookEndpointResponse "Endpoints (without secrets)"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	trainerID, ok := currentUserID(c)
+	if !ok { return }
+
+	endpoints, err := h.webhookService.ListEndpoints(c.Request.Context(), trainerID)
+	if err != nil { abortWithWebhookError(c, err, "Failed to retrieve webhooks."); return }
+	resp := make([]WebhookEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		resp[i] = MapWebhookEndpointToResponse(&endpoints[i], false)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook endpoint
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Success 200 {object} WebhookEndpointResponse "Endpoint, with its secret"
+// @Failure 400 {object} gin.H "Invalid webhook ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+
+	endpoint, err := h.webhookService.GetEndpoint(c.Request.Context(), trainerID, endpointID)
+	if err != nil { abortWithWebhookError(c, err, "Failed to retrieve webhook."); return }
+	c.JSON(http.StatusOK, MapWebhookEndpointToResponse(endpoint, true))
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook endpoint
+// @Description Replaces the URL, event types and description. Omit active to keep the current setting; deactivating pauses deliveries and fails pending retries.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Param webhook body WebhookEndpointRequest true "New settings"
+// @Success 200 {object} WebhookEndpointResponse "Updated endpoint"
+// @Failure 400 {object} gin.H "Invalid input"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Request.Context(), trainerID, endpointID, req.toWebhookInput())
+	if err != nil { abortWithWebhookError(c, err, "Failed to update webhook."); return }
+	c.JSON(http.StatusOK, MapWebhookEndpointToResponse(endpoint, false))
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook endpoint
+// @Description Deletes the endpoint and its delivery log; pending retries are dropped.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Success 204 "Webhook deleted"
+// @Failure 400 {object} gin.H "Invalid webhook ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+
+	if err := h.webhookService.DeleteEndpoint(c.Request.Context(), trainerID, endpointID); err != nil {
+		abortWithWebhookError(c, err, "Failed to delete webhook.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PingWebhook godoc
+// @Summary Send a test event
+// @Description Immediately POSTs a signed "ping" event to the endpoint, once, and returns the logged delivery with the endpoint's answer.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Success 200 {object} WebhookDeliveryResponse "The ping delivery; status is succeeded or failed"
+// @Failure 400 {object} gin.H "Invalid webhook ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook not found"
+// @Failure 409 {object} gin.H "Webhook is inactive"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId}/ping [post]
+func (h *WebhookHandler) PingWebhook(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+
+	delivery, err := h.webhookService.Ping(c.Request.Context(), trainerID, endpointID)
+	if err != nil { abortWithWebhookError(c, err, "Failed to ping webhook."); return }
+	c.JSON(http.StatusOK, MapWebhookDeliveryToResponse(delivery))
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List a webhook's deliveries
+// @Description The delivery log, newest first, with every attempt and the endpoint's answers. Entries are kept for 30 days.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Param before query string false "Return deliveries older than this ID (nextBefore of the previous page)"
+// @Param limit query int false "Page size (default 30, max 100)"
+// @Success 200 {object} WebhookDeliveryPageResponse "One page of deliveries"
+// @Failure 400 {object} gin.H "Invalid ID, cursor or limit"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+
+	var query service.WebhookDeliveryQuery
+	if raw := c.Query("before"); raw != "" {
+		before, err := primitive.ObjectIDFromHex(raw)
+		if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid before cursor."); return }
+		query.BeforeID = &before
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 1 { abortWithError(c, http.StatusBadRequest, "Invalid limit."); return }
+		query.Limit = limit
+	}
+
+	page, err := h.webhookService.ListDeliveries(c.Request.Context(), trainerID, endpointID, query)
+	if err != nil { abortWithWebhookError(c, err, "Failed to retrieve webhook deliveries."); return }
+	resp := WebhookDeliveryPageResponse{Deliveries: make([]WebhookDeliveryResponse, len(page.Deliveries))}
+	for i := range page.Deliveries {
+		resp.Deliveries[i] = MapWebhookDeliveryToResponse(&page.Deliveries[i])
+	}
+	if page.NextBeforeID != nil {
+		hex := page.NextBeforeID.Hex()
+		resp.NextBefore = &hex
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a logged event
+// @Description Queues the same payload (same event ID) as a new delivery with a fresh retry schedule.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook endpoint's ObjectID Hex"
+// @Param deliveryId path string true "Delivery's ObjectID Hex"
+// @Success 202 {object} WebhookDeliveryResponse "The queued delivery"
+// @Failure 400 {object} gin.H "Invalid ID format"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Forbidden (not a trainer)"
+// @Failure 404 {object} gin.H "Webhook or delivery not found"
+// @Failure 409 {object} gin.H "Webhook is inactive"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /trainer/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	trainerID, endpointID, ok := userAndPathID(c, "webhookId", "webhook")
+	if !ok { return }
+	deliveryID, err := primitive.ObjectIDFromHex(c.Param("deliveryId"))
+	if err != nil { abortWithError(c, http.StatusBadRequest, "Invalid delivery ID."); return }
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), trainerID, endpointID, deliveryID)
+	if err != nil { abortWithWebhookError(c, err, "Failed to redeliver webhook."); return }
+	c.JSON(http.StatusAccepted, MapWebhookDeliveryToResponse(delivery))
+}
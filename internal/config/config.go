@@ -11,10 +11,17 @@ import (
 // Config holds all configuration for the application.
 // The values are read by Viper from a config file or environment variables.
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	S3       S3Config       `mapstructure:"s3"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	S3        S3Config        `mapstructure:"s3"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Admin     AdminConfig     `mapstructure:"admin"`
+	Quotas    QuotaConfig     `mapstructure:"quotas"`
+	Push      PushConfig      `mapstructure:"push"`
+	Webhooks  WebhookConfig   `mapstructure:"webhooks"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Mail      MailConfig      `mapstructure:"mail"`
 }
 
 type ServerConfig struct {
@@ -36,6 +43,85 @@ type S3Config struct {
 	UseSSL          bool   `mapstructure:"use_ssl"`
 }
 
+// StorageConfig selects the FileStorage backend.
+type StorageConfig struct {
+	Driver        string `mapstructure:"driver"`          // "s3" (default), "local" or "memory"
+	LocalPath     string `mapstructure:"local_path"`      // Root directory for the "local" driver
+	PublicBaseURL string `mapstructure:"public_base_url"` // This server's URL as seen by clients; signed URLs for "local"/"memory" point here
+	SigningKey    string `mapstructure:"signing_key"`     // HMAC key for "local"/"memory" URLs; falls back to the JWT secret
+
+	// Reconciliation of stored objects with upload, exercise media and feedback records
+	GCInterval    time.Duration `mapstructure:"gc_interval"`     // How often the scheduler runs it; 0 disables the background job
+	GCGracePeriod time.Duration `mapstructure:"gc_grace_period"` // Unrecorded objects and multipart uploads younger than this are kept
+}
+
+// AdminConfig protects operator endpoints (/api/v1/admin). They are disabled when APIKey is empty.
+type AdminConfig struct {
+	APIKey string `mapstructure:"api_key"` // Sent in the X-Admin-Key header
+}
+
+// QuotaConfig limits client video uploads. Sizes are in bytes; 0 means unlimited.
+type QuotaConfig struct {
+	TrainerBytes int64 `mapstructure:"trainer_bytes"` // Total across all of a trainer's clients
+	ClientBytes  int64 `mapstructure:"client_bytes"`  // Total per client
+	MaxFileSize  int64 `mapstructure:"max_file_size"` // Per upload; content types have their own, larger caps
+}
+
+// PushConfig selects how notifications reach the iOS and Android apps.
+type PushConfig struct {
+	Driver      string     `mapstructure:"driver"`       // "log" (default; logs instead of sending) or "live"
+	APNs        APNsConfig `mapstructure:"apns"`         // iOS; with "live", unset means iOS pushes are only logged
+	FCM         FCMConfig  `mapstructure:"fcm"`          // Android; likewise
+	Workers     int        `mapstructure:"workers"`      // Concurrent deliveries
+	MaxAttempts int        `mapstructure:"max_attempts"` // Per device, including the first try
+}
+
+// APNsConfig holds token-based (.p8 key) credentials for Apple Push Notification service.
+type APNsConfig struct {
+	KeyID      string `mapstructure:"key_id"`
+	TeamID     string `mapstructure:"team_id"`
+	Topic      string `mapstructure:"topic"`      // The iOS app's bundle ID
+	KeyFile    string `mapstructure:"key_file"`   // Path to the .p8 key
+	Production bool   `mapstructure:"production"` // false uses the sandbox (development builds)
+}
+
+// FCMConfig holds the Firebase service account used for Android pushes.
+type FCMConfig struct {
+	ProjectID       string `mapstructure:"project_id"`       // Defaults to the service account's project
+	CredentialsFile string `mapstructure:"credentials_file"` // Path to the service account JSON key
+}
+
+// WebhookConfig controls outbound webhook delivery.
+type WebhookConfig struct {
+	PollInterval        time.Duration `mapstructure:"poll_interval"`         // How often workers look for due retries
+	AllowPrivateTargets bool          `mapstructure:"allow_private_targets"` // Allow localhost/private IPs; for development only
+}
+
+// SchedulerConfig controls the periodic jobs. Only one server instance (the holder of
+// the "scheduler" lease in Mongo) runs them at a time.
+type SchedulerConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`                 // false = this instance never runs jobs
+	ReminderInterval      time.Duration `mapstructure:"reminder_interval"`       // How often due reminders and nudges are sent
+	DigestInterval        time.Duration `mapstructure:"digest_interval"`         // How often due weekly digests are looked for
+	PlanLifecycleInterval time.Duration `mapstructure:"plan_lifecycle_interval"` // How often plans are activated/completed by their dates
+	PlanEndingNotice      time.Duration `mapstructure:"plan_ending_notice"`      // How long before EndDate trainers hear a plan ends with none queued
+}
+
+// MailConfig selects how email (e.g. the trainers' weekly digest) is sent.
+type MailConfig struct {
+	Driver string     `mapstructure:"driver"` // "log" (default; logs instead of sending) or "smtp"
+	From   string     `mapstructure:"from"`   // e.g. "Fitness App <no-reply@example.com>"
+	SMTP   SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig holds the relay used with the "smtp" mail driver.
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`     // 587 (STARTTLS) if unset
+	Username string `mapstructure:"username"` // Empty disables authentication
+	Password string `mapstructure:"password"`
+}
+
 // JWTConfig defines JWT specific configuration
 type JWTConfig struct {
 	Secret string `mapstructure:"secret"`
@@ -67,6 +153,37 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.BindEnv("s3.secret_access_key", "S3_SECRET_ACCESS_KEY") // If using access keys for S3
 	viper.BindEnv("s3.bucket_name", "S3_BUCKET_NAME")
 	viper.BindEnv("s3.use_ssl", "S3_USE_SSL")
+	viper.BindEnv("storage.driver", "STORAGE_DRIVER")
+	viper.BindEnv("storage.local_path", "STORAGE_LOCAL_PATH")
+	viper.BindEnv("storage.public_base_url", "STORAGE_PUBLIC_BASE_URL")
+	viper.BindEnv("storage.signing_key", "STORAGE_SIGNING_KEY")
+	viper.BindEnv("storage.gc_interval", "STORAGE_GC_INTERVAL")
+	viper.BindEnv("storage.gc_grace_period", "STORAGE_GC_GRACE_PERIOD")
+	viper.BindEnv("admin.api_key", "ADMIN_API_KEY")
+	viper.BindEnv("quotas.trainer_bytes", "QUOTA_TRAINER_BYTES")
+	viper.BindEnv("quotas.client_bytes", "QUOTA_CLIENT_BYTES")
+	viper.BindEnv("quotas.max_file_size", "QUOTA_MAX_FILE_SIZE")
+	viper.BindEnv("push.driver", "PUSH_DRIVER")
+	viper.BindEnv("push.apns.key_id", "PUSH_APNS_KEY_ID")
+	viper.BindEnv("push.apns.team_id", "PUSH_APNS_TEAM_ID")
+	viper.BindEnv("push.apns.topic", "PUSH_APNS_TOPIC")
+	viper.BindEnv("push.apns.key_file", "PUSH_APNS_KEY_FILE")
+	viper.BindEnv("push.apns.production", "PUSH_APNS_PRODUCTION")
+	viper.BindEnv("push.fcm.project_id", "PUSH_FCM_PROJECT_ID")
+	viper.BindEnv("push.fcm.credentials_file", "PUSH_FCM_CREDENTIALS_FILE")
+	viper.BindEnv("webhooks.poll_interval", "WEBHOOKS_POLL_INTERVAL")
+	viper.BindEnv("webhooks.allow_private_targets", "WEBHOOKS_ALLOW_PRIVATE_TARGETS")
+	viper.BindEnv("scheduler.enabled", "SCHEDULER_ENABLED")
+	viper.BindEnv("scheduler.reminder_interval", "SCHEDULER_REMINDER_INTERVAL")
+	viper.BindEnv("scheduler.digest_interval", "SCHEDULER_DIGEST_INTERVAL")
+	viper.BindEnv("scheduler.plan_lifecycle_interval", "SCHEDULER_PLAN_LIFECYCLE_INTERVAL")
+	viper.BindEnv("scheduler.plan_ending_notice", "SCHEDULER_PLAN_ENDING_NOTICE")
+	viper.BindEnv("mail.driver", "MAIL_DRIVER")
+	viper.BindEnv("mail.from", "MAIL_FROM")
+	viper.BindEnv("mail.smtp.host", "MAIL_SMTP_HOST")
+	viper.BindEnv("mail.smtp.port", "MAIL_SMTP_PORT")
+	viper.BindEnv("mail.smtp.username", "MAIL_SMTP_USERNAME")
+	viper.BindEnv("mail.smtp.password", "MAIL_SMTP_PASSWORD")
 	// Add any other critical env vars here
 
 	// AutomaticEnv can still be used for other variables or as a fallback
@@ -79,6 +196,22 @@ func LoadConfig(path string) (config Config, err error) {
 	// Set defaults (these are lower precedence than ENV and config file)
 	viper.SetDefault("server.address", ":8080")
 	viper.SetDefault("jwt.expiration", "1h")
+	viper.SetDefault("storage.driver", "s3")
+	viper.SetDefault("storage.local_path", "./data/storage")
+	viper.SetDefault("storage.public_base_url", "http://localhost:8080")
+	viper.SetDefault("storage.gc_interval", "6h")
+	viper.SetDefault("storage.gc_grace_period", "24h")
+	viper.SetDefault("push.driver", "log")
+	viper.SetDefault("push.workers", 4)
+	viper.SetDefault("push.max_attempts", 5)
+	viper.SetDefault("webhooks.poll_interval", "15s")
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.reminder_interval", "5m")
+	viper.SetDefault("scheduler.digest_interval", "15m")
+	viper.SetDefault("scheduler.plan_lifecycle_interval", "1h")
+	viper.SetDefault("scheduler.plan_ending_notice", "72h")
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "Fitness App <no-reply@localhost>")
 	// ... other defaults ...
 
 	// Attempt to read the config file
@@ -102,6 +235,7 @@ func LoadConfig(path string) (config Config, err error) {
 	}
 
 	log.Printf("Loaded config: JWT Secret length: %d", len(config.JWT.Secret)) // Check length instead of value for sensitive
+	log.Printf("Loaded config: Storage driver is '%s'", config.Storage.Driver)
 	log.Printf("Loaded config: S3 Bucket is '%s'", config.S3.BucketName)
 	log.Printf("Loaded config: S3 Endpoint is '%s'", config.S3.Endpoint)
 
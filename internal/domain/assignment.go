@@ -21,7 +21,11 @@ type Assignment struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	WorkoutID  primitive.ObjectID `bson:"workoutId" json:"workoutId"`   // <<< CHANGED: Link to the Workout session
 	ExerciseID primitive.ObjectID `bson:"exerciseId" json:"exerciseId"` // Link to the specific Exercise
-    // ClientID/TrainerID are implicitly known via the WorkoutID, but could be denormalized if needed for perf
+	ExerciseRevision int       `bson:"exerciseRevision,omitempty" json:"exerciseRevision,omitempty"` // Pinned ExerciseRevision; 0 means "not pinned yet"
+	RevisionExerciseID *primitive.ObjectID `bson:"revisionExerciseId,omitempty" json:"-"` // Set when merged onto ExerciseID: the exercise whose history ExerciseRevision belongs to
+	// Denormalized from the Workout for cross-workout queries (e.g. the trainer's review queue)
+	TrainerID primitive.ObjectID `bson:"trainerId,omitempty" json:"trainerId,omitempty"`
+	ClientID  primitive.ObjectID `bson:"clientId,omitempty" json:"clientId,omitempty"`
 
 	// --- Exercise Execution Details ---
 	Sets           *int    `bson:"sets,omitempty" json:"sets,omitempty"`
@@ -30,7 +34,8 @@ type Assignment struct {
 	Tempo          *string `bson:"tempo,omitempty" json:"tempo,omitempty"`           // e.g., "2010" (2 sec down, 0 pause, 1 sec up, 0 pause)
 	Weight         *string `bson:"weight,omitempty" json:"weight,omitempty"`         // e.g., "10kg", "BW", "RPE 8"
 	Duration       *string `bson:"duration,omitempty" json:"duration,omitempty"`     // e.g., "30min", "5km" (for cardio/timed)
-	Sequence       int     `bson:"sequence"`                                         // Order of exercise within the workout
+	Sequence       int     `bson:"sequence"`                                         // Order of exercise within the workout (or within its block)
+	BlockID        *primitive.ObjectID `bson:"blockId,omitempty" json:"blockId,omitempty"` // WorkoutBlock this exercise belongs to; nil = ungrouped
 	TrainerNotes   string  `bson:"trainerNotes,omitempty" json:"trainerNotes,omitempty"` // Specific notes for this exercise assignment
 
 	// --- Client Achieved Performance Fields ---
@@ -39,12 +44,35 @@ type Assignment struct {
 	AchievedWeight        *string `bson:"achievedWeight,omitempty" json:"achievedWeight,omitempty"`
 	AchievedDuration      *string `bson:"achievedDuration,omitempty" json:"achievedDuration,omitempty"`
 	ClientPerformanceNotes *string `bson:"clientPerformanceNotes,omitempty" json:"clientPerformanceNotes,omitempty"` // Made this a pointer too for consistency
+	SetLogs               []SetLog `bson:"setLogs,omitempty" json:"setLogs,omitempty"` // Per-set/per-round results, one entry per round
 
     // --- Client Tracking Fields ---
 	AssignedAt     time.Time          `bson:"assignedAt" json:"assignedAt"` // When this specific assignment was configured
 	Status         AssignmentStatus   `bson:"status" json:"status"`
+	SubmittedAt    *time.Time         `bson:"submittedAt,omitempty" json:"submittedAt,omitempty"` // When it last entered StatusSubmitted; review queue order
 	ClientNotes    string             `bson:"clientNotes,omitempty" json:"clientNotes,omitempty"`
 	UploadID       *primitive.ObjectID `bson:"uploadId,omitempty" json:"uploadId,omitempty"` // Link to video proof
 	Feedback       string             `bson:"feedback,omitempty" json:"feedback,omitempty"` // Trainer feedback on submission
+	FeedbackAttachments []FeedbackAttachment `bson:"feedbackAttachments,omitempty" json:"feedbackAttachments,omitempty"` // Trainer's video/voice replies, oldest first
 	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
-}
\ No newline at end of file
+}
+
+// RevisionSource returns the exercise whose revision history ExerciseRevision refers to.
+func (a *Assignment) RevisionSource() primitive.ObjectID {
+	if a.RevisionExerciseID != nil {
+		return *a.RevisionExerciseID
+	}
+	return a.ExerciseID
+}
+
+// SetLog records what the client achieved in one set of an assignment. For grouped
+// blocks (superset, circuit, EMOM, AMRAP) Round is the block round; for straight
+// sets it is the set number.
+type SetLog struct {
+	Round    int       `bson:"round" json:"round"` // 1-based
+	Reps     *string   `bson:"reps,omitempty" json:"reps,omitempty"`
+	Weight   *string   `bson:"weight,omitempty" json:"weight,omitempty"`
+	Duration *string   `bson:"duration,omitempty" json:"duration,omitempty"`
+	Notes    *string   `bson:"notes,omitempty" json:"notes,omitempty"`
+	LoggedAt time.Time `bson:"loggedAt" json:"loggedAt"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DevicePlatform is the push provider family of a registered device.
+type DevicePlatform string
+
+const (
+	PlatformIOS     DevicePlatform = "ios"     // Apple Push Notification service
+	PlatformAndroid DevicePlatform = "android" // Firebase Cloud Messaging
+)
+
+// IsValid reports whether p is a supported platform.
+func (p DevicePlatform) IsValid() bool {
+	return p == PlatformIOS || p == PlatformAndroid
+}
+
+// Device is a phone or tablet that receives push notifications for a user.
+// A push token belongs to one app install, so it is unique across users: signing in
+// as someone else on the same phone moves the token to the new user.
+type Device struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Platform   DevicePlatform     `bson:"platform" json:"platform"`
+	Token      string             `bson:"token" json:"token"`                               // APNs device token or FCM registration token
+	AppVersion string             `bson:"appVersion,omitempty" json:"appVersion,omitempty"` // For support; not used for delivery
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	LastSeenAt time.Time          `bson:"lastSeenAt" json:"lastSeenAt"` // Last registration; apps re-register on launch
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DigestSettings is a trainer's opt-in to the weekly email digest. Trainers without a
+// document (or with Enabled false) get no digest.
+type DigestSettings struct {
+	TrainerID primitive.ObjectID `bson:"_id" json:"trainerId"`
+	Enabled   bool               `bson:"enabled" json:"enabled"`
+	Email     string             `bson:"email,omitempty" json:"email,omitempty"`       // Overrides the account email; empty = account email
+	TimeZone  string             `bson:"timeZone,omitempty" json:"timeZone,omitempty"` // IANA name; weeks run Monday to Sunday here; empty = UTC
+
+	// Monday (YYYY-MM-DD) of the last week a digest was sent for, so each week is sent once.
+	LastSentWeek string `bson:"lastSentWeek,omitempty" json:"-"`
+
+	UpdatedAt time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// Location returns the trainer's time zone, falling back to UTC.
+func (s *DigestSettings) Location() *time.Location {
+	if s.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
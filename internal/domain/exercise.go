@@ -18,9 +18,11 @@ type Exercise struct {
 	ExecutionTechnic string `bson:"executionTechnic,omitempty" json:"executionTechnic,omitempty"` // Detailed instructions
 	Applicability  string `bson:"applicability,omitempty" json:"applicability,omitempty"`     // e.g., "Home", "Gym", "Home/Gym"
 	Difficulty     string `bson:"difficulty,omitempty" json:"difficulty,omitempty"`         // e.g., "Novice", "Medium", "Advanced"
-	VideoURL       string `bson:"videoUrl,omitempty" json:"videoUrl,omitempty"` // Optional URL to an example video (trainer might upload later to S3 and link here)
+	VideoURL       string `bson:"videoUrl,omitempty" json:"videoUrl,omitempty"` // Optional external URL to an example video; uploaded demonstration files are stored as ExerciseMedia
 	// --- END NEW FIELDS ---
 
+	CurrentRevision int `bson:"currentRevision" json:"currentRevision"` // Latest ExerciseRevision number (0 for exercises created before versioning)
+
 	// Instructions field might be redundant now if ExecutionTechnic covers it.
 	// Let's remove 'Instructions' if 'ExecutionTechnic' is more comprehensive.
 	// Instructions string            `bson:"instructions,omitempty" json:"instructions,omitempty"` 
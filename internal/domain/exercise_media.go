@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MediaKind distinguishes the role a media item plays for an exercise.
+type MediaKind string
+
+const (
+	MediaKindVideo     MediaKind = "video"
+	MediaKindImage     MediaKind = "image"
+	MediaKindThumbnail MediaKind = "thumbnail"
+)
+
+// ExerciseMedia stores metadata about a demonstration file uploaded by a trainer
+// for an Exercise. The actual file resides in S3, like client Uploads.
+type ExerciseMedia struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ExerciseID  primitive.ObjectID `bson:"exerciseId" json:"exerciseId"`   // Link back to the exercise
+	TrainerID   primitive.ObjectID `bson:"trainerId" json:"trainerId"`     // Owner of the exercise (denormalized)
+	Kind        MediaKind          `bson:"kind" json:"kind"`               // video, image or thumbnail
+	S3ObjectKey string             `bson:"s3ObjectKey" json:"-"`           // Internal object key in the bucket
+	FileName    string             `bson:"fileName" json:"fileName"`       // Original filename provided by trainer
+	ContentType string             `bson:"contentType" json:"contentType"` // MIME type (e.g., "video/mp4", "image/jpeg")
+	Size        int64              `bson:"size" json:"size"`               // File size in bytes
+	Sequence    int                `bson:"sequence" json:"sequence"`       // Display order among the exercise's media
+	UploadedAt  time.Time          `bson:"uploadedAt" json:"uploadedAt"`
+}
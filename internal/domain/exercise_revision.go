@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExerciseRevision is an immutable snapshot of an Exercise's client-facing content.
+// Assignments pin the revision they were created with so later edits to the
+// exercise don't rewrite the instructions of past assignments.
+type ExerciseRevision struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ExerciseID       primitive.ObjectID `bson:"exerciseId" json:"exerciseId"`
+	TrainerID        primitive.ObjectID `bson:"trainerId" json:"trainerId"`
+	Revision         int                `bson:"revision" json:"revision"` // 1-based, increments on every content change
+	Name             string             `bson:"name" json:"name"`
+	Description      string             `bson:"description,omitempty" json:"description,omitempty"`
+	MuscleGroup      string             `bson:"muscleGroup,omitempty" json:"muscleGroup,omitempty"`
+	ExecutionTechnic string             `bson:"executionTechnic,omitempty" json:"executionTechnic,omitempty"`
+	Applicability    string             `bson:"applicability,omitempty" json:"applicability,omitempty"`
+	Difficulty       string             `bson:"difficulty,omitempty" json:"difficulty,omitempty"`
+	VideoURL         string             `bson:"videoUrl,omitempty" json:"videoUrl,omitempty"`
+	CreatedAt        time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// NewExerciseRevision snapshots the current content of an exercise as the given revision.
+func NewExerciseRevision(ex *Exercise, revision int) *ExerciseRevision {
+	return &ExerciseRevision{
+		ExerciseID:       ex.ID,
+		TrainerID:        ex.TrainerID,
+		Revision:         revision,
+		Name:             ex.Name,
+		Description:      ex.Description,
+		MuscleGroup:      ex.MuscleGroup,
+		ExecutionTechnic: ex.ExecutionTechnic,
+		Applicability:    ex.Applicability,
+		Difficulty:       ex.Difficulty,
+		VideoURL:         ex.VideoURL,
+	}
+}
+
+// SameContent reports whether two revisions carry identical client-facing content.
+func (r *ExerciseRevision) SameContent(other *ExerciseRevision) bool {
+	return r.Name == other.Name &&
+		r.Description == other.Description &&
+		r.MuscleGroup == other.MuscleGroup &&
+		r.ExecutionTechnic == other.ExecutionTechnic &&
+		r.Applicability == other.Applicability &&
+		r.Difficulty == other.Difficulty &&
+		r.VideoURL == other.VideoURL
+}
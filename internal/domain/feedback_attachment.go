@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedbackAttachmentKind distinguishes trainer replies recorded as video or voice notes.
+type FeedbackAttachmentKind string
+
+const (
+	FeedbackAttachmentVideo FeedbackAttachmentKind = "video"
+	FeedbackAttachmentAudio FeedbackAttachmentKind = "audio"
+)
+
+// IsValid reports whether k is a supported attachment kind.
+func (k FeedbackAttachmentKind) IsValid() bool {
+	return k == FeedbackAttachmentVideo || k == FeedbackAttachmentAudio
+}
+
+// FeedbackAttachment is a demonstration video or voice note a trainer recorded in reply
+// to a client's submission. Attachments are embedded in their Assignment; the file
+// resides in storage under the trainer's feedback prefix.
+type FeedbackAttachment struct {
+	ID          primitive.ObjectID     `bson:"_id" json:"id"`
+	Kind        FeedbackAttachmentKind `bson:"kind" json:"kind"`
+	S3ObjectKey string                 `bson:"s3ObjectKey" json:"-"`
+	FileName    string                 `bson:"fileName" json:"fileName"`
+	ContentType string                 `bson:"contentType" json:"contentType"`
+	Size        int64                  `bson:"size" json:"size"`
+	DurationMs  *int64                 `bson:"durationMs,omitempty" json:"durationMs,omitempty"` // As reported by the trainer's app
+	UploadedAt  time.Time              `bson:"uploadedAt" json:"uploadedAt"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedbackComment is a trainer's remark on one Upload, optionally pinned to a moment
+// or range of the video ("at 0:12 your knees cave in"). Comments without StartMs
+// apply to the whole video.
+type FeedbackComment struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UploadID     primitive.ObjectID `bson:"uploadId" json:"uploadId"`
+	AssignmentID primitive.ObjectID `bson:"assignmentId" json:"assignmentId"`           // Denormalized from the upload
+	TrainerID    primitive.ObjectID `bson:"trainerId" json:"trainerId"`                 // Author
+	ClientID     primitive.ObjectID `bson:"clientId" json:"clientId"`                   // Denormalized for client reads
+	StartMs      *int64             `bson:"startMs,omitempty" json:"startMs,omitempty"` // Offset into the video, in milliseconds
+	EndMs        *int64             `bson:"endMs,omitempty" json:"endMs,omitempty"`     // End of the range; requires StartMs
+	Text         string             `bson:"text" json:"text"`
+	ResolvedAt   *time.Time         `bson:"resolvedAt,omitempty" json:"resolvedAt,omitempty"` // Nil while open
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// IsResolved reports whether the comment has been marked resolved.
+func (c *FeedbackComment) IsResolved() bool {
+	return c.ResolvedAt != nil
+}
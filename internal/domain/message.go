@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MessageAnchorType is what a message thread is about.
+type MessageAnchorType string
+
+const (
+	AnchorPlan       MessageAnchorType = "plan"
+	AnchorWorkout    MessageAnchorType = "workout"
+	AnchorAssignment MessageAnchorType = "assignment"
+)
+
+// IsValid reports whether t is a known anchor type.
+func (t MessageAnchorType) IsValid() bool {
+	switch t {
+	case AnchorPlan, AnchorWorkout, AnchorAssignment:
+		return true
+	}
+	return false
+}
+
+// MessageAnchor ties a message to a plan, workout or assignment of the conversation's
+// client. Messages sharing an anchor form a thread.
+type MessageAnchor struct {
+	Type MessageAnchorType  `bson:"type" json:"type"`
+	ID   primitive.ObjectID `bson:"id" json:"id"`
+}
+
+// Conversation is the single message channel between a trainer and one of their clients.
+type Conversation struct {
+	ID                 primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TrainerID          primitive.ObjectID  `bson:"trainerId" json:"trainerId"`
+	ClientID           primitive.ObjectID  `bson:"clientId" json:"clientId"`
+	LastMessageAt      *time.Time          `bson:"lastMessageAt,omitempty" json:"lastMessageAt,omitempty"`
+	LastMessagePreview string              `bson:"lastMessagePreview,omitempty" json:"lastMessagePreview,omitempty"` // Start of the latest message, for inbox lists
+	LastSenderID       *primitive.ObjectID `bson:"lastSenderId,omitempty" json:"lastSenderId,omitempty"`
+	CreatedAt          time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt          time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// HasParticipant reports whether userID is the conversation's trainer or client.
+func (c *Conversation) HasParticipant(userID primitive.ObjectID) bool {
+	return userID == c.TrainerID || userID == c.ClientID
+}
+
+// OtherParticipant returns the ID of the participant who is not userID.
+func (c *Conversation) OtherParticipant(userID primitive.ObjectID) primitive.ObjectID {
+	if userID == c.TrainerID {
+		return c.ClientID
+	}
+	return c.TrainerID
+}
+
+// Message is one message in a Conversation. ReadAt is the read receipt, set when the
+// recipient first reads it.
+type Message struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversationId" json:"conversationId"`
+	SenderID       primitive.ObjectID `bson:"senderId" json:"senderId"`
+	RecipientID    primitive.ObjectID `bson:"recipientId" json:"recipientId"`
+	Anchor         *MessageAnchor     `bson:"anchor,omitempty" json:"anchor,omitempty"` // Nil for the main conversation
+	Body           string             `bson:"body" json:"body"`
+	ReadAt         *time.Time         `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// MessageThread summarizes the messages of a conversation that share one anchor.
+type MessageThread struct {
+	Anchor        MessageAnchor `bson:"_id" json:"anchor"`
+	MessageCount  int64         `bson:"messageCount" json:"messageCount"`
+	UnreadCount   int64         `bson:"unreadCount" json:"unreadCount"` // For the requesting user
+	LastMessageAt time.Time     `bson:"lastMessageAt" json:"lastMessageAt"`
+}
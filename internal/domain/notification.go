@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification is an entry in a user's notification center, created from a service
+// event (new plan, feedback, upload, message...).
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"userId" json:"userId"` // Recipient
+	Type      string              `bson:"type" json:"type"`     // Event type, e.g. "plan.created"
+	ActorID   *primitive.ObjectID `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Title     string              `bson:"title" json:"title"`
+	Data      map[string]any      `bson:"data,omitempty" json:"data,omitempty"` // IDs of the affected resources
+	ReadAt    *time.Time          `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// IsRead reports whether the user has read the notification.
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreferences records which notification types a user has turned off.
+// Types not listed are on, so new types reach users without a migration.
+type NotificationPreferences struct {
+	UserID    primitive.ObjectID `bson:"_id" json:"userId"`
+	Muted     []string           `bson:"muted" json:"muted"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Wants reports whether the user wants notifications of the given type.
+func (p *NotificationPreferences) Wants(notificationType string) bool {
+	for _, muted := range p.Muted {
+		if muted == notificationType {
+			return false
+		}
+	}
+	return true
+}
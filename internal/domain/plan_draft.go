@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlanDraft holds the edits a trainer made to a published plan's workouts, blocks and
+// exercises since it was last published. The client keeps seeing the published
+// versions until the plan is published again. One draft per plan; none means no
+// pending edits.
+type PlanDraft struct {
+	PlanID    primitive.ObjectID `bson:"_id" json:"planId"`
+	TrainerID primitive.ObjectID `bson:"trainerId" json:"trainerId"`
+
+	// Edited or added copies, keyed by the hex ID of the item they replace (or add)
+	Workouts    map[string]Workout      `bson:"workouts,omitempty" json:"workouts,omitempty"`
+	Assignments map[string]Assignment   `bson:"assignments,omitempty" json:"assignments,omitempty"`
+	Blocks      map[string]WorkoutBlock `bson:"blocks,omitempty" json:"blocks,omitempty"`
+
+	Added   []primitive.ObjectID `bson:"added,omitempty" json:"added,omitempty"`     // Items created in the draft; not published yet
+	Removed []primitive.ObjectID `bson:"removed,omitempty" json:"removed,omitempty"` // Published items deleted in the draft
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PlanDraftEdit is one change to a draft, saved in a single step.
+type PlanDraftEdit struct {
+	Workouts    []Workout // Copies to stage, replacing earlier ones
+	Assignments []Assignment
+	Blocks      []WorkoutBlock
+	Added       []primitive.ObjectID // IDs of new items among the copies
+	Removed     []primitive.ObjectID // Published items to delete on publish
+	Dropped     []primitive.ObjectID // Items whose staged copies are discarded
+}
+
+// Workout returns the staged copy of a workout, if it was edited or added.
+func (d *PlanDraft) Workout(id primitive.ObjectID) (*Workout, bool) {
+	if d == nil {
+		return nil, false
+	}
+	w, ok := d.Workouts[id.Hex()]
+	return &w, ok
+}
+
+// Assignment returns the staged copy of an assignment, if it was edited or added.
+func (d *PlanDraft) Assignment(id primitive.ObjectID) (*Assignment, bool) {
+	if d == nil {
+		return nil, false
+	}
+	a, ok := d.Assignments[id.Hex()]
+	return &a, ok
+}
+
+// Block returns the staged copy of a workout block, if it was edited or added.
+func (d *PlanDraft) Block(id primitive.ObjectID) (*WorkoutBlock, bool) {
+	if d == nil {
+		return nil, false
+	}
+	b, ok := d.Blocks[id.Hex()]
+	return &b, ok
+}
+
+// IsAdded reports whether id is an item created in the draft and still in it.
+func (d *PlanDraft) IsAdded(id primitive.ObjectID) bool {
+	if d == nil || !containsID(d.Added, id) {
+		return false
+	}
+	key := id.Hex()
+	_, workout := d.Workouts[key]
+	_, assignment := d.Assignments[key]
+	_, block := d.Blocks[key]
+	return workout || assignment || block
+}
+
+// IsRemoved reports whether the published item id is deleted in the draft.
+func (d *PlanDraft) IsRemoved(id primitive.ObjectID) bool {
+	return d != nil && containsID(d.Removed, id)
+}
+
+// IsEmpty reports whether the draft stages no edits.
+func (d *PlanDraft) IsEmpty() bool {
+	return d == nil || len(d.Workouts) == 0 && len(d.Assignments) == 0 && len(d.Blocks) == 0 && len(d.Removed) == 0
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Defaults for clients who never changed their reminder settings.
+const (
+	DefaultReminderTime = "07:00" // Morning reminder of the day's workouts
+	DefaultNudgeTime    = "10:00" // Nudge about exercises left from the day before
+)
+
+// ReminderSettings is a client's choice of when to be reminded about workouts.
+// The zero value means "on, at the default times, in UTC", so clients get reminders
+// without ever saving settings.
+type ReminderSettings struct {
+	ClientID          primitive.ObjectID `bson:"_id" json:"clientId"`
+	RemindersDisabled bool               `bson:"remindersDisabled" json:"remindersDisabled"`
+	ReminderTime      string             `bson:"reminderTime,omitempty" json:"reminderTime,omitempty"` // "HH:MM" local; empty = DefaultReminderTime
+	NudgesDisabled    bool               `bson:"nudgesDisabled" json:"nudgesDisabled"`
+	NudgeTime         string             `bson:"nudgeTime,omitempty" json:"nudgeTime,omitempty"` // "HH:MM" local; empty = DefaultNudgeTime
+	TimeZone          string             `bson:"timeZone,omitempty" json:"timeZone,omitempty"`   // IANA name, e.g. "Europe/Kyiv"; empty = UTC
+
+	// Local dates (YYYY-MM-DD) already handled, so each is sent at most once a day.
+	LastReminderDate string `bson:"lastReminderDate,omitempty" json:"-"`
+	LastNudgeDate    string `bson:"lastNudgeDate,omitempty" json:"-"`
+
+	UpdatedAt time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// EffectiveReminderTime returns ReminderTime or the default.
+func (s *ReminderSettings) EffectiveReminderTime() string {
+	if s.ReminderTime == "" {
+		return DefaultReminderTime
+	}
+	return s.ReminderTime
+}
+
+// EffectiveNudgeTime returns NudgeTime or the default.
+func (s *ReminderSettings) EffectiveNudgeTime() string {
+	if s.NudgeTime == "" {
+		return DefaultNudgeTime
+	}
+	return s.NudgeTime
+}
+
+// Location returns the client's time zone, falling back to UTC.
+func (s *ReminderSettings) Location() *time.Location {
+	if s.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -16,7 +16,56 @@ type TrainingPlan struct {
 	Description string             `bson:"description,omitempty" json:"description,omitempty"`
 	StartDate   *time.Time         `bson:"startDate,omitempty" json:"startDate,omitempty"` // Optional start date
 	EndDate     *time.Time         `bson:"endDate,omitempty" json:"endDate,omitempty"`   // Optional end date
-	IsActive    bool               `bson:"isActive" json:"isActive"`         // Is this the currently active plan for the client?
+	IsActive    bool               `bson:"isActive" json:"isActive"`         // Is this the currently active plan for the client? Kept equal to Status == active
+	Status      PlanStatus         `bson:"status,omitempty" json:"status,omitempty"` // Lifecycle; see PlanStatus
+	EndingNotifiedAt *time.Time    `bson:"endingNotifiedAt,omitempty" json:"-"`       // Trainer was told the plan ends with nothing queued
+	PublishedAt *time.Time         `bson:"publishedAt" json:"publishedAt,omitempty"`  // Last publish to the client; nil = never published, hidden from the client
+	ChangeSummary []string         `bson:"changeSummary,omitempty" json:"changeSummary,omitempty"` // What the last publish changed, one line per change, for the client
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PlanStatus is where a plan is in its lifecycle. The daily lifecycle job moves
+// scheduled plans to active when StartDate arrives and active plans to completed
+// after EndDate.
+type PlanStatus string
+
+const (
+	PlanStatusDraft     PlanStatus = "draft"     // Being prepared; never activated automatically
+	PlanStatusScheduled PlanStatus = "scheduled" // Activates on StartDate
+	PlanStatusActive    PlanStatus = "active"    // The client's current plan
+	PlanStatusCompleted PlanStatus = "completed" // Ended (EndDate passed or replaced by the next plan)
+	PlanStatusArchived  PlanStatus = "archived"  // Put away by the trainer
+)
+
+// IsValid reports whether s is a known status.
+func (s PlanStatus) IsValid() bool {
+	switch s {
+	case PlanStatusDraft, PlanStatusScheduled, PlanStatusActive, PlanStatusCompleted, PlanStatusArchived:
+		return true
+	}
+	return false
+}
+
+// EffectiveStatus returns Status, deriving it from IsActive for plans saved before
+// statuses existed.
+func (p *TrainingPlan) EffectiveStatus() PlanStatus {
+	if p.Status != "" {
+		return p.Status
+	}
+	if p.IsActive {
+		return PlanStatusActive
+	}
+	return PlanStatusDraft
+}
+
+// IsPublished reports whether the plan has been published to its client.
+func (p *TrainingPlan) IsPublished() bool {
+	return p.PublishedAt != nil
+}
+
+// SetStatus changes Status and keeps IsActive in step with it.
+func (p *TrainingPlan) SetStatus(status PlanStatus) {
+	p.Status = status
+	p.IsActive = status == PlanStatusActive
 }
\ No newline at end of file
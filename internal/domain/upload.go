@@ -8,6 +8,8 @@ import (
 
 // Upload stores metadata about a file uploaded by a client,
 // typically linked to an Assignment. The actual file resides in S3.
+// An assignment may have many uploads (retries, different angles); the one
+// referenced by Assignment.UploadID is the primary upload.
 type Upload struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	AssignmentID primitive.ObjectID `bson:"assignmentId" json:"assignmentId"` // Link back to the assignment
@@ -17,6 +19,8 @@ type Upload struct {
 	FileName     string             `bson:"fileName" json:"fileName"`         // Original filename provided by client
 	ContentType  string             `bson:"contentType" json:"contentType"`   // MIME type (e.g., "video/mp4")
 	Size         int64              `bson:"size" json:"size"`                 // File size in bytes
+	Attempt      int                `bson:"attempt,omitempty" json:"attempt,omitempty"` // 1-based upload number within the assignment
 	UploadedAt   time.Time          `bson:"uploadedAt" json:"uploadedAt"`
+	ObjectMissingAt *time.Time      `bson:"objectMissingAt,omitempty" json:"objectMissingAt,omitempty"` // Set by storage reconciliation when the S3 object is gone
 	// PresignedURL string          `bson:"-" json:"presignedUrl,omitempty"` // Optionally generate and add this for downloads (not stored in DB)
 }
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookEventType names an event sent to integrations (Zapier, CRMs). These are a
+// stable, public contract, separate from the app's realtime event types.
+type WebhookEventType string
+
+const (
+	WebhookAssignmentSubmitted WebhookEventType = "assignment.submitted" // Client submitted a video
+	WebhookAssignmentReviewed  WebhookEventType = "assignment.reviewed"  // Trainer marked an assignment reviewed
+	WebhookPlanCreated         WebhookEventType = "plan.created"         // Trainer created a plan for a client
+	WebhookClientLinked        WebhookEventType = "client.linked"        // Trainer added a client
+	WebhookPing                WebhookEventType = "ping"                 // Test delivery; cannot be subscribed to
+)
+
+// WebhookEventTypes lists the event types endpoints can subscribe to.
+var WebhookEventTypes = []WebhookEventType{WebhookAssignmentSubmitted, WebhookAssignmentReviewed, WebhookPlanCreated, WebhookClientLinked}
+
+// IsValid reports whether t can be subscribed to.
+func (t WebhookEventType) IsValid() bool {
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpoint is a trainer's URL that receives signed POSTs for the subscribed events.
+type WebhookEndpoint struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TrainerID   primitive.ObjectID `bson:"trainerId" json:"trainerId"`
+	URL         string             `bson:"url" json:"url"`
+	Secret      string             `bson:"secret" json:"-"` // HMAC-SHA256 key for the X-Webhook-Signature header
+	EventTypes  []WebhookEventType `bson:"eventTypes" json:"eventTypes"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Active      bool               `bson:"active" json:"active"` // Inactive endpoints receive nothing, pings included
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Subscribes reports whether the endpoint wants events of type t.
+func (e *WebhookEndpoint) Subscribes(t WebhookEventType) bool {
+	for _, subscribed := range e.EventTypes {
+		if subscribed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is where a delivery is in its retry schedule.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting for its first or next attempt
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // The endpoint answered 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Out of attempts, or the endpoint is gone
+)
+
+// WebhookAttempt records one POST of a delivery.
+type WebhookAttempt struct {
+	At           time.Time `bson:"at" json:"at"`
+	StatusCode   int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"` // 0 when no response was received
+	Error        string    `bson:"error,omitempty" json:"error,omitempty"`
+	ResponseBody string    `bson:"responseBody,omitempty" json:"responseBody,omitempty"` // Truncated
+	DurationMs   int64     `bson:"durationMs" json:"durationMs"`
+}
+
+// WebhookDelivery is one event sent (or to be sent) to one endpoint: the delivery log.
+// The payload is stored exactly as signed so redeliveries send the same body.
+type WebhookDelivery struct {
+	ID            primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	EndpointID    primitive.ObjectID    `bson:"endpointId" json:"endpointId"`
+	TrainerID     primitive.ObjectID    `bson:"trainerId" json:"trainerId"`
+	EventID       string                `bson:"eventId" json:"eventId"` // Same for redeliveries; receivers can deduplicate on it
+	EventType     WebhookEventType      `bson:"eventType" json:"eventType"`
+	Payload       string                `bson:"payload" json:"payload"`
+	Status        WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts      []WebhookAttempt      `bson:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time            `bson:"nextAttemptAt,omitempty" json:"nextAttemptAt,omitempty"`
+	RedeliveryOf  *primitive.ObjectID   `bson:"redeliveryOf,omitempty" json:"redeliveryOf,omitempty"`
+	CreatedAt     time.Time             `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time             `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BlockType describes how the exercises in a WorkoutBlock are performed.
+type BlockType string
+
+const (
+	BlockTypeStraightSets BlockType = "straight_sets" // Each exercise's sets done back to back
+	BlockTypeSuperset     BlockType = "superset"      // A1/A2 alternated, one set each per round
+	BlockTypeCircuit      BlockType = "circuit"       // All exercises once per round
+	BlockTypeEMOM         BlockType = "emom"          // Every minute on the minute, one round per interval
+	BlockTypeAMRAP        BlockType = "amrap"         // As many rounds as possible within a time cap
+)
+
+// IsValid reports whether t is one of the supported block types.
+func (t BlockType) IsValid() bool {
+	switch t {
+	case BlockTypeStraightSets, BlockTypeSuperset, BlockTypeCircuit, BlockTypeEMOM, BlockTypeAMRAP:
+		return true
+	}
+	return false
+}
+
+// WorkoutBlock groups assignments within a Workout, e.g. "A: superset, 3 rounds".
+// Assignments reference their block via Assignment.BlockID; assignments without a
+// block behave like single-exercise straight sets.
+type WorkoutBlock struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id,omitzero"`       // Zero for the implicit blocks of ungrouped assignments
+	WorkoutID         primitive.ObjectID `bson:"workoutId" json:"workoutId"`             // Link to the Workout session
+	TrainerID         primitive.ObjectID `bson:"trainerId" json:"trainerId"`             // Denormalized for auth
+	Label             string             `bson:"label,omitempty" json:"label,omitempty"` // e.g., "A", "Finisher"
+	Type              BlockType          `bson:"type" json:"type"`
+	Rounds            int                `bson:"rounds" json:"rounds"`                                           // Planned rounds; for AMRAP a target, not a limit
+	RestBetweenRounds *string            `bson:"restBetweenRounds,omitempty" json:"restBetweenRounds,omitempty"` // e.g., "90s"
+	TimeCap           *string            `bson:"timeCap,omitempty" json:"timeCap,omitempty"`                     // e.g., "12min" for AMRAP, "1min" interval for EMOM
+	Sequence          int                `bson:"sequence" json:"sequence"`                                       // Order within the workout, shared with ungrouped assignments
+	Notes             string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedAt         time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// MaxRounds returns how many set logs an assignment may hold: one per round for
+// grouped blocks, one per set for straight sets. 0 means unbounded (AMRAP).
+func (b *WorkoutBlock) MaxRounds(a *Assignment) int {
+	if b == nil || b.Type == BlockTypeStraightSets {
+		if a.Sets != nil {
+			return *a.Sets
+		}
+		return 0
+	}
+	if b.Type == BlockTypeAMRAP {
+		return 0
+	}
+	return b.Rounds
+}
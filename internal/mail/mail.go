@@ -0,0 +1,35 @@
+// Package mail sends transactional email such as the trainers' weekly digest.
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+// Message is one email with an HTML body and a plain-text alternative.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender delivers email. Implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender is a Sender for development and tests: it logs each message instead of
+// sending it and always succeeds.
+type LogSender struct{}
+
+// NewLogSender creates a LogSender.
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs msg's recipient, subject and the length of its bodies.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("MAIL to %s: %q (html %d bytes, text %d bytes)", msg.To, msg.Subject, len(msg.HTML), len(msg.Text))
+	return nil
+}
@@ -0,0 +1,156 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles refreshing more
+	// often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig holds the token-based (.p8 key) credentials for Apple Push Notification service.
+type APNsConfig struct {
+	KeyID      string // Key ID of the .p8 key
+	TeamID     string // Apple developer team ID
+	Topic      string // The app's bundle ID
+	KeyFile    string // Path to the .p8 key
+	Production bool   // false sends to the sandbox (development builds)
+}
+
+// APNsSender delivers to iOS devices over the APNs HTTP/2 API.
+type APNsSender struct {
+	cfg     APNsConfig
+	key     *ecdsa.PrivateKey
+	baseURL string
+	client  *http.Client
+
+	mu          sync.Mutex
+	bearer      string
+	bearerIssue time.Time
+}
+
+// NewAPNsSender loads the signing key and creates an APNsSender.
+func NewAPNsSender(cfg APNsConfig) (*APNsSender, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" || cfg.KeyFile == "" {
+		return nil, errors.New("apns requires key_id, team_id, topic and key_file")
+	}
+	pemBytes, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apns key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apns key: %w", err)
+	}
+	baseURL := apnsSandboxURL
+	if cfg.Production {
+		baseURL = apnsProductionURL
+	}
+	return &APNsSender{
+		cfg:     cfg,
+		key:     key,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 15 * time.Second}, // The default transport negotiates HTTP/2
+	}, nil
+}
+
+// providerToken returns the cached JWT, signing a new one when it is due.
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bearer != "" && time.Since(s.bearerIssue) < apnsTokenLifetime {
+		return s.bearer, nil
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:   s.cfg.TeamID,
+		IssuedAt: jwt.NewNumericDate(now),
+	})
+	token.Header["kid"] = s.cfg.KeyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+	s.bearer, s.bearerIssue = signed, now
+	return signed, nil
+}
+
+// Send delivers msg as an alert notification.
+func (s *APNsSender) Send(ctx context.Context, msg Message) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	bearer, err := s.providerToken()
+	if err != nil {
+		return fmt.Errorf("failed to sign apns token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/3/device/"+url.PathEscape(msg.Token), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&apnsErr)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apnsErr.Reason == "BadDeviceToken",
+		apnsErr.Reason == "Unregistered",
+		apnsErr.Reason == "DeviceTokenNotForTopic":
+		return providerError(ErrInvalidToken, "apns", resp.StatusCode, apnsErr.Reason)
+	case resp.StatusCode == http.StatusForbidden && apnsErr.Reason == "ExpiredProviderToken":
+		s.mu.Lock()
+		s.bearer = ""
+		s.mu.Unlock()
+		return providerError(nil, "apns", resp.StatusCode, apnsErr.Reason)
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return providerError(nil, "apns", resp.StatusCode, apnsErr.Reason)
+	default:
+		return providerError(ErrRejected, "apns", resp.StatusCode, apnsErr.Reason)
+	}
+}
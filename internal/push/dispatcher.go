@@ -0,0 +1,192 @@
+package push
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBackoff caps the wait between retries of one delivery.
+const maxBackoff = time.Minute
+
+// eventTitles are the alert titles per event type; the event summary is the body.
+var eventTitles = map[realtime.EventType]string{
+	realtime.EventFeedback:         "New feedback",
+	realtime.EventAssignmentStatus: "Exercise updated",
+	realtime.EventUpload:           "New video",
+	realtime.EventPlan:             "New training plan",
+	realtime.EventPlanUpdated:      "Training plan updated",
+	realtime.EventMessage:          "New message",
+	realtime.EventClientLinked:     "New trainer",
+	realtime.EventWorkoutReminder:  "Workout today",
+	realtime.EventWorkoutMissed:    "Unfinished workout",
+	realtime.EventPlanEnding:       "Plan ending soon",
+}
+
+// DispatcherConfig tunes push delivery. Zero values take the defaults.
+type DispatcherConfig struct {
+	Workers     int           // Concurrent deliveries (default 4)
+	QueueSize   int           // Events waiting for a worker; more are dropped (default 256)
+	MaxAttempts int           // Per device, including the first try (default 5)
+	BaseBackoff time.Duration // Wait before the first retry, doubled after each (default 2s)
+}
+
+type dispatchJob struct {
+	event  realtime.Event
+	userID primitive.ObjectID
+}
+
+// Dispatcher is a realtime.Publisher that pushes events to the recipients' registered
+// devices. Publish only queues; Run delivers in the background, retrying temporary
+// provider failures with exponential backoff and removing tokens the provider reports
+// as invalid.
+type Dispatcher struct {
+	devices repository.DeviceRepository
+	senders map[domain.DevicePlatform]PushSender
+	cfg     DispatcherConfig
+	queue   chan dispatchJob
+}
+
+// NewDispatcher creates a Dispatcher. Devices of a platform without a sender are skipped.
+func NewDispatcher(devices repository.DeviceRepository, senders map[domain.DevicePlatform]PushSender, cfg DispatcherConfig) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 2 * time.Second
+	}
+	return &Dispatcher{
+		devices: devices,
+		senders: senders,
+		cfg:     cfg,
+		queue:   make(chan dispatchJob, cfg.QueueSize),
+	}
+}
+
+// Publish queues event for each recipient. It never blocks: when the queue is full
+// the push is dropped (the notification center and realtime stream still have it).
+func (d *Dispatcher) Publish(ctx context.Context, event realtime.Event, recipients ...primitive.ObjectID) {
+	for _, userID := range recipients {
+		select {
+		case d.queue <- dispatchJob{event: event, userID: userID}:
+		default:
+			log.Printf("WARN: Push queue full; dropping %s push for %s", event.Type, userID.Hex())
+		}
+	}
+}
+
+// Run delivers queued events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-d.queue:
+					d.deliver(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver pushes one event to every device of one user.
+func (d *Dispatcher) deliver(ctx context.Context, job dispatchJob) {
+	devices, err := d.devices.GetByUserID(ctx, job.userID)
+	if err != nil {
+		log.Printf("WARN: Failed to load devices of %s for push: %v", job.userID.Hex(), err)
+		return
+	}
+	base := messageForEvent(job.event)
+	for _, device := range devices {
+		sender, ok := d.senders[device.Platform]
+		if !ok {
+			continue
+		}
+		msg := base
+		msg.Token = device.Token
+		d.sendWithRetry(ctx, sender, device, msg)
+	}
+}
+
+// sendWithRetry sends msg, retrying temporary failures and pruning invalid tokens.
+func (d *Dispatcher) sendWithRetry(ctx context.Context, sender PushSender, device domain.Device, msg Message) {
+	backoff := d.cfg.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := sender.Send(ctx, msg)
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, ErrInvalidToken):
+			if err := d.devices.DeleteByToken(ctx, device.Token); err != nil {
+				log.Printf("WARN: Failed to remove invalid push token %s: %v", shortToken(device.Token), err)
+			} else {
+				log.Printf("Removed invalid %s push token %s of user %s (%v)", device.Platform, shortToken(device.Token), device.UserID.Hex(), err)
+			}
+			return
+		case errors.Is(err, ErrRejected):
+			log.Printf("WARN: Push to %s rejected: %v", shortToken(device.Token), err)
+			return
+		case attempt >= d.cfg.MaxAttempts:
+			log.Printf("WARN: Giving up on push to %s after %d attempts: %v", shortToken(device.Token), attempt, err)
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// messageForEvent builds the alert for an event. Data carries the event type and the
+// flat IDs from the event, as strings as the providers require.
+func messageForEvent(event realtime.Event) Message {
+	title, ok := eventTitles[event.Type]
+	if !ok {
+		title = "Fitness update"
+	}
+	data := map[string]string{
+		"type":    string(event.Type),
+		"eventId": event.ID,
+	}
+	for k, v := range event.Data {
+		switch v := v.(type) {
+		case nil:
+		case primitive.ObjectID:
+			data[k] = v.Hex()
+		case *primitive.ObjectID:
+			if v != nil {
+				data[k] = v.Hex()
+			}
+		case string:
+			data[k] = v
+		default:
+			data[k] = fmt.Sprint(v)
+		}
+	}
+	return Message{Title: title, Body: event.Summary, Data: data}
+}
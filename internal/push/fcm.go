@@ -0,0 +1,203 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	fcmSendURL     = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// FCMConfig holds the Firebase service account used for the FCM HTTP v1 API.
+type FCMConfig struct {
+	ProjectID       string // Defaults to the service account's project
+	CredentialsFile string // Path to the service account JSON key
+}
+
+// serviceAccount is the part of a Google service account key the sender needs.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMSender delivers to Android devices through Firebase Cloud Messaging.
+type FCMSender struct {
+	projectID string
+	account   serviceAccount
+	key       *rsa.PrivateKey
+	client    *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender loads the service account key and creates an FCMSender.
+func NewFCMSender(cfg FCMConfig) (*FCMSender, error) {
+	if cfg.CredentialsFile == "" {
+		return nil, errors.New("fcm requires credentials_file")
+	}
+	raw, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("fcm credentials are not a service account key")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fcm private key: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = googleTokenURL
+	}
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm requires project_id")
+	}
+	return &FCMSender{
+		projectID: projectID,
+		account:   account,
+		key:       key,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// token returns a cached OAuth access token, exchanging a signed service account
+// assertion for a new one when it is about to expire.
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && time.Now().Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signed, err := assertion.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signed},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("google token endpoint returned %d", resp.StatusCode)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return s.accessToken, nil
+}
+
+// Send delivers msg as a notification message.
+func (s *FCMSender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        msg.Token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get fcm access token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmSendURL, s.projectID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var fcmErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&fcmErr)
+	reason := fcmErr.Error.Status
+	for _, detail := range fcmErr.Error.Details {
+		if detail.ErrorCode != "" {
+			reason = detail.ErrorCode
+		}
+	}
+	switch {
+	case reason == "UNREGISTERED", reason == "SENDER_ID_MISMATCH",
+		reason == "INVALID_ARGUMENT" && strings.Contains(fcmErr.Error.Message, "registration token"):
+		return providerError(ErrInvalidToken, "fcm", resp.StatusCode, reason)
+	case resp.StatusCode == http.StatusUnauthorized:
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+		return providerError(nil, "fcm", resp.StatusCode, reason)
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return providerError(nil, "fcm", resp.StatusCode, reason)
+	default:
+		return providerError(ErrRejected, "fcm", resp.StatusCode, reason+": "+fcmErr.Error.Message)
+	}
+}
@@ -0,0 +1,23 @@
+package push
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender is a PushSender for development and tests: it logs each message instead of
+// contacting a provider and always succeeds.
+type LogSender struct {
+	name string
+}
+
+// NewLogSender creates a LogSender; name labels its log lines (e.g. "ios").
+func NewLogSender(name string) *LogSender {
+	return &LogSender{name: name}
+}
+
+// Send logs msg.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("PUSH [%s] to %s: %q %q %v", s.name, shortToken(msg.Token), msg.Title, msg.Body, msg.Data)
+	return nil
+}
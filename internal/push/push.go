@@ -0,0 +1,47 @@
+// Package push delivers notifications to registered iOS and Android devices.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidToken means the provider will never accept the token again (app
+	// uninstalled, token rotated, wrong app). The device registration should be removed.
+	ErrInvalidToken = errors.New("push token is no longer valid")
+	// ErrRejected means the provider refused the message itself; retrying won't help.
+	ErrRejected = errors.New("push message rejected")
+)
+
+// Message is one notification for one device.
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string // Delivered to the app alongside the alert, e.g. IDs to open
+}
+
+// PushSender delivers messages through one provider. Errors other than ErrInvalidToken
+// and ErrRejected are treated as temporary and retried.
+type PushSender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// providerError builds an error wrapping sentinel (or a plain temporary error when nil)
+// with the provider's status and reason.
+func providerError(sentinel error, provider string, status int, reason string) error {
+	if sentinel == nil {
+		return fmt.Errorf("%s returned %d: %s", provider, status, reason)
+	}
+	return fmt.Errorf("%w: %s returned %d: %s", sentinel, provider, status, reason)
+}
+
+// shortToken shortens a token for logs.
+func shortToken(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:8] + "…"
+}
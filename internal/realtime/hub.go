@@ -0,0 +1,157 @@
+// Package realtime delivers server-side events to connected trainers and clients.
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventType names what happened. Clients switch on it to decide what to refetch.
+type EventType string
+
+const (
+	EventFeedback         EventType = "assignment.feedback"       // Trainer left feedback (text, comment or attachment)
+	EventAssignmentStatus EventType = "assignment.status_changed" // Status changed by the other party
+	EventUpload           EventType = "upload.created"            // Client submitted a video
+	EventPlan             EventType = "plan.created"              // Trainer published a new plan to the client
+	EventPlanUpdated      EventType = "plan.updated"              // Trainer published changes to one of the client's plans
+	EventMessage          EventType = "message.created"           // New message in a conversation
+	EventClientLinked     EventType = "client.linked"             // Trainer added the client
+	EventWorkoutReminder  EventType = "workout.reminder"          // Scheduled: today's workouts
+	EventWorkoutMissed    EventType = "workout.missed"            // Scheduled: exercises left from yesterday
+	EventPlanEnding       EventType = "plan.ending"               // Scheduled: a client's plan ends soon with none queued (to the trainer)
+)
+
+// Event is one notification for a user. Data carries the IDs the app needs to
+// refresh the affected screen, not the full resource.
+type Event struct {
+	ID        string             `json:"id"`
+	Type      EventType          `json:"type"`
+	ActorID   primitive.ObjectID `json:"actorId"` // Who caused the event
+	Summary   string             `json:"summary"` // One line for toasts and the notification center
+	Data      map[string]any     `json:"data,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// NewEvent creates an event with a fresh ID and timestamp.
+func NewEvent(eventType EventType, actorID primitive.ObjectID, summary string, data map[string]any) Event {
+	return Event{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      eventType,
+		ActorID:   actorID,
+		Summary:   summary,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// EventTypes lists every event type, e.g. for notification preferences.
+var EventTypes = []EventType{EventFeedback, EventAssignmentStatus, EventUpload, EventPlan, EventPlanUpdated, EventMessage, EventClientLinked, EventWorkoutReminder, EventWorkoutMissed, EventPlanEnding}
+
+// IsValid reports whether t is a known event type.
+func (t EventType) IsValid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Publisher sends events to users. Publish never blocks on slow receivers; delivery
+// is best effort, so apps should still refetch on reconnect.
+type Publisher interface {
+	Publish(ctx context.Context, event Event, recipients ...primitive.ObjectID)
+}
+
+// Publishers fans an event out to several publishers, in order.
+type Publishers []Publisher
+
+// Publish passes the event to each publisher.
+func (p Publishers) Publish(ctx context.Context, event Event, recipients ...primitive.ObjectID) {
+	for _, publisher := range p {
+		publisher.Publish(ctx, event, recipients...)
+	}
+}
+
+// Broker is a Publisher that connections can subscribe to. The in-process Hub is one
+// implementation; a shared broker (Redis, NATS) would let several instances serve
+// the same users.
+type Broker interface {
+	Publisher
+	Subscribe(userID primitive.ObjectID) *Subscription
+}
+
+// Subscription receives one user's events until Close is called.
+type Subscription struct {
+	Events <-chan Event
+	close  func()
+}
+
+// Close unsubscribes and releases the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// Hub is an in-process Broker. Each subscription has a bounded buffer; events for a
+// subscriber whose buffer is full are dropped rather than slowing the publisher.
+type Hub struct {
+	mu         sync.RWMutex
+	subs       map[primitive.ObjectID]map[chan Event]struct{}
+	bufferSize int
+}
+
+// NewHub creates a Hub whose subscriptions buffer up to bufferSize events.
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = 16
+	}
+	return &Hub{
+		subs:       make(map[primitive.ObjectID]map[chan Event]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+// Subscribe registers a connection for userID. A user may hold several (phone, tablet).
+func (h *Hub) Subscribe(userID primitive.ObjectID) *Subscription {
+	ch := make(chan Event, h.bufferSize)
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan Event]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return &Subscription{
+		Events: ch,
+		close: func() {
+			once.Do(func() {
+				h.mu.Lock()
+				delete(h.subs[userID], ch)
+				if len(h.subs[userID]) == 0 {
+					delete(h.subs, userID)
+				}
+				h.mu.Unlock()
+				close(ch)
+			})
+		},
+	}
+}
+
+// Publish delivers event to every subscription of each recipient.
+func (h *Hub) Publish(ctx context.Context, event Event, recipients ...primitive.ObjectID) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, userID := range recipients {
+		for ch := range h.subs[userID] {
+			select {
+			case ch <- event:
+			default: // Subscriber is not keeping up; it will resync on reconnect.
+			}
+		}
+	}
+}
@@ -38,7 +38,9 @@ func (r *mongoAssignmentRepository) Create(ctx context.Context, assignment *doma
         // REMOVED: Checks for ClientID, TrainerID
 	}
 
-	assignment.ID = primitive.NewObjectID()
+	if assignment.ID == primitive.NilObjectID { // Items published from a plan draft keep their staged ID
+		assignment.ID = primitive.NewObjectID()
+	}
 	now := time.Now().UTC()
 	assignment.AssignedAt = now // Set assignment time
 	assignment.UpdatedAt = now  // Set initial update time
@@ -133,27 +135,30 @@ func (r *mongoAssignmentRepository) Update(ctx context.Context, assignment *doma
 	// WorkoutID should not change via this update.
 	// ExerciseID *could* change if trainer wants to swap exercise for this slot.
 	setDoc := bson.M{
-			"$set": bson.M{
-					"exerciseId":   assignment.ExerciseID, // Allow updating linked exercise
-					"sets":         assignment.Sets,
-					"reps":         assignment.Reps,
-					"rest":         assignment.Rest,
-					"tempo":        assignment.Tempo,
-					"weight":       assignment.Weight,
-					"duration":     assignment.Duration,
-					"sequence":     assignment.Sequence,
-					"trainerNotes": assignment.TrainerNotes,
-					"status":       assignment.Status,       // Trainer might adjust status via edit too
-					"clientNotes":  assignment.ClientNotes,  // Usually client sets this, but for completeness
-					"uploadId":     assignment.UploadID,     // Can be set/cleared
-					"feedback":     assignment.Feedback,
-					"achievedSets":          assignment.AchievedSets,
-					"achievedReps":          assignment.AchievedReps,
-					"achievedWeight":        assignment.AchievedWeight,
-					"achievedDuration":      assignment.AchievedDuration,
-					"clientPerformanceNotes": assignment.ClientPerformanceNotes,
-					"updatedAt":    time.Now().UTC(),
-			},
+			"exerciseId":   assignment.ExerciseID, // Allow updating linked exercise
+			"exerciseRevision": assignment.ExerciseRevision,
+			"revisionExerciseId": assignment.RevisionExerciseID,
+			"sets":         assignment.Sets,
+			"reps":         assignment.Reps,
+			"rest":         assignment.Rest,
+			"tempo":        assignment.Tempo,
+			"weight":       assignment.Weight,
+			"duration":     assignment.Duration,
+			"sequence":     assignment.Sequence,
+			"blockId":      assignment.BlockID,
+			"trainerNotes": assignment.TrainerNotes,
+			"status":       assignment.Status,       // Trainer might adjust status via edit too
+			"clientNotes":  assignment.ClientNotes,  // Usually client sets this, but for completeness
+			"uploadId":     assignment.UploadID,     // Can be set/cleared
+			"feedback":     assignment.Feedback,
+			"submittedAt":  assignment.SubmittedAt,
+			"achievedSets":          assignment.AchievedSets,
+			"achievedReps":          assignment.AchievedReps,
+			"achievedWeight":        assignment.AchievedWeight,
+			"achievedDuration":      assignment.AchievedDuration,
+			"clientPerformanceNotes": assignment.ClientPerformanceNotes,
+			"setLogs":               assignment.SetLogs,
+			"updatedAt":    time.Now().UTC(),
 	}
 	// If any optional fields are nil and you want to $unset them from MongoDB:
     // If you want to explicitly remove fields from MongoDB document if their Go pointer is nil:
@@ -166,7 +171,13 @@ func (r *mongoAssignmentRepository) Update(ctx context.Context, assignment *doma
 		if assignment.Weight == nil { unsetDoc["weight"] = "" }
 		if assignment.Duration == nil { unsetDoc["duration"] = "" }
 		if assignment.AchievedReps == nil { unsetDoc["achievedReps"] = "" }
-			
+		if assignment.BlockID == nil { unsetDoc["blockId"] = "" }
+		if assignment.SetLogs == nil { unsetDoc["setLogs"] = "" }
+		if assignment.RevisionExerciseID == nil { unsetDoc["revisionExerciseId"] = "" }
+	// A field may only appear in one operator; unset ones must not also be set to null.
+	for field := range unsetDoc {
+			delete(setDoc, field)
+	}
 
 	updateParts := bson.M{"$set": setDoc}
 	if len(unsetDoc) > 0 {
@@ -230,6 +241,16 @@ func EnsureAssignmentIndexes(ctx context.Context, collection *mongo.Collection)
 			Keys:    bson.D{{Key: "status", Value: 1}},
 			Options: options.Index(),
 		},
+		{
+			// Trainer's review queue: submitted assignments, oldest submission first
+			Keys:    bson.D{{Key: "trainerId", Value: 1}, {Key: "status", Value: 1}, {Key: "submittedAt", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			// Assignments grouped into a superset/circuit block
+			Keys:    bson.D{{Key: "blockId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
@@ -279,4 +300,309 @@ func (r *mongoAssignmentRepository) Delete(ctx context.Context, assignmentID pri
 			return repository.ErrNotFound
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// PinExerciseRevision pins every assignment of an exercise that has no revision yet
+// (created before exercises were versioned) to the given revision.
+func (r *mongoAssignmentRepository) PinExerciseRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int) (int64, error) {
+	filter := bson.M{
+		"exerciseId": exerciseID,
+		"$or": bson.A{
+			bson.M{"exerciseRevision": bson.M{"$exists": false}},
+			bson.M{"exerciseRevision": 0},
+		},
+	}
+	update := bson.M{"$set": bson.M{"exerciseRevision": revision, "updatedAt": time.Now().UTC()}}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// UpgradeExerciseRevision moves assignments of an exercise in one of the given statuses to a newer revision.
+func (r *mongoAssignmentRepository) UpgradeExerciseRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int, statuses []domain.AssignmentStatus) (int64, error) {
+	filter := bson.M{
+		"exerciseId":       exerciseID,
+		"status":           bson.M{"$in": statuses},
+		"exerciseRevision": bson.M{"$lt": revision},
+	}
+	update := bson.M{
+		"$set":   bson.M{"exerciseRevision": revision, "updatedAt": time.Now().UTC()},
+		"$unset": bson.M{"revisionExerciseId": ""}, // The new revision belongs to exerciseId's own history
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// GetByExerciseID retrieves all assignments that reference an exercise, across all workouts.
+func (r *mongoAssignmentRepository) GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.Assignment, error) {
+	var assignments []domain.Assignment
+	filter := bson.M{"exerciseId": exerciseID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "workoutId", Value: 1}, {Key: "sequence", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// CountByExerciseID counts the assignments that reference an exercise.
+func (r *mongoAssignmentRepository) CountByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"exerciseId": exerciseID})
+}
+
+// ReassignExercise re-points every assignment of one exercise to another. Assignments in one
+// of the repin statuses are pinned to toRevision; the others keep their pinned revision and
+// record the exercise it belongs to in revisionExerciseId.
+func (r *mongoAssignmentRepository) ReassignExercise(ctx context.Context, fromExerciseID, toExerciseID primitive.ObjectID, toRevision int, repin []domain.AssignmentStatus) (int64, error) {
+	now := time.Now().UTC()
+	repinned, err := r.collection.UpdateMany(ctx,
+		bson.M{"exerciseId": fromExerciseID, "status": bson.M{"$in": repin}},
+		bson.M{
+			"$set":   bson.M{"exerciseId": toExerciseID, "exerciseRevision": toRevision, "updatedAt": now},
+			"$unset": bson.M{"revisionExerciseId": ""},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	// An assignment merged before keeps pointing at the history it was first pinned in.
+	kept, err := r.collection.UpdateMany(ctx,
+		bson.M{"exerciseId": fromExerciseID},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"exerciseId":         toExerciseID,
+			"revisionExerciseId": bson.M{"$ifNull": bson.A{"$revisionExerciseId", fromExerciseID}},
+			"updatedAt":          now,
+		}}}})
+	if err != nil {
+		return 0, err
+	}
+	return repinned.ModifiedCount + kept.ModifiedCount, nil
+}
+
+// DeleteByExerciseID removes every assignment that references an exercise.
+func (r *mongoAssignmentRepository) DeleteByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"exerciseId": exerciseID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// ClearBlock removes the block reference from all assignments of a block, making them ungrouped.
+func (r *mongoAssignmentRepository) ClearBlock(ctx context.Context, blockID primitive.ObjectID) (int64, error) {
+	filter := bson.M{"blockId": blockID}
+	update := bson.M{
+		"$unset": bson.M{"blockId": ""},
+		"$set":   bson.M{"updatedAt": time.Now().UTC()},
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// UpdateSequences sets each assignment's sequence to its position in orderedIDs with a single
+// bulk write. Every ID must belong to the given workout, otherwise ErrNotFound is returned.
+func (r *mongoAssignmentRepository) UpdateSequences(ctx context.Context, workoutID primitive.ObjectID, orderedIDs []primitive.ObjectID) error {
+	if len(orderedIDs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	models := make([]mongo.WriteModel, len(orderedIDs))
+	for i, id := range orderedIDs {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id, "workoutId": workoutID}).
+			SetUpdate(bson.M{"$set": bson.M{"sequence": i, "updatedAt": now}})
+	}
+
+	result, err := r.collection.BulkWrite(ctx, models)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount != int64(len(orderedIDs)) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateFeedback sets only the feedback and/or status, so a concurrent client update
+// (e.g. a logged set) is never overwritten. Returns the assignment after the update.
+func (r *mongoAssignmentRepository) UpdateFeedback(ctx context.Context, assignmentID primitive.ObjectID, feedback *string, status domain.AssignmentStatus) (*domain.Assignment, error) {
+	set := bson.M{"updatedAt": time.Now().UTC()}
+	if feedback != nil {
+		set["feedback"] = *feedback
+	}
+	if status != "" {
+		set["status"] = status
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated domain.Assignment
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": assignmentID}, bson.M{"$set": set}, opts).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// AddFeedbackAttachment appends a trainer's reply attachment without touching other fields.
+func (r *mongoAssignmentRepository) AddFeedbackAttachment(ctx context.Context, assignmentID primitive.ObjectID, attachment domain.FeedbackAttachment) error {
+	update := bson.M{
+		"$push": bson.M{"feedbackAttachments": attachment},
+		"$set":  bson.M{"updatedAt": time.Now().UTC()},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": assignmentID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListFeedbackAttachmentKeys returns the object key of every feedback attachment. Used by storage reconciliation.
+func (r *mongoAssignmentRepository) ListFeedbackAttachmentKeys(ctx context.Context) ([]string, error) {
+	filter := bson.M{"feedbackAttachments.0": bson.M{"$exists": true}}
+	findOptions := options.Find().SetProjection(bson.M{"feedbackAttachments.s3ObjectKey": 1})
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			FeedbackAttachments []struct {
+				S3ObjectKey string `bson:"s3ObjectKey"`
+			} `bson:"feedbackAttachments"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		for _, a := range doc.FeedbackAttachments {
+			keys = append(keys, a.S3ObjectKey)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RemoveFeedbackAttachment pulls one attachment; ErrNotFound if the assignment doesn't have it.
+func (r *mongoAssignmentRepository) RemoveFeedbackAttachment(ctx context.Context, assignmentID, attachmentID primitive.ObjectID) error {
+	filter := bson.M{"_id": assignmentID, "feedbackAttachments._id": attachmentID}
+	update := bson.M{
+		"$pull": bson.M{"feedbackAttachments": bson.M{"_id": attachmentID}},
+		"$set":  bson.M{"updatedAt": time.Now().UTC()},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// GetSubmittedByTrainer lists the trainer's assignments awaiting review, optionally for one
+// client, oldest submission first. Assignments submitted before submittedAt was recorded
+// fall back to updatedAt.
+func (r *mongoAssignmentRepository) GetSubmittedByTrainer(ctx context.Context, trainerID primitive.ObjectID, clientID *primitive.ObjectID) ([]domain.Assignment, error) {
+	var assignments []domain.Assignment
+	filter := bson.M{"trainerId": trainerID, "status": domain.StatusSubmitted}
+	if clientID != nil {
+		filter["clientId"] = *clientID
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "submittedAt", Value: 1}, {Key: "updatedAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// GetByClientID lists all of a client's assignments, oldest first. It relies on the
+// denormalized clientId (see BackfillOwnerIDs).
+func (r *mongoAssignmentRepository) GetByClientID(ctx context.Context, clientID primitive.ObjectID) ([]domain.Assignment, error) {
+	var assignments []domain.Assignment
+	findOptions := options.Find().SetSort(bson.D{{Key: "assignedAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"clientId": clientID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// BackfillOwnerIDs sets trainerId and clientId on assignments created before they were
+// denormalized, copying them from each assignment's workout. Safe to run repeatedly.
+func (r *mongoAssignmentRepository) BackfillOwnerIDs(ctx context.Context) (int64, error) {
+	workoutIDs, err := r.collection.Distinct(ctx, "workoutId", bson.M{"trainerId": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+
+	workouts := r.collection.Database().Collection(workoutCollectionName)
+	var updated int64
+	for _, raw := range workoutIDs {
+		workoutID, ok := raw.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		var workout domain.Workout
+		if err := workouts.FindOne(ctx, bson.M{"_id": workoutID}).Decode(&workout); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue // Orphaned assignments; nothing to copy
+			}
+			return updated, err
+		}
+		result, err := r.collection.UpdateMany(ctx,
+			bson.M{"workoutId": workoutID, "trainerId": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"trainerId": workout.TrainerID, "clientId": workout.ClientID}},
+		)
+		if err != nil {
+			return updated, err
+		}
+		updated += result.ModifiedCount
+	}
+	return updated, nil
+}
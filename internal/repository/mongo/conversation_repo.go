@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const conversationCollectionName = "conversations"
+
+// mongoConversationRepository implements repository.ConversationRepository
+type mongoConversationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoConversationRepository creates a new Conversation repository.
+func NewMongoConversationRepository(db *mongo.Database) repository.ConversationRepository {
+	return &mongoConversationRepository{
+		collection: db.Collection(conversationCollectionName),
+	}
+}
+
+// GetOrCreate returns the trainer–client conversation, creating it on first use.
+func (r *mongoConversationRepository) GetOrCreate(ctx context.Context, trainerID, clientID primitive.ObjectID) (*domain.Conversation, error) {
+	if trainerID == primitive.NilObjectID || clientID == primitive.NilObjectID {
+		return nil, errors.New("conversation requires trainerId and clientId")
+	}
+	filter := bson.M{"trainerId": trainerID, "clientId": clientID}
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{"createdAt": now, "updatedAt": now}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var conversation domain.Conversation
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		// Lost a race with a concurrent first message; the other upsert created it.
+		err = r.collection.FindOne(ctx, filter).Decode(&conversation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetByID retrieves a single conversation by its ID.
+func (r *mongoConversationRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Conversation, error) {
+	var conversation domain.Conversation
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&conversation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetByParticipant lists the conversations a trainer or client takes part in, most
+// recently active first.
+func (r *mongoConversationRepository) GetByParticipant(ctx context.Context, userID primitive.ObjectID) ([]domain.Conversation, error) {
+	var conversations []domain.Conversation
+	filter := bson.M{"$or": []bson.M{{"trainerId": userID}, {"clientId": userID}}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "lastMessageAt", Value: -1}, {Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &conversations); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// SetLastMessage records msg as the conversation's latest activity.
+func (r *mongoConversationRepository) SetLastMessage(ctx context.Context, id primitive.ObjectID, msg *domain.Message, preview string) error {
+	update := bson.M{"$set": bson.M{
+		"lastMessageAt":      msg.CreatedAt,
+		"lastMessagePreview": preview,
+		"lastSenderId":       msg.SenderID,
+		"updatedAt":          time.Now().UTC(),
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// EnsureConversationIndexes creates necessary indexes for the conversations collection.
+func EnsureConversationIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// One conversation per trainer–client pair
+			Keys:    bson.D{{Key: "trainerId", Value: 1}, {Key: "clientId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "clientId", Value: 1}, {Key: "lastMessageAt", Value: -1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
@@ -0,0 +1,118 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const deviceCollectionName = "devices"
+
+// mongoDeviceRepository implements repository.DeviceRepository
+type mongoDeviceRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDeviceRepository creates a new Device repository.
+func NewMongoDeviceRepository(db *mongo.Database) repository.DeviceRepository {
+	return &mongoDeviceRepository{
+		collection: db.Collection(deviceCollectionName),
+	}
+}
+
+// Upsert registers a token for the device's user, or refreshes it if already known.
+// A token registered by another user is moved to this one.
+func (r *mongoDeviceRepository) Upsert(ctx context.Context, device *domain.Device) (*domain.Device, error) {
+	if device.UserID == primitive.NilObjectID || device.Token == "" {
+		return nil, errors.New("device requires userId and token")
+	}
+	now := time.Now().UTC()
+	update := bson.M{
+		"$set": bson.M{
+			"userId":     device.UserID,
+			"platform":   device.Platform,
+			"appVersion": device.AppVersion,
+			"lastSeenAt": now,
+		},
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved domain.Device
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"token": device.Token}, update, opts).Decode(&saved)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Two concurrent first registrations of the same token; the other one won.
+			err = r.collection.FindOneAndUpdate(ctx, bson.M{"token": device.Token}, update, opts).Decode(&saved)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &saved, nil
+}
+
+// GetByUserID retrieves all devices registered for a user.
+func (r *mongoDeviceRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Device, error) {
+	var devices []domain.Device
+	findOptions := options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Delete unregisters one of the user's devices.
+func (r *mongoDeviceRepository) Delete(ctx context.Context, userID primitive.ObjectID, token string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"userId": userID, "token": token})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByToken removes a token whoever it belongs to. Deleting an unknown token is not an error.
+func (r *mongoDeviceRepository) DeleteByToken(ctx context.Context, token string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"token": token})
+	return err
+}
+
+// EnsureDeviceIndexes creates necessary indexes for the devices collection.
+func EnsureDeviceIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// One registration per app install
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Devices to push to for a user
+			Keys:    bson.D{{Key: "userId", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
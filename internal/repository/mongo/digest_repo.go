@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const digestSettingsCollectionName = "digest_settings"
+
+// mongoDigestSettingsRepository implements repository.DigestSettingsRepository
+type mongoDigestSettingsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDigestSettingsRepository creates a new DigestSettings repository.
+func NewMongoDigestSettingsRepository(db *mongo.Database) repository.DigestSettingsRepository {
+	return &mongoDigestSettingsRepository{
+		collection: db.Collection(digestSettingsCollectionName),
+	}
+}
+
+// Get retrieves a trainer's settings, keyed by trainer ID.
+func (r *mongoDigestSettingsRepository) Get(ctx context.Context, trainerID primitive.ObjectID) (*domain.DigestSettings, error) {
+	var settings domain.DigestSettings
+	err := r.collection.FindOne(ctx, bson.M{"_id": trainerID}).Decode(&settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Upsert saves the trainer's choices, creating the document on first change.
+func (r *mongoDigestSettingsRepository) Upsert(ctx context.Context, settings *domain.DigestSettings) error {
+	if settings.TrainerID == primitive.NilObjectID {
+		return errors.New("trainer ID is required for digest settings")
+	}
+	settings.UpdatedAt = time.Now().UTC()
+	update := bson.M{"$set": bson.M{
+		"enabled":   settings.Enabled,
+		"email":     settings.Email,
+		"timeZone":  settings.TimeZone,
+		"updatedAt": settings.UpdatedAt,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": settings.TrainerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetEnabled lists the settings of every trainer who opted in.
+func (r *mongoDigestSettingsRepository) GetEnabled(ctx context.Context) ([]domain.DigestSettings, error) {
+	var settings []domain.DigestSettings
+	cursor, err := r.collection.Find(ctx, bson.M{"enabled": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// MarkSent sets lastSentWeek unless it already holds weekStart.
+func (r *mongoDigestSettingsRepository) MarkSent(ctx context.Context, trainerID primitive.ObjectID, weekStart string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": trainerID, "lastSentWeek": bson.M{"$ne": weekStart}},
+		bson.M{"$set": bson.M{"lastSentWeek": weekStart}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const exerciseMediaCollectionName = "exercise_media"
+
+// mongoExerciseMediaRepository implements repository.ExerciseMediaRepository
+type mongoExerciseMediaRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoExerciseMediaRepository creates a new ExerciseMedia repository backed by MongoDB.
+func NewMongoExerciseMediaRepository(db *mongo.Database) repository.ExerciseMediaRepository {
+	return &mongoExerciseMediaRepository{
+		collection: db.Collection(exerciseMediaCollectionName),
+	}
+}
+
+// Create inserts new exercise media metadata into the database.
+func (r *mongoExerciseMediaRepository) Create(ctx context.Context, media *domain.ExerciseMedia) (primitive.ObjectID, error) {
+	if media.ExerciseID == primitive.NilObjectID ||
+		media.TrainerID == primitive.NilObjectID ||
+		media.S3ObjectKey == "" {
+		return primitive.NilObjectID, errors.New("exercise media requires exerciseId, trainerId, and s3ObjectKey")
+	}
+
+	media.ID = primitive.NewObjectID()
+	media.UploadedAt = time.Now().UTC()
+
+	result, err := r.collection.InsertOne(ctx, media)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return primitive.NilObjectID, repository.ErrDuplicateKey
+		}
+		return primitive.NilObjectID, err
+	}
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted exercise media ID")
+	}
+	return insertedID, nil
+}
+
+// GetByID retrieves exercise media metadata by its ID.
+func (r *mongoExerciseMediaRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ExerciseMedia, error) {
+	var media domain.ExerciseMedia
+	filter := bson.M{"_id": id}
+
+	err := r.collection.FindOne(ctx, filter).Decode(&media)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &media, nil
+}
+
+// GetByExerciseID retrieves all media items for an exercise, in display order.
+func (r *mongoExerciseMediaRepository) GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.ExerciseMedia, error) {
+	var media []domain.ExerciseMedia
+	filter := bson.M{"exerciseId": exerciseID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}, {Key: "uploadedAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &media); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// Delete removes exercise media metadata owned by the given trainer.
+func (r *mongoExerciseMediaRepository) Delete(ctx context.Context, id primitive.ObjectID, trainerID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":       id,
+		"trainerId": trainerID,
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListAll retrieves every exercise media record. Used by storage reconciliation.
+func (r *mongoExerciseMediaRepository) ListAll(ctx context.Context) ([]domain.ExerciseMedia, error) {
+	var media []domain.ExerciseMedia
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &media); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// EnsureExerciseMediaIndexes creates necessary indexes for the exercise_media collection.
+func EnsureExerciseMediaIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Main query pattern: all media for an exercise in display order
+			Keys:    bson.D{{Key: "exerciseId", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "trainerId", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+
+	// One media item per storage object. Created on its own: duplicates left by older
+	// versions fail only this index, not the ones above.
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "s3ObjectKey", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("WARN: Failed to create unique s3ObjectKey index on %s (remove duplicate media first): %v", collection.Name(), err)
+	}
+}
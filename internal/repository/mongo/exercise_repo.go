@@ -107,6 +107,7 @@ func (r *mongoExerciseRepository) Update(ctx context.Context, exercise *domain.E
 			"applicability":    exercise.Applicability,     // ADDED/VERIFIED
 			"difficulty":       exercise.Difficulty,        // ADDED/VERIFIED
 			"videoUrl":         exercise.VideoURL,
+			"currentRevision":  exercise.CurrentRevision,
 			"updatedAt":        time.Now().UTC(),
 			// REMOVED: "instructions": exercise.Instructions,
 		},
@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const exerciseRevisionCollectionName = "exercise_revisions"
+
+// mongoExerciseRevisionRepository implements repository.ExerciseRevisionRepository
+type mongoExerciseRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoExerciseRevisionRepository creates a new ExerciseRevision repository backed by MongoDB.
+func NewMongoExerciseRevisionRepository(db *mongo.Database) repository.ExerciseRevisionRepository {
+	return &mongoExerciseRevisionRepository{
+		collection: db.Collection(exerciseRevisionCollectionName),
+	}
+}
+
+// Create inserts a new revision. Revisions are never updated once written.
+func (r *mongoExerciseRevisionRepository) Create(ctx context.Context, revision *domain.ExerciseRevision) (primitive.ObjectID, error) {
+	if revision.ExerciseID == primitive.NilObjectID || revision.TrainerID == primitive.NilObjectID || revision.Revision <= 0 {
+		return primitive.NilObjectID, errors.New("exercise revision requires exerciseId, trainerId, and a positive revision number")
+	}
+
+	revision.ID = primitive.NewObjectID()
+	revision.CreatedAt = time.Now().UTC()
+
+	result, err := r.collection.InsertOne(ctx, revision)
+	if err != nil {
+		// The unique (exerciseId, revision) index rejects concurrent writers of the same revision.
+		return primitive.NilObjectID, err
+	}
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted exercise revision ID")
+	}
+	return insertedID, nil
+}
+
+// GetByExerciseID retrieves all revisions of an exercise, newest first.
+func (r *mongoExerciseRevisionRepository) GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.ExerciseRevision, error) {
+	var revisions []domain.ExerciseRevision
+	filter := bson.M{"exerciseId": exerciseID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetByExerciseAndRevision retrieves one specific revision of an exercise.
+func (r *mongoExerciseRevisionRepository) GetByExerciseAndRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int) (*domain.ExerciseRevision, error) {
+	var rev domain.ExerciseRevision
+	filter := bson.M{"exerciseId": exerciseID, "revision": revision}
+
+	err := r.collection.FindOne(ctx, filter).Decode(&rev)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// EnsureExerciseRevisionIndexes creates necessary indexes for the exercise_revisions collection.
+func EnsureExerciseRevisionIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// One document per revision number of an exercise
+			Keys:    bson.D{{Key: "exerciseId", Value: 1}, {Key: "revision", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
@@ -0,0 +1,176 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const feedbackCommentCollectionName = "feedback_comments"
+
+// mongoFeedbackCommentRepository implements repository.FeedbackCommentRepository
+type mongoFeedbackCommentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoFeedbackCommentRepository creates a new FeedbackComment repository.
+func NewMongoFeedbackCommentRepository(db *mongo.Database) repository.FeedbackCommentRepository {
+	return &mongoFeedbackCommentRepository{
+		collection: db.Collection(feedbackCommentCollectionName),
+	}
+}
+
+// Create inserts a new comment on an upload.
+func (r *mongoFeedbackCommentRepository) Create(ctx context.Context, comment *domain.FeedbackComment) (primitive.ObjectID, error) {
+	if comment.UploadID == primitive.NilObjectID || comment.TrainerID == primitive.NilObjectID || comment.Text == "" {
+		return primitive.NilObjectID, errors.New("feedback comment requires uploadId, trainerId, and text")
+	}
+	comment.ID = primitive.NewObjectID()
+	now := time.Now().UTC()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted feedback comment ID")
+	}
+	return insertedID, nil
+}
+
+// GetByID retrieves a single comment by its ID.
+func (r *mongoFeedbackCommentRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.FeedbackComment, error) {
+	var comment domain.FeedbackComment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetByUploadID retrieves all comments on an upload in playback order. Comments
+// without a timestamp sort first, then by creation time.
+func (r *mongoFeedbackCommentRepository) GetByUploadID(ctx context.Context, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error) {
+	var comments []domain.FeedbackComment
+	filter := bson.M{"uploadId": uploadID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "startMs", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Update changes a comment's timestamp range and text.
+func (r *mongoFeedbackCommentRepository) Update(ctx context.Context, comment *domain.FeedbackComment) error {
+	if comment.ID == primitive.NilObjectID {
+		return errors.New("feedback comment ID is required for update")
+	}
+	set := bson.M{
+		"text":      comment.Text,
+		"updatedAt": time.Now().UTC(),
+	}
+	unset := bson.M{}
+	if comment.StartMs != nil {
+		set["startMs"] = *comment.StartMs
+	} else {
+		unset["startMs"] = ""
+	}
+	if comment.EndMs != nil {
+		set["endMs"] = *comment.EndMs
+	} else {
+		unset["endMs"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": comment.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// SetResolved marks a comment resolved at resolvedAt, or reopens it when resolvedAt is nil.
+func (r *mongoFeedbackCommentRepository) SetResolved(ctx context.Context, id primitive.ObjectID, resolvedAt *time.Time) error {
+	var update bson.M
+	if resolvedAt != nil {
+		update = bson.M{"$set": bson.M{"resolvedAt": *resolvedAt, "updatedAt": time.Now().UTC()}}
+	} else {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": time.Now().UTC()},
+			"$unset": bson.M{"resolvedAt": ""},
+		}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a single comment.
+func (r *mongoFeedbackCommentRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByUploadID removes every comment on an upload, e.g. when the upload is deleted.
+func (r *mongoFeedbackCommentRepository) DeleteByUploadID(ctx context.Context, uploadID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"uploadId": uploadID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureFeedbackCommentIndexes creates necessary indexes for the feedback_comments collection.
+func EnsureFeedbackCommentIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Comments of an upload in playback order
+			Keys:    bson.D{{Key: "uploadId", Value: 1}, {Key: "startMs", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
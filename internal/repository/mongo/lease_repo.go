@@ -0,0 +1,54 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const leaseCollectionName = "leases"
+
+// mongoLeaseRepository implements repository.LeaseRepository. Each lease is one
+// document keyed by name; the unique _id makes taking it atomic.
+type mongoLeaseRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoLeaseRepository creates a new Lease repository.
+func NewMongoLeaseRepository(db *mongo.Database) repository.LeaseRepository {
+	return &mongoLeaseRepository{
+		collection: db.Collection(leaseCollectionName),
+	}
+}
+
+// TryAcquire renews holder's lease, or takes it over if it is free or expired.
+func (r *mongoLeaseRepository) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"holder": holder},
+			{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"holder": holder, "expiresAt": now.Add(ttl), "renewedAt": now}}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// The lease exists and is someone else's.
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release gives up holder's lease so another instance can take over immediately.
+func (r *mongoLeaseRepository) Release(ctx context.Context, name, holder string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
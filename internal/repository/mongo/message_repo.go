@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const messageCollectionName = "messages"
+
+// mongoMessageRepository implements repository.MessageRepository
+type mongoMessageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoMessageRepository creates a new Message repository.
+func NewMongoMessageRepository(db *mongo.Database) repository.MessageRepository {
+	return &mongoMessageRepository{
+		collection: db.Collection(messageCollectionName),
+	}
+}
+
+// conversationFilter selects a conversation's messages, or one thread of it.
+func conversationFilter(conversationID primitive.ObjectID, anchor *domain.MessageAnchor) bson.M {
+	filter := bson.M{"conversationId": conversationID}
+	if anchor != nil {
+		filter["anchor.type"] = anchor.Type
+		filter["anchor.id"] = anchor.ID
+	}
+	return filter
+}
+
+// Create inserts a new message.
+func (r *mongoMessageRepository) Create(ctx context.Context, msg *domain.Message) (primitive.ObjectID, error) {
+	if msg.ConversationID == primitive.NilObjectID || msg.SenderID == primitive.NilObjectID || msg.Body == "" {
+		return primitive.NilObjectID, errors.New("message requires conversationId, senderId, and body")
+	}
+	msg.ID = primitive.NewObjectID()
+	msg.CreatedAt = time.Now().UTC()
+
+	result, err := r.collection.InsertOne(ctx, msg)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted message ID")
+	}
+	return insertedID, nil
+}
+
+// List returns one page of messages, newest first. ObjectIDs grow with creation time,
+// so beforeID is a stable cursor even when new messages arrive between pages.
+func (r *mongoMessageRepository) List(ctx context.Context, conversationID primitive.ObjectID, anchor *domain.MessageAnchor, beforeID *primitive.ObjectID, limit int64) ([]domain.Message, error) {
+	var messages []domain.Message
+	filter := conversationFilter(conversationID, anchor)
+	if beforeID != nil {
+		filter["_id"] = bson.M{"$lt": *beforeID}
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkRead sets the read receipt on every unread message to recipientID in the
+// conversation (or thread) and returns how many were marked.
+func (r *mongoMessageRepository) MarkRead(ctx context.Context, conversationID, recipientID primitive.ObjectID, anchor *domain.MessageAnchor, readAt time.Time) (int64, error) {
+	filter := conversationFilter(conversationID, anchor)
+	filter["recipientId"] = recipientID
+	filter["readAt"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"readAt": readAt}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// CountUnread counts the messages recipientID has not read, across all conversations.
+func (r *mongoMessageRepository) CountUnread(ctx context.Context, recipientID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"recipientId": recipientID, "readAt": bson.M{"$exists": false}})
+}
+
+// CountUnreadByConversation counts recipientID's unread messages per conversation.
+// Conversations with nothing unread are absent from the map.
+func (r *mongoMessageRepository) CountUnreadByConversation(ctx context.Context, recipientID primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"recipientId": recipientID, "readAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$conversationId", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ConversationID primitive.ObjectID `bson:"_id"`
+		Count          int64              `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[primitive.ObjectID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetThreads summarizes the anchored threads of a conversation, most recently active first.
+func (r *mongoMessageRepository) GetThreads(ctx context.Context, conversationID, userID primitive.ObjectID) ([]domain.MessageThread, error) {
+	unread := bson.M{"$cond": bson.A{
+		bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$recipientId", userID}},
+			bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$readAt", nil}}, nil}},
+		}},
+		1, 0,
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"conversationId": conversationID, "anchor": bson.M{"$exists": true}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$anchor",
+			"messageCount":  bson.M{"$sum": 1},
+			"unreadCount":   bson.M{"$sum": unread},
+			"lastMessageAt": bson.M{"$max": "$createdAt"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "lastMessageAt", Value: -1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var threads []domain.MessageThread
+	if err = cursor.All(ctx, &threads); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// EnsureMessageIndexes creates necessary indexes for the messages collection.
+func EnsureMessageIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Conversation history, newest first
+			Keys:    bson.D{{Key: "conversationId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index(),
+		},
+		{
+			// Thread history
+			Keys:    bson.D{{Key: "conversationId", Value: 1}, {Key: "anchor.type", Value: 1}, {Key: "anchor.id", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index(),
+		},
+		{
+			// Unread counts
+			Keys:    bson.D{{Key: "recipientId", Value: 1}, {Key: "readAt", Value: 1}, {Key: "conversationId", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	notificationCollectionName            = "notifications"
+	notificationPreferencesCollectionName = "notification_preferences"
+)
+
+// mongoNotificationRepository implements repository.NotificationRepository
+type mongoNotificationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoNotificationRepository creates a new Notification repository.
+func NewMongoNotificationRepository(db *mongo.Database) repository.NotificationRepository {
+	return &mongoNotificationRepository{
+		collection: db.Collection(notificationCollectionName),
+	}
+}
+
+// Create inserts a new notification.
+func (r *mongoNotificationRepository) Create(ctx context.Context, notification *domain.Notification) (primitive.ObjectID, error) {
+	if notification.UserID == primitive.NilObjectID || notification.Type == "" {
+		return primitive.NilObjectID, errors.New("notification requires userId and type")
+	}
+	notification.ID = primitive.NewObjectID()
+	notification.CreatedAt = time.Now().UTC()
+
+	result, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted notification ID")
+	}
+	return insertedID, nil
+}
+
+// List returns one page of the user's notifications, newest first.
+func (r *mongoNotificationRepository) List(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, beforeID *primitive.ObjectID, limit int64) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	filter := bson.M{"userId": userID}
+	if unreadOnly {
+		filter["readAt"] = bson.M{"$exists": false}
+	}
+	if beforeID != nil {
+		filter["_id"] = bson.M{"$lt": *beforeID}
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkRead marks one of the user's notifications read. Already-read notifications keep
+// their original read time.
+func (r *mongoNotificationRepository) MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID, readAt time.Time) error {
+	filter := bson.M{"_id": notificationID, "userId": userID}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": notificationID, "userId": userID, "readAt": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"readAt": readAt}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Either already read or not this user's notification.
+		count, err := r.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return repository.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user read.
+func (r *mongoNotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, readAt time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"userId": userID, "readAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"readAt": readAt}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// CountUnread counts the user's unread notifications.
+func (r *mongoNotificationRepository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"userId": userID, "readAt": bson.M{"$exists": false}})
+}
+
+// EnsureNotificationIndexes creates necessary indexes for the notifications collection.
+func EnsureNotificationIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Notification center, newest first
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index(),
+		},
+		{
+			// Unread counts and "unread only" lists
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "readAt", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
+
+// mongoNotificationPreferencesRepository implements repository.NotificationPreferencesRepository
+type mongoNotificationPreferencesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoNotificationPreferencesRepository creates a new NotificationPreferences repository.
+func NewMongoNotificationPreferencesRepository(db *mongo.Database) repository.NotificationPreferencesRepository {
+	return &mongoNotificationPreferencesRepository{
+		collection: db.Collection(notificationPreferencesCollectionName),
+	}
+}
+
+// Get retrieves a user's preferences, keyed by user ID.
+func (r *mongoNotificationPreferencesRepository) Get(ctx context.Context, userID primitive.ObjectID) (*domain.NotificationPreferences, error) {
+	var prefs domain.NotificationPreferences
+	err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&prefs)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// Upsert saves a user's preferences, creating them on first change.
+func (r *mongoNotificationPreferencesRepository) Upsert(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	if prefs.UserID == primitive.NilObjectID {
+		return errors.New("user ID is required for notification preferences")
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+	if prefs.Muted == nil {
+		prefs.Muted = []string{}
+	}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": prefs.UserID}, prefs, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const planDraftCollectionName = "plan_drafts"
+
+// mongoPlanDraftRepository implements repository.PlanDraftRepository
+type mongoPlanDraftRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoPlanDraftRepository creates a new PlanDraft repository.
+func NewMongoPlanDraftRepository(db *mongo.Database) repository.PlanDraftRepository {
+	return &mongoPlanDraftRepository{
+		collection: db.Collection(planDraftCollectionName),
+	}
+}
+
+// GetByPlanID retrieves a plan's staged edits, keyed by plan ID.
+func (r *mongoPlanDraftRepository) GetByPlanID(ctx context.Context, planID primitive.ObjectID) (*domain.PlanDraft, error) {
+	var draft domain.PlanDraft
+	err := r.collection.FindOne(ctx, bson.M{"_id": planID}).Decode(&draft)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// GetByWorkoutID retrieves the draft holding a workout that was added in it and not
+// published yet.
+func (r *mongoPlanDraftRepository) GetByWorkoutID(ctx context.Context, workoutID primitive.ObjectID) (*domain.PlanDraft, error) {
+	var draft domain.PlanDraft
+	filter := bson.M{"added": workoutID, "workouts." + workoutID.Hex(): bson.M{"$exists": true}}
+	err := r.collection.FindOne(ctx, filter).Decode(&draft)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// GetByTrainerID retrieves every draft of a trainer's plans.
+func (r *mongoPlanDraftRepository) GetByTrainerID(ctx context.Context, trainerID primitive.ObjectID) ([]domain.PlanDraft, error) {
+	var drafts []domain.PlanDraft
+	cursor, err := r.collection.Find(ctx, bson.M{"trainerId": trainerID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// Stage saves one edit in the draft of planID with a single update, creating the draft on
+// the first edit. Each item is its own field, so concurrent edits of different items
+// don't overwrite each other.
+func (r *mongoPlanDraftRepository) Stage(ctx context.Context, trainerID, planID primitive.ObjectID, edit domain.PlanDraftEdit) error {
+	if planID == primitive.NilObjectID {
+		return errors.New("plan ID is required to stage an edit")
+	}
+	now := time.Now().UTC()
+	set := bson.M{"updatedAt": now}
+	stage := func(field string, id primitive.ObjectID, item any) error {
+		if id == primitive.NilObjectID {
+			return errors.New("staged items need an ID")
+		}
+		set[field+id.Hex()] = item
+		return nil
+	}
+	for _, w := range edit.Workouts {
+		if err := stage("workouts.", w.ID, w); err != nil {
+			return err
+		}
+	}
+	for _, a := range edit.Assignments {
+		if err := stage("assignments.", a.ID, a); err != nil {
+			return err
+		}
+	}
+	for _, b := range edit.Blocks {
+		if err := stage("blocks.", b.ID, b); err != nil {
+			return err
+		}
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"trainerId": trainerID, "createdAt": now},
+	}
+
+	addToSet := bson.M{}
+	if len(edit.Added) > 0 {
+		addToSet["added"] = bson.M{"$each": edit.Added}
+	}
+	if len(edit.Removed) > 0 {
+		addToSet["removed"] = bson.M{"$each": edit.Removed}
+	}
+	if len(addToSet) > 0 {
+		update["$addToSet"] = addToSet
+	}
+	if len(edit.Dropped) > 0 {
+		unset := bson.M{}
+		for _, id := range edit.Dropped {
+			for _, field := range []string{"workouts.", "assignments.", "blocks."} {
+				if _, staged := set[field+id.Hex()]; !staged { // Mongo rejects $set and $unset of one field
+					unset[field+id.Hex()] = ""
+				}
+			}
+		}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": planID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Delete drops a plan's draft. A plan without one is not an error.
+func (r *mongoPlanDraftRepository) Delete(ctx context.Context, planID primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": planID})
+	return err
+}
+
+// EnsurePlanDraftIndexes creates necessary indexes for the plan_drafts collection.
+func EnsurePlanDraftIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Drafts of a trainer's plans, for exercise usage and merges
+			Keys:    bson.D{{Key: "trainerId", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
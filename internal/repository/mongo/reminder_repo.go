@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const reminderSettingsCollectionName = "reminder_settings"
+
+// mongoReminderSettingsRepository implements repository.ReminderSettingsRepository
+type mongoReminderSettingsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoReminderSettingsRepository creates a new ReminderSettings repository.
+func NewMongoReminderSettingsRepository(db *mongo.Database) repository.ReminderSettingsRepository {
+	return &mongoReminderSettingsRepository{
+		collection: db.Collection(reminderSettingsCollectionName),
+	}
+}
+
+// Get retrieves a client's settings, keyed by client ID.
+func (r *mongoReminderSettingsRepository) Get(ctx context.Context, clientID primitive.ObjectID) (*domain.ReminderSettings, error) {
+	var settings domain.ReminderSettings
+	err := r.collection.FindOne(ctx, bson.M{"_id": clientID}).Decode(&settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Upsert saves the client's choices, creating the document on first change.
+func (r *mongoReminderSettingsRepository) Upsert(ctx context.Context, settings *domain.ReminderSettings) error {
+	if settings.ClientID == primitive.NilObjectID {
+		return errors.New("client ID is required for reminder settings")
+	}
+	settings.UpdatedAt = time.Now().UTC()
+	update := bson.M{"$set": bson.M{
+		"remindersDisabled": settings.RemindersDisabled,
+		"reminderTime":      settings.ReminderTime,
+		"nudgesDisabled":    settings.NudgesDisabled,
+		"nudgeTime":         settings.NudgeTime,
+		"timeZone":          settings.TimeZone,
+		"updatedAt":         settings.UpdatedAt,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": settings.ClientID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoReminderSettingsRepository) MarkReminderSent(ctx context.Context, clientID primitive.ObjectID, date string) (bool, error) {
+	return r.markSent(ctx, clientID, "lastReminderDate", date)
+}
+
+func (r *mongoReminderSettingsRepository) MarkNudgeSent(ctx context.Context, clientID primitive.ObjectID, date string) (bool, error) {
+	return r.markSent(ctx, clientID, "lastNudgeDate", date)
+}
+
+// markSent sets field to date unless it already holds it. Clients without settings get
+// a document; if it exists with the date already set, the upsert's insert collides
+// with it, which also means "already sent".
+func (r *mongoReminderSettingsRepository) markSent(ctx context.Context, clientID primitive.ObjectID, field, date string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": clientID, field: bson.M{"$ne": date}},
+		bson.M{"$set": bson.M{field: date}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return result.ModifiedCount > 0 || result.UpsertedCount > 0, nil
+}
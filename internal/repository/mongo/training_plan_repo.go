@@ -105,6 +105,10 @@ func EnsureTrainingPlanIndexes(ctx context.Context, collection *mongo.Collection
 			Keys:    bson.D{{Key: "clientId", Value: 1}, {Key: "isActive", Value: 1}},
 			Options: options.Index().SetSparse(true), // Sparse if not all docs might have isActive? Or if only true matters?
 		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}}, // Lifecycle job: scheduled and active plans
+			Options: options.Index(),
+		},
 		{
 			Keys:    bson.D{{Key: "trainerId", Value: 1}}, // Simple index on trainerId
 			Options: options.Index(),
@@ -136,6 +140,10 @@ func (r *mongoTrainingPlanRepository) Update(ctx context.Context, plan *domain.T
             "startDate":   plan.StartDate, // Pass pointer directly
             "endDate":     plan.EndDate,   // Pass pointer directly
             "isActive":    plan.IsActive,
+            "status":      plan.EffectiveStatus(),
+            "endingNotifiedAt": plan.EndingNotifiedAt, // nil clears it (e.g. EndDate moved)
+            "publishedAt": plan.PublishedAt,
+            "changeSummary": plan.ChangeSummary,
             "updatedAt":   time.Now().UTC(), // Always update this
         },
     }
@@ -159,7 +167,8 @@ func (r *mongoTrainingPlanRepository) DeactivateOtherPlansForClient(ctx context.
         "isActive":  true,
         "_id":       bson.M{"$ne": excludePlanID}, // Don't deactivate the plan we're trying to activate
     }
-    update := bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now().UTC()}}
+    // The replaced plan is over for the client.
+    update := bson.M{"$set": bson.M{"isActive": false, "status": domain.PlanStatusCompleted, "updatedAt": time.Now().UTC()}}
     _, err := r.collection.UpdateMany(ctx, filter, update)
     return err
 }
@@ -187,4 +196,65 @@ func (r *mongoTrainingPlanRepository) Delete(ctx context.Context, planID primiti
         return repository.ErrNotFound // Or a more specific "delete failed / not authorized"
     }
     return nil
-}
\ No newline at end of file
+}
+
+// GetActiveClientIDs returns the clients that have at least one active plan.
+func (r *mongoTrainingPlanRepository) GetActiveClientIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	values, err := r.collection.Distinct(ctx, "clientId", bson.M{"isActive": true})
+	if err != nil {
+		return nil, err
+	}
+	clientIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			clientIDs = append(clientIDs, id)
+		}
+	}
+	return clientIDs, nil
+}
+
+// GetByStatuses lists all plans in any of statuses, e.g. for the lifecycle job.
+func (r *mongoTrainingPlanRepository) GetByStatuses(ctx context.Context, statuses []domain.PlanStatus) ([]domain.TrainingPlan, error) {
+	var plans []domain.TrainingPlan
+	findOptions := options.Find().SetSort(bson.D{{Key: "startDate", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"status": bson.M{"$in": statuses}}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// BackfillStatuses gives plans saved before statuses existed one: active if isActive,
+// draft otherwise. Safe to run repeatedly.
+func (r *mongoTrainingPlanRepository) BackfillStatuses(ctx context.Context) (int64, error) {
+	var total int64
+	for _, status := range []domain.PlanStatus{domain.PlanStatusActive, domain.PlanStatusDraft} {
+		filter := bson.M{"status": bson.M{"$exists": false}, "isActive": status == domain.PlanStatusActive}
+		result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": status}})
+		if err != nil {
+			return total, err
+		}
+		total += result.ModifiedCount
+	}
+	return total, nil
+}
+
+// BackfillPublishedAt marks plans saved before publishing existed as published when
+// they were created, so their clients keep seeing them. Safe to run repeatedly.
+func (r *mongoTrainingPlanRepository) BackfillPublishedAt(ctx context.Context) (int64, error) {
+	filter := bson.M{"publishedAt": bson.M{"$exists": false}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"publishedAt": "$createdAt"}}}}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,94 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoTransactor implements repository.Transactor using MongoDB multi-document transactions.
+type mongoTransactor struct {
+	client *mongo.Client
+
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// NewMongoTransactor creates a Transactor for the given client.
+func NewMongoTransactor(client *mongo.Client) repository.Transactor {
+	return &mongoTransactor{client: client}
+}
+
+// WithTransaction runs fn inside a transaction. Repository calls made with the ctx
+// passed to fn take part in it. Transactions need a replica set or sharded cluster
+// (docker-compose runs a single-node replica set); on a standalone server
+// repository.ErrTransactionsUnsupported is returned instead of writing without one.
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	supported, err := t.supportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return repository.ErrTransactionsUnsupported
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// supportsTransactions checks the deployment once; a failed check is retried on the next call.
+func (t *mongoTransactor) supportsTransactions(ctx context.Context) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.checked {
+		return t.supported, nil
+	}
+	supported, err := supportsTransactions(ctx, t.client)
+	if err != nil {
+		return false, err
+	}
+	if !supported {
+		log.Println("ERROR: MongoDB deployment does not support transactions; batch edits, reorders, publishing and exercise edits will fail until it runs as a replica set")
+	}
+	t.checked, t.supported = true, supported
+	return supported, nil
+}
+
+// RequireTransactions returns repository.ErrTransactionsUnsupported when the deployment
+// is a standalone mongod. Call it at startup so a misconfigured server fails fast
+// instead of rejecting every transactional write later.
+func RequireTransactions(ctx context.Context, client *mongo.Client) error {
+	supported, err := supportsTransactions(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return repository.ErrTransactionsUnsupported
+	}
+	return nil
+}
+
+// supportsTransactions reports whether the server is a replica set member or mongos.
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var result bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&result); err != nil {
+		return false, err
+	}
+	if _, ok := result["setName"]; ok {
+		return true, nil
+	}
+	return result["msg"] == "isdbgrid", nil
+}
@@ -11,7 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	// "log" // Uncomment if adding logging for indexes
+	"log"
 )
 
 const uploadCollectionName = "uploads"
@@ -44,7 +44,10 @@ func (r *mongoUploadRepository) Create(ctx context.Context, upload *domain.Uploa
 
 	result, err := r.collection.InsertOne(ctx, upload)
 	if err != nil {
-		// Could potentially check for duplicate S3ObjectKey if it must be unique, though unlikely collision
+		if mongo.IsDuplicateKeyError(err) {
+			// The object key was already confirmed; one object must back only one upload.
+			return primitive.NilObjectID, repository.ErrDuplicateKey
+		}
 		return primitive.NilObjectID, err
 	}
 
@@ -71,21 +74,12 @@ func (r *mongoUploadRepository) GetByID(ctx context.Context, id primitive.Object
 	return &upload, nil
 }
 
-// GetByAssignmentID retrieves upload metadata linked to a specific assignment.
-// Assumes one upload per assignment based on the interface definition.
-// If multiple uploads per assignment are allowed, this should return []domain.Upload.
-func (r *mongoUploadRepository) GetByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) (*domain.Upload, error) {
+// GetByObjectKey retrieves the upload metadata recorded for a storage object key.
+func (r *mongoUploadRepository) GetByObjectKey(ctx context.Context, objectKey string) (*domain.Upload, error) {
 	var upload domain.Upload
-	filter := bson.M{"assignmentId": assignmentID}
-
-	// Optional: Sort if multiple could exist and you want the latest/earliest
-	// findOneOptions := options.FindOne().SetSort(bson.D{{"uploadedAt", -1}})
-
-	err := r.collection.FindOne(ctx, filter /*, findOneOptions*/).Decode(&upload)
+	err := r.collection.FindOne(ctx, bson.M{"s3ObjectKey": objectKey}).Decode(&upload)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			// It's valid for an assignment to not have an upload yet,
-			// so ErrNotFound might be the expected outcome in some flows.
 			return nil, repository.ErrNotFound
 		}
 		return nil, err
@@ -93,8 +87,34 @@ func (r *mongoUploadRepository) GetByAssignmentID(ctx context.Context, assignmen
 	return &upload, nil
 }
 
-/*
-// Delete - Optional: Implement if you need to delete metadata, e.g., after deleting from S3.
+// GetByAssignmentID retrieves all uploads linked to a specific assignment, oldest first.
+func (r *mongoUploadRepository) GetByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) ([]domain.Upload, error) {
+	var uploads []domain.Upload
+	filter := bson.M{"assignmentId": assignmentID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "uploadedAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &uploads); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// CountByAssignmentID returns how many uploads exist for an assignment.
+func (r *mongoUploadRepository) CountByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"assignmentId": assignmentID})
+}
+
+// Delete removes upload metadata. The caller is responsible for deleting the S3 object.
 func (r *mongoUploadRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 	result, err := r.collection.DeleteOne(ctx, filter)
@@ -102,19 +122,97 @@ func (r *mongoUploadRepository) Delete(ctx context.Context, id primitive.ObjectI
 		return err
 	}
 	if result.DeletedCount == 0 {
-		return repository.ErrNotFound // Or ErrDeleteFailed
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListAll retrieves every upload record. Used by storage reconciliation.
+func (r *mongoUploadRepository) ListAll(ctx context.Context) ([]domain.Upload, error) {
+	var uploads []domain.Upload
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &uploads); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// SetObjectMissing flags (or, with nil, unflags) an upload whose storage object is gone.
+func (r *mongoUploadRepository) SetObjectMissing(ctx context.Context, id primitive.ObjectID, missingAt *time.Time) error {
+	update := bson.M{"$unset": bson.M{"objectMissingAt": ""}}
+	if missingAt != nil {
+		update = bson.M{"$set": bson.M{"objectMissingAt": missingAt}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
 	}
 	return nil
 }
-*/
+
+// aggregateUsage sums upload sizes per client for uploads matching filter.
+func (r *mongoUploadRepository) aggregateUsage(ctx context.Context, filter bson.M) ([]repository.UploadUsage, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$clientId",
+			"totalBytes":  bson.M{"$sum": "$size"},
+			"uploadCount": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"totalBytes": -1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usage []repository.UploadUsage
+	if err = cursor.All(ctx, &usage); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetUsageByTrainer returns storage used by each of a trainer's clients, largest first.
+func (r *mongoUploadRepository) GetUsageByTrainer(ctx context.Context, trainerID primitive.ObjectID) ([]repository.UploadUsage, error) {
+	return r.aggregateUsage(ctx, bson.M{"trainerId": trainerID})
+}
+
+// GetUsageByClient returns storage used by one client (zero usage if they have no uploads).
+func (r *mongoUploadRepository) GetUsageByClient(ctx context.Context, clientID primitive.ObjectID) (*repository.UploadUsage, error) {
+	usage, err := r.aggregateUsage(ctx, bson.M{"clientId": clientID})
+	if err != nil {
+		return nil, err
+	}
+	if len(usage) == 0 {
+		return &repository.UploadUsage{ClientID: clientID}, nil
+	}
+	return &usage[0], nil
+}
 
 // EnsureUploadIndexes creates necessary indexes for the uploads collection.
 func EnsureUploadIndexes(ctx context.Context, collection *mongo.Collection) {
 	indexes := []mongo.IndexModel{
 		{
-			// Index for finding the upload linked to an assignment (likely unique link)
-			Keys:    bson.D{{Key: "assignmentId", Value: 1}},
-			Options: options.Index().SetUnique(true), // Assuming one upload per assignment
+			// Index for listing an assignment's uploads in order (many per assignment)
+			Keys:    bson.D{{Key: "assignmentId", Value: 1}, {Key: "uploadedAt", Value: 1}},
+			Options: options.Index(),
 		},
 		{
 			// Index for finding uploads by client
@@ -126,15 +224,29 @@ func EnsureUploadIndexes(ctx context.Context, collection *mongo.Collection) {
 			Keys:    bson.D{{Key: "trainerId", Value: 1}},
 			Options: options.Index(),
 		},
-		// Optional: Index on S3ObjectKey if you ever need to look up metadata by the S3 key
-		// {
-		//	Keys:    bson.D{{Key: "s3ObjectKey", Value: 1}},
-		//	Options: options.Index().SetUnique(true), // S3 keys should be unique within the bucket
-		// },
+		{
+			// Index for finding flagged uploads whose storage object is missing
+			Keys:    bson.D{{Key: "objectMissingAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
+	// Older deployments enforced one upload per assignment with a unique index; drop it
+	// so retries and extra angles can be stored. Ignore the error when it no longer exists.
+	_, _ = collection.Indexes().DropOne(ctx, "assignmentId_1")
+
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	if err != nil {
 		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
 	}
+
+	// One upload per storage object, so a key confirmed twice isn't counted or deleted twice.
+	// Created on its own: duplicates left by older versions fail only this index, not the ones above.
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "s3ObjectKey", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("WARN: Failed to create unique s3ObjectKey index on %s (remove duplicate uploads first): %v", collection.Name(), err)
+	}
 }
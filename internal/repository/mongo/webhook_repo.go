@@ -0,0 +1,303 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webhookEndpointCollectionName = "webhook_endpoints"
+	webhookDeliveryCollectionName = "webhook_deliveries"
+
+	// webhookDeliveryRetention is how long the delivery log keeps entries.
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+)
+
+// mongoWebhookEndpointRepository implements repository.WebhookEndpointRepository
+type mongoWebhookEndpointRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWebhookEndpointRepository creates a new WebhookEndpoint repository.
+func NewMongoWebhookEndpointRepository(db *mongo.Database) repository.WebhookEndpointRepository {
+	return &mongoWebhookEndpointRepository{
+		collection: db.Collection(webhookEndpointCollectionName),
+	}
+}
+
+// Create inserts a new webhook endpoint.
+func (r *mongoWebhookEndpointRepository) Create(ctx context.Context, endpoint *domain.WebhookEndpoint) (primitive.ObjectID, error) {
+	if endpoint.TrainerID == primitive.NilObjectID || endpoint.URL == "" {
+		return primitive.NilObjectID, errors.New("webhook endpoint requires trainerId and url")
+	}
+	endpoint.ID = primitive.NewObjectID()
+	endpoint.CreatedAt = time.Now().UTC()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, endpoint)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted webhook endpoint ID")
+	}
+	return insertedID, nil
+}
+
+// GetByID retrieves a webhook endpoint by its ID.
+func (r *mongoWebhookEndpointRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&endpoint)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// GetByTrainerID retrieves a trainer's webhook endpoints, oldest first.
+func (r *mongoWebhookEndpointRepository) GetByTrainerID(ctx context.Context, trainerID primitive.ObjectID) ([]domain.WebhookEndpoint, error) {
+	return r.find(ctx, bson.M{"trainerId": trainerID})
+}
+
+// GetSubscribed returns the active endpoints of the given trainers that want eventType.
+func (r *mongoWebhookEndpointRepository) GetSubscribed(ctx context.Context, trainerIDs []primitive.ObjectID, eventType domain.WebhookEventType) ([]domain.WebhookEndpoint, error) {
+	if len(trainerIDs) == 0 {
+		return []domain.WebhookEndpoint{}, nil
+	}
+	return r.find(ctx, bson.M{
+		"trainerId":  bson.M{"$in": trainerIDs},
+		"active":     true,
+		"eventTypes": eventType,
+	})
+}
+
+func (r *mongoWebhookEndpointRepository) find(ctx context.Context, filter bson.M) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &endpoints); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// Update saves the editable fields of an endpoint.
+func (r *mongoWebhookEndpointRepository) Update(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	if endpoint.ID == primitive.NilObjectID {
+		return errors.New("webhook endpoint ID is required for update")
+	}
+	endpoint.UpdatedAt = time.Now().UTC()
+	update := bson.M{"$set": bson.M{
+		"url":         endpoint.URL,
+		"secret":      endpoint.Secret,
+		"eventTypes":  endpoint.EventTypes,
+		"description": endpoint.Description,
+		"active":      endpoint.Active,
+		"updatedAt":   endpoint.UpdatedAt,
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": endpoint.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a webhook endpoint by its ID.
+func (r *mongoWebhookEndpointRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// EnsureWebhookEndpointIndexes creates necessary indexes for the webhook_endpoints collection.
+func EnsureWebhookEndpointIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Endpoints to deliver an event to
+			Keys:    bson.D{{Key: "trainerId", Value: 1}, {Key: "active", Value: 1}, {Key: "eventTypes", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
+
+// mongoWebhookDeliveryRepository implements repository.WebhookDeliveryRepository
+type mongoWebhookDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWebhookDeliveryRepository creates a new WebhookDelivery repository.
+func NewMongoWebhookDeliveryRepository(db *mongo.Database) repository.WebhookDeliveryRepository {
+	return &mongoWebhookDeliveryRepository{
+		collection: db.Collection(webhookDeliveryCollectionName),
+	}
+}
+
+// Create inserts a new delivery.
+func (r *mongoWebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) (primitive.ObjectID, error) {
+	if delivery.EndpointID == primitive.NilObjectID || delivery.EventType == "" {
+		return primitive.NilObjectID, errors.New("webhook delivery requires endpointId and eventType")
+	}
+	delivery.ID = primitive.NewObjectID()
+	delivery.CreatedAt = time.Now().UTC()
+	delivery.UpdatedAt = delivery.CreatedAt
+	if delivery.Attempts == nil {
+		delivery.Attempts = []domain.WebhookAttempt{}
+	}
+
+	result, err := r.collection.InsertOne(ctx, delivery)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted webhook delivery ID")
+	}
+	return insertedID, nil
+}
+
+// GetByID retrieves a delivery by its ID.
+func (r *mongoWebhookDeliveryRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListByEndpoint returns one page of an endpoint's delivery log, newest first.
+func (r *mongoWebhookDeliveryRepository) ListByEndpoint(ctx context.Context, endpointID primitive.ObjectID, beforeID *primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	filter := bson.M{"endpointId": endpointID}
+	if beforeID != nil {
+		filter["_id"] = bson.M{"$lt": *beforeID}
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDue leases the oldest due pending delivery to the caller.
+func (r *mongoWebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*domain.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":        domain.WebhookDeliveryPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"nextAttemptAt": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery domain.WebhookDelivery
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// RecordAttempt appends an attempt to the delivery and updates its status.
+func (r *mongoWebhookDeliveryRepository) RecordAttempt(ctx context.Context, id primitive.ObjectID, attempt domain.WebhookAttempt, status domain.WebhookDeliveryStatus, nextAttemptAt *time.Time) error {
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  bson.M{"status": status, "updatedAt": time.Now().UTC()},
+	}
+	if nextAttemptAt != nil {
+		update["$set"].(bson.M)["nextAttemptAt"] = *nextAttemptAt
+	} else {
+		update["$unset"] = bson.M{"nextAttemptAt": ""}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByEndpoint removes an endpoint's delivery log, pending deliveries included.
+func (r *mongoWebhookDeliveryRepository) DeleteByEndpoint(ctx context.Context, endpointID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"endpointId": endpointID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureWebhookDeliveryIndexes creates necessary indexes for the webhook_deliveries collection.
+func EnsureWebhookDeliveryIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Delivery log, newest first
+			Keys:    bson.D{{Key: "endpointId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index(),
+		},
+		{
+			// Retry queue
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			// Log retention
+			Keys:    bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(webhookDeliveryRetention.Seconds())),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const workoutBlockCollectionName = "workout_blocks"
+
+// mongoWorkoutBlockRepository implements repository.WorkoutBlockRepository
+type mongoWorkoutBlockRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWorkoutBlockRepository creates a new WorkoutBlock repository.
+func NewMongoWorkoutBlockRepository(db *mongo.Database) repository.WorkoutBlockRepository {
+	return &mongoWorkoutBlockRepository{
+		collection: db.Collection(workoutBlockCollectionName),
+	}
+}
+
+// Create inserts a new block into a workout.
+func (r *mongoWorkoutBlockRepository) Create(ctx context.Context, block *domain.WorkoutBlock) (primitive.ObjectID, error) {
+	if block.WorkoutID == primitive.NilObjectID || block.TrainerID == primitive.NilObjectID || block.Type == "" {
+		return primitive.NilObjectID, errors.New("workout block requires workoutId, trainerId, and type")
+	}
+	if block.ID == primitive.NilObjectID { // Items published from a plan draft keep their staged ID
+		block.ID = primitive.NewObjectID()
+	}
+	now := time.Now().UTC()
+	block.CreatedAt = now
+	block.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, block)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("failed to convert inserted workout block ID")
+	}
+	return insertedID, nil
+}
+
+// GetByID retrieves a single block by its ID.
+func (r *mongoWorkoutBlockRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WorkoutBlock, error) {
+	var block domain.WorkoutBlock
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&block)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetByWorkoutID retrieves all blocks of a workout, ordered by sequence.
+func (r *mongoWorkoutBlockRepository) GetByWorkoutID(ctx context.Context, workoutID primitive.ObjectID) ([]domain.WorkoutBlock, error) {
+	var blocks []domain.WorkoutBlock
+	filter := bson.M{"workoutId": workoutID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Update modifies the structure of a block. WorkoutID and TrainerID never change.
+func (r *mongoWorkoutBlockRepository) Update(ctx context.Context, block *domain.WorkoutBlock) error {
+	if block.ID == primitive.NilObjectID {
+		return errors.New("workout block ID is required for update")
+	}
+	filter := bson.M{"_id": block.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"label":             block.Label,
+			"type":              block.Type,
+			"rounds":            block.Rounds,
+			"restBetweenRounds": block.RestBetweenRounds,
+			"timeCap":           block.TimeCap,
+			"sequence":          block.Sequence,
+			"notes":             block.Notes,
+			"updatedAt":         time.Now().UTC(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a block that belongs to the given workout.
+func (r *mongoWorkoutBlockRepository) Delete(ctx context.Context, blockID primitive.ObjectID, workoutID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":       blockID,
+		"workoutId": workoutID,
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// EnsureWorkoutBlockIndexes creates necessary indexes for the workout_blocks collection.
+func EnsureWorkoutBlockIndexes(ctx context.Context, collection *mongo.Collection) {
+	indexes := []mongo.IndexModel{
+		{
+			// Blocks of a workout in display order
+			Keys:    bson.D{{Key: "workoutId", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		// log.Printf("WARN: Failed to create indexes for collection %s: %v", collection.Name(), err)
+	}
+}
@@ -35,7 +35,9 @@ func (r *mongoWorkoutRepository) Create(ctx context.Context, workout *domain.Wor
 	if workout.TrainingPlanID == primitive.NilObjectID || workout.TrainerID == primitive.NilObjectID || workout.ClientID == primitive.NilObjectID || workout.Name == "" {
 		return primitive.NilObjectID, errors.New("workout requires trainingPlanId, trainerId, clientId, and name")
 	}
-	workout.ID = primitive.NewObjectID()
+	if workout.ID == primitive.NilObjectID { // Items published from a plan draft keep their staged ID
+		workout.ID = primitive.NewObjectID()
+	}
 	now := time.Now().UTC()
 	workout.CreatedAt = now
 	workout.UpdatedAt = now
@@ -163,3 +165,27 @@ func (r *mongoWorkoutRepository) Delete(ctx context.Context, workoutID primitive
 	}
 	return nil
 }
+
+// UpdateSequences sets each workout's sequence to its position in orderedIDs with a single
+// bulk write. Every ID must belong to the given training plan, otherwise ErrNotFound is returned.
+func (r *mongoWorkoutRepository) UpdateSequences(ctx context.Context, planID primitive.ObjectID, orderedIDs []primitive.ObjectID) error {
+	if len(orderedIDs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	models := make([]mongo.WriteModel, len(orderedIDs))
+	for i, id := range orderedIDs {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id, "trainingPlanId": planID}).
+			SetUpdate(bson.M{"$set": bson.M{"sequence": i, "updatedAt": now}})
+	}
+
+	result, err := r.collection.BulkWrite(ctx, models)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount != int64(len(orderedIDs)) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"alcyxob/fitness-app/internal/domain" // Import our defined domain models
 	"context"                             // Standard for request-scoped deadlines, cancellation signals, etc.
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive" // For using ObjectIDs
 )
@@ -12,6 +13,10 @@ var (
 	ErrNotFound     = RepositoryError("not found")
 	ErrUpdateFailed = RepositoryError("update failed")
 	ErrDeleteFailed = RepositoryError("delete failed")
+	ErrDuplicateKey = RepositoryError("already exists") // A unique field (e.g. an object key) is already recorded
+	// ErrTransactionsUnsupported is returned by Transactor when the database cannot
+	// run multi-document transactions (e.g. a standalone MongoDB server).
+	ErrTransactionsUnsupported = RepositoryError("database does not support transactions")
 	// Add more specific errors as needed
 )
 
@@ -22,6 +27,14 @@ func (e RepositoryError) Error() string {
 	return string(e)
 }
 
+// Transactor runs a unit of work atomically across repositories. Repository calls
+// made with the ctx passed to fn take part in the transaction. fn may be run more than
+// once if the transaction is retried. Where the database can't provide transactions,
+// ErrTransactionsUnsupported is returned without running fn.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // UserRepository defines the interface for interacting with user data.
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) (primitive.ObjectID, error)
@@ -43,6 +56,22 @@ type ExerciseRepository interface {
 	Delete(ctx context.Context, id primitive.ObjectID, trainerID primitive.ObjectID) error // Ensure trainer owns the exercise
 }
 
+// ExerciseMediaRepository defines the interface for interacting with exercise media metadata.
+type ExerciseMediaRepository interface {
+	Create(ctx context.Context, media *domain.ExerciseMedia) (primitive.ObjectID, error) // ErrDuplicateKey if the object key is already recorded
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ExerciseMedia, error)
+	GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.ExerciseMedia, error)
+	Delete(ctx context.Context, id primitive.ObjectID, trainerID primitive.ObjectID) error // Ensure trainer owns the media
+	ListAll(ctx context.Context) ([]domain.ExerciseMedia, error) // For storage reconciliation
+}
+
+// ExerciseRevisionRepository defines the interface for interacting with immutable exercise revisions.
+type ExerciseRevisionRepository interface {
+	Create(ctx context.Context, revision *domain.ExerciseRevision) (primitive.ObjectID, error)
+	GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.ExerciseRevision, error) // Newest first
+	GetByExerciseAndRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int) (*domain.ExerciseRevision, error)
+}
+
 // AssignmentRepository defines the interface for interacting with assignment data.
 type AssignmentRepository interface {
 	Create(ctx context.Context, assignment *domain.Assignment) (primitive.ObjectID, error)
@@ -50,14 +79,44 @@ type AssignmentRepository interface {
 	GetByWorkoutID(ctx context.Context, workoutID primitive.ObjectID) ([]domain.Assignment, error) // <<< ADD/VERIFY THIS
 	Update(ctx context.Context, assignment *domain.Assignment) error
 	Delete(ctx context.Context, assignmentID primitive.ObjectID, workoutID primitive.ObjectID) error 
+	PinExerciseRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int) (int64, error) // Pins assignments that have no revision yet
+	UpgradeExerciseRevision(ctx context.Context, exerciseID primitive.ObjectID, revision int, statuses []domain.AssignmentStatus) (int64, error)
+	GetByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) ([]domain.Assignment, error)
+	CountByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) (int64, error)
+	ReassignExercise(ctx context.Context, fromExerciseID, toExerciseID primitive.ObjectID, toRevision int, repin []domain.AssignmentStatus) (int64, error) // Re-points all assignments; only repin statuses move to toRevision
+	DeleteByExerciseID(ctx context.Context, exerciseID primitive.ObjectID) (int64, error)
+	ClearBlock(ctx context.Context, blockID primitive.ObjectID) (int64, error) // Ungroups assignments of a deleted block
+	UpdateSequences(ctx context.Context, workoutID primitive.ObjectID, orderedIDs []primitive.ObjectID) error // Sequence = position in orderedIDs
+	AddFeedbackAttachment(ctx context.Context, assignmentID primitive.ObjectID, attachment domain.FeedbackAttachment) error
+	UpdateFeedback(ctx context.Context, assignmentID primitive.ObjectID, feedback *string, status domain.AssignmentStatus) (*domain.Assignment, error) // Nil feedback / empty status are left alone; returns the updated assignment
+	RemoveFeedbackAttachment(ctx context.Context, assignmentID, attachmentID primitive.ObjectID) error // ErrNotFound if absent
+	GetSubmittedByTrainer(ctx context.Context, trainerID primitive.ObjectID, clientID *primitive.ObjectID) ([]domain.Assignment, error) // Review queue, oldest submission first
+	BackfillOwnerIDs(ctx context.Context) (int64, error) // Copies trainerId/clientId from workouts onto older assignments
+	GetByClientID(ctx context.Context, clientID primitive.ObjectID) ([]domain.Assignment, error) // All of a client's assignments, across plans
+	ListFeedbackAttachmentKeys(ctx context.Context) ([]string, error) // Object keys of every feedback attachment, for storage reconciliation
 }
 
 // UploadRepository defines the interface for interacting with upload metadata.
 type UploadRepository interface {
-	Create(ctx context.Context, upload *domain.Upload) (primitive.ObjectID, error)
+	Create(ctx context.Context, upload *domain.Upload) (primitive.ObjectID, error) // ErrDuplicateKey if the object key is already recorded
 	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Upload, error)
-	GetByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) (*domain.Upload, error) // Assuming one upload per assignment? Adjust if multiple allowed.
-	// Delete(ctx context.Context, id primitive.ObjectID) error // Usually delete metadata when S3 object is deleted
+	GetByObjectKey(ctx context.Context, objectKey string) (*domain.Upload, error) // ErrNotFound if the key isn't recorded
+	GetByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) ([]domain.Upload, error) // All uploads of an assignment, oldest first
+	CountByAssignmentID(ctx context.Context, assignmentID primitive.ObjectID) (int64, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	ListAll(ctx context.Context) ([]domain.Upload, error) // For storage reconciliation
+	SetObjectMissing(ctx context.Context, id primitive.ObjectID, missingAt *time.Time) error // nil clears the flag
+
+	// Storage accounting
+	GetUsageByTrainer(ctx context.Context, trainerID primitive.ObjectID) ([]UploadUsage, error) // One entry per client
+	GetUsageByClient(ctx context.Context, clientID primitive.ObjectID) (*UploadUsage, error)
+}
+
+// UploadUsage aggregates the uploads of one client.
+type UploadUsage struct {
+	ClientID    primitive.ObjectID `bson:"_id"`
+	TotalBytes  int64              `bson:"totalBytes"`
+	UploadCount int64              `bson:"uploadCount"`
 }
 
 // TrainingPlanRepository defines the interface for interacting with training plan data.
@@ -68,6 +127,10 @@ type TrainingPlanRepository interface {
 	Update(ctx context.Context, plan *domain.TrainingPlan) error
 	DeactivateOtherPlansForClient(ctx context.Context, clientID, trainerID primitive.ObjectID, excludePlanID primitive.ObjectID) error // For isActive logic
 	Delete(ctx context.Context, planID primitive.ObjectID, trainerID primitive.ObjectID) error
+	GetActiveClientIDs(ctx context.Context) ([]primitive.ObjectID, error) // Clients with at least one active plan
+	GetByStatuses(ctx context.Context, statuses []domain.PlanStatus) ([]domain.TrainingPlan, error)
+	BackfillStatuses(ctx context.Context) (int64, error) // Sets status on plans saved before statuses existed
+	BackfillPublishedAt(ctx context.Context) (int64, error) // Publishes plans saved before publishing existed
 }
 
 // WorkoutRepository defines the interface for interacting with workout data.
@@ -77,4 +140,131 @@ type WorkoutRepository interface {
 	GetByPlanID(ctx context.Context, planID primitive.ObjectID) ([]domain.Workout, error) // Get all workouts for a plan
 	Update(ctx context.Context, workout *domain.Workout) error // <<< ADD THIS
 	Delete(ctx context.Context, workoutID primitive.ObjectID, trainerID primitive.ObjectID) error 
+	UpdateSequences(ctx context.Context, planID primitive.ObjectID, orderedIDs []primitive.ObjectID) error // Sequence = position in orderedIDs
+}
+
+// WorkoutBlockRepository defines the interface for interacting with workout block (superset/circuit) data.
+type WorkoutBlockRepository interface {
+	Create(ctx context.Context, block *domain.WorkoutBlock) (primitive.ObjectID, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WorkoutBlock, error)
+	GetByWorkoutID(ctx context.Context, workoutID primitive.ObjectID) ([]domain.WorkoutBlock, error) // Ordered by sequence
+	Update(ctx context.Context, block *domain.WorkoutBlock) error
+	Delete(ctx context.Context, blockID primitive.ObjectID, workoutID primitive.ObjectID) error
+}
+
+// FeedbackCommentRepository defines the interface for interacting with timestamped feedback on uploads.
+type FeedbackCommentRepository interface {
+	Create(ctx context.Context, comment *domain.FeedbackComment) (primitive.ObjectID, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.FeedbackComment, error)
+	GetByUploadID(ctx context.Context, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error) // Playback order, untimed first
+	Update(ctx context.Context, comment *domain.FeedbackComment) error                                 // Text and timestamp range
+	SetResolved(ctx context.Context, id primitive.ObjectID, resolvedAt *time.Time) error                // nil reopens
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByUploadID(ctx context.Context, uploadID primitive.ObjectID) (int64, error)
+}
+
+// ConversationRepository defines the interface for trainer–client message channels.
+type ConversationRepository interface {
+	GetOrCreate(ctx context.Context, trainerID, clientID primitive.ObjectID) (*domain.Conversation, error) // One per pair
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Conversation, error)
+	GetByParticipant(ctx context.Context, userID primitive.ObjectID) ([]domain.Conversation, error) // Trainer or client; latest activity first
+	SetLastMessage(ctx context.Context, id primitive.ObjectID, msg *domain.Message, preview string) error
+}
+
+// MessageRepository defines the interface for messages within conversations.
+type MessageRepository interface {
+	Create(ctx context.Context, msg *domain.Message) (primitive.ObjectID, error)
+	// List returns up to limit messages older than beforeID (nil = newest), newest first.
+	// A nil anchor lists the whole conversation, threads included.
+	List(ctx context.Context, conversationID primitive.ObjectID, anchor *domain.MessageAnchor, beforeID *primitive.ObjectID, limit int64) ([]domain.Message, error)
+	MarkRead(ctx context.Context, conversationID, recipientID primitive.ObjectID, anchor *domain.MessageAnchor, readAt time.Time) (int64, error) // nil anchor = whole conversation
+	CountUnread(ctx context.Context, recipientID primitive.ObjectID) (int64, error)
+	CountUnreadByConversation(ctx context.Context, recipientID primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	GetThreads(ctx context.Context, conversationID, userID primitive.ObjectID) ([]domain.MessageThread, error) // Latest activity first; unread counts for userID
+}
+
+// NotificationRepository defines the interface for users' notification centers.
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) (primitive.ObjectID, error)
+	// List returns up to limit of the user's notifications older than beforeID (nil = newest), newest first.
+	List(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, beforeID *primitive.ObjectID, limit int64) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID, readAt time.Time) error // ErrNotFound if not the user's
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID, readAt time.Time) (int64, error)
+	CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// NotificationPreferencesRepository defines the interface for per-user notification settings.
+type NotificationPreferencesRepository interface {
+	Get(ctx context.Context, userID primitive.ObjectID) (*domain.NotificationPreferences, error) // ErrNotFound if never saved
+	Upsert(ctx context.Context, prefs *domain.NotificationPreferences) error
+}
+
+// DeviceRepository defines the interface for devices registered for push notifications.
+type DeviceRepository interface {
+	Upsert(ctx context.Context, device *domain.Device) (*domain.Device, error) // Keyed by token
+	GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Device, error)
+	Delete(ctx context.Context, userID primitive.ObjectID, token string) error // ErrNotFound if not the user's
+	DeleteByToken(ctx context.Context, token string) error                     // Used to prune tokens the provider rejected
+}
+
+// WebhookEndpointRepository defines the interface for trainers' webhook endpoints.
+type WebhookEndpointRepository interface {
+	Create(ctx context.Context, endpoint *domain.WebhookEndpoint) (primitive.ObjectID, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookEndpoint, error)
+	GetByTrainerID(ctx context.Context, trainerID primitive.ObjectID) ([]domain.WebhookEndpoint, error)
+	Update(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// GetSubscribed returns the active endpoints of any of the trainers subscribed to eventType.
+	GetSubscribed(ctx context.Context, trainerIDs []primitive.ObjectID, eventType domain.WebhookEventType) ([]domain.WebhookEndpoint, error)
+}
+
+// WebhookDeliveryRepository defines the interface for the webhook delivery log and retry queue.
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) (primitive.ObjectID, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error)
+	// ListByEndpoint returns up to limit deliveries older than beforeID (nil = newest), newest first.
+	ListByEndpoint(ctx context.Context, endpointID primitive.ObjectID, beforeID *primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error)
+	// ClaimDue takes the oldest pending delivery due by now and pushes its next attempt to
+	// leaseUntil, so no other worker picks it up meanwhile. ErrNotFound if none is due.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*domain.WebhookDelivery, error)
+	// RecordAttempt appends an attempt and sets the resulting status (nil nextAttemptAt when done).
+	RecordAttempt(ctx context.Context, id primitive.ObjectID, attempt domain.WebhookAttempt, status domain.WebhookDeliveryStatus, nextAttemptAt *time.Time) error
+	DeleteByEndpoint(ctx context.Context, endpointID primitive.ObjectID) (int64, error)
+}
+
+// ReminderSettingsRepository defines the interface for clients' workout reminder settings.
+type ReminderSettingsRepository interface {
+	Get(ctx context.Context, clientID primitive.ObjectID) (*domain.ReminderSettings, error) // ErrNotFound if never saved
+	Upsert(ctx context.Context, settings *domain.ReminderSettings) error                  // Leaves the sent dates alone
+	// MarkReminderSent / MarkNudgeSent record that the client's reminder (nudge) for a local
+	// date was handled. They return false if it already was, so each goes out once.
+	MarkReminderSent(ctx context.Context, clientID primitive.ObjectID, date string) (bool, error)
+	MarkNudgeSent(ctx context.Context, clientID primitive.ObjectID, date string) (bool, error)
+}
+
+// LeaseRepository defines the interface for named, expiring locks shared by server instances.
+type LeaseRepository interface {
+	// TryAcquire takes or renews the lease for holder. It returns false while another
+	// holder's lease has not expired.
+	TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+// DigestSettingsRepository defines the interface for trainers' weekly digest opt-ins.
+type DigestSettingsRepository interface {
+	Get(ctx context.Context, trainerID primitive.ObjectID) (*domain.DigestSettings, error) // ErrNotFound if never saved
+	Upsert(ctx context.Context, settings *domain.DigestSettings) error                    // Leaves LastSentWeek alone
+	GetEnabled(ctx context.Context) ([]domain.DigestSettings, error)
+	// MarkSent records that the digest for the week starting on weekStart (YYYY-MM-DD)
+	// went out. It returns false if it already had.
+	MarkSent(ctx context.Context, trainerID primitive.ObjectID, weekStart string) (bool, error)
+}
+
+// PlanDraftRepository defines the interface for edits staged on published plans.
+type PlanDraftRepository interface {
+	GetByPlanID(ctx context.Context, planID primitive.ObjectID) (*domain.PlanDraft, error) // ErrNotFound if nothing is staged
+	GetByWorkoutID(ctx context.Context, workoutID primitive.ObjectID) (*domain.PlanDraft, error) // The draft a workout was added in; ErrNotFound if none
+	GetByTrainerID(ctx context.Context, trainerID primitive.ObjectID) ([]domain.PlanDraft, error)
+	Stage(ctx context.Context, trainerID, planID primitive.ObjectID, edit domain.PlanDraftEdit) error // Creates the draft on the first edit
+	Delete(ctx context.Context, planID primitive.ObjectID) error // No error if there is no draft
 }
@@ -0,0 +1,119 @@
+// Package scheduler runs periodic background jobs on one server instance at a time.
+package scheduler
+
+import (
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLeaseTTL is how long a leader keeps the lease without renewing it. A crashed
+// leader is replaced after at most this long.
+const DefaultLeaseTTL = 30 * time.Second
+
+// Job is a periodic task. Run must be idempotent: after a leader change a job may run
+// again sooner than its interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type scheduledJob struct {
+	Job
+	nextRun time.Time
+	running atomic.Bool
+}
+
+// Scheduler runs its jobs only while it holds a shared lease, so with several server
+// instances exactly one of them (the leader) runs jobs. The others keep trying to take
+// the lease and step in when the leader stops renewing it.
+type Scheduler struct {
+	leases    repository.LeaseRepository
+	leaseName string
+	holder    string
+	leaseTTL  time.Duration
+	jobs      []*scheduledJob
+}
+
+// New creates a Scheduler competing for leaseName as holder, which must be unique per
+// server instance (e.g. hostname plus process ID).
+func New(leases repository.LeaseRepository, leaseName, holder string, leaseTTL time.Duration) *Scheduler {
+	if leaseTTL <= 0 {
+		leaseTTL = DefaultLeaseTTL
+	}
+	return &Scheduler{
+		leases:    leases,
+		leaseName: leaseName,
+		holder:    holder,
+		leaseTTL:  leaseTTL,
+	}
+}
+
+// Every registers a job. Jobs must be registered before Run.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, &scheduledJob{Job: Job{Name: name, Interval: interval, Run: run}})
+}
+
+// Run competes for leadership and runs due jobs until ctx is cancelled. On the way
+// out it waits for running jobs and releases the lease.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+
+	leader := false
+	for {
+		acquired, err := s.leases.TryAcquire(ctx, s.leaseName, s.holder, s.leaseTTL)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("WARN: Scheduler failed to renew lease %q: %v", s.leaseName, err)
+		}
+		if acquired != leader {
+			leader = acquired
+			if leader {
+				log.Printf("Scheduler: %s is now the leader for %q", s.holder, s.leaseName)
+			} else {
+				log.Printf("Scheduler: %s lost the lease %q", s.holder, s.leaseName)
+			}
+		}
+		if leader {
+			now := time.Now()
+			for _, job := range s.jobs {
+				if now.Before(job.nextRun) || !job.running.CompareAndSwap(false, true) {
+					continue
+				}
+				job.nextRun = now.Add(job.Interval)
+				wg.Add(1)
+				go func(job *scheduledJob) {
+					defer wg.Done()
+					defer job.running.Store(false)
+					if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+						log.Printf("WARN: Scheduled job %s failed: %v", job.Name, err)
+					}
+				}(job)
+			}
+		} else {
+			// Whoever leads next starts from a clean slate.
+			for _, job := range s.jobs {
+				job.nextRun = time.Time{}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			if leader {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := s.leases.Release(releaseCtx, s.leaseName, s.holder); err != nil {
+					log.Printf("WARN: Scheduler failed to release lease %q: %v", s.leaseName, err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
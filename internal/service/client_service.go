@@ -2,12 +2,14 @@ package service
 
 import (
 	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
 	"alcyxob/fitness-app/internal/repository"
 	"alcyxob/fitness-app/internal/storage" // Import storage package
 	"context"
 	"errors"
 	"fmt"
 	"path" // For constructing object keys
+	"sort"
 	"strings"
 	"time"
 
@@ -27,6 +29,19 @@ var (
 	ErrPlanNotAssignedToClient = errors.New("this training plan is not assigned to the client")
 	ErrWorkoutNotBelongToPlan = errors.New("this workout does not belong to the specified plan for this client")
 	ErrInvalidAssignmentStatusUpdate = errors.New("invalid status update for assignment")
+	ErrInvalidSetRound = errors.New("set round is outside the rounds planned for this exercise")
+	ErrUploadObjectKeyInvalid  = errors.New("object key does not belong to this assignment")
+	ErrUploadObjectNotFound    = errors.New("uploaded object not found in storage")
+	ErrUploadMetadataMismatch  = errors.New("uploaded object does not match the reported size or content type")
+	ErrUploadNotFound          = errors.New("upload not found for this assignment")
+	ErrUploadTooLarge          = errors.New("upload exceeds the maximum file size")
+	ErrTrainerStorageQuotaExceeded = errors.New("the trainer's video storage quota is used up")
+	ErrClientStorageQuotaExceeded  = errors.New("your video storage quota is used up")
+	ErrInvalidUploadContentType = errors.New("invalid or missing video content type")
+	ErrMultipartUploadNotFound = errors.New("multipart upload not found; it may have been completed or aborted")
+	ErrInvalidUploadPart       = errors.New("invalid part number")
+	ErrMultipartUploadIncomplete = errors.New("multipart upload is missing parts")
+	ErrUploadAlreadyConfirmed  = errors.New("this object has already been confirmed")
 )
 
 // --- Service Interface (Optional) ---
@@ -44,12 +59,46 @@ type AssignmentDetails struct {
 	VideoUploadURL *string          `json:"videoUploadUrl"` // Temporary URL to view the client's upload
 }
 
+// PinnedAssignment is one of the client's assignments with the exercise content it was
+// pinned to (nil if the exercise has been deleted since).
+type PinnedAssignment struct {
+	domain.Assignment
+	Exercise *domain.ExerciseRevision `json:"exercise"`
+}
+
+// PinnedWorkoutBlock is a block of one of the client's workouts with its pinned assignments.
+type PinnedWorkoutBlock struct {
+	domain.WorkoutBlock
+	Assignments []PinnedAssignment `json:"assignments"`
+}
+
+// MultipartUploadSession tells the client how to split a large file into parts.
+type MultipartUploadSession struct {
+	UploadID  string `json:"uploadId"`  // Storage provider's multipart upload ID
+	ObjectKey string `json:"objectKey"` // Key to report back on every multipart call
+	PartSize  int64  `json:"partSize"`  // Every part except the last must be exactly this size
+	PartCount int    `json:"partCount"`
+}
+
+// PresignedPart is a temporary URL for PUTting one part of a multipart upload.
+type PresignedPart struct {
+	PartNumber int    `json:"partNumber"`
+	UploadURL  string `json:"uploadUrl"`
+}
+
+// UploadDetails is one upload of an assignment with a short-lived download URL.
+type UploadDetails struct {
+	domain.Upload
+	IsPrimary   bool   `json:"isPrimary"` // Referenced by Assignment.UploadID
+	DownloadURL string `json:"downloadUrl"`
+}
+
 type ClientService interface {
 	// Assignment Viewing
 	GetMyAssignments(ctx context.Context, clientID primitive.ObjectID) ([]AssignmentDetails, error)
 
 	// Upload Process
-	RequestUploadURL(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string) (*UploadURLResponse, error)
+	RequestUploadURL(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string, fileSize int64) (*UploadURLResponse, error)
 	ConfirmUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, fileName string, fileSize int64, contentType string) (*domain.Assignment, error)
 
 	// Optional: Get download URL for client's own video
@@ -57,11 +106,33 @@ type ClientService interface {
 
 	GetMyActiveTrainingPlans(ctx context.Context, clientID primitive.ObjectID) ([]domain.TrainingPlan, error) // Could also be GetMyTrainingPlans
 	GetWorkoutsForMyPlan(ctx context.Context, clientID, planID primitive.ObjectID) ([]domain.Workout, error)
-	GetAssignmentsForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]domain.Assignment, error)
+	GetAssignmentsForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]PinnedAssignment, error)
 	UpdateMyAssignmentStatus(ctx context.Context, clientID, assignmentID primitive.ObjectID, newStatus domain.AssignmentStatus) (*domain.Assignment, error)
 	LogPerformanceForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID, performanceData domain.Assignment) (*domain.Assignment, error)
 	// --- NEW: Get Current Workout(s) for Client ---
 	GetMyCurrentWorkouts(ctx context.Context, clientID primitive.ObjectID, targetDate time.Time) ([]domain.Workout, error)
+
+	// Demonstration media for the exercise behind one of my assignments
+	GetExerciseMediaForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]ExerciseMediaDetails, error)
+	GetExerciseForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) (*domain.ExerciseRevision, error)
+
+	// --- Blocks & per-set logging ---
+	GetWorkoutStructureForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]PinnedWorkoutBlock, error)
+	LogSetForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID, setLog domain.SetLog) (*domain.Assignment, error)
+
+	// --- Upload history (retries, extra angles) ---
+	GetMyUploadsForAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]UploadDetails, error)
+	SetMyPrimaryUpload(ctx context.Context, clientID, assignmentID, uploadID primitive.ObjectID) (*domain.Assignment, error)
+	DeleteMyUpload(ctx context.Context, clientID, assignmentID, uploadID primitive.ObjectID) (*domain.Assignment, error)
+	// Trainer's video/voice replies to the assignment, with download URLs
+	GetMyFeedbackAttachments(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]FeedbackAttachmentDetails, error)
+
+	// --- Multipart / resumable upload process (large videos) ---
+	InitiateMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string, fileSize int64) (*MultipartUploadSession, error)
+	PresignMultipartParts(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string, partNumbers []int) ([]PresignedPart, error)
+	ListMultipartParts(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string) ([]storage.UploadedPart, error)
+	CompleteMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID, fileName string, fileSize int64, contentType string) (*domain.Assignment, error)
+	AbortMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string) error
 }
 
 // --- Service Implementation ---
@@ -74,7 +145,13 @@ type clientService struct {
 	exerciseRepo      repository.ExerciseRepository // Still needed to enrich assignments with exercise details
 	workoutRepo       repository.WorkoutRepository
 	trainingPlanRepo  repository.TrainingPlanRepository 
+	exerciseMediaRepo repository.ExerciseMediaRepository
+	exerciseRevisionRepo repository.ExerciseRevisionRepository
+	workoutBlockRepo  repository.WorkoutBlockRepository
+	feedbackCommentRepo repository.FeedbackCommentRepository
 	fileStorage       storage.FileStorage
+	uploadLimits      UploadLimits
+	events            realtime.Publisher
 }
 
 // NewClientService creates a new instance of clientService.
@@ -85,7 +162,13 @@ func NewClientService(
 	exerciseRepo repository.ExerciseRepository, // Added dependency
 	workoutRepo    repository.WorkoutRepository,
 	trainingPlanRepo repository.TrainingPlanRepository,
+	exerciseMediaRepo repository.ExerciseMediaRepository,
+	exerciseRevisionRepo repository.ExerciseRevisionRepository,
+	workoutBlockRepo repository.WorkoutBlockRepository,
+	feedbackCommentRepo repository.FeedbackCommentRepository,
 	fileStorage storage.FileStorage,
+	uploadLimits UploadLimits,
+	events realtime.Publisher,
 ) ClientService {
 	return &clientService{
 		userRepo:         userRepo,
@@ -94,7 +177,13 @@ func NewClientService(
 		exerciseRepo:   exerciseRepo,
 		workoutRepo:    workoutRepo,
 		trainingPlanRepo:  trainingPlanRepo,
+		exerciseMediaRepo: exerciseMediaRepo,
+		exerciseRevisionRepo: exerciseRevisionRepo,
+		workoutBlockRepo: workoutBlockRepo,
+		feedbackCommentRepo: feedbackCommentRepo,
 		fileStorage:    fileStorage,
+		uploadLimits:   uploadLimits,
+		events:         events,
 	}
 }
 
@@ -109,13 +198,15 @@ func (s *clientService) GetMyAssignments(ctx context.Context, clientID primitive
 // === Upload Process ===
 
 // RequestUploadURL generates a pre-signed URL for a client to upload a video for an assignment.
-func (s *clientService) RequestUploadURL(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string) (*UploadURLResponse, error) {
+// fileSize is optional (0 = unknown); when given it is checked against the maximum file
+// size and the remaining quotas up front, otherwise only exhausted quotas are rejected.
+func (s *clientService) RequestUploadURL(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string, fileSize int64) (*UploadURLResponse, error) {
 	// 1. Validate Inputs
 	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
 		return nil, errors.New("client ID and assignment ID are required")
 	}
 	if contentType == "" || !strings.HasPrefix(strings.ToLower(contentType), "video/") {
-		return nil, errors.New("invalid or missing video content type")
+		return nil, ErrInvalidUploadContentType
 	}
 
 	// 2. Get the assignment
@@ -141,23 +232,32 @@ func (s *clientService) RequestUploadURL(ctx context.Context, clientID, assignme
     if workout.ClientID != clientID {
         return nil, ErrAssignmentNotBelongToClient
     }
+    if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+        return nil, err
+    }
     // --- END CORRECTED AUTHORIZATION CHECK ---
 
 
 	// 3. Check if upload is allowed based on status
+	// Submitted is allowed too: clients may add extra angles or retries to a submission.
 	if assignment.Status != domain.StatusAssigned && 
+		 assignment.Status != domain.StatusSubmitted &&
 		 assignment.Status != domain.StatusReviewed &&
 		 assignment.Status != domain.StatusCompleted {
 		return nil, ErrUploadNotAllowed
 	}
 
+	// 3b. Enforce the file size limit and storage quotas before handing out a URL
+	if fileSize < 0 {
+		return nil, errors.New("file size cannot be negative")
+	}
+	if err := s.checkUploadLimits(ctx, clientID, workout.TrainerID, contentType, fileSize); err != nil {
+		return nil, err
+	}
+
 	// ... (rest of the function: generate key, generate URL) ...
 	// 4. Generate a unique object key for S3
-	uniqueID := uuid.NewString()
-	fileExtension := ""
-	parts := strings.Split(contentType, "/")
-	if len(parts) == 2 { fileExtension = parts[1] }
-	objectKey := path.Join("uploads", clientID.Hex(), assignmentID.Hex(), fmt.Sprintf("%s.%s", uniqueID, fileExtension))
+	objectKey := newUploadObjectKey(clientID, assignmentID, contentType)
 
 	// 5. Generate the pre-signed URL
 	uploadURL, err := s.fileStorage.GeneratePresignedUploadURL(ctx, objectKey, contentType, storage.DefaultPresignedURLExpiry)
@@ -203,12 +303,31 @@ func (s *clientService) ConfirmUpload(ctx context.Context, clientID, assignmentI
     if workout.ClientID != clientID {
         return nil, ErrAssignmentNotBelongToClient
     }
+    if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+        return nil, err
+    }
     // --- END CORRECTED AUTHORIZATION CHECK ---
 
 
 	// Check status? Only allow confirm if 'assigned' or 'reviewed'?
 	// if assignment.Status != domain.StatusAssigned && assignment.Status != domain.StatusReviewed { ... }
 
+	// 3. Verify the object was really uploaded, to a key issued for this client and assignment,
+	// and isn't recorded yet (a second record would count it twice and outlive its deletion)
+	if err := s.ensureObjectKeyUnrecorded(ctx, objectKey); err != nil {
+		return nil, err
+	}
+	if err := s.verifyUploadedObject(ctx, clientID, assignmentID, objectKey, fileSize, contentType); err != nil {
+		return nil, err
+	}
+	// Quotas may have filled up since the URL was issued; don't keep an object we won't record.
+	if err := s.checkUploadLimits(ctx, clientID, workout.TrainerID, contentType, fileSize); err != nil {
+		if errors.Is(err, ErrTrainerStorageQuotaExceeded) || errors.Is(err, ErrClientStorageQuotaExceeded) {
+			_ = s.fileStorage.DeleteObject(ctx, objectKey)
+		}
+		return nil, err
+	}
+
 
 	// --- CORRECTED Upload metadata object Creation ---
 	// Get TrainerID from the fetched workout, not the (now non-existent) field on assignment
@@ -224,16 +343,31 @@ func (s *clientService) ConfirmUpload(ctx context.Context, clientID, assignmentI
 	}
     // --- END CORRECTION ---
 
+	// Earlier uploads are kept as history; number this one after them.
+	previousUploads, err := s.uploadRepo.CountByAssignmentID(ctx, assignmentID)
+	if err != nil {
+		return nil, ErrUploadConfirmationFailed
+	}
+	upload.Attempt = int(previousUploads) + 1
+
 
 	// 4. Save Upload metadata
 	uploadID, err := s.uploadRepo.Create(ctx, upload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrUploadAlreadyConfirmed // Lost a race with a concurrent confirm of the same key
+		}
 		// log.Printf("Error saving upload metadata: %v", err)
 		return nil, ErrUploadConfirmationFailed
 	}
 
-	// 5. Update the Assignment: set UploadID and change Status
+	// 5. Update the Assignment: the newest upload becomes primary, and change Status
 	assignment.UploadID = &uploadID
+	if assignment.Status != domain.StatusSubmitted || assignment.SubmittedAt == nil {
+		// Extra uploads to a pending submission keep its place in the trainer's review queue.
+		now := time.Now().UTC()
+		assignment.SubmittedAt = &now
+	}
 	assignment.Status = domain.StatusSubmitted // Set status to submitted
 
 	err = s.assignmentRepo.Update(ctx, assignment)
@@ -248,10 +382,143 @@ func (s *clientService) ConfirmUpload(ctx context.Context, clientID, assignmentI
 		return nil, ErrUploadConfirmationFailed
 	}
 
+	data := assignmentEventData(assignment)
+	data["uploadId"] = uploadID
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventUpload, clientID, "A client submitted a video for review", data), workout.TrainerID)
+
 	// 6. Return the updated assignment
 	return assignment, nil
 }
 
+// verifyUploadedObject checks that objectKey lies under the uploads/<client>/<assignment>/
+// prefix handed out by RequestUploadURL and that storage holds an object of the
+// reported size and content type.
+func (s *clientService) verifyUploadedObject(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey string, fileSize int64, contentType string) error {
+	if !isUploadKeyFor(clientID, assignmentID, objectKey) {
+		return ErrUploadObjectKeyInvalid
+	}
+
+	metadata, err := s.fileStorage.GetObjectMetadata(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return ErrUploadObjectNotFound
+		}
+		return fmt.Errorf("%w: could not read object metadata", ErrUploadConfirmationFailed)
+	}
+
+	if metadata.Size != fileSize || !sameMediaType(metadata.ContentType, contentType) {
+		return fmt.Errorf("%w: stored %d bytes of %q", ErrUploadMetadataMismatch, metadata.Size, metadata.ContentType)
+	}
+	if limit := s.maxFileSize(metadata.ContentType); metadata.Size > limit {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrUploadTooLarge, metadata.Size, limit)
+	}
+	return nil
+}
+
+// newUploadObjectKey generates a unique key under the uploads/<client>/<assignment>/ prefix.
+func newUploadObjectKey(clientID, assignmentID primitive.ObjectID, contentType string) string {
+	uniqueID := uuid.NewString()
+	fileExtension := ""
+	parts := strings.Split(contentType, "/")
+	if len(parts) == 2 { fileExtension = parts[1] }
+	return path.Join("uploads", clientID.Hex(), assignmentID.Hex(), fmt.Sprintf("%s.%s", uniqueID, fileExtension))
+}
+
+// isUploadKeyFor reports whether objectKey lies under the client's prefix for the assignment.
+func isUploadKeyFor(clientID, assignmentID primitive.ObjectID, objectKey string) bool {
+	prefix := path.Join("uploads", clientID.Hex(), assignmentID.Hex()) + "/"
+	// path.Clean rejects keys such as "uploads/<client>/<assignment>/../<other>/x.mp4"
+	return path.Clean(objectKey) == objectKey && strings.HasPrefix(objectKey, prefix) && len(objectKey) > len(prefix)
+}
+
+// uploadSizeLimits caps client video uploads by content type. Phone cameras record
+// MP4 or QuickTime; other video types fall back to defaultMaxUploadSize.
+var uploadSizeLimits = map[string]int64{
+	"video/mp4":       4 << 30, // 4 GiB
+	"video/quicktime": 4 << 30,
+	"video/webm":      2 << 30,
+}
+
+const defaultMaxUploadSize int64 = 1 << 30 // 1 GiB
+
+// maxUploadSize returns the largest upload accepted for a content type.
+func maxUploadSize(contentType string) int64 {
+	base := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	if limit, ok := uploadSizeLimits[base]; ok {
+		return limit
+	}
+	return defaultMaxUploadSize
+}
+
+// UploadLimits caps client uploads. Zero values mean "no limit".
+type UploadLimits struct {
+	MaxFileSize  int64 // Per file, on top of the per-content-type limits
+	TrainerQuota int64 // Total bytes across all of a trainer's clients
+	ClientQuota  int64 // Total bytes per client
+}
+
+// maxFileSize is the smaller of the content type's limit and the configured maximum.
+func (s *clientService) maxFileSize(contentType string) int64 {
+	limit := maxUploadSize(contentType)
+	if s.uploadLimits.MaxFileSize > 0 && s.uploadLimits.MaxFileSize < limit {
+		limit = s.uploadLimits.MaxFileSize
+	}
+	return limit
+}
+
+// checkUploadLimits rejects an upload of fileSize bytes (0 = unknown) that would exceed
+// the maximum file size or the client's or trainer's storage quota.
+func (s *clientService) checkUploadLimits(ctx context.Context, clientID, trainerID primitive.ObjectID, contentType string, fileSize int64) error {
+	if limit := s.maxFileSize(contentType); fileSize > limit {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrUploadTooLarge, fileSize, limit)
+	}
+
+	if quota := s.uploadLimits.ClientQuota; quota > 0 {
+		usage, err := s.uploadRepo.GetUsageByClient(ctx, clientID)
+		if err != nil {
+			return errors.New("failed to check storage usage")
+		}
+		if usage.TotalBytes >= quota || usage.TotalBytes+fileSize > quota {
+			return fmt.Errorf("%w: %d of %d bytes used", ErrClientStorageQuotaExceeded, usage.TotalBytes, quota)
+		}
+	}
+
+	if quota := s.uploadLimits.TrainerQuota; quota > 0 {
+		used, _, err := trainerStorageUsage(ctx, s.uploadRepo, trainerID)
+		if err != nil {
+			return errors.New("failed to check storage usage")
+		}
+		if used >= quota || used+fileSize > quota {
+			return fmt.Errorf("%w: %d of %d bytes used", ErrTrainerStorageQuotaExceeded, used, quota)
+		}
+	}
+	return nil
+}
+
+// trainerStorageUsage totals the uploads of all of a trainer's clients.
+func trainerStorageUsage(ctx context.Context, uploadRepo repository.UploadRepository, trainerID primitive.ObjectID) (int64, []repository.UploadUsage, error) {
+	perClient, err := uploadRepo.GetUsageByTrainer(ctx, trainerID)
+	if err != nil {
+		return 0, nil, err
+	}
+	var total int64
+	for _, u := range perClient {
+		total += u.TotalBytes
+	}
+	return total, perClient, nil
+}
+
+// sameMediaType compares two Content-Type values ignoring case and parameters (e.g. "; codecs=...").
+func sameMediaType(a, b string) bool {
+	base := func(v string) string {
+		if i := strings.Index(v, ";"); i >= 0 {
+			v = v[:i]
+		}
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+	return base(a) == base(b)
+}
+
 // GetMyVideoDownloadURL generates a temporary URL for the client to view their own uploaded video.
 func (s *clientService) GetMyVideoDownloadURL(ctx context.Context, clientID, assignmentID primitive.ObjectID) (string, error) {
 	// 1. Get assignment & verify ownership
@@ -280,6 +547,9 @@ func (s *clientService) GetMyVideoDownloadURL(ctx context.Context, clientID, ass
 		// The user making the request is not the client this workout (and thus assignment) belongs to
 				return "", ErrAssignmentNotBelongToClient // Keep this error or use a generic auth error
 		}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return "", err
+	}
 
 	// 4. Check if an upload exists for this assignment
 	if assignment.UploadID == nil || *assignment.UploadID == primitive.NilObjectID {
@@ -338,7 +608,14 @@ func (s *clientService) GetMyActiveTrainingPlans(ctx context.Context, clientID p
 	//     }
 	// }
 	// return activePlans, nil
-	return plans, nil // Returning all plans for now, client can see active flag
+	// Plans the trainer has not published yet are not the client's to see.
+	published := make([]domain.TrainingPlan, 0, len(plans))
+	for _, p := range plans {
+		if p.IsPublished() {
+			published = append(published, p)
+		}
+	}
+	return published, nil // Returning all published plans for now, client can see active flag
 }
 
 // GetWorkoutsForMyPlan fetches workouts for a specific plan IF that plan belongs to the client.
@@ -358,6 +635,9 @@ func (s *clientService) GetWorkoutsForMyPlan(ctx context.Context, clientID, plan
 	if plan.ClientID != clientID {
 			return nil, ErrPlanNotAssignedToClient // Security check
 	}
+	if !plan.IsPublished() {
+			return nil, ErrTrainingPlanNotFound // Not published to the client yet
+	}
 
 	// 2. Fetch workouts for this validated plan
 	workouts, err := s.workoutRepo.GetByPlanID(ctx, planID)
@@ -368,8 +648,9 @@ func (s *clientService) GetWorkoutsForMyPlan(ctx context.Context, clientID, plan
 	return workouts, nil
 }
 
-// GetAssignmentsForMyWorkout fetches assignments for a workout IF it belongs to client's plan.
-func (s *clientService) GetAssignmentsForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]domain.Assignment, error) {
+// GetAssignmentsForMyWorkout fetches assignments for a workout IF it belongs to client's plan,
+// each with the exercise revision it is pinned to.
+func (s *clientService) GetAssignmentsForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]PinnedAssignment, error) {
 	if clientID == primitive.NilObjectID || workoutID == primitive.NilObjectID {
 			return nil, errors.New("client ID and workout ID are required")
 	}
@@ -385,6 +666,9 @@ func (s *clientService) GetAssignmentsForMyWorkout(ctx context.Context, clientID
 	if workout.ClientID != clientID { // Check via the denormalized ClientID on Workout
 			return nil, ErrWorkoutNotBelongToPlan // Or a more generic auth error
 	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+			return nil, err
+	}
 
 	// 2. Fetch assignments for this validated workout
 	assignments, err := s.assignmentRepo.GetByWorkoutID(ctx, workoutID)
@@ -392,7 +676,7 @@ func (s *clientService) GetAssignmentsForMyWorkout(ctx context.Context, clientID
 			// log.Printf("Error fetching assignments for client's workout %s: %v", workoutID.Hex(), err)
 			return nil, errors.New("failed to retrieve assignments for the workout")
 	}
-	return assignments, nil
+	return s.pinAssignments(ctx, assignments, newExerciseRevisionCache())
 }
 
 func (s *clientService) UpdateMyAssignmentStatus(ctx context.Context, clientID, assignmentID primitive.ObjectID, newStatus domain.AssignmentStatus) (*domain.Assignment, error) {
@@ -429,6 +713,9 @@ func (s *clientService) UpdateMyAssignmentStatus(ctx context.Context, clientID,
 	if workout.ClientID != clientID {
 			return nil, ErrAssignmentNotBelongToClient
 	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, err
+	}
 
 	// 3. Update status and save
 	// Optional: Add logic here to prevent updating status if already "reviewed" by trainer, etc.
@@ -442,6 +729,7 @@ func (s *clientService) UpdateMyAssignmentStatus(ctx context.Context, clientID,
 			// log.Printf("Error updating assignment status for %s: %v", assignmentID.Hex(), err)
 			return nil, errors.New("failed to update assignment status")
 	}
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventAssignmentStatus, clientID, fmt.Sprintf("A client marked an exercise %s", assignment.Status), assignmentEventData(assignment)), workout.TrainerID)
 
 	// Return the updated assignment (Update might not return the full object, so GetByID again if needed)
 	// The current s.assignmentRepo.Update doesn't return the updated object.
@@ -475,6 +763,9 @@ func (s *clientService) LogPerformanceForMyAssignment(ctx context.Context, clien
 	if workout.ClientID != clientID {
 			return nil, ErrAssignmentNotBelongToClient
 	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, err
+	}
 
 	// 3. Update only the performance-related fields on the fetched assignment object
 	// The 'performanceData' struct only carries the fields being updated.
@@ -535,7 +826,7 @@ func (s *clientService) GetMyCurrentWorkouts(ctx context.Context, clientID primi
 	var activePlan *domain.TrainingPlan
 	for i := range allPlans {
 			plan := allPlans[i] // Avoid G601: Implicit memory aliasing in for loop.
-			if plan.IsActive {
+			if plan.IsActive && plan.IsPublished() {
 					// Check if targetDate falls within this plan's StartDate and EndDate (if they exist)
 					if plan.StartDate != nil && targetDate.Before(*plan.StartDate) {
 							continue // Plan hasn't started yet
@@ -605,4 +896,619 @@ func (s *clientService) GetMyCurrentWorkouts(ctx context.Context, clientID primi
 	// })
 
 	return currentWorkouts, nil
-}
\ No newline at end of file
+}
+
+// GetExerciseMediaForMyAssignment returns the trainer's demonstration media for the exercise
+// behind one of the client's assignments, each with a short-lived download URL.
+func (s *clientService) GetExerciseMediaForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]ExerciseMediaDetails, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+
+	// 1. Get the assignment
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, err
+	}
+
+	// 2. Authorization: Verify assignment belongs to this client (via workout)
+	workout, err := s.workoutRepo.GetByID(ctx, assignment.WorkoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutNotFound
+		}
+		return nil, errors.New("failed to verify workout for assignment")
+	}
+	if workout.ClientID != clientID {
+		return nil, ErrAssignmentNotBelongToClient
+	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, err
+	}
+
+	// 3. Fetch media and sign download URLs
+	media, err := s.exerciseMediaRepo.GetByExerciseID(ctx, assignment.ExerciseID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve exercise media")
+	}
+	return presignExerciseMedia(ctx, s.fileStorage, media)
+}
+
+// GetExerciseForMyAssignment returns the exercise instructions exactly as they were when the
+// assignment was created (its pinned revision), not the exercise's latest edit.
+func (s *clientService) GetExerciseForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) (*domain.ExerciseRevision, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+
+	// 1. Get the assignment
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, err
+	}
+
+	// 2. Authorization: Verify assignment belongs to this client (via workout)
+	workout, err := s.workoutRepo.GetByID(ctx, assignment.WorkoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutNotFound
+		}
+		return nil, errors.New("failed to verify workout for assignment")
+	}
+	if workout.ClientID != clientID {
+		return nil, ErrAssignmentNotBelongToClient
+	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, err
+	}
+
+	// 3. Resolve the pinned revision
+	return s.pinnedExercise(ctx, assignment)
+}
+
+// pinnedExercise returns the exercise content an assignment is pinned to. Clients always
+// read exercise content through it, never from the live exercise.
+func (s *clientService) pinnedExercise(ctx context.Context, assignment *domain.Assignment) (*domain.ExerciseRevision, error) {
+	if assignment.ExerciseRevision > 0 {
+		rev, err := s.exerciseRevisionRepo.GetByExerciseAndRevision(ctx, assignment.RevisionSource(), assignment.ExerciseRevision)
+		if err == nil {
+			return rev, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	// Unpinned (legacy) assignment: the exercise hasn't been edited since, so its current content applies.
+	exercise, err := s.exerciseRepo.GetByID(ctx, assignment.ExerciseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExerciseNotFound
+		}
+		return nil, err
+	}
+	revision := exercise.CurrentRevision
+	if revision == 0 {
+		revision = 1
+	}
+	rev := domain.NewExerciseRevision(exercise, revision)
+	rev.CreatedAt = exercise.UpdatedAt
+	return rev, nil
+}
+
+// exerciseRevisionKey identifies one pinned exercise revision; 0 means unpinned.
+type exerciseRevisionKey struct {
+	exerciseID primitive.ObjectID
+	revision   int
+}
+
+// exerciseRevisionCache keeps the revisions resolved while building one response, as a
+// workout usually pins the same exercise more than once.
+type exerciseRevisionCache map[exerciseRevisionKey]*domain.ExerciseRevision
+
+func newExerciseRevisionCache() exerciseRevisionCache {
+	return make(exerciseRevisionCache)
+}
+
+// pinAssignments attaches the pinned exercise content to each assignment. Assignments of
+// deleted exercises are returned without one.
+func (s *clientService) pinAssignments(ctx context.Context, assignments []domain.Assignment, cache exerciseRevisionCache) ([]PinnedAssignment, error) {
+	pinned := make([]PinnedAssignment, len(assignments))
+	for i := range assignments {
+		a := &assignments[i]
+		key := exerciseRevisionKey{exerciseID: a.RevisionSource(), revision: a.ExerciseRevision}
+		rev, ok := cache[key]
+		if !ok {
+			var err error
+			rev, err = s.pinnedExercise(ctx, a)
+			if err != nil && !errors.Is(err, ErrExerciseNotFound) {
+				return nil, errors.New("failed to retrieve exercises for the assignments")
+			}
+			cache[key] = rev
+		}
+		pinned[i] = PinnedAssignment{Assignment: *a, Exercise: rev}
+	}
+	return pinned, nil
+}
+
+// GetWorkoutStructureForMyWorkout returns one of the client's workouts as ordered blocks
+// (supersets, circuits, ...) with their assignments nested inside, each with the exercise
+// revision it is pinned to.
+func (s *clientService) GetWorkoutStructureForMyWorkout(ctx context.Context, clientID, workoutID primitive.ObjectID) ([]PinnedWorkoutBlock, error) {
+	if clientID == primitive.NilObjectID || workoutID == primitive.NilObjectID {
+		return nil, errors.New("client ID and workout ID are required")
+	}
+
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutNotFound
+		}
+		return nil, err
+	}
+	if workout.ClientID != clientID {
+		return nil, ErrWorkoutNotBelongToPlan
+	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, err
+	}
+
+	blocks, err := loadWorkoutStructure(ctx, s.workoutBlockRepo, s.assignmentRepo, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	cache := newExerciseRevisionCache()
+	pinned := make([]PinnedWorkoutBlock, len(blocks))
+	for i, b := range blocks {
+		assignments, err := s.pinAssignments(ctx, b.Assignments, cache)
+		if err != nil {
+			return nil, err
+		}
+		pinned[i] = PinnedWorkoutBlock{WorkoutBlock: b.WorkoutBlock, Assignments: assignments}
+	}
+	return pinned, nil
+}
+
+// LogSetForMyAssignment records the result of one round (or set) of an assignment.
+// The round must fall within the rounds of the assignment's block (or its sets, for
+// ungrouped/straight-set exercises); AMRAP blocks accept any positive round.
+// Logging the same round again replaces the earlier entry.
+func (s *clientService) LogSetForMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID, setLog domain.SetLog) (*domain.Assignment, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+	if setLog.Round < 1 {
+		return nil, ErrInvalidSetRound
+	}
+
+	// 1-2. Get the assignment and verify it belongs to this client's published plan
+	assignment, _, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Validate the round against the block structure
+	var block *domain.WorkoutBlock
+	if assignment.BlockID != nil {
+		block, err = s.workoutBlockRepo.GetByID(ctx, *assignment.BlockID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		// A block deleted in the meantime leaves the assignment ungrouped.
+	}
+	if maxRounds := block.MaxRounds(assignment); maxRounds > 0 && setLog.Round > maxRounds {
+		return nil, ErrInvalidSetRound
+	}
+
+	// 4. Upsert the log for this round, keeping logs ordered by round
+	setLog.LoggedAt = time.Now().UTC()
+	replaced := false
+	for i := range assignment.SetLogs {
+		if assignment.SetLogs[i].Round == setLog.Round {
+			assignment.SetLogs[i] = setLog
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		assignment.SetLogs = append(assignment.SetLogs, setLog)
+		sort.Slice(assignment.SetLogs, func(i, j int) bool { return assignment.SetLogs[i].Round < assignment.SetLogs[j].Round })
+	}
+
+	// Keep the aggregate field in step with the detailed log.
+	loggedSets := len(assignment.SetLogs)
+	assignment.AchievedSets = &loggedSets
+	if maxRounds := block.MaxRounds(assignment); maxRounds > 0 && loggedSets >= maxRounds && assignment.Status == domain.StatusAssigned {
+		assignment.Status = domain.StatusCompleted
+	}
+	assignment.UpdatedAt = time.Now().UTC()
+
+	if err := s.assignmentRepo.Update(ctx, assignment); err != nil {
+		return nil, errors.New("failed to log set")
+	}
+	return assignment, nil
+}
+
+// requirePublishedPlan returns ErrWorkoutNotFound unless the plan has been published
+// to the client; the trainer may still be building it.
+func (s *clientService) requirePublishedPlan(ctx context.Context, planID primitive.ObjectID) error {
+	plan, err := s.trainingPlanRepo.GetByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrWorkoutNotFound
+		}
+		return err
+	}
+	if !plan.IsPublished() {
+		return ErrWorkoutNotFound
+	}
+	return nil
+}
+
+// getMyAssignment loads an assignment and verifies (via its workout) that it belongs to
+// the client and that its plan has been published. The workout is returned too; callers
+// may need its TrainerID.
+func (s *clientService) getMyAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) (*domain.Assignment, *domain.Workout, error) {
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrAssignmentNotFound
+		}
+		return nil, nil, err
+	}
+
+	workout, err := s.workoutRepo.GetByID(ctx, assignment.WorkoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrWorkoutNotFound
+		}
+		return nil, nil, errors.New("failed to verify workout for assignment")
+	}
+	if workout.ClientID != clientID {
+		return nil, nil, ErrAssignmentNotBelongToClient
+	}
+	if err := s.requirePublishedPlan(ctx, workout.TrainingPlanID); err != nil {
+		return nil, nil, err
+	}
+	return assignment, workout, nil
+}
+
+// getUploadOfAssignment loads an upload and checks that it was made for the given assignment.
+func (s *clientService) getUploadOfAssignment(ctx context.Context, assignmentID, uploadID primitive.ObjectID) (*domain.Upload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, uploadID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if upload.AssignmentID != assignmentID {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// presignUploads lists an assignment's uploads, oldest first, marking the primary one
+// and attaching short-lived download URLs.
+func presignUploads(ctx context.Context, uploadRepo repository.UploadRepository, fileStorage storage.FileStorage, assignment *domain.Assignment) ([]UploadDetails, error) {
+	uploads, err := uploadRepo.GetByAssignmentID(ctx, assignment.ID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve uploads")
+	}
+
+	details := make([]UploadDetails, 0, len(uploads))
+	for _, u := range uploads {
+		downloadURL, err := fileStorage.GeneratePresignedDownloadURL(ctx, u.S3ObjectKey, storage.DefaultPresignedURLExpiry)
+		if err != nil {
+			return nil, ErrDownloadURLError
+		}
+		details = append(details, UploadDetails{
+			Upload:      u,
+			IsPrimary:   assignment.UploadID != nil && *assignment.UploadID == u.ID,
+			DownloadURL: downloadURL,
+		})
+	}
+	return details, nil
+}
+
+// GetMyUploadsForAssignment lists every upload the client made for an assignment.
+func (s *clientService) GetMyUploadsForAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]UploadDetails, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+
+	assignment, _, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return presignUploads(ctx, s.uploadRepo, s.fileStorage, assignment)
+}
+
+// SetMyPrimaryUpload marks one of the assignment's uploads as the one the trainer reviews.
+func (s *clientService) SetMyPrimaryUpload(ctx context.Context, clientID, assignmentID, uploadID primitive.ObjectID) (*domain.Assignment, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || uploadID == primitive.NilObjectID {
+		return nil, errors.New("client ID, assignment ID and upload ID are required")
+	}
+
+	assignment, _, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getUploadOfAssignment(ctx, assignmentID, uploadID); err != nil {
+		return nil, err
+	}
+
+	assignment.UploadID = &uploadID
+	if err := s.assignmentRepo.Update(ctx, assignment); err != nil {
+		return nil, errors.New("failed to set primary upload")
+	}
+	return assignment, nil
+}
+
+// DeleteMyUpload removes one upload (storage object, metadata and the trainer's comments on it). If it was the primary
+// upload, the newest remaining upload takes its place; if none remain, a submitted
+// assignment goes back to assigned.
+func (s *clientService) DeleteMyUpload(ctx context.Context, clientID, assignmentID, uploadID primitive.ObjectID) (*domain.Assignment, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || uploadID == primitive.NilObjectID {
+		return nil, errors.New("client ID, assignment ID and upload ID are required")
+	}
+
+	assignment, _, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := s.getUploadOfAssignment(ctx, assignmentID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Remove the object first so a failure never leaves it without metadata
+	if err := s.fileStorage.DeleteObject(ctx, upload.S3ObjectKey); err != nil {
+		return nil, errors.New("failed to delete upload from storage")
+	}
+	if err := s.uploadRepo.Delete(ctx, uploadID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.New("failed to delete upload metadata")
+	}
+	if _, err := s.feedbackCommentRepo.DeleteByUploadID(ctx, uploadID); err != nil {
+		return nil, errors.New("failed to delete feedback comments of upload")
+	}
+
+	// 2. Re-point the primary upload if we just removed it
+	if assignment.UploadID == nil || *assignment.UploadID != uploadID {
+		return assignment, nil
+	}
+	remaining, err := s.uploadRepo.GetByAssignmentID(ctx, assignmentID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve remaining uploads")
+	}
+	if len(remaining) > 0 {
+		newest := remaining[len(remaining)-1].ID
+		assignment.UploadID = &newest
+	} else {
+		assignment.UploadID = nil
+		if assignment.Status == domain.StatusSubmitted {
+			assignment.Status = domain.StatusAssigned
+			assignment.SubmittedAt = nil
+		}
+	}
+	if err := s.assignmentRepo.Update(ctx, assignment); err != nil {
+		return nil, errors.New("failed to update assignment after deleting upload")
+	}
+	return assignment, nil
+}
+
+// GetMyFeedbackAttachments lists the trainer's video and voice replies to one of the client's assignments.
+func (s *clientService) GetMyFeedbackAttachments(ctx context.Context, clientID, assignmentID primitive.ObjectID) ([]FeedbackAttachmentDetails, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+	assignment, _, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return presignFeedbackAttachments(ctx, s.fileStorage, assignment.FeedbackAttachments)
+}
+
+// === Multipart / Resumable Upload Process ===
+
+const (
+	// Preferred part size; grows for very large files so they stay within MaxMultipartParts.
+	defaultMultipartPartSize int64 = 8 << 20 // 8 MiB
+	// Part URLs outlive single-PUT URLs so a slow connection can finish a part.
+	multipartPartURLExpiry = time.Hour
+	// Cap on part URLs per presign call; clients request more as they go.
+	maxPresignedPartsPerRequest = 100
+)
+
+// multipartPartSize picks a part size (a whole number of MiB) that fits fileSize in
+// at most storage.MaxMultipartParts parts.
+func multipartPartSize(fileSize int64) int64 {
+	partSize := defaultMultipartPartSize
+	if minSize := (fileSize + storage.MaxMultipartParts - 1) / storage.MaxMultipartParts; minSize > partSize {
+		partSize = (minSize + (1 << 20) - 1) / (1 << 20) * (1 << 20)
+	}
+	return partSize
+}
+
+// getMyUploadableAssignment is getMyAssignment plus the status check RequestUploadURL applies.
+func (s *clientService) getMyUploadableAssignment(ctx context.Context, clientID, assignmentID primitive.ObjectID) (*domain.Assignment, *domain.Workout, error) {
+	assignment, workout, err := s.getMyAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if assignment.Status != domain.StatusAssigned &&
+		assignment.Status != domain.StatusSubmitted &&
+		assignment.Status != domain.StatusReviewed &&
+		assignment.Status != domain.StatusCompleted {
+		return nil, nil, ErrUploadNotAllowed
+	}
+	return assignment, workout, nil
+}
+
+// InitiateMultipartUpload starts a multipart upload for a large video. The size is
+// checked against the file size limit and storage quotas before anything is stored.
+func (s *clientService) InitiateMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, contentType string, fileSize int64) (*MultipartUploadSession, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("client ID and assignment ID are required")
+	}
+	if contentType == "" || !strings.HasPrefix(strings.ToLower(contentType), "video/") {
+		return nil, ErrInvalidUploadContentType
+	}
+	if fileSize <= 0 {
+		return nil, errors.New("file size must be positive")
+	}
+	_, workout, err := s.getMyUploadableAssignment(ctx, clientID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUploadLimits(ctx, clientID, workout.TrainerID, contentType, fileSize); err != nil {
+		return nil, err
+	}
+
+	objectKey := newUploadObjectKey(clientID, assignmentID, contentType)
+	uploadID, err := s.fileStorage.CreateMultipartUpload(ctx, objectKey, contentType)
+	if err != nil {
+		return nil, ErrUploadURLError
+	}
+
+	partSize := multipartPartSize(fileSize)
+	session := &MultipartUploadSession{
+		UploadID:  uploadID,
+		ObjectKey: objectKey,
+		PartSize:  partSize,
+		PartCount: int((fileSize + partSize - 1) / partSize),
+	}
+	return session, nil
+}
+
+// PresignMultipartParts issues upload URLs for the requested part numbers. Clients
+// resuming an upload ask only for the parts ListMultipartParts doesn't report.
+func (s *clientService) PresignMultipartParts(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string, partNumbers []int) ([]PresignedPart, error) {
+	if len(partNumbers) == 0 || len(partNumbers) > maxPresignedPartsPerRequest {
+		return nil, fmt.Errorf("%w: request between 1 and %d parts at a time", ErrInvalidUploadPart, maxPresignedPartsPerRequest)
+	}
+	for _, n := range partNumbers {
+		if n < 1 || n > storage.MaxMultipartParts {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidUploadPart, n)
+		}
+	}
+	if _, err := s.getMyMultipartUpload(ctx, clientID, assignmentID, objectKey, uploadID); err != nil {
+		return nil, err
+	}
+
+	parts := make([]PresignedPart, len(partNumbers))
+	for i, n := range partNumbers {
+		url, err := s.fileStorage.GeneratePresignedUploadPartURL(ctx, objectKey, uploadID, int32(n), multipartPartURLExpiry)
+		if err != nil {
+			return nil, ErrUploadURLError
+		}
+		parts[i] = PresignedPart{PartNumber: n, UploadURL: url}
+	}
+	return parts, nil
+}
+
+// ListMultipartParts returns the parts storage has received, so an interrupted upload
+// can resume with the missing ones.
+func (s *clientService) ListMultipartParts(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string) ([]storage.UploadedPart, error) {
+	return s.getMyMultipartUpload(ctx, clientID, assignmentID, objectKey, uploadID)
+}
+
+// CompleteMultipartUpload assembles the uploaded parts and then confirms the upload
+// exactly like the single-PUT flow (ConfirmUpload).
+func (s *clientService) CompleteMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID, fileName string, fileSize int64, contentType string) (*domain.Assignment, error) {
+	if limit := s.maxFileSize(contentType); fileSize > limit {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrUploadTooLarge, fileSize, limit)
+	}
+
+	parts, err := s.getMyMultipartUpload(ctx, clientID, assignmentID, objectKey, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	// Completing would overwrite the object an existing upload already points at.
+	if err := s.ensureObjectKeyUnrecorded(ctx, objectKey); err != nil {
+		return nil, err
+	}
+
+	// Parts must run 1..N without gaps and add up to the reported size.
+	var total int64
+	for i, p := range parts {
+		if int(p.PartNumber) != i+1 {
+			return nil, fmt.Errorf("%w: part %d not uploaded", ErrMultipartUploadIncomplete, i+1)
+		}
+		total += p.Size
+	}
+	if len(parts) == 0 || total != fileSize {
+		return nil, fmt.Errorf("%w: received %d of %d bytes", ErrMultipartUploadIncomplete, total, fileSize)
+	}
+
+	if err := s.fileStorage.CompleteMultipartUpload(ctx, objectKey, uploadID, parts); err != nil {
+		if errors.Is(err, storage.ErrMultipartUploadNotFound) {
+			return nil, ErrMultipartUploadNotFound
+		}
+		return nil, fmt.Errorf("%w: could not assemble parts", ErrUploadConfirmationFailed)
+	}
+
+	return s.ConfirmUpload(ctx, clientID, assignmentID, objectKey, fileName, fileSize, contentType)
+}
+
+// ensureObjectKeyUnrecorded rejects an object key that already backs an upload.
+func (s *clientService) ensureObjectKeyUnrecorded(ctx context.Context, objectKey string) error {
+	_, err := s.uploadRepo.GetByObjectKey(ctx, objectKey)
+	if err == nil {
+		return ErrUploadAlreadyConfirmed
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: could not check for an existing upload", ErrUploadConfirmationFailed)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards an unfinished multipart upload and its parts.
+func (s *clientService) AbortMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string) error {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || uploadID == "" {
+		return errors.New("client ID, assignment ID and upload ID are required")
+	}
+	if !isUploadKeyFor(clientID, assignmentID, objectKey) {
+		return ErrUploadObjectKeyInvalid
+	}
+	if _, _, err := s.getMyAssignment(ctx, clientID, assignmentID); err != nil {
+		return err
+	}
+
+	if err := s.fileStorage.AbortMultipartUpload(ctx, objectKey, uploadID); err != nil {
+		if errors.Is(err, storage.ErrMultipartUploadNotFound) {
+			return ErrMultipartUploadNotFound
+		}
+		return errors.New("failed to abort multipart upload")
+	}
+	return nil
+}
+
+// getMyMultipartUpload verifies the assignment, the key prefix and that the multipart
+// upload still exists, returning the parts received so far.
+func (s *clientService) getMyMultipartUpload(ctx context.Context, clientID, assignmentID primitive.ObjectID, objectKey, uploadID string) ([]storage.UploadedPart, error) {
+	if clientID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || uploadID == "" {
+		return nil, errors.New("client ID, assignment ID and upload ID are required")
+	}
+	if !isUploadKeyFor(clientID, assignmentID, objectKey) {
+		return nil, ErrUploadObjectKeyInvalid
+	}
+	if _, _, err := s.getMyUploadableAssignment(ctx, clientID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	parts, err := s.fileStorage.ListUploadedParts(ctx, objectKey, uploadID)
+	if err != nil {
+		if errors.Is(err, storage.ErrMultipartUploadNotFound) {
+			return nil, ErrMultipartUploadNotFound
+		}
+		return nil, errors.New("failed to list uploaded parts")
+	}
+	return parts, nil
+}
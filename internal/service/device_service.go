@@ -0,0 +1,97 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDeviceTokenLength is well above APNs (64 hex characters) and FCM (~160) tokens.
+const maxDeviceTokenLength = 512
+
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrInvalidDevice  = errors.New("invalid device")
+)
+
+// DeviceInput describes a device registering for push notifications.
+type DeviceInput struct {
+	Platform   domain.DevicePlatform
+	Token      string
+	AppVersion string
+}
+
+// DeviceService manages the signed-in user's devices for push notifications.
+type DeviceService interface {
+	// RegisterDevice adds or refreshes a device. Apps call it on every launch and
+	// whenever the provider issues a new token.
+	RegisterDevice(ctx context.Context, userID primitive.ObjectID, input DeviceInput) (*domain.Device, error)
+	ListDevices(ctx context.Context, userID primitive.ObjectID) ([]domain.Device, error)
+	// UnregisterDevice stops pushes to a device, e.g. on sign-out.
+	UnregisterDevice(ctx context.Context, userID primitive.ObjectID, token string) error
+}
+
+type deviceService struct {
+	deviceRepo repository.DeviceRepository
+}
+
+// NewDeviceService creates a new DeviceService.
+func NewDeviceService(deviceRepo repository.DeviceRepository) DeviceService {
+	return &deviceService{deviceRepo: deviceRepo}
+}
+
+func (s *deviceService) RegisterDevice(ctx context.Context, userID primitive.ObjectID, input DeviceInput) (*domain.Device, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	if !input.Platform.IsValid() {
+		return nil, fmt.Errorf("%w: platform must be ios or android", ErrInvalidDevice)
+	}
+	token := strings.TrimSpace(input.Token)
+	if token == "" || len(token) > maxDeviceTokenLength || strings.ContainsAny(token, " /") {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidDevice)
+	}
+
+	device, err := s.deviceRepo.Upsert(ctx, &domain.Device{
+		UserID:     userID,
+		Platform:   input.Platform,
+		Token:      token,
+		AppVersion: strings.TrimSpace(input.AppVersion),
+	})
+	if err != nil {
+		return nil, errors.New("failed to register device")
+	}
+	return device, nil
+}
+
+func (s *deviceService) ListDevices(ctx context.Context, userID primitive.ObjectID) ([]domain.Device, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	devices, err := s.deviceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve devices")
+	}
+	if devices == nil {
+		devices = []domain.Device{}
+	}
+	return devices, nil
+}
+
+func (s *deviceService) UnregisterDevice(ctx context.Context, userID primitive.ObjectID, token string) error {
+	if userID == primitive.NilObjectID || token == "" {
+		return errors.New("user ID and token are required")
+	}
+	if err := s.deviceRepo.Delete(ctx, userID, token); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrDeviceNotFound
+		}
+		return errors.New("failed to unregister device")
+	}
+	return nil
+}
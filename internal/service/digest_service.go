@@ -0,0 +1,593 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/mail"
+	"alcyxob/fitness-app/internal/repository"
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	netmail "net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// digestSendHour is the local hour on Monday from which the previous week's digest goes out.
+const digestSendHour = 8
+
+var ErrInvalidDigestSettings = errors.New("invalid digest settings")
+
+//go:embed templates/trainer_digest.html.tmpl templates/trainer_digest.txt.tmpl
+var digestTemplateFS embed.FS
+
+var digestTemplateFuncs = map[string]any{
+	"day": func(t time.Time) string { return t.Format("Mon, Jan 2") },
+	"percent": func(done, total int) int {
+		if total == 0 {
+			return 0
+		}
+		return done * 100 / total
+	},
+}
+
+var (
+	digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("trainer_digest.html.tmpl").Funcs(digestTemplateFuncs).ParseFS(digestTemplateFS, "templates/trainer_digest.html.tmpl"))
+	digestTextTemplate = texttemplate.Must(texttemplate.New("trainer_digest.txt.tmpl").Funcs(digestTemplateFuncs).ParseFS(digestTemplateFS, "templates/trainer_digest.txt.tmpl"))
+)
+
+// DigestSettingsInput changes a trainer's digest settings. Nil fields are left unchanged;
+// an empty email or time zone restores the default.
+type DigestSettingsInput struct {
+	Enabled  *bool
+	Email    *string
+	TimeZone *string // IANA name
+}
+
+// TrainerDigest summarizes one week (Monday to Sunday in the trainer's time zone) of a
+// trainer's clients.
+type TrainerDigest struct {
+	TrainerID   primitive.ObjectID `json:"trainerId"`
+	TrainerName string             `json:"trainerName"`
+	WeekStart   time.Time          `json:"weekStart"` // Monday 00:00
+	WeekEnd     time.Time          `json:"weekEnd"`   // The following Monday 00:00 (exclusive)
+
+	WorkoutsScheduled int `json:"workoutsScheduled"`
+	WorkoutsCompleted int `json:"workoutsCompleted"`
+	PendingReviews    int `json:"pendingReviews"` // Submissions awaiting review now, any week
+
+	Clients         []ClientDigest   `json:"clients"`         // Clients with workouts or activity, by name
+	InactiveClients []ClientDigest   `json:"inactiveClients"` // Clients with no activity in the week
+	PersonalRecords []PersonalRecord `json:"personalRecords"` // New bests across all clients
+}
+
+// LastDay returns the Sunday the digest ends on.
+func (d *TrainerDigest) LastDay() time.Time {
+	return d.WeekEnd.AddDate(0, 0, -1)
+}
+
+// ClientDigest is one client's line in the digest.
+type ClientDigest struct {
+	ClientID          primitive.ObjectID `json:"clientId"`
+	Name              string             `json:"name"`
+	WorkoutsScheduled int                `json:"workoutsScheduled"` // Resolved like GetMyCurrentWorkouts, day by day
+	WorkoutsCompleted int                `json:"workoutsCompleted"` // Nothing left "assigned" and worked on during the week
+	PendingReviews    int                `json:"pendingReviews"`
+	OldestPendingAt   *time.Time         `json:"oldestPendingAt,omitempty"`
+	PersonalRecords   []PersonalRecord   `json:"personalRecords,omitempty"`
+	LastActivityAt    *time.Time         `json:"lastActivityAt,omitempty"` // Latest logged set or submission, ever
+}
+
+// PersonalRecord is a weight logged during the week above the client's previous best
+// for the exercise (compared in the same unit).
+type PersonalRecord struct {
+	ClientID     primitive.ObjectID `json:"clientId"`
+	ClientName   string             `json:"clientName"`
+	ExerciseID   primitive.ObjectID `json:"exerciseId"`
+	ExerciseName string             `json:"exerciseName"`
+	Weight       string             `json:"weight"`       // As logged, e.g. "105kg"
+	PreviousBest string             `json:"previousBest"` // As logged
+	AchievedAt   time.Time          `json:"achievedAt"`
+}
+
+// RenderedDigest is a digest with the email it becomes.
+type RenderedDigest struct {
+	Digest  *TrainerDigest
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// DigestService builds and emails the trainers' weekly digest.
+type DigestService interface {
+	GetSettings(ctx context.Context, trainerID primitive.ObjectID) (*domain.DigestSettings, error)
+	UpdateSettings(ctx context.Context, trainerID primitive.ObjectID, input DigestSettingsInput) (*domain.DigestSettings, error)
+
+	// Preview renders the digest for the week containing weekOf, or for the last full
+	// week when weekOf is nil. Nothing is sent.
+	Preview(ctx context.Context, trainerID primitive.ObjectID, weekOf *time.Time) (*RenderedDigest, error)
+
+	// SendDue emails last week's digest to every opted-in trainer for whom it is past
+	// Monday 08:00 locally and who has not received it yet. Meant for the scheduler.
+	SendDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type digestService struct {
+	settingsRepo   repository.DigestSettingsRepository
+	userRepo       repository.UserRepository
+	assignmentRepo repository.AssignmentRepository
+	exerciseRepo   repository.ExerciseRepository
+	clientService  ClientService
+	mailer         mail.Sender
+}
+
+// NewDigestService creates a new DigestService. Scheduled workouts are resolved through
+// clientService so the digest counts what clients saw under "today".
+func NewDigestService(
+	settingsRepo repository.DigestSettingsRepository,
+	userRepo repository.UserRepository,
+	assignmentRepo repository.AssignmentRepository,
+	exerciseRepo repository.ExerciseRepository,
+	clientService ClientService,
+	mailer mail.Sender,
+) DigestService {
+	return &digestService{
+		settingsRepo:   settingsRepo,
+		userRepo:       userRepo,
+		assignmentRepo: assignmentRepo,
+		exerciseRepo:   exerciseRepo,
+		clientService:  clientService,
+		mailer:         mailer,
+	}
+}
+
+// getSettings loads a trainer's settings; trainers who never saved any get the defaults.
+func (s *digestService) getSettings(ctx context.Context, trainerID primitive.ObjectID) (*domain.DigestSettings, error) {
+	settings, err := s.settingsRepo.Get(ctx, trainerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &domain.DigestSettings{TrainerID: trainerID}, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *digestService) GetSettings(ctx context.Context, trainerID primitive.ObjectID) (*domain.DigestSettings, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+	settings, err := s.getSettings(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load digest settings")
+	}
+	return settings, nil
+}
+
+func (s *digestService) UpdateSettings(ctx context.Context, trainerID primitive.ObjectID, input DigestSettingsInput) (*domain.DigestSettings, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+	settings, err := s.getSettings(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load digest settings")
+	}
+
+	if input.Enabled != nil {
+		settings.Enabled = *input.Enabled
+	}
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		if email != "" {
+			addr, err := netmail.ParseAddress(email)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid email address", ErrInvalidDigestSettings)
+			}
+			email = addr.Address
+		}
+		settings.Email = email
+	}
+	if input.TimeZone != nil {
+		tz := strings.TrimSpace(*input.TimeZone)
+		if _, err := time.LoadLocation(tz); tz != "" && err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidDigestSettings, tz)
+		}
+		settings.TimeZone = tz
+	}
+
+	if err := s.settingsRepo.Upsert(ctx, settings); err != nil {
+		return nil, errors.New("failed to save digest settings")
+	}
+	return settings, nil
+}
+
+// weekStartOf returns 00:00 on the Monday of t's week, in t's location.
+func weekStartOf(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -daysSinceMonday).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func (s *digestService) Preview(ctx context.Context, trainerID primitive.ObjectID, weekOf *time.Time) (*RenderedDigest, error) {
+	settings, err := s.GetSettings(ctx, trainerID)
+	if err != nil {
+		return nil, err
+	}
+	trainer, err := s.userRepo.GetByID(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load trainer")
+	}
+
+	loc := settings.Location()
+	weekStart := weekStartOf(time.Now().In(loc)).AddDate(0, 0, -7)
+	if weekOf != nil {
+		y, m, d := weekOf.Date()
+		weekStart = weekStartOf(time.Date(y, m, d, 12, 0, 0, 0, loc))
+	}
+
+	digest, err := s.build(ctx, trainer, weekStart)
+	if err != nil {
+		return nil, err
+	}
+	return renderDigest(digest, digestRecipient(settings, trainer))
+}
+
+func (s *digestService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	all, err := s.settingsRepo.GetEnabled(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list digest subscribers: %w", err)
+	}
+	sent := 0
+	for i := range all {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		settings := &all[i]
+		thisWeek := weekStartOf(now.In(settings.Location()))
+		if now.Before(thisWeek.Add(digestSendHour * time.Hour)) {
+			continue
+		}
+		weekStart := thisWeek.AddDate(0, 0, -7)
+		weekKey := weekStart.Format(reminderDateLayout)
+		if settings.LastSentWeek == weekKey {
+			continue
+		}
+
+		if err := s.send(ctx, settings, weekStart); err != nil {
+			// Not marked sent, so the next run tries again.
+			log.Printf("WARN: Failed to send weekly digest to trainer %s: %v", settings.TrainerID.Hex(), err)
+			continue
+		}
+		if _, err := s.settingsRepo.MarkSent(ctx, settings.TrainerID, weekKey); err != nil {
+			log.Printf("WARN: Failed to record weekly digest for trainer %s: %v", settings.TrainerID.Hex(), err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// send builds, renders and emails one trainer's digest. Trainers without clients get none.
+func (s *digestService) send(ctx context.Context, settings *domain.DigestSettings, weekStart time.Time) error {
+	trainer, err := s.userRepo.GetByID(ctx, settings.TrainerID)
+	if err != nil {
+		return err
+	}
+	digest, err := s.build(ctx, trainer, weekStart)
+	if err != nil {
+		return err
+	}
+	if len(digest.Clients)+len(digest.InactiveClients) == 0 {
+		return nil
+	}
+	rendered, err := renderDigest(digest, digestRecipient(settings, trainer))
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      rendered.To,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	})
+}
+
+// digestRecipient returns the address the digest goes to.
+func digestRecipient(settings *domain.DigestSettings, trainer *domain.User) string {
+	if settings.Email != "" {
+		return settings.Email
+	}
+	return trainer.Email
+}
+
+// build collects the digest of trainer's clients for the week starting at weekStart.
+func (s *digestService) build(ctx context.Context, trainer *domain.User, weekStart time.Time) (*TrainerDigest, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	digest := &TrainerDigest{
+		TrainerID:       trainer.ID,
+		TrainerName:     trainer.Name,
+		WeekStart:       weekStart,
+		WeekEnd:         weekEnd,
+		Clients:         []ClientDigest{},
+		InactiveClients: []ClientDigest{},
+		PersonalRecords: []PersonalRecord{},
+	}
+
+	clients, err := s.userRepo.GetClientsByTrainerID(ctx, trainer.ID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve clients")
+	}
+	submitted, err := s.assignmentRepo.GetSubmittedByTrainer(ctx, trainer.ID, nil)
+	if err != nil {
+		return nil, errors.New("failed to retrieve pending reviews")
+	}
+	pendingByClient := make(map[primitive.ObjectID][]domain.Assignment)
+	for _, a := range submitted {
+		pendingByClient[a.ClientID] = append(pendingByClient[a.ClientID], a)
+	}
+
+	exerciseNames := make(map[primitive.ObjectID]string)
+	for _, client := range clients {
+		cd, active, err := s.buildClient(ctx, client, weekStart, weekEnd, pendingByClient[client.ID], exerciseNames)
+		if err != nil {
+			return nil, err
+		}
+		digest.WorkoutsScheduled += cd.WorkoutsScheduled
+		digest.WorkoutsCompleted += cd.WorkoutsCompleted
+		digest.PendingReviews += cd.PendingReviews
+		digest.PersonalRecords = append(digest.PersonalRecords, cd.PersonalRecords...)
+		if active {
+			digest.Clients = append(digest.Clients, cd)
+		} else {
+			digest.InactiveClients = append(digest.InactiveClients, cd)
+		}
+	}
+
+	byName := func(list []ClientDigest) func(i, j int) bool {
+		return func(i, j int) bool { return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name) }
+	}
+	sort.Slice(digest.Clients, byName(digest.Clients))
+	sort.Slice(digest.InactiveClients, byName(digest.InactiveClients))
+	sort.Slice(digest.PersonalRecords, func(i, j int) bool {
+		return digest.PersonalRecords[i].AchievedAt.Before(digest.PersonalRecords[j].AchievedAt)
+	})
+	return digest, nil
+}
+
+// buildClient summarizes one client's week. active reports whether the client logged a
+// set or submitted anything during it.
+func (s *digestService) buildClient(
+	ctx context.Context,
+	client domain.User,
+	weekStart, weekEnd time.Time,
+	pending []domain.Assignment,
+	exerciseNames map[primitive.ObjectID]string,
+) (ClientDigest, bool, error) {
+	cd := ClientDigest{ClientID: client.ID, Name: client.Name, PendingReviews: len(pending)}
+	for _, a := range pending {
+		if a.SubmittedAt != nil && (cd.OldestPendingAt == nil || a.SubmittedAt.Before(*cd.OldestPendingAt)) {
+			submittedAt := *a.SubmittedAt
+			cd.OldestPendingAt = &submittedAt
+		}
+	}
+
+	assignments, err := s.assignmentRepo.GetByClientID(ctx, client.ID)
+	if err != nil {
+		return cd, false, errors.New("failed to retrieve client assignments")
+	}
+	inWeek := func(t time.Time) bool { return !t.Before(weekStart) && t.Before(weekEnd) }
+
+	byWorkout := make(map[primitive.ObjectID][]domain.Assignment)
+	active := false
+	for _, a := range assignments {
+		byWorkout[a.WorkoutID] = append(byWorkout[a.WorkoutID], a)
+		if t := lastClientActivity(&a); t != nil {
+			if cd.LastActivityAt == nil || t.After(*cd.LastActivityAt) {
+				cd.LastActivityAt = t
+			}
+		}
+		if a.SubmittedAt != nil && inWeek(*a.SubmittedAt) {
+			active = true
+		}
+		for _, set := range a.SetLogs {
+			if inWeek(set.LoggedAt) {
+				active = true
+			}
+		}
+	}
+
+	// A workout recurs every week, but its assignments carry one status; it counts as
+	// completed when nothing is left assigned and it was worked on this week.
+	for day := weekStart; day.Before(weekEnd); day = day.AddDate(0, 0, 1) {
+		workouts, err := s.clientService.GetMyCurrentWorkouts(ctx, client.ID, day)
+		if err != nil {
+			return cd, false, fmt.Errorf("failed to resolve workouts of client %s: %w", client.ID.Hex(), err)
+		}
+		for _, w := range workouts {
+			cd.WorkoutsScheduled++
+			if workoutCompletedDuring(byWorkout[w.ID], inWeek) {
+				cd.WorkoutsCompleted++
+			}
+		}
+	}
+
+	records, err := s.personalRecords(ctx, &client, assignments, inWeek, weekStart, exerciseNames)
+	if err != nil {
+		return cd, false, err
+	}
+	cd.PersonalRecords = records
+	return cd, active, nil
+}
+
+// workoutCompletedDuring reports whether none of assignments is still assigned and at
+// least one was submitted, logged or updated in the week.
+func workoutCompletedDuring(assignments []domain.Assignment, inWeek func(time.Time) bool) bool {
+	if len(assignments) == 0 {
+		return false
+	}
+	touched := false
+	for _, a := range assignments {
+		if a.Status == domain.StatusAssigned {
+			return false
+		}
+		if inWeek(a.UpdatedAt) || (a.SubmittedAt != nil && inWeek(*a.SubmittedAt)) {
+			touched = true
+		}
+		for _, set := range a.SetLogs {
+			if inWeek(set.LoggedAt) {
+				touched = true
+			}
+		}
+	}
+	return touched
+}
+
+// lastClientActivity returns the latest time the client logged a set or submitted a.
+func lastClientActivity(a *domain.Assignment) *time.Time {
+	var latest *time.Time
+	if a.SubmittedAt != nil {
+		submittedAt := *a.SubmittedAt
+		latest = &submittedAt
+	}
+	for _, set := range a.SetLogs {
+		if latest == nil || set.LoggedAt.After(*latest) {
+			loggedAt := set.LoggedAt
+			latest = &loggedAt
+		}
+	}
+	return latest
+}
+
+var weightPattern = regexp.MustCompile(`^\s*(\d+(?:[.,]\d+)?)\s*([a-zA-Z]*)`)
+
+// parseWeight reads a logged weight such as "102.5 kg" or "225lbs" into a number and a
+// normalized unit. Non-numeric weights ("BW", "RPE 8") are not comparable.
+func parseWeight(raw string) (float64, string, bool) {
+	m := weightPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return 0, "", false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	unit := strings.ToLower(m[2])
+	switch unit {
+	case "kgs", "kilo", "kilos":
+		unit = "kg"
+	case "lbs", "pound", "pounds":
+		unit = "lb"
+	}
+	return value, unit, true
+}
+
+// personalRecords finds, per exercise and unit, the week's best logged weight that beats
+// the best logged before the week. First-ever logs set a baseline, not a record.
+func (s *digestService) personalRecords(
+	ctx context.Context,
+	client *domain.User,
+	assignments []domain.Assignment,
+	inWeek func(time.Time) bool,
+	weekStart time.Time,
+	exerciseNames map[primitive.ObjectID]string,
+) ([]PersonalRecord, error) {
+	type key struct {
+		exerciseID primitive.ObjectID
+		unit       string
+	}
+	type best struct {
+		value float64
+		raw   string
+		at    time.Time
+		set   bool
+	}
+	before := make(map[key]best)
+	during := make(map[key]best)
+	var order []key
+
+	for _, a := range assignments {
+		for _, set := range a.SetLogs {
+			if set.Weight == nil {
+				continue
+			}
+			value, unit, ok := parseWeight(*set.Weight)
+			if !ok {
+				continue
+			}
+			k := key{a.ExerciseID, unit}
+			switch {
+			case set.LoggedAt.Before(weekStart):
+				if b := before[k]; !b.set || value > b.value {
+					before[k] = best{value, *set.Weight, set.LoggedAt, true}
+				}
+			case inWeek(set.LoggedAt):
+				b, seen := during[k]
+				if !seen {
+					order = append(order, k)
+				}
+				if !b.set || value > b.value {
+					during[k] = best{value, *set.Weight, set.LoggedAt, true}
+				}
+			}
+		}
+	}
+
+	var records []PersonalRecord
+	for _, k := range order {
+		prev, week := before[k], during[k]
+		if !prev.set || week.value <= prev.value {
+			continue
+		}
+		name, ok := exerciseNames[k.exerciseID]
+		if !ok {
+			exercise, err := s.exerciseRepo.GetByID(ctx, k.exerciseID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, errors.New("failed to retrieve exercise")
+			}
+			name = "Exercise"
+			if exercise != nil {
+				name = exercise.Name
+			}
+			exerciseNames[k.exerciseID] = name
+		}
+		records = append(records, PersonalRecord{
+			ClientID:     client.ID,
+			ClientName:   client.Name,
+			ExerciseID:   k.exerciseID,
+			ExerciseName: name,
+			Weight:       strings.TrimSpace(week.raw),
+			PreviousBest: strings.TrimSpace(prev.raw),
+			AchievedAt:   week.at,
+		})
+	}
+	return records, nil
+}
+
+// renderDigest renders digest as the email sent to to.
+func renderDigest(digest *TrainerDigest, to string) (*RenderedDigest, error) {
+	var html, text bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&html, digest); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := digestTextTemplate.Execute(&text, digest); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	subject := fmt.Sprintf("Your clients' week: %s – %s",
+		digest.WeekStart.Format("Jan 2"), digest.LastDay().Format("Jan 2"))
+	return &RenderedDigest{
+		Digest:  digest,
+		To:      to,
+		Subject: subject,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
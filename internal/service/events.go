@@ -0,0 +1,20 @@
+package service
+
+import "alcyxob/fitness-app/internal/domain"
+
+// assignmentEventData is the realtime payload for events about an assignment.
+func assignmentEventData(a *domain.Assignment) map[string]any {
+	return map[string]any{
+		"assignmentId": a.ID,
+		"workoutId":    a.WorkoutID,
+		"status":       a.Status,
+	}
+}
+
+// feedbackEventData is assignmentEventData plus whether the feedback moved the
+// assignment to a new status (e.g. submitted -> reviewed).
+func feedbackEventData(a *domain.Assignment, previousStatus domain.AssignmentStatus) map[string]any {
+	data := assignmentEventData(a)
+	data["statusChanged"] = a.Status != previousStatus
+	return data
+}
@@ -3,9 +3,16 @@ package service
 import (
 	"alcyxob/fitness-app/internal/domain"
 	"alcyxob/fitness-app/internal/repository" // Import repository package
+	"alcyxob/fitness-app/internal/storage"
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -14,29 +21,135 @@ var (
 	ErrExerciseNotFound     = errors.New("exercise not found")
 	ErrExerciseAccessDenied = errors.New("access denied to modify or delete this exercise")
 	ErrValidationFailed     = errors.New("exercise validation failed")
+	ErrExerciseMediaNotFound     = errors.New("exercise media not found")
+	ErrInvalidMediaKind          = errors.New("invalid media kind; expected video, image or thumbnail")
+	ErrInvalidMediaContentType   = errors.New("content type does not match the media kind")
+	ErrMediaObjectKeyMismatch    = errors.New("object key does not belong to this exercise")
+	ErrExerciseRevisionNotFound  = errors.New("exercise revision not found")
+	ErrExerciseInUse             = errors.New("exercise is used by existing assignments")
+	ErrMergeSameExercise         = errors.New("cannot merge an exercise into itself")
 )
 
+// ExerciseUsage reports where an exercise is used: which plans and workouts
+// reference it through assignments, and which clients those belong to. Drafts
+// lists the plans whose unpublished edits (see PlanDraft) stage assignments of it.
+type ExerciseUsage struct {
+	ExerciseID           primitive.ObjectID    `json:"exerciseId"`
+	AssignmentCount      int                   `json:"assignmentCount"`
+	Plans                []ExercisePlanUsage   `json:"plans"`
+	Clients              []ExerciseClientUsage `json:"clients"`
+	DraftAssignmentCount int                   `json:"draftAssignmentCount"`
+	Drafts               []ExercisePlanUsage   `json:"drafts"`
+}
+
+// ExercisePlanUsage lists the workouts of one plan that use an exercise.
+type ExercisePlanUsage struct {
+	PlanID   primitive.ObjectID     `json:"planId"`
+	PlanName string                 `json:"planName"`
+	ClientID primitive.ObjectID     `json:"clientId"`
+	IsActive bool                   `json:"isActive"`
+	Workouts []ExerciseWorkoutUsage `json:"workouts"`
+}
+
+// ExerciseWorkoutUsage counts the assignments of an exercise in one workout.
+type ExerciseWorkoutUsage struct {
+	WorkoutID       primitive.ObjectID `json:"workoutId"`
+	WorkoutName     string             `json:"workoutName"`
+	AssignmentCount int                `json:"assignmentCount"`
+}
+
+// ExerciseClientUsage identifies a client who has assignments of an exercise.
+type ExerciseClientUsage struct {
+	ClientID primitive.ObjectID `json:"clientId"`
+	Name     string             `json:"name"`
+	Email    string             `json:"email"`
+}
+
+// ExerciseFieldChange describes one field that differs between two exercise revisions.
+type ExerciseFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ExerciseMediaDetails combines media metadata with a short-lived download URL.
+type ExerciseMediaDetails struct {
+	domain.ExerciseMedia
+	DownloadURL string `json:"downloadUrl"`
+}
+
 // --- Service Interface (Optional) ---
 type ExerciseService interface {
 	CreateExercise(ctx context.Context, trainerID primitive.ObjectID, name, description, muscleGroup, executionTechnic, applicability, difficulty, videoURL string) (*domain.Exercise, error)
 	GetExerciseByID(ctx context.Context, exerciseID primitive.ObjectID) (*domain.Exercise, error)
 	GetExercisesByTrainer(ctx context.Context, trainerID primitive.ObjectID) ([]domain.Exercise, error)
-	UpdateExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, name, description, muscleGroup, executionTechnic, applicability, difficulty, videoURL string) (*domain.Exercise, error)
-	DeleteExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID) error
+	UpdateExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, name, description, muscleGroup, executionTechnic, applicability, difficulty, videoURL string, upgradeOpenAssignments bool) (*domain.Exercise, error)
+	DeleteExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, force bool) (int64, error)
+
+	// --- Usage & Merge ---
+	GetExerciseUsage(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (*ExerciseUsage, error)
+	MergeExercises(ctx context.Context, trainerID, sourceExerciseID, targetExerciseID primitive.ObjectID) (int64, error)
+
+	// --- Revision History ---
+	GetExerciseRevisions(ctx context.Context, trainerID, exerciseID primitive.ObjectID) ([]domain.ExerciseRevision, error)
+	GetExerciseRevision(ctx context.Context, trainerID, exerciseID primitive.ObjectID, revision int) (*domain.ExerciseRevision, error)
+	DiffExerciseRevisions(ctx context.Context, trainerID, exerciseID primitive.ObjectID, fromRevision, toRevision int) ([]ExerciseFieldChange, error)
+	UpgradeOpenAssignments(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (int64, error)
+
+	// --- Demonstration Media ---
+	RequestMediaUploadURL(ctx context.Context, trainerID, exerciseID primitive.ObjectID, kind domain.MediaKind, contentType string) (*UploadURLResponse, error)
+	ConfirmMediaUpload(ctx context.Context, trainerID, exerciseID primitive.ObjectID, kind domain.MediaKind, objectKey, fileName string, fileSize int64, contentType string, sequence int) (*domain.ExerciseMedia, error)
+	GetExerciseMedia(ctx context.Context, trainerID, exerciseID primitive.ObjectID) ([]ExerciseMediaDetails, error)
+	DeleteExerciseMedia(ctx context.Context, trainerID, exerciseID, mediaID primitive.ObjectID) error
 }
 
 // --- Service Implementation ---
 
 // exerciseService implements the ExerciseService interface.
 type exerciseService struct {
-	exerciseRepo repository.ExerciseRepository
+	exerciseRepo         repository.ExerciseRepository
+	exerciseMediaRepo    repository.ExerciseMediaRepository
+	exerciseRevisionRepo repository.ExerciseRevisionRepository
+	assignmentRepo       repository.AssignmentRepository
+	workoutRepo          repository.WorkoutRepository
+	trainingPlanRepo     repository.TrainingPlanRepository
+	userRepo             repository.UserRepository
+	uploadRepo           repository.UploadRepository
+	feedbackCommentRepo  repository.FeedbackCommentRepository
+	planDraftRepo        repository.PlanDraftRepository
+	transactor           repository.Transactor
+	fileStorage          storage.FileStorage
 }
 
 
 // NewExerciseService creates a new instance of exerciseService.
-func NewExerciseService(exerciseRepo repository.ExerciseRepository) ExerciseService {
+func NewExerciseService(
+	exerciseRepo repository.ExerciseRepository,
+	exerciseMediaRepo repository.ExerciseMediaRepository,
+	exerciseRevisionRepo repository.ExerciseRevisionRepository,
+	assignmentRepo repository.AssignmentRepository,
+	workoutRepo repository.WorkoutRepository,
+	trainingPlanRepo repository.TrainingPlanRepository,
+	userRepo repository.UserRepository,
+	uploadRepo repository.UploadRepository,
+	feedbackCommentRepo repository.FeedbackCommentRepository,
+	planDraftRepo repository.PlanDraftRepository,
+	transactor repository.Transactor,
+	fileStorage storage.FileStorage,
+) ExerciseService {
 	return &exerciseService{
-		exerciseRepo: exerciseRepo,
+		exerciseRepo:         exerciseRepo,
+		exerciseMediaRepo:    exerciseMediaRepo,
+		exerciseRevisionRepo: exerciseRevisionRepo,
+		assignmentRepo:       assignmentRepo,
+		workoutRepo:          workoutRepo,
+		trainingPlanRepo:     trainingPlanRepo,
+		userRepo:             userRepo,
+		uploadRepo:           uploadRepo,
+		feedbackCommentRepo:  feedbackCommentRepo,
+		planDraftRepo:        planDraftRepo,
+		transactor:           transactor,
+		fileStorage:          fileStorage,
 	}
 }
 
@@ -60,13 +173,27 @@ func (s *exerciseService) CreateExercise(ctx context.Context, trainerID primitiv
 		Applicability: applicability,
 		Difficulty:   difficulty,
 		VideoURL:     videoURL, // Optional, can be empty
+		CurrentRevision: 1,
 	}
 
-	exerciseID, err := s.exerciseRepo.Create(ctx, exercise)
+	// Every exercise starts with revision 1 so assignments always have something to pin;
+	// the exercise is only created together with it.
+	var exerciseID primitive.ObjectID
+	err := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		id, err := s.exerciseRepo.Create(txCtx, exercise)
+		if err != nil {
+			return err
+		}
+		exercise.ID = id
+		if _, err := s.exerciseRevisionRepo.Create(txCtx, domain.NewExerciseRevision(exercise, 1)); err != nil {
+			return fmt.Errorf("failed to store exercise revision: %w", err)
+		}
+		exerciseID = id
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	exercise.ID = exerciseID
 	// To get CreatedAt/UpdatedAt populated by the DB back into the returned object:
 	return s.exerciseRepo.GetByID(ctx, exerciseID) // Fetch again to get all fields
 }
@@ -101,7 +228,10 @@ func (s *exerciseService) GetExercisesByTrainer(ctx context.Context, trainerID p
 }
 
 // UpdateExercise handles updating an existing exercise, ensuring ownership.
-func (s *exerciseService) UpdateExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, name, description, muscleGroup, executionTechnic, applicability, difficulty, videoURL string) (*domain.Exercise, error) {
+// A content change creates a new revision; existing assignments stay pinned to the
+// revision they were created with unless upgradeOpenAssignments is set, in which case
+// assignments the client hasn't started yet move to the new revision.
+func (s *exerciseService) UpdateExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, name, description, muscleGroup, executionTechnic, applicability, difficulty, videoURL string, upgradeOpenAssignments bool) (*domain.Exercise, error) {
 	if name == "" {
 		return nil, ErrValidationFailed
 	}
@@ -121,6 +251,8 @@ func (s *exerciseService) UpdateExercise(ctx context.Context, trainerID, exercis
 		return nil, ErrExerciseAccessDenied
 	}
 
+	stored := *existingExercise
+
 	// Update fields
 	existingExercise.Name = name
 	existingExercise.Description = description
@@ -130,7 +262,40 @@ func (s *exerciseService) UpdateExercise(ctx context.Context, trainerID, exercis
 	existingExercise.Difficulty = difficulty
 	existingExercise.VideoURL = videoURL // Allow updating the video URL
 
-	err = s.exerciseRepo.Update(ctx, existingExercise)
+	// The new revision and the exercise pointing at it are written together, so a failed
+	// update leaves no orphan revision behind to block the next attempt.
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		current := stored // fn may be retried; start from the stored exercise every time
+
+		// Exercises created before versioning get their current content recorded as revision 1
+		// before it is overwritten, and their existing assignments are pinned to it.
+		if current.CurrentRevision == 0 {
+			if err := s.backfillFirstRevision(txCtx, &current); err != nil {
+				return err
+			}
+		}
+		previous := domain.NewExerciseRevision(&current, current.CurrentRevision)
+
+		existingExercise.CurrentRevision = previous.Revision
+		next := domain.NewExerciseRevision(existingExercise, previous.Revision+1)
+		contentChanged := !next.SameContent(previous)
+		if contentChanged {
+			if _, err := s.exerciseRevisionRepo.Create(txCtx, next); err != nil {
+				return fmt.Errorf("failed to store exercise revision: %w", err)
+			}
+			existingExercise.CurrentRevision = next.Revision
+		}
+
+		if err := s.exerciseRepo.Update(txCtx, existingExercise); err != nil {
+			return err
+		}
+		if contentChanged && upgradeOpenAssignments {
+			if _, err := s.upgradeOpenAssignments(txCtx, existingExercise); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, ErrExerciseNotFound
@@ -140,33 +305,660 @@ func (s *exerciseService) UpdateExercise(ctx context.Context, trainerID, exercis
 	return existingExercise, nil
 }
 
+// backfillFirstRevision records an unversioned exercise's current content as revision 1.
+func (s *exerciseService) backfillFirstRevision(ctx context.Context, exercise *domain.Exercise) error {
+	if _, err := s.exerciseRevisionRepo.Create(ctx, domain.NewExerciseRevision(exercise, 1)); err != nil {
+		return fmt.Errorf("failed to store initial exercise revision: %w", err)
+	}
+	if _, err := s.assignmentRepo.PinExerciseRevision(ctx, exercise.ID, 1); err != nil {
+		return fmt.Errorf("failed to pin existing assignments: %w", err)
+	}
+	exercise.CurrentRevision = 1
+	return nil
+}
+
 // DeleteExercise handles deleting an exercise, ensuring ownership.
-func (s *exerciseService) DeleteExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID) error {
+// An exercise still referenced by assignments, published or staged in a plan draft,
+// is only deleted when force is set, in which case those assignments are removed with
+// it, together with their uploads, the feedback comments on those uploads and the
+// trainer's feedback attachments. Returns the number of published assignments deleted.
+func (s *exerciseService) DeleteExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID, force bool) (int64, error) {
 	if trainerID == primitive.NilObjectID || exerciseID == primitive.NilObjectID {
-		return errors.New("trainer ID and exercise ID are required")
+		return 0, errors.New("trainer ID and exercise ID are required")
 	}
 
-	// Note: The repository's Delete method already includes the trainerID check in its filter.
-	// So, calling it directly enforces ownership at the DB level.
-	// We could add an explicit GetByID check first (like in Update) for a more specific
-	// error message ("not found" vs "access denied"), but relying on the repo's
-	// combined filter is also efficient.
+	// The usage check and every delete run in one transaction, so an assignment added
+	// concurrently either blocks the delete or is removed with the exercise.
+	var deletedAssignments int64
+	var objectKeys []string
+	err := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		deletedAssignments, objectKeys = 0, nil // fn may be retried
+
+		if _, err := s.getOwnedExercise(txCtx, trainerID, exerciseID); err != nil {
+			return err
+		}
 
-	err := s.exerciseRepo.Delete(ctx, exerciseID, trainerID)
+		assignments, err := s.assignmentRepo.GetByExerciseID(txCtx, exerciseID)
+		if err != nil {
+			return fmt.Errorf("failed to check exercise usage: %w", err)
+		}
+		drafts, err := s.stagedAssignments(txCtx, trainerID, exerciseID)
+		if err != nil {
+			return err
+		}
+		if (len(assignments) > 0 || len(drafts) > 0) && !force {
+			return ErrExerciseInUse
+		}
+
+		// Note: The repository's Delete method also includes the trainerID check in its filter.
+		if err := s.exerciseRepo.Delete(txCtx, exerciseID, trainerID); err != nil {
+			return err
+		}
+
+		for _, draft := range drafts {
+			var edit domain.PlanDraftEdit
+			for _, a := range draft.Assignments {
+				edit.Dropped = append(edit.Dropped, a.ID)
+			}
+			if err := s.planDraftRepo.Stage(txCtx, trainerID, draft.PlanID, edit); err != nil {
+				return fmt.Errorf("failed to drop staged assignments of exercise: %w", err)
+			}
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+
+		for i := range assignments {
+			keys, err := s.deleteAssignmentDependents(txCtx, &assignments[i])
+			if err != nil {
+				return err
+			}
+			objectKeys = append(objectKeys, keys...)
+		}
+		deletedAssignments, err = s.assignmentRepo.DeleteByExerciseID(txCtx, exerciseID)
+		if err != nil {
+			return fmt.Errorf("failed to delete assignments of exercise: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			// This error from the repo could mean "not found" OR "found but wrong trainer".
-			// Map to a consistent service error. ErrExerciseNotFound or ErrExerciseAccessDenied might be suitable.
-			// Let's use NotFound for simplicity, assuming the API layer already confirmed the user is the trainer.
-			return ErrExerciseNotFound // Or ErrExerciseAccessDenied if preferred
+			return 0, ErrExerciseNotFound
 		}
-		// Propagate other repository errors (e.g., db connection issues)
-		return err
+		return 0, err
+	}
+
+	// Files go only once the metadata is committed; a leftover object is collected by storage reconciliation.
+	for _, key := range objectKeys {
+		if err := s.fileStorage.DeleteObject(ctx, key); err != nil {
+			log.Printf("WARN: Failed to delete object %s of deleted exercise %s: %v", key, exerciseID.Hex(), err)
+		}
+	}
+	s.deleteAllExerciseMedia(ctx, exerciseID, trainerID)
+
+	return deletedAssignments, nil
+}
+
+// deleteAssignmentDependents removes the uploads of an assignment and the feedback comments
+// on them, returning the object keys of those uploads and of the assignment's feedback attachments.
+func (s *exerciseService) deleteAssignmentDependents(ctx context.Context, assignment *domain.Assignment) ([]string, error) {
+	uploads, err := s.uploadRepo.GetByAssignmentID(ctx, assignment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve uploads of assignment %s: %w", assignment.ID.Hex(), err)
+	}
+
+	var keys []string
+	for _, u := range uploads {
+		if _, err := s.feedbackCommentRepo.DeleteByUploadID(ctx, u.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete feedback comments of upload %s: %w", u.ID.Hex(), err)
+		}
+		if err := s.uploadRepo.Delete(ctx, u.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete upload %s: %w", u.ID.Hex(), err)
+		}
+		keys = append(keys, u.S3ObjectKey)
+	}
+	for _, a := range assignment.FeedbackAttachments {
+		keys = append(keys, a.S3ObjectKey)
+	}
+	return keys, nil
+}
+
+// === Usage & Merge ===
+
+// GetExerciseUsage lists the plans, workouts and clients that use an exercise through assignments.
+func (s *exerciseService) GetExerciseUsage(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (*ExerciseUsage, error) {
+	if _, err := s.getOwnedExercise(ctx, trainerID, exerciseID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.assignmentRepo.GetByExerciseID(ctx, exerciseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve assignments for exercise: %w", err)
 	}
 
-	// Optional: Check if any assignments use this exercise and handle cleanup?
-	// This adds complexity (would need AssignmentRepository dependency) and depends on business rules.
-	// For now, we just delete the exercise itself.
+	usage := &ExerciseUsage{
+		ExerciseID:      exerciseID,
+		AssignmentCount: len(assignments),
+		Plans:           []ExercisePlanUsage{},
+		Clients:         []ExerciseClientUsage{},
+		Drafts:          []ExercisePlanUsage{},
+	}
+
+	drafts, err := s.stagedAssignments(ctx, trainerID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range drafts {
+		draftUsage, err := s.draftUsage(ctx, &drafts[i])
+		if err != nil {
+			return nil, err
+		}
+		usage.Drafts = append(usage.Drafts, *draftUsage)
+		usage.DraftAssignmentCount += len(drafts[i].Assignments)
+	}
+
+	// Group assignment counts by workout, keeping first-seen order.
+	workoutCounts := make(map[primitive.ObjectID]int)
+	var workoutOrder []primitive.ObjectID
+	for _, a := range assignments {
+		if _, seen := workoutCounts[a.WorkoutID]; !seen {
+			workoutOrder = append(workoutOrder, a.WorkoutID)
+		}
+		workoutCounts[a.WorkoutID]++
+	}
+
+	planIndex := make(map[primitive.ObjectID]int)
+	seenClients := make(map[primitive.ObjectID]bool)
+	for _, workoutID := range workoutOrder {
+		workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue // Orphaned assignment; its workout is already gone
+			}
+			return nil, err
+		}
+
+		idx, ok := planIndex[workout.TrainingPlanID]
+		if !ok {
+			planUsage := ExercisePlanUsage{PlanID: workout.TrainingPlanID, ClientID: workout.ClientID, Workouts: []ExerciseWorkoutUsage{}}
+			if plan, err := s.trainingPlanRepo.GetByID(ctx, workout.TrainingPlanID); err == nil {
+				planUsage.PlanName = plan.Name
+				planUsage.IsActive = plan.IsActive
+			} else if !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+			usage.Plans = append(usage.Plans, planUsage)
+			idx = len(usage.Plans) - 1
+			planIndex[workout.TrainingPlanID] = idx
+		}
+		usage.Plans[idx].Workouts = append(usage.Plans[idx].Workouts, ExerciseWorkoutUsage{
+			WorkoutID:       workout.ID,
+			WorkoutName:     workout.Name,
+			AssignmentCount: workoutCounts[workoutID],
+		})
+
+		if !seenClients[workout.ClientID] {
+			seenClients[workout.ClientID] = true
+			clientUsage := ExerciseClientUsage{ClientID: workout.ClientID}
+			if client, err := s.userRepo.GetByID(ctx, workout.ClientID); err == nil {
+				clientUsage.Name = client.Name
+				clientUsage.Email = client.Email
+			} else if !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+			usage.Clients = append(usage.Clients, clientUsage)
+		}
+	}
+
+	return usage, nil
+}
+
+// draftUsage lists the workouts of a draft's plan that stage assignments of an exercise;
+// draft holds only those assignments (see stagedAssignments).
+func (s *exerciseService) draftUsage(ctx context.Context, draft *domain.PlanDraft) (*ExercisePlanUsage, error) {
+	planUsage := &ExercisePlanUsage{PlanID: draft.PlanID, Workouts: []ExerciseWorkoutUsage{}}
+	if plan, err := s.trainingPlanRepo.GetByID(ctx, draft.PlanID); err == nil {
+		planUsage.PlanName = plan.Name
+		planUsage.ClientID = plan.ClientID
+		planUsage.IsActive = plan.IsActive
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	workoutCounts := make(map[primitive.ObjectID]int)
+	var workoutIDs []primitive.ObjectID
+	for _, a := range draft.Assignments {
+		if _, seen := workoutCounts[a.WorkoutID]; !seen {
+			workoutIDs = append(workoutIDs, a.WorkoutID)
+		}
+		workoutCounts[a.WorkoutID]++
+	}
+	sort.Slice(workoutIDs, func(i, j int) bool { return workoutIDs[i].Hex() < workoutIDs[j].Hex() })
+
+	for _, workoutID := range workoutIDs {
+		workoutUsage := ExerciseWorkoutUsage{WorkoutID: workoutID, AssignmentCount: workoutCounts[workoutID]}
+		if staged, ok := draft.Workout(workoutID); ok {
+			workoutUsage.WorkoutName = staged.Name
+		} else if workout, err := s.workoutRepo.GetByID(ctx, workoutID); err == nil {
+			workoutUsage.WorkoutName = workout.Name
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		planUsage.Workouts = append(planUsage.Workouts, workoutUsage)
+	}
+	return planUsage, nil
+}
+
+// stagedAssignments returns the drafts of the trainer's plans that stage assignments of
+// an exercise, each narrowed to those assignments.
+func (s *exerciseService) stagedAssignments(ctx context.Context, trainerID, exerciseID primitive.ObjectID) ([]domain.PlanDraft, error) {
+	drafts, err := s.planDraftRepo.GetByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve plan drafts: %w", err)
+	}
+	var using []domain.PlanDraft
+	for _, draft := range drafts {
+		staged := make(map[string]domain.Assignment)
+		for key, a := range draft.Assignments {
+			if a.ExerciseID == exerciseID {
+				staged[key] = a
+			}
+		}
+		if len(staged) > 0 {
+			draft.Blocks, draft.Assignments = nil, staged // Staged workouts stay for their names
+			using = append(using, draft)
+		}
+	}
+	return using, nil
+}
+
+// MergeExercises re-points every assignment of a duplicate (source) exercise, including
+// those staged in plan drafts, to the target exercise, then deletes the source. Assignments the client hasn't started are
+// pinned to the target's current revision; the others keep the source revision they
+// were done against. Returns the number of assignments moved.
+func (s *exerciseService) MergeExercises(ctx context.Context, trainerID, sourceExerciseID, targetExerciseID primitive.ObjectID) (int64, error) {
+	if sourceExerciseID == targetExerciseID {
+		return 0, ErrMergeSameExercise
+	}
+
+	// Re-pointing and deleting the source run in one transaction, so no assignment is
+	// left behind on the deleted source and a failed merge changes nothing.
+	var moved int64
+	err := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		moved = 0 // fn may be retried
+
+		source, err := s.getOwnedExercise(txCtx, trainerID, sourceExerciseID)
+		if err != nil {
+			return err
+		}
+		target, err := s.getOwnedExercise(txCtx, trainerID, targetExerciseID)
+		if err != nil {
+			return err
+		}
+
+		// Started assignments keep their source revision, so an unversioned source needs one too.
+		if source.CurrentRevision == 0 {
+			if err := s.backfillFirstRevision(txCtx, source); err != nil {
+				return err
+			}
+		}
+		// Give an unversioned target its first revision so moved assignments have something to pin.
+		if target.CurrentRevision == 0 {
+			if err := s.backfillFirstRevision(txCtx, target); err != nil {
+				return err
+			}
+			if err := s.exerciseRepo.Update(txCtx, target); err != nil {
+				return err
+			}
+		}
+
+		open := []domain.AssignmentStatus{domain.StatusAssigned}
+		moved, err = s.assignmentRepo.ReassignExercise(txCtx, source.ID, target.ID, target.CurrentRevision, open)
+		if err != nil {
+			return fmt.Errorf("failed to re-point assignments: %w", err)
+		}
+
+		// Staged copies follow the same rule, so publishing a draft later doesn't undo the merge.
+		drafts, err := s.stagedAssignments(txCtx, trainerID, source.ID)
+		if err != nil {
+			return err
+		}
+		for _, draft := range drafts {
+			var edit domain.PlanDraftEdit
+			for _, a := range draft.Assignments {
+				a.ExerciseID = target.ID
+				if a.Status == domain.StatusAssigned {
+					a.ExerciseRevision, a.RevisionExerciseID = target.CurrentRevision, nil
+				} else if a.RevisionExerciseID == nil {
+					a.RevisionExerciseID = &source.ID
+				}
+				edit.Assignments = append(edit.Assignments, a)
+			}
+			if err := s.planDraftRepo.Stage(txCtx, trainerID, draft.PlanID, edit); err != nil {
+				return fmt.Errorf("failed to re-point staged assignments: %w", err)
+			}
+		}
+
+		return s.exerciseRepo.Delete(txCtx, source.ID, trainerID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrExerciseNotFound
+		}
+		return 0, err
+	}
+	s.deleteAllExerciseMedia(ctx, sourceExerciseID, trainerID)
+
+	return moved, nil
+}
+
+// deleteAllExerciseMedia removes the media metadata and files of a deleted exercise.
+// Failures are only logged: the exercise is already gone and nothing references the files.
+func (s *exerciseService) deleteAllExerciseMedia(ctx context.Context, exerciseID, trainerID primitive.ObjectID) {
+	media, err := s.exerciseMediaRepo.GetByExerciseID(ctx, exerciseID)
+	if err != nil {
+		log.Printf("WARN: Failed to list media of deleted exercise %s: %v", exerciseID.Hex(), err)
+		return
+	}
+	for _, m := range media {
+		if err := s.exerciseMediaRepo.Delete(ctx, m.ID, trainerID); err != nil {
+			log.Printf("WARN: Failed to delete media %s of deleted exercise %s: %v", m.ID.Hex(), exerciseID.Hex(), err)
+			continue
+		}
+		if err := s.fileStorage.DeleteObject(ctx, m.S3ObjectKey); err != nil {
+			log.Printf("WARN: Failed to delete exercise media object %s: %v", m.S3ObjectKey, err)
+		}
+	}
+}
 
+// === Demonstration Media ===
+
+// exerciseMediaPrefix is the object key prefix under which all media for an exercise is stored.
+func exerciseMediaPrefix(trainerID, exerciseID primitive.ObjectID) string {
+	return path.Join("exercises", trainerID.Hex(), exerciseID.Hex()) + "/"
+}
+
+// isExerciseMediaKeyFor reports whether objectKey lies under the exercise's media prefix.
+func isExerciseMediaKeyFor(trainerID, exerciseID primitive.ObjectID, objectKey string) bool {
+	prefix := exerciseMediaPrefix(trainerID, exerciseID)
+	// path.Clean rejects keys such as "exercises/<trainer>/<exercise>/../<other>/x.mp4"
+	return path.Clean(objectKey) == objectKey && strings.HasPrefix(objectKey, prefix) && len(objectKey) > len(prefix)
+}
+
+// validateMediaContentType checks that the MIME type fits the requested media kind.
+func validateMediaContentType(kind domain.MediaKind, contentType string) error {
+	ct := strings.ToLower(contentType)
+	switch kind {
+	case domain.MediaKindVideo:
+		if !strings.HasPrefix(ct, "video/") {
+			return ErrInvalidMediaContentType
+		}
+	case domain.MediaKindImage, domain.MediaKindThumbnail:
+		if !strings.HasPrefix(ct, "image/") {
+			return ErrInvalidMediaContentType
+		}
+	default:
+		return ErrInvalidMediaKind
+	}
+	return nil
+}
+
+// getOwnedExercise fetches an exercise and verifies the trainer owns it.
+func (s *exerciseService) getOwnedExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (*domain.Exercise, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExerciseNotFound
+		}
+		return nil, err
+	}
+	if exercise.TrainerID != trainerID {
+		return nil, ErrExerciseAccessDenied
+	}
+	return exercise, nil
+}
+
+// RequestMediaUploadURL generates a pre-signed URL for a trainer to upload demonstration media for an exercise.
+func (s *exerciseService) RequestMediaUploadURL(ctx context.Context, trainerID, exerciseID primitive.ObjectID, kind domain.MediaKind, contentType string) (*UploadURLResponse, error) {
+	if trainerID == primitive.NilObjectID || exerciseID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and exercise ID are required")
+	}
+	if err := validateMediaContentType(kind, contentType); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedExercise(ctx, trainerID, exerciseID); err != nil {
+		return nil, err
+	}
+
+	fileExtension := ""
+	parts := strings.Split(contentType, "/")
+	if len(parts) == 2 { fileExtension = parts[1] }
+	objectKey := exerciseMediaPrefix(trainerID, exerciseID) + fmt.Sprintf("%s.%s", uuid.NewString(), fileExtension)
+
+	uploadURL, err := s.fileStorage.GeneratePresignedUploadURL(ctx, objectKey, contentType, storage.DefaultPresignedURLExpiry)
+	if err != nil {
+		return nil, ErrUploadURLError
+	}
+	return &UploadURLResponse{UploadURL: uploadURL, ObjectKey: objectKey}, nil
+}
+
+// ConfirmMediaUpload records the media metadata after the trainer uploaded the file using the pre-signed URL.
+func (s *exerciseService) ConfirmMediaUpload(ctx context.Context, trainerID, exerciseID primitive.ObjectID, kind domain.MediaKind, objectKey, fileName string, fileSize int64, contentType string, sequence int) (*domain.ExerciseMedia, error) {
+	if trainerID == primitive.NilObjectID || exerciseID == primitive.NilObjectID || objectKey == "" {
+		return nil, errors.New("trainer ID, exercise ID, and object key are required")
+	}
+	if err := validateMediaContentType(kind, contentType); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedExercise(ctx, trainerID, exerciseID); err != nil {
+		return nil, err
+	}
+	// The key must be one we handed out for this exercise, not an arbitrary object in the bucket.
+	if !isExerciseMediaKeyFor(trainerID, exerciseID, objectKey) {
+		return nil, ErrMediaObjectKeyMismatch
+	}
+	// Record what is really stored, not just what the trainer reports.
+	metadata, err := s.fileStorage.GetObjectMetadata(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrUploadObjectNotFound
+		}
+		return nil, fmt.Errorf("%w: could not read object metadata", ErrUploadConfirmationFailed)
+	}
+	if metadata.Size != fileSize || !sameMediaType(metadata.ContentType, contentType) {
+		return nil, fmt.Errorf("%w: stored %d bytes of %q", ErrUploadMetadataMismatch, metadata.Size, metadata.ContentType)
+	}
+
+	media := &domain.ExerciseMedia{
+		ExerciseID:  exerciseID,
+		TrainerID:   trainerID,
+		Kind:        kind,
+		S3ObjectKey: objectKey,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        fileSize,
+		Sequence:    sequence,
+		// ID, UploadedAt set by repository
+	}
+	mediaID, err := s.exerciseMediaRepo.Create(ctx, media)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrUploadAlreadyConfirmed
+		}
+		return nil, ErrUploadConfirmationFailed
+	}
+	media.ID = mediaID
+	return media, nil
+}
+
+// GetExerciseMedia lists an exercise's media with short-lived download URLs for its owner.
+func (s *exerciseService) GetExerciseMedia(ctx context.Context, trainerID, exerciseID primitive.ObjectID) ([]ExerciseMediaDetails, error) {
+	if trainerID == primitive.NilObjectID || exerciseID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and exercise ID are required")
+	}
+	if _, err := s.getOwnedExercise(ctx, trainerID, exerciseID); err != nil {
+		return nil, err
+	}
+	media, err := s.exerciseMediaRepo.GetByExerciseID(ctx, exerciseID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve exercise media")
+	}
+	return presignExerciseMedia(ctx, s.fileStorage, media)
+}
+
+// DeleteExerciseMedia removes a media item from storage and deletes its metadata.
+func (s *exerciseService) DeleteExerciseMedia(ctx context.Context, trainerID, exerciseID, mediaID primitive.ObjectID) error {
+	if trainerID == primitive.NilObjectID || exerciseID == primitive.NilObjectID || mediaID == primitive.NilObjectID {
+		return errors.New("trainer ID, exercise ID, and media ID are required")
+	}
+	media, err := s.exerciseMediaRepo.GetByID(ctx, mediaID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrExerciseMediaNotFound
+		}
+		return err
+	}
+	if media.ExerciseID != exerciseID {
+		return ErrExerciseMediaNotFound
+	}
+	if media.TrainerID != trainerID {
+		return ErrExerciseAccessDenied
+	}
+
+	if err := s.exerciseMediaRepo.Delete(ctx, mediaID, trainerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrExerciseMediaNotFound
+		}
+		return err
+	}
+	// Metadata is gone, so a failed object delete only leaves an unreferenced file behind.
+	if err := s.fileStorage.DeleteObject(ctx, media.S3ObjectKey); err != nil {
+		log.Printf("WARN: Failed to delete exercise media object %s: %v", media.S3ObjectKey, err)
+	}
 	return nil
 }
+
+// presignExerciseMedia attaches a short-lived download URL to each media item.
+func presignExerciseMedia(ctx context.Context, fileStorage storage.FileStorage, media []domain.ExerciseMedia) ([]ExerciseMediaDetails, error) {
+	details := make([]ExerciseMediaDetails, 0, len(media))
+	for _, m := range media {
+		downloadURL, err := fileStorage.GeneratePresignedDownloadURL(ctx, m.S3ObjectKey, storage.DefaultPresignedURLExpiry)
+		if err != nil {
+			return nil, ErrDownloadURLError
+		}
+		details = append(details, ExerciseMediaDetails{ExerciseMedia: m, DownloadURL: downloadURL})
+	}
+	return details, nil
+}
+
+// --- Revision History ---
+
+// GetExerciseRevisions lists all revisions of a trainer's exercise, newest first.
+func (s *exerciseService) GetExerciseRevisions(ctx context.Context, trainerID, exerciseID primitive.ObjectID) ([]domain.ExerciseRevision, error) {
+	exercise, err := s.getOwnedExercise(ctx, trainerID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if exercise.CurrentRevision == 0 {
+		// Never edited since before versioning: the current content is the only revision.
+		rev := domain.NewExerciseRevision(exercise, 1)
+		rev.CreatedAt = exercise.CreatedAt
+		return []domain.ExerciseRevision{*rev}, nil
+	}
+	return s.exerciseRevisionRepo.GetByExerciseID(ctx, exerciseID)
+}
+
+// GetExerciseRevision retrieves one revision of a trainer's exercise.
+func (s *exerciseService) GetExerciseRevision(ctx context.Context, trainerID, exerciseID primitive.ObjectID, revision int) (*domain.ExerciseRevision, error) {
+	exercise, err := s.getOwnedExercise(ctx, trainerID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadRevision(ctx, exercise, revision)
+}
+
+// DiffExerciseRevisions lists the fields that differ between two revisions of an exercise.
+func (s *exerciseService) DiffExerciseRevisions(ctx context.Context, trainerID, exerciseID primitive.ObjectID, fromRevision, toRevision int) ([]ExerciseFieldChange, error) {
+	exercise, err := s.getOwnedExercise(ctx, trainerID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := s.loadRevision(ctx, exercise, fromRevision)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.loadRevision(ctx, exercise, toRevision)
+	if err != nil {
+		return nil, err
+	}
+	return diffExerciseRevisions(from, to), nil
+}
+
+// UpgradeOpenAssignments moves assignments the client hasn't started yet to the
+// exercise's current revision. Returns the number of assignments upgraded.
+func (s *exerciseService) UpgradeOpenAssignments(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (int64, error) {
+	exercise, err := s.getOwnedExercise(ctx, trainerID, exerciseID)
+	if err != nil {
+		return 0, err
+	}
+	if exercise.CurrentRevision == 0 {
+		return 0, nil // Unversioned exercise: nothing to upgrade to
+	}
+	return s.upgradeOpenAssignments(ctx, exercise)
+}
+
+func (s *exerciseService) upgradeOpenAssignments(ctx context.Context, exercise *domain.Exercise) (int64, error) {
+	open := []domain.AssignmentStatus{domain.StatusAssigned}
+	count, err := s.assignmentRepo.UpgradeExerciseRevision(ctx, exercise.ID, exercise.CurrentRevision, open)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upgrade open assignments: %w", err)
+	}
+	return count, nil
+}
+
+// loadRevision fetches a revision, treating revision 1 of an unversioned exercise as its current content.
+func (s *exerciseService) loadRevision(ctx context.Context, exercise *domain.Exercise, revision int) (*domain.ExerciseRevision, error) {
+	if revision <= 0 {
+		return nil, ErrExerciseRevisionNotFound
+	}
+	if exercise.CurrentRevision == 0 {
+		if revision != 1 {
+			return nil, ErrExerciseRevisionNotFound
+		}
+		rev := domain.NewExerciseRevision(exercise, 1)
+		rev.CreatedAt = exercise.CreatedAt
+		return rev, nil
+	}
+	rev, err := s.exerciseRevisionRepo.GetByExerciseAndRevision(ctx, exercise.ID, revision)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExerciseRevisionNotFound
+		}
+		return nil, err
+	}
+	return rev, nil
+}
+
+// diffExerciseRevisions compares the client-facing fields of two revisions.
+func diffExerciseRevisions(from, to *domain.ExerciseRevision) []ExerciseFieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"description", from.Description, to.Description},
+		{"muscleGroup", from.MuscleGroup, to.MuscleGroup},
+		{"executionTechnic", from.ExecutionTechnic, to.ExecutionTechnic},
+		{"applicability", from.Applicability, to.Applicability},
+		{"difficulty", from.Difficulty, to.Difficulty},
+		{"videoUrl", from.VideoURL, to.VideoURL},
+	}
+
+	changes := []ExerciseFieldChange{}
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, ExerciseFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxFeedbackCommentLength caps the text of one comment, in characters.
+const maxFeedbackCommentLength = 2000
+
+var (
+	ErrFeedbackCommentNotFound     = errors.New("feedback comment not found")
+	ErrFeedbackCommentAccessDenied = errors.New("access denied to this feedback comment")
+	ErrUploadAccessDenied          = errors.New("access denied to this upload")
+	ErrInvalidFeedbackComment      = errors.New("invalid feedback comment")
+)
+
+// FeedbackCommentInput is the editable part of a FeedbackComment.
+type FeedbackCommentInput struct {
+	StartMs *int64 // Optional; nil means the comment is about the whole video
+	EndMs   *int64 // Optional; requires StartMs
+	Text    string
+}
+
+// FeedbackService manages trainers' timestamped comments on client uploads.
+// Trainers write and resolve comments; clients read them and may mark them resolved.
+type FeedbackService interface {
+	// Trainer
+	CreateComment(ctx context.Context, trainerID, uploadID primitive.ObjectID, input FeedbackCommentInput) (*domain.FeedbackComment, error)
+	GetCommentsForUpload(ctx context.Context, trainerID, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error)
+	UpdateComment(ctx context.Context, trainerID, commentID primitive.ObjectID, input FeedbackCommentInput) (*domain.FeedbackComment, error)
+	DeleteComment(ctx context.Context, trainerID, commentID primitive.ObjectID) error
+	SetCommentResolved(ctx context.Context, trainerID, commentID primitive.ObjectID, resolved bool) (*domain.FeedbackComment, error)
+
+	// Client
+	GetMyCommentsForUpload(ctx context.Context, clientID, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error)
+	SetMyCommentResolved(ctx context.Context, clientID, commentID primitive.ObjectID, resolved bool) (*domain.FeedbackComment, error)
+}
+
+type feedbackService struct {
+	uploadRepo          repository.UploadRepository
+	feedbackCommentRepo repository.FeedbackCommentRepository
+	events              realtime.Publisher
+}
+
+// NewFeedbackService creates a new FeedbackService.
+func NewFeedbackService(uploadRepo repository.UploadRepository, feedbackCommentRepo repository.FeedbackCommentRepository, events realtime.Publisher) FeedbackService {
+	return &feedbackService{
+		uploadRepo:          uploadRepo,
+		feedbackCommentRepo: feedbackCommentRepo,
+		events:              events,
+	}
+}
+
+// validate normalizes the text and checks the timestamp range.
+func (in *FeedbackCommentInput) validate() error {
+	in.Text = strings.TrimSpace(in.Text)
+	if in.Text == "" {
+		return fmt.Errorf("%w: text is required", ErrInvalidFeedbackComment)
+	}
+	if len([]rune(in.Text)) > maxFeedbackCommentLength {
+		return fmt.Errorf("%w: text is longer than %d characters", ErrInvalidFeedbackComment, maxFeedbackCommentLength)
+	}
+	if in.StartMs != nil && *in.StartMs < 0 {
+		return fmt.Errorf("%w: startMs cannot be negative", ErrInvalidFeedbackComment)
+	}
+	if in.EndMs != nil {
+		if in.StartMs == nil {
+			return fmt.Errorf("%w: endMs requires startMs", ErrInvalidFeedbackComment)
+		}
+		if *in.EndMs < *in.StartMs {
+			return fmt.Errorf("%w: endMs is before startMs", ErrInvalidFeedbackComment)
+		}
+	}
+	return nil
+}
+
+// getUpload loads an upload, mapping repository not-found to ErrUploadNotFound.
+func (s *feedbackService) getUpload(ctx context.Context, uploadID primitive.ObjectID) (*domain.Upload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, uploadID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// getComment loads a comment and checks it with owns (trainer or client ownership).
+func (s *feedbackService) getComment(ctx context.Context, commentID primitive.ObjectID, owns func(*domain.FeedbackComment) bool) (*domain.FeedbackComment, error) {
+	if commentID == primitive.NilObjectID {
+		return nil, errors.New("comment ID is required")
+	}
+	comment, err := s.feedbackCommentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFeedbackCommentNotFound
+		}
+		return nil, err
+	}
+	if !owns(comment) {
+		return nil, ErrFeedbackCommentAccessDenied
+	}
+	return comment, nil
+}
+
+// getTrainerComment loads a comment on one of the trainer's clients' uploads.
+func (s *feedbackService) getTrainerComment(ctx context.Context, trainerID, commentID primitive.ObjectID) (*domain.FeedbackComment, error) {
+	return s.getComment(ctx, commentID, func(c *domain.FeedbackComment) bool { return c.TrainerID == trainerID })
+}
+
+// getClientComment loads a comment on one of the client's uploads.
+func (s *feedbackService) getClientComment(ctx context.Context, clientID, commentID primitive.ObjectID) (*domain.FeedbackComment, error) {
+	return s.getComment(ctx, commentID, func(c *domain.FeedbackComment) bool { return c.ClientID == clientID })
+}
+
+// setResolved resolves or reopens a comment; repeating the current state is a no-op.
+func (s *feedbackService) setResolved(ctx context.Context, comment *domain.FeedbackComment, resolved bool) (*domain.FeedbackComment, error) {
+	if comment.IsResolved() == resolved {
+		return comment, nil
+	}
+	var resolvedAt *time.Time
+	if resolved {
+		now := time.Now().UTC()
+		resolvedAt = &now
+	}
+	if err := s.feedbackCommentRepo.SetResolved(ctx, comment.ID, resolvedAt); err != nil {
+		return nil, errors.New("failed to update feedback comment")
+	}
+	comment.ResolvedAt = resolvedAt
+	return comment, nil
+}
+
+// --- Trainer ---
+
+func (s *feedbackService) CreateComment(ctx context.Context, trainerID, uploadID primitive.ObjectID, input FeedbackCommentInput) (*domain.FeedbackComment, error) {
+	if trainerID == primitive.NilObjectID || uploadID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and upload ID are required")
+	}
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	upload, err := s.getUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.TrainerID != trainerID {
+		return nil, ErrUploadAccessDenied
+	}
+
+	comment := &domain.FeedbackComment{
+		UploadID:     upload.ID,
+		AssignmentID: upload.AssignmentID,
+		TrainerID:    trainerID,
+		ClientID:     upload.ClientID,
+		StartMs:      input.StartMs,
+		EndMs:        input.EndMs,
+		Text:         input.Text,
+	}
+	if _, err := s.feedbackCommentRepo.Create(ctx, comment); err != nil {
+		return nil, errors.New("failed to save feedback comment")
+	}
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventFeedback, trainerID, "Your trainer commented on your video", map[string]any{
+		"assignmentId": comment.AssignmentID,
+		"uploadId":     comment.UploadID,
+		"commentId":    comment.ID,
+	}), comment.ClientID)
+	return comment, nil
+}
+
+func (s *feedbackService) GetCommentsForUpload(ctx context.Context, trainerID, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error) {
+	if trainerID == primitive.NilObjectID || uploadID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and upload ID are required")
+	}
+	upload, err := s.getUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.TrainerID != trainerID {
+		return nil, ErrUploadAccessDenied
+	}
+	return s.listComments(ctx, uploadID)
+}
+
+func (s *feedbackService) UpdateComment(ctx context.Context, trainerID, commentID primitive.ObjectID, input FeedbackCommentInput) (*domain.FeedbackComment, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	comment, err := s.getTrainerComment(ctx, trainerID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.StartMs = input.StartMs
+	comment.EndMs = input.EndMs
+	comment.Text = input.Text
+	if err := s.feedbackCommentRepo.Update(ctx, comment); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFeedbackCommentNotFound
+		}
+		return nil, errors.New("failed to update feedback comment")
+	}
+	comment.UpdatedAt = time.Now().UTC()
+	return comment, nil
+}
+
+func (s *feedbackService) DeleteComment(ctx context.Context, trainerID, commentID primitive.ObjectID) error {
+	if _, err := s.getTrainerComment(ctx, trainerID, commentID); err != nil {
+		return err
+	}
+	if err := s.feedbackCommentRepo.Delete(ctx, commentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrFeedbackCommentNotFound
+		}
+		return errors.New("failed to delete feedback comment")
+	}
+	return nil
+}
+
+func (s *feedbackService) SetCommentResolved(ctx context.Context, trainerID, commentID primitive.ObjectID, resolved bool) (*domain.FeedbackComment, error) {
+	comment, err := s.getTrainerComment(ctx, trainerID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setResolved(ctx, comment, resolved)
+}
+
+// --- Client ---
+
+func (s *feedbackService) GetMyCommentsForUpload(ctx context.Context, clientID, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error) {
+	if clientID == primitive.NilObjectID || uploadID == primitive.NilObjectID {
+		return nil, errors.New("client ID and upload ID are required")
+	}
+	upload, err := s.getUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.ClientID != clientID {
+		return nil, ErrUploadAccessDenied
+	}
+	return s.listComments(ctx, uploadID)
+}
+
+func (s *feedbackService) SetMyCommentResolved(ctx context.Context, clientID, commentID primitive.ObjectID, resolved bool) (*domain.FeedbackComment, error) {
+	comment, err := s.getClientComment(ctx, clientID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setResolved(ctx, comment, resolved)
+}
+
+// listComments returns an upload's comments, never nil.
+func (s *feedbackService) listComments(ctx context.Context, uploadID primitive.ObjectID) ([]domain.FeedbackComment, error) {
+	comments, err := s.feedbackCommentRepo.GetByUploadID(ctx, uploadID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve feedback comments")
+	}
+	if comments == nil {
+		comments = []domain.FeedbackComment{}
+	}
+	return comments, nil
+}
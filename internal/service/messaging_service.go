@@ -0,0 +1,350 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxMessageLength       = 4000 // Characters
+	messagePreviewLength   = 120  // Characters kept on the conversation for inbox lists
+	defaultMessagePageSize = 30
+	maxMessagePageSize     = 100
+)
+
+var (
+	ErrConversationNotFound     = errors.New("conversation not found")
+	ErrConversationAccessDenied = errors.New("access denied to this conversation")
+	ErrConversationClosed       = errors.New("client is no longer managed by this trainer")
+	ErrNoTrainerAssigned        = errors.New("client has no trainer")
+	ErrInvalidMessage           = errors.New("invalid message")
+	ErrInvalidMessageAnchor     = errors.New("message anchor must be a plan, workout or assignment of this client")
+)
+
+// ConversationSummary is a conversation as listed in a user's inbox.
+type ConversationSummary struct {
+	Conversation     domain.Conversation
+	ParticipantName  string // The other participant
+	ParticipantEmail string
+	UnreadCount      int64 // Messages the requesting user has not read
+}
+
+// MessageQuery selects one page of a conversation's history.
+type MessageQuery struct {
+	Anchor   *domain.MessageAnchor // Nil for the whole conversation
+	BeforeID *primitive.ObjectID   // Cursor: the oldest message of the previous page
+	Limit    int64                 // 0 = default page size
+}
+
+// MessagePage is one page of messages, newest first.
+type MessagePage struct {
+	Messages     []domain.Message
+	NextBeforeID *primitive.ObjectID // Pass as BeforeID for older messages; nil on the last page
+}
+
+// MessagingService manages trainer–client conversations. Conversations are addressed
+// by ID from both sides; every method checks the user takes part in the conversation.
+type MessagingService interface {
+	StartTrainerConversation(ctx context.Context, trainerID, clientID primitive.ObjectID) (*ConversationSummary, error)
+	StartClientConversation(ctx context.Context, clientID primitive.ObjectID) (*ConversationSummary, error) // With the client's current trainer
+	ListConversations(ctx context.Context, userID primitive.ObjectID) ([]ConversationSummary, error)
+	GetMessages(ctx context.Context, userID, conversationID primitive.ObjectID, query MessageQuery) (*MessagePage, error)
+	GetThreads(ctx context.Context, userID, conversationID primitive.ObjectID) ([]domain.MessageThread, error)
+	SendMessage(ctx context.Context, userID, conversationID primitive.ObjectID, body string, anchor *domain.MessageAnchor) (*domain.Message, error)
+	MarkRead(ctx context.Context, userID, conversationID primitive.ObjectID, anchor *domain.MessageAnchor) (int64, error) // nil anchor = whole conversation
+	GetUnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+type messagingService struct {
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	userRepo         repository.UserRepository
+	trainingPlanRepo repository.TrainingPlanRepository
+	workoutRepo      repository.WorkoutRepository
+	assignmentRepo   repository.AssignmentRepository
+	events           realtime.Publisher
+}
+
+// NewMessagingService creates a new MessagingService.
+func NewMessagingService(
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	userRepo repository.UserRepository,
+	trainingPlanRepo repository.TrainingPlanRepository,
+	workoutRepo repository.WorkoutRepository,
+	assignmentRepo repository.AssignmentRepository,
+	events realtime.Publisher,
+) MessagingService {
+	return &messagingService{
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		userRepo:         userRepo,
+		trainingPlanRepo: trainingPlanRepo,
+		workoutRepo:      workoutRepo,
+		assignmentRepo:   assignmentRepo,
+		events:           events,
+	}
+}
+
+// getConversation loads a conversation and checks userID takes part in it.
+func (s *messagingService) getConversation(ctx context.Context, userID, conversationID primitive.ObjectID) (*domain.Conversation, error) {
+	if userID == primitive.NilObjectID || conversationID == primitive.NilObjectID {
+		return nil, errors.New("user ID and conversation ID are required")
+	}
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	if !conversation.HasParticipant(userID) {
+		return nil, ErrConversationAccessDenied
+	}
+	return conversation, nil
+}
+
+// summarize builds userID's inbox entry for a conversation.
+func (s *messagingService) summarize(ctx context.Context, userID primitive.ObjectID, conversation *domain.Conversation, unread int64) ConversationSummary {
+	summary := ConversationSummary{Conversation: *conversation, UnreadCount: unread}
+	if other, err := s.userRepo.GetByID(ctx, conversation.OtherParticipant(userID)); err == nil {
+		summary.ParticipantName = other.Name
+		summary.ParticipantEmail = other.Email
+	}
+	return summary
+}
+
+func (s *messagingService) StartTrainerConversation(ctx context.Context, trainerID, clientID primitive.ObjectID) (*ConversationSummary, error) {
+	if trainerID == primitive.NilObjectID || clientID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and client ID are required")
+	}
+	client, err := s.userRepo.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	if client.TrainerID == nil || *client.TrainerID != trainerID {
+		return nil, ErrClientNotManaged
+	}
+	return s.start(ctx, trainerID, trainerID, clientID)
+}
+
+func (s *messagingService) StartClientConversation(ctx context.Context, clientID primitive.ObjectID) (*ConversationSummary, error) {
+	if clientID == primitive.NilObjectID {
+		return nil, errors.New("client ID is required")
+	}
+	client, err := s.userRepo.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	if client.TrainerID == nil {
+		return nil, ErrNoTrainerAssigned
+	}
+	return s.start(ctx, clientID, *client.TrainerID, clientID)
+}
+
+// start gets or creates the pair's conversation and summarizes it for userID.
+func (s *messagingService) start(ctx context.Context, userID, trainerID, clientID primitive.ObjectID) (*ConversationSummary, error) {
+	conversation, err := s.conversationRepo.GetOrCreate(ctx, trainerID, clientID)
+	if err != nil {
+		return nil, errors.New("failed to open conversation")
+	}
+	counts, err := s.messageRepo.CountUnreadByConversation(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to count unread messages")
+	}
+	summary := s.summarize(ctx, userID, conversation, counts[conversation.ID])
+	return &summary, nil
+}
+
+func (s *messagingService) ListConversations(ctx context.Context, userID primitive.ObjectID) ([]ConversationSummary, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	conversations, err := s.conversationRepo.GetByParticipant(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve conversations")
+	}
+	counts, err := s.messageRepo.CountUnreadByConversation(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to count unread messages")
+	}
+	summaries := make([]ConversationSummary, 0, len(conversations))
+	for i := range conversations {
+		summaries = append(summaries, s.summarize(ctx, userID, &conversations[i], counts[conversations[i].ID]))
+	}
+	return summaries, nil
+}
+
+func (s *messagingService) GetMessages(ctx context.Context, userID, conversationID primitive.ObjectID, query MessageQuery) (*MessagePage, error) {
+	if query.Anchor != nil && !query.Anchor.Type.IsValid() {
+		return nil, ErrInvalidMessageAnchor
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMessagePageSize
+	}
+	if limit > maxMessagePageSize {
+		limit = maxMessagePageSize
+	}
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra to learn whether an older page exists.
+	messages, err := s.messageRepo.List(ctx, conversationID, query.Anchor, query.BeforeID, limit+1)
+	if err != nil {
+		return nil, errors.New("failed to retrieve messages")
+	}
+	page := &MessagePage{Messages: messages}
+	if int64(len(messages)) > limit {
+		page.Messages = messages[:limit]
+		next := page.Messages[limit-1].ID
+		page.NextBeforeID = &next
+	}
+	if page.Messages == nil {
+		page.Messages = []domain.Message{}
+	}
+	return page, nil
+}
+
+func (s *messagingService) GetThreads(ctx context.Context, userID, conversationID primitive.ObjectID) ([]domain.MessageThread, error) {
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+	threads, err := s.messageRepo.GetThreads(ctx, conversationID, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve threads")
+	}
+	if threads == nil {
+		threads = []domain.MessageThread{}
+	}
+	return threads, nil
+}
+
+func (s *messagingService) SendMessage(ctx context.Context, userID, conversationID primitive.ObjectID, body string, anchor *domain.MessageAnchor) (*domain.Message, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidMessage)
+	}
+	if len([]rune(body)) > maxMessageLength {
+		return nil, fmt.Errorf("%w: body is longer than %d characters", ErrInvalidMessage, maxMessageLength)
+	}
+	conversation, err := s.getConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// History stays readable after a client leaves, but only a current pair can write.
+	client, err := s.userRepo.GetByID(ctx, conversation.ClientID)
+	if err != nil || client.TrainerID == nil || *client.TrainerID != conversation.TrainerID {
+		return nil, ErrConversationClosed
+	}
+	if anchor != nil {
+		if err := s.checkAnchor(ctx, conversation, anchor); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := &domain.Message{
+		ConversationID: conversation.ID,
+		SenderID:       userID,
+		RecipientID:    conversation.OtherParticipant(userID),
+		Anchor:         anchor,
+		Body:           body,
+	}
+	if _, err := s.messageRepo.Create(ctx, msg); err != nil {
+		return nil, errors.New("failed to save message")
+	}
+	preview := body
+	if runes := []rune(body); len(runes) > messagePreviewLength {
+		preview = string(runes[:messagePreviewLength]) + "…"
+	}
+	if err := s.conversationRepo.SetLastMessage(ctx, conversation.ID, msg, preview); err != nil {
+		// The message is saved; a stale inbox preview is not worth failing the send.
+		// log.Printf("WARN: Failed to update conversation %s after message %s: %v", conversation.ID.Hex(), msg.ID.Hex(), err)
+	}
+
+	data := map[string]any{"conversationId": msg.ConversationID, "messageId": msg.ID, "senderId": msg.SenderID}
+	if msg.Anchor != nil {
+		data["anchorType"] = msg.Anchor.Type
+		data["anchorId"] = msg.Anchor.ID
+	}
+	// The sender's other devices update too.
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventMessage, userID, "New message: "+preview, data), msg.RecipientID, msg.SenderID)
+	return msg, nil
+}
+
+// checkAnchor verifies the anchored plan, workout or assignment belongs to the conversation's pair.
+func (s *messagingService) checkAnchor(ctx context.Context, conversation *domain.Conversation, anchor *domain.MessageAnchor) error {
+	if !anchor.Type.IsValid() || anchor.ID == primitive.NilObjectID {
+		return ErrInvalidMessageAnchor
+	}
+	var trainerID, clientID primitive.ObjectID
+	switch anchor.Type {
+	case domain.AnchorPlan:
+		plan, err := s.trainingPlanRepo.GetByID(ctx, anchor.ID)
+		if err != nil {
+			return ErrInvalidMessageAnchor
+		}
+		trainerID, clientID = plan.TrainerID, plan.ClientID
+	case domain.AnchorWorkout:
+		workout, err := s.workoutRepo.GetByID(ctx, anchor.ID)
+		if err != nil {
+			return ErrInvalidMessageAnchor
+		}
+		trainerID, clientID = workout.TrainerID, workout.ClientID
+	case domain.AnchorAssignment:
+		assignment, err := s.assignmentRepo.GetByID(ctx, anchor.ID)
+		if err != nil {
+			return ErrInvalidMessageAnchor
+		}
+		workout, err := s.workoutRepo.GetByID(ctx, assignment.WorkoutID)
+		if err != nil {
+			return ErrInvalidMessageAnchor
+		}
+		trainerID, clientID = workout.TrainerID, workout.ClientID
+	}
+	if trainerID != conversation.TrainerID || clientID != conversation.ClientID {
+		return ErrInvalidMessageAnchor
+	}
+	return nil
+}
+
+func (s *messagingService) MarkRead(ctx context.Context, userID, conversationID primitive.ObjectID, anchor *domain.MessageAnchor) (int64, error) {
+	if anchor != nil && !anchor.Type.IsValid() {
+		return 0, ErrInvalidMessageAnchor
+	}
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return 0, err
+	}
+	marked, err := s.messageRepo.MarkRead(ctx, conversationID, userID, anchor, time.Now().UTC())
+	if err != nil {
+		return 0, errors.New("failed to mark messages read")
+	}
+	return marked, nil
+}
+
+func (s *messagingService) GetUnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	if userID == primitive.NilObjectID {
+		return 0, errors.New("user ID is required")
+	}
+	count, err := s.messageRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, errors.New("failed to count unread messages")
+	}
+	return count, nil
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNotificationPageSize = 30
+	maxNotificationPageSize     = 100
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrInvalidNotificationType = errors.New("unknown notification type")
+)
+
+// NotificationQuery selects one page of a user's notifications.
+type NotificationQuery struct {
+	UnreadOnly bool
+	BeforeID   *primitive.ObjectID // Cursor: the oldest notification of the previous page
+	Limit      int64               // 0 = default page size
+}
+
+// NotificationPage is one page of notifications, newest first.
+type NotificationPage struct {
+	Notifications []domain.Notification
+	NextBeforeID  *primitive.ObjectID // nil on the last page
+}
+
+// NotificationService is the notification center. It is also the realtime.Publisher
+// the other services publish to: each event is passed on to the realtime broker, then
+// stored as a notification for every recipient who wants that type (never for the
+// user who caused it) and pushed to those recipients' devices.
+type NotificationService interface {
+	realtime.Publisher
+
+	List(ctx context.Context, userID primitive.ObjectID, query NotificationQuery) (*NotificationPage, error)
+	MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) error
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	GetUnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error)
+
+	// Preferences: every event type with whether the user wants it
+	GetPreferences(ctx context.Context, userID primitive.ObjectID) (map[realtime.EventType]bool, error)
+	UpdatePreferences(ctx context.Context, userID primitive.ObjectID, changes map[realtime.EventType]bool) (map[realtime.EventType]bool, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	preferencesRepo  repository.NotificationPreferencesRepository
+	next             realtime.Publisher
+	push             realtime.Publisher
+}
+
+// NewNotificationService creates a NotificationService that forwards events to next
+// and sends stored notifications on to push (nil disables push).
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	preferencesRepo repository.NotificationPreferencesRepository,
+	next realtime.Publisher,
+	push realtime.Publisher,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		preferencesRepo:  preferencesRepo,
+		next:             next,
+		push:             push,
+	}
+}
+
+// Publish forwards the event, records it in each recipient's notification center and
+// pushes it to the same recipients.
+// Failures are logged, not returned: the action that raised the event already succeeded.
+func (s *notificationService) Publish(ctx context.Context, event realtime.Event, recipients ...primitive.ObjectID) {
+	s.next.Publish(ctx, event, recipients...)
+
+	seen := make(map[primitive.ObjectID]bool, len(recipients))
+	var notified []primitive.ObjectID
+	for _, userID := range recipients {
+		if userID == primitive.NilObjectID || userID == event.ActorID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		prefs, err := s.getPreferences(ctx, userID)
+		if err != nil {
+			log.Printf("WARN: Failed to load notification preferences for %s: %v", userID.Hex(), err)
+			continue
+		}
+		if !prefs.Wants(string(event.Type)) {
+			continue
+		}
+		notification := &domain.Notification{
+			UserID: userID,
+			Type:   string(event.Type),
+			Title:  event.Summary,
+			Data:   event.Data,
+		}
+		if event.ActorID != primitive.NilObjectID {
+			actorID := event.ActorID
+			notification.ActorID = &actorID
+		}
+		if _, err := s.notificationRepo.Create(ctx, notification); err != nil {
+			log.Printf("WARN: Failed to save %s notification for %s: %v", event.Type, userID.Hex(), err)
+		}
+		notified = append(notified, userID)
+	}
+	if s.push != nil && len(notified) > 0 {
+		s.push.Publish(ctx, event, notified...)
+	}
+}
+
+// getPreferences loads a user's preferences; users who never changed them get everything.
+func (s *notificationService) getPreferences(ctx context.Context, userID primitive.ObjectID) (*domain.NotificationPreferences, error) {
+	prefs, err := s.preferencesRepo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &domain.NotificationPreferences{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (s *notificationService) List(ctx context.Context, userID primitive.ObjectID, query NotificationQuery) (*NotificationPage, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultNotificationPageSize
+	}
+	if limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+
+	// Fetch one extra to learn whether an older page exists.
+	notifications, err := s.notificationRepo.List(ctx, userID, query.UnreadOnly, query.BeforeID, limit+1)
+	if err != nil {
+		return nil, errors.New("failed to retrieve notifications")
+	}
+	page := &NotificationPage{Notifications: notifications}
+	if int64(len(notifications)) > limit {
+		page.Notifications = notifications[:limit]
+		next := page.Notifications[limit-1].ID
+		page.NextBeforeID = &next
+	}
+	if page.Notifications == nil {
+		page.Notifications = []domain.Notification{}
+	}
+	return page, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) error {
+	if userID == primitive.NilObjectID || notificationID == primitive.NilObjectID {
+		return errors.New("user ID and notification ID are required")
+	}
+	if err := s.notificationRepo.MarkRead(ctx, userID, notificationID, time.Now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotificationNotFound
+		}
+		return errors.New("failed to mark notification read")
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	if userID == primitive.NilObjectID {
+		return 0, errors.New("user ID is required")
+	}
+	marked, err := s.notificationRepo.MarkAllRead(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return 0, errors.New("failed to mark notifications read")
+	}
+	return marked, nil
+}
+
+func (s *notificationService) GetUnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	if userID == primitive.NilObjectID {
+		return 0, errors.New("user ID is required")
+	}
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, errors.New("failed to count unread notifications")
+	}
+	return count, nil
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID primitive.ObjectID) (map[realtime.EventType]bool, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	prefs, err := s.getPreferences(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to load notification preferences")
+	}
+	return preferenceMap(prefs), nil
+}
+
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID primitive.ObjectID, changes map[realtime.EventType]bool) (map[realtime.EventType]bool, error) {
+	if userID == primitive.NilObjectID {
+		return nil, errors.New("user ID is required")
+	}
+	for eventType := range changes {
+		if !eventType.IsValid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidNotificationType, eventType)
+		}
+	}
+	prefs, err := s.getPreferences(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to load notification preferences")
+	}
+
+	current := preferenceMap(prefs)
+	for eventType, wanted := range changes {
+		current[eventType] = wanted
+	}
+	prefs.Muted = []string{}
+	for _, eventType := range realtime.EventTypes {
+		if !current[eventType] {
+			prefs.Muted = append(prefs.Muted, string(eventType))
+		}
+	}
+	if err := s.preferencesRepo.Upsert(ctx, prefs); err != nil {
+		return nil, errors.New("failed to save notification preferences")
+	}
+	return current, nil
+}
+
+// preferenceMap expands stored preferences to every known event type.
+func preferenceMap(prefs *domain.NotificationPreferences) map[realtime.EventType]bool {
+	m := make(map[realtime.EventType]bool, len(realtime.EventTypes))
+	for _, eventType := range realtime.EventTypes {
+		m[eventType] = prefs.Wants(string(eventType))
+	}
+	return m
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Changes to published plans (edited, added, deleted and reordered workouts, blocks and
+// exercises) are staged in a domain.PlanDraft. The trainer sees them overlaid on the
+// published plan; the client sees them once the plan is published again.
+
+// applyWorkoutEdits copies the fields UpdateWorkout changes from src to dst.
+func applyWorkoutEdits(dst *domain.Workout, src *domain.Workout) {
+	dst.Name = src.Name
+	dst.DayOfWeek = src.DayOfWeek
+	dst.Notes = src.Notes
+	dst.Sequence = src.Sequence
+}
+
+// applyAssignmentEdits copies the exercise details UpdateAssignmentInWorkout changes
+// from src to dst. The client's progress on dst (status, logs, uploads) is kept.
+func applyAssignmentEdits(dst *domain.Assignment, src *domain.Assignment) {
+	dst.ExerciseID = src.ExerciseID
+	dst.ExerciseRevision = src.ExerciseRevision
+	dst.RevisionExerciseID = src.RevisionExerciseID
+	dst.Sets = src.Sets
+	dst.Reps = src.Reps
+	dst.Rest = src.Rest
+	dst.Tempo = src.Tempo
+	dst.Weight = src.Weight
+	dst.Duration = src.Duration
+	dst.Sequence = src.Sequence
+	dst.TrainerNotes = src.TrainerNotes
+	dst.BlockID = src.BlockID
+}
+
+// applyBlockEdits copies the fields UpdateWorkoutBlock changes from src to dst.
+func applyBlockEdits(dst *domain.WorkoutBlock, src *domain.WorkoutBlock) {
+	dst.Label = src.Label
+	dst.Type = src.Type
+	dst.Rounds = src.Rounds
+	dst.RestBetweenRounds = src.RestBetweenRounds
+	dst.TimeCap = src.TimeCap
+	dst.Sequence = src.Sequence
+	dst.Notes = src.Notes
+}
+
+// overlayWorkouts returns a plan's workouts as the draft leaves them (edited, without
+// removed ones, with added ones), ordered by sequence.
+func overlayWorkouts(workouts []domain.Workout, draft *domain.PlanDraft) []domain.Workout {
+	if draft.IsEmpty() {
+		return workouts
+	}
+	result := make([]domain.Workout, 0, len(workouts))
+	for _, w := range workouts {
+		if draft.IsRemoved(w.ID) {
+			continue
+		}
+		if staged, ok := draft.Workout(w.ID); ok {
+			applyWorkoutEdits(&w, staged)
+		}
+		result = append(result, w)
+	}
+	for _, id := range draft.Added {
+		if staged, ok := draft.Workout(id); ok {
+			result = append(result, *staged)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Sequence < result[j].Sequence })
+	return result
+}
+
+// overlayAssignments returns a workout's assignments as the draft leaves them, ordered by
+// sequence.
+func overlayAssignments(workoutID primitive.ObjectID, assignments []domain.Assignment, draft *domain.PlanDraft) []domain.Assignment {
+	if draft.IsEmpty() {
+		return assignments
+	}
+	result := make([]domain.Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		if draft.IsRemoved(a.ID) {
+			continue
+		}
+		if staged, ok := draft.Assignment(a.ID); ok {
+			applyAssignmentEdits(&a, staged)
+		}
+		result = append(result, a)
+	}
+	for _, id := range draft.Added {
+		if staged, ok := draft.Assignment(id); ok && staged.WorkoutID == workoutID {
+			result = append(result, *staged)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Sequence < result[j].Sequence })
+	return result
+}
+
+// overlayBlocks returns a workout's blocks as the draft leaves them, ordered by sequence.
+func overlayBlocks(workoutID primitive.ObjectID, blocks []domain.WorkoutBlock, draft *domain.PlanDraft) []domain.WorkoutBlock {
+	if draft.IsEmpty() {
+		return blocks
+	}
+	result := make([]domain.WorkoutBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if draft.IsRemoved(b.ID) {
+			continue
+		}
+		if staged, ok := draft.Block(b.ID); ok {
+			applyBlockEdits(&b, staged)
+		}
+		result = append(result, b)
+	}
+	for _, id := range draft.Added {
+		if staged, ok := draft.Block(id); ok && staged.WorkoutID == workoutID {
+			result = append(result, *staged)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Sequence < result[j].Sequence })
+	return result
+}
+
+// draftRemoval is the draft edit deleting item id: a published item is removed on the next
+// publish, one added in the draft is just dropped from it.
+func draftRemoval(draft *domain.PlanDraft, id primitive.ObjectID) domain.PlanDraftEdit {
+	edit := domain.PlanDraftEdit{Dropped: []primitive.ObjectID{id}}
+	if !draft.IsAdded(id) {
+		edit.Removed = []primitive.ObjectID{id}
+	}
+	return edit
+}
+
+// workoutRemoval is draftRemoval for a workout, also dropping what is staged for its
+// blocks and exercises.
+func workoutRemoval(draft *domain.PlanDraft, workoutID primitive.ObjectID) domain.PlanDraftEdit {
+	edit := draftRemoval(draft, workoutID)
+	if draft == nil {
+		return edit
+	}
+	for _, a := range draft.Assignments {
+		if a.WorkoutID == workoutID {
+			edit.Dropped = append(edit.Dropped, a.ID)
+		}
+	}
+	for _, b := range draft.Blocks {
+		if b.WorkoutID == workoutID {
+			edit.Dropped = append(edit.Dropped, b.ID)
+		}
+	}
+	return edit
+}
+
+// applicableDraft returns the part of draft that publishing applies to content: staged
+// edits of items deleted since, and items of workouts no longer in the plan, are left out.
+// An exercise that no longer exists (is not in exerciseNames) is not swapped in; an
+// assignment adding one is left out.
+func applicableDraft(content *planContent, draft *domain.PlanDraft, exerciseNames map[primitive.ObjectID]string) *domain.PlanDraft {
+	if draft.IsEmpty() {
+		return draft
+	}
+	liveWorkouts := make(map[primitive.ObjectID]bool, len(content.workouts))
+	liveAssignments := make(map[primitive.ObjectID]domain.Assignment)
+	liveBlocks := make(map[primitive.ObjectID]bool)
+	for _, w := range content.workouts {
+		liveWorkouts[w.ID] = true
+		for _, a := range content.assignments[w.ID] {
+			liveAssignments[a.ID] = a
+		}
+		for _, b := range content.blocks[w.ID] {
+			liveBlocks[b.ID] = true
+		}
+	}
+
+	result := &domain.PlanDraft{
+		PlanID:      draft.PlanID,
+		TrainerID:   draft.TrainerID,
+		Workouts:    make(map[string]domain.Workout),
+		Assignments: make(map[string]domain.Assignment),
+		Blocks:      make(map[string]domain.WorkoutBlock),
+		Added:       draft.Added, // IsAdded only counts the ones kept below
+		CreatedAt:   draft.CreatedAt,
+		UpdatedAt:   draft.UpdatedAt,
+	}
+	for key, w := range draft.Workouts {
+		if liveWorkouts[w.ID] && !draft.IsRemoved(w.ID) || draft.IsAdded(w.ID) {
+			result.Workouts[key] = w
+		}
+	}
+	for _, id := range draft.Removed {
+		if _, assignment := liveAssignments[id]; liveWorkouts[id] || assignment || liveBlocks[id] {
+			result.Removed = append(result.Removed, id)
+		}
+	}
+	inPlan := func(workoutID primitive.ObjectID) bool {
+		return (liveWorkouts[workoutID] || result.IsAdded(workoutID)) && !result.IsRemoved(workoutID)
+	}
+	for key, a := range draft.Assignments {
+		live, published := liveAssignments[a.ID]
+		if !inPlan(a.WorkoutID) || !published && !draft.IsAdded(a.ID) {
+			continue
+		}
+		if _, ok := exerciseNames[a.ExerciseID]; !ok {
+			if !published {
+				continue
+			}
+			a.ExerciseID, a.ExerciseRevision, a.RevisionExerciseID = live.ExerciseID, live.ExerciseRevision, live.RevisionExerciseID
+		}
+		result.Assignments[key] = a
+	}
+	for key, b := range draft.Blocks {
+		if inPlan(b.WorkoutID) && (liveBlocks[b.ID] || draft.IsAdded(b.ID)) {
+			result.Blocks[key] = b
+		}
+	}
+	return result
+}
+
+// PlanChange is one staged change, described for the client.
+type PlanChange struct {
+	WorkoutID    *primitive.ObjectID `json:"workoutId,omitempty"` // Unset for changes to the whole plan
+	AssignmentID *primitive.ObjectID `json:"assignmentId,omitempty"`
+	BlockID      *primitive.ObjectID `json:"blockId,omitempty"`
+	Field        string              `json:"field"` // e.g. name, dayOfWeek, sets, exercise; added, removed or order
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	Summary      string              `json:"summary"` // e.g. `Bench Press: sets 3 → 4`
+}
+
+// describePlanChanges lists what the draft changes compared with the published plan, in
+// plan order: added and removed workouts, blocks and exercises, edited fields, and new
+// orders. Edits that change nothing are left out; draft should be what publishing applies
+// (see applicableDraft). exerciseNames maps exercise IDs to names.
+func describePlanChanges(content *planContent, draft *domain.PlanDraft, exerciseNames map[primitive.ObjectID]string) []PlanChange {
+	changes := []PlanChange{}
+	if draft.IsEmpty() {
+		return changes
+	}
+	workouts := overlayWorkouts(content.workouts, draft)
+	if reordered(workoutIDs(content.workouts), workoutIDs(workouts)) {
+		changes = append(changes, PlanChange{Field: "order", Summary: "Workouts reordered"})
+	}
+
+	for _, live := range content.workouts {
+		workoutID := live.ID
+		label := live.Name
+		if draft.IsRemoved(live.ID) {
+			changes = append(changes, PlanChange{WorkoutID: &workoutID, Field: "removed", From: live.Name, To: "none", Summary: live.Name + ": removed"})
+			continue
+		}
+		if staged, ok := draft.Workout(live.ID); ok {
+			add := func(field, from, to string) {
+				if from != to {
+					changes = append(changes, PlanChange{WorkoutID: &workoutID, Field: field, From: from, To: to, Summary: changeSummary(label, field, from, to)})
+				}
+			}
+			add("name", live.Name, staged.Name)
+			add("dayOfWeek", formatDayOfWeek(live.DayOfWeek), formatDayOfWeek(staged.DayOfWeek))
+			add("notes", orNone(live.Notes), orNone(staged.Notes))
+			label = staged.Name
+		}
+
+		blocks := overlayBlocks(live.ID, content.blocks[live.ID], draft)
+		for _, b := range content.blocks[live.ID] {
+			blockID := b.ID
+			what := label + " – " + blockName(&b)
+			if draft.IsRemoved(b.ID) {
+				changes = append(changes, PlanChange{WorkoutID: &workoutID, BlockID: &blockID, Field: "removed", From: blockName(&b), To: "none", Summary: what + ": removed"})
+				continue
+			}
+			staged, ok := draft.Block(b.ID)
+			if !ok {
+				continue
+			}
+			add := func(field, from, to string) {
+				if from != to {
+					changes = append(changes, PlanChange{WorkoutID: &workoutID, BlockID: &blockID, Field: field, From: from, To: to, Summary: changeSummary(what, field, from, to)})
+				}
+			}
+			add("label", orNone(b.Label), orNone(staged.Label))
+			add("type", string(b.Type), string(staged.Type))
+			add("rounds", strconv.Itoa(b.Rounds), strconv.Itoa(staged.Rounds))
+			add("restBetweenRounds", formatOptional(b.RestBetweenRounds), formatOptional(staged.RestBetweenRounds))
+			add("timeCap", formatOptional(b.TimeCap), formatOptional(staged.TimeCap))
+			add("notes", orNone(b.Notes), orNone(staged.Notes))
+		}
+		for _, b := range blocks {
+			if draft.IsAdded(b.ID) {
+				blockID := b.ID
+				changes = append(changes, PlanChange{WorkoutID: &workoutID, BlockID: &blockID, Field: "added", From: "none", To: blockName(&b), Summary: label + " – " + blockName(&b) + ": added"})
+			}
+		}
+
+		assignments := overlayAssignments(live.ID, content.assignments[live.ID], draft)
+		for _, a := range content.assignments[live.ID] {
+			assignmentID := a.ID
+			exercise := exerciseNames[a.ExerciseID]
+			if draft.IsRemoved(a.ID) {
+				changes = append(changes, PlanChange{WorkoutID: &workoutID, AssignmentID: &assignmentID, Field: "removed", From: exercise, To: "none", Summary: label + " – " + exercise + ": removed"})
+				continue
+			}
+			staged, ok := draft.Assignment(a.ID)
+			if !ok {
+				continue
+			}
+			add := func(field, from, to string) {
+				if from != to {
+					changes = append(changes, PlanChange{WorkoutID: &workoutID, AssignmentID: &assignmentID, Field: field, From: from, To: to, Summary: changeSummary(label+" – "+exercise, field, from, to)})
+				}
+			}
+			if staged.ExerciseID != a.ExerciseID {
+				add("exercise", exercise, exerciseNames[staged.ExerciseID])
+				exercise = exerciseNames[staged.ExerciseID]
+			}
+			add("sets", formatOptionalInt(a.Sets), formatOptionalInt(staged.Sets))
+			add("reps", formatOptional(a.Reps), formatOptional(staged.Reps))
+			add("weight", formatOptional(a.Weight), formatOptional(staged.Weight))
+			add("rest", formatOptional(a.Rest), formatOptional(staged.Rest))
+			add("tempo", formatOptional(a.Tempo), formatOptional(staged.Tempo))
+			add("duration", formatOptional(a.Duration), formatOptional(staged.Duration))
+			add("trainerNotes", orNone(a.TrainerNotes), orNone(staged.TrainerNotes))
+			if !sameBlock(a.BlockID, staged.BlockID) {
+				to := formatBlock(staged.BlockID)
+				if a.BlockID != nil && staged.BlockID != nil {
+					to = "in another group"
+				}
+				add("block", formatBlock(a.BlockID), to)
+			}
+		}
+		for _, a := range assignments {
+			if draft.IsAdded(a.ID) {
+				assignmentID := a.ID
+				exercise := exerciseNames[a.ExerciseID]
+				changes = append(changes, PlanChange{WorkoutID: &workoutID, AssignmentID: &assignmentID, Field: "added", From: "none", To: exercise, Summary: label + " – " + exercise + ": added"})
+			}
+		}
+		// The order the client goes through the exercises in, blocks included
+		before := structureOrder(content.blocks[live.ID], content.assignments[live.ID])
+		if reordered(before, structureOrder(blocks, assignments)) {
+			changes = append(changes, PlanChange{WorkoutID: &workoutID, Field: "order", Summary: label + ": exercises reordered"})
+		}
+	}
+
+	for _, w := range workouts {
+		if !draft.IsAdded(w.ID) {
+			continue
+		}
+		workoutID := w.ID
+		summary := w.Name + ": added"
+		switch n := len(overlayAssignments(w.ID, nil, draft)); n {
+		case 0:
+		case 1:
+			summary = w.Name + ": added with 1 exercise"
+		default:
+			summary = fmt.Sprintf("%s: added with %d exercises", w.Name, n)
+		}
+		changes = append(changes, PlanChange{WorkoutID: &workoutID, Field: "added", From: "none", To: w.Name, Summary: summary})
+	}
+	return changes
+}
+
+// reordered reports whether the items in both before and after come in a different order.
+func reordered(before, after []primitive.ObjectID) bool {
+	inBefore := make(map[primitive.ObjectID]bool, len(before))
+	for _, id := range before {
+		inBefore[id] = true
+	}
+	inAfter := make(map[primitive.ObjectID]bool, len(after))
+	for _, id := range after {
+		inAfter[id] = true
+	}
+	var kept []primitive.ObjectID
+	for _, id := range before {
+		if inAfter[id] {
+			kept = append(kept, id)
+		}
+	}
+	i := 0
+	for _, id := range after {
+		if !inBefore[id] {
+			continue
+		}
+		if kept[i] != id {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+func workoutIDs(workouts []domain.Workout) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(workouts))
+	for i, w := range workouts {
+		ids[i] = w.ID
+	}
+	return ids
+}
+
+// structureOrder lists a workout's assignments in the order the client does them.
+func structureOrder(blocks []domain.WorkoutBlock, assignments []domain.Assignment) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(assignments))
+	for _, block := range groupAssignmentsIntoBlocks(blocks, assignments) {
+		for _, a := range block.Assignments {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+// blockName names a block for the client, e.g. `superset A`.
+func blockName(b *domain.WorkoutBlock) string {
+	if b.Label == "" {
+		return string(b.Type)
+	}
+	return fmt.Sprintf("%s %s", b.Type, b.Label)
+}
+
+// changeSummaryLines returns the summaries of changes, one per line.
+func changeSummaryLines(changes []PlanChange) []string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.Summary
+	}
+	return lines
+}
+
+// changeSummary describes one change of what, e.g. `Day 1 – Squat: sets 3 → 4`. Notes are
+// too long to quote.
+func changeSummary(what, field, from, to string) string {
+	switch field {
+	case "block":
+		return fmt.Sprintf("%s: now %s", what, to)
+	case "notes", "trainerNotes":
+		return what + ": notes updated"
+	case "dayOfWeek":
+		field = "day"
+	case "restBetweenRounds":
+		field = "rest between rounds"
+	case "timeCap":
+		field = "time cap"
+	}
+	return fmt.Sprintf("%s: %s %s → %s", what, field, from, to)
+}
+
+func formatDayOfWeek(day *int) string {
+	if day == nil {
+		return "none"
+	}
+	if *day < 1 || *day > 7 {
+		return strconv.Itoa(*day)
+	}
+	return time.Weekday(*day % 7).String()
+}
+
+func formatOptional(s *string) string {
+	if s == nil {
+		return "none"
+	}
+	return orNone(*s)
+}
+
+func formatOptionalInt(n *int) string {
+	if n == nil {
+		return "none"
+	}
+	return strconv.Itoa(*n)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func sameBlock(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func formatBlock(id *primitive.ObjectID) string {
+	if id == nil {
+		return "on its own"
+	}
+	return "in a group"
+}
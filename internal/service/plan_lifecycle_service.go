@@ -0,0 +1,209 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// activationStatus is the status of a plan the trainer wants active: scheduled until
+// its StartDate arrives, active from then on.
+func activationStatus(plan *domain.TrainingPlan, now time.Time) domain.PlanStatus {
+	if plan.StartDate != nil && plan.StartDate.After(now) {
+		return domain.PlanStatusScheduled
+	}
+	return domain.PlanStatusActive
+}
+
+// sameDate reports whether two optional dates are both unset or equal.
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// PlanLifecycleResult counts what one lifecycle run changed.
+type PlanLifecycleResult struct {
+	Activated      int
+	Completed      int
+	EndingNotified int
+}
+
+// PlanLifecycleService moves plans through their lifecycle by their dates, so a plan
+// with a future StartDate activates by itself and plans stop after their EndDate.
+type PlanLifecycleService interface {
+	// Run completes active plans whose EndDate has passed, activates scheduled plans
+	// whose StartDate has arrived (the latest one per client wins; it replaces the
+	// current plan) and tells trainers about plans ending soon with nothing scheduled
+	// after them. It is idempotent; meant for the scheduler.
+	Run(ctx context.Context, now time.Time) (PlanLifecycleResult, error)
+}
+
+type planLifecycleService struct {
+	trainingPlanRepo repository.TrainingPlanRepository
+	userRepo         repository.UserRepository
+	transactor       repository.Transactor
+	events           realtime.Publisher
+	endingNotice     time.Duration
+}
+
+// NewPlanLifecycleService creates a new PlanLifecycleService. Trainers are warned
+// endingNotice before a plan's EndDate.
+func NewPlanLifecycleService(
+	trainingPlanRepo repository.TrainingPlanRepository,
+	userRepo repository.UserRepository,
+	transactor repository.Transactor,
+	events realtime.Publisher,
+	endingNotice time.Duration,
+) PlanLifecycleService {
+	return &planLifecycleService{
+		trainingPlanRepo: trainingPlanRepo,
+		userRepo:         userRepo,
+		transactor:       transactor,
+		events:           events,
+		endingNotice:     endingNotice,
+	}
+}
+
+// clientPlans is one trainer-client pair's plans that the lifecycle job acts on.
+type clientPlans struct {
+	trainerID primitive.ObjectID
+	clientID  primitive.ObjectID
+	active    []*domain.TrainingPlan
+	scheduled []*domain.TrainingPlan // Oldest StartDate first
+}
+
+func (s *planLifecycleService) Run(ctx context.Context, now time.Time) (PlanLifecycleResult, error) {
+	var result PlanLifecycleResult
+	plans, err := s.trainingPlanRepo.GetByStatuses(ctx, []domain.PlanStatus{domain.PlanStatusScheduled, domain.PlanStatusActive})
+	if err != nil {
+		return result, fmt.Errorf("failed to list scheduled and active plans: %w", err)
+	}
+
+	type pairKey struct{ trainerID, clientID primitive.ObjectID }
+	var order []pairKey
+	groups := make(map[pairKey]*clientPlans)
+	for i := range plans {
+		plan := &plans[i]
+		if !plan.IsPublished() {
+			continue // Clients never saw it; it waits for the trainer to publish
+		}
+		key := pairKey{plan.TrainerID, plan.ClientID}
+		group, ok := groups[key]
+		if !ok {
+			group = &clientPlans{trainerID: plan.TrainerID, clientID: plan.ClientID}
+			groups[key] = group
+			order = append(order, key)
+		}
+		if plan.Status == domain.PlanStatusActive {
+			group.active = append(group.active, plan)
+		} else {
+			group.scheduled = append(group.scheduled, plan)
+		}
+	}
+
+	for _, key := range order {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		s.runForClient(ctx, groups[key], now, &result)
+	}
+	return result, nil
+}
+
+// runForClient applies the lifecycle to one client's plans. Failures are logged and
+// retried on the next run.
+func (s *planLifecycleService) runForClient(ctx context.Context, group *clientPlans, now time.Time, result *PlanLifecycleResult) {
+	// 1. Complete active plans past their EndDate (same test as GetMyCurrentWorkouts).
+	var current *domain.TrainingPlan
+	for _, plan := range group.active {
+		if plan.EndDate != nil && now.After(*plan.EndDate) {
+			if s.setStatus(ctx, plan, domain.PlanStatusCompleted) {
+				result.Completed++
+			}
+			continue
+		}
+		current = plan
+	}
+
+	// 2. Activate the latest scheduled plan whose StartDate has arrived. Earlier due
+	//    ones were overtaken before they started, and ones already past their EndDate
+	//    never got a chance; both complete.
+	var due *domain.TrainingPlan
+	var upcoming []*domain.TrainingPlan
+	for _, plan := range group.scheduled {
+		switch {
+		case plan.EndDate != nil && now.After(*plan.EndDate):
+			if s.setStatus(ctx, plan, domain.PlanStatusCompleted) {
+				result.Completed++
+			}
+		case plan.StartDate == nil || !plan.StartDate.After(now):
+			if due != nil && s.setStatus(ctx, due, domain.PlanStatusCompleted) {
+				result.Completed++
+			}
+			due = plan
+		default:
+			upcoming = append(upcoming, plan)
+		}
+	}
+	if due != nil {
+		activated := *due
+		activated.SetStatus(domain.PlanStatusActive)
+		err := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+			if err := s.trainingPlanRepo.Update(txCtx, &activated); err != nil {
+				return err
+			}
+			return s.trainingPlanRepo.DeactivateOtherPlansForClient(txCtx, group.clientID, group.trainerID, due.ID)
+		})
+		if err != nil {
+			log.Printf("WARN: Failed to activate plan %s of client %s: %v", due.ID.Hex(), group.clientID.Hex(), err)
+			return
+		}
+		*due = activated
+		result.Activated++
+		current = due
+	}
+
+	// 3. Warn the trainer once when the current plan is about to end with nothing queued.
+	if current == nil || current.EndDate == nil || current.EndingNotifiedAt != nil || len(upcoming) > 0 {
+		return
+	}
+	if current.EndDate.Sub(now) > s.endingNotice {
+		return
+	}
+	notifiedAt := now.UTC()
+	current.EndingNotifiedAt = &notifiedAt
+	if err := s.trainingPlanRepo.Update(ctx, current); err != nil {
+		log.Printf("WARN: Failed to record ending notice for plan %s: %v", current.ID.Hex(), err)
+		return
+	}
+
+	clientName := "Your client"
+	if client, err := s.userRepo.GetByID(ctx, group.clientID); err == nil {
+		clientName = client.Name
+	}
+	summary := fmt.Sprintf("%s's plan %q ends on %s with no plan scheduled next", clientName, current.Name, current.EndDate.Format("Jan 2"))
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventPlanEnding, primitive.NilObjectID, summary, map[string]any{
+		"planId":   current.ID,
+		"clientId": group.clientID,
+		"endDate":  current.EndDate.UTC().Format(time.RFC3339),
+	}), group.trainerID)
+	result.EndingNotified++
+}
+
+// setStatus saves plan with status, logging failures.
+func (s *planLifecycleService) setStatus(ctx context.Context, plan *domain.TrainingPlan, status domain.PlanStatus) bool {
+	plan.SetStatus(status)
+	if err := s.trainingPlanRepo.Update(ctx, plan); err != nil {
+		log.Printf("WARN: Failed to mark plan %s %s: %v", plan.ID.Hex(), status, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrPlanValidationFailed = errors.New("training plan has validation errors")
+
+// PlanPublishingService checks training plans and publishes them to clients. Clients
+// only see plans that have been published, and a plan with validation errors cannot be.
+// Changes to a published plan's workouts, blocks and exercises wait in a draft until the
+// plan is published again.
+type PlanPublishingService interface {
+	// ValidatePlan checks one of the trainer's plans, including unpublished edits,
+	// without changing it.
+	ValidatePlan(ctx context.Context, trainerID, planID primitive.ObjectID) (*PlanValidationReport, error)
+	// PublishPlan validates the plan and, if it has no errors, makes it visible to the
+	// client. A draft is activated (or scheduled, if it starts in the future). Edits
+	// staged since the last publish replace the published versions in one step, and the
+	// client is told what changed (also kept in the plan's ChangeSummary). On
+	// ErrPlanValidationFailed the report says what to fix.
+	PublishPlan(ctx context.Context, trainerID, planID primitive.ObjectID) (*domain.TrainingPlan, *PlanValidationReport, error)
+	// GetPendingChanges lists the edits the next publish would show the client.
+	GetPendingChanges(ctx context.Context, trainerID, planID primitive.ObjectID) ([]PlanChange, error)
+	// DiscardPendingChanges drops the staged edits, going back to the published plan.
+	DiscardPendingChanges(ctx context.Context, trainerID, planID primitive.ObjectID) error
+}
+
+type planPublishingService struct {
+	trainingPlanRepo repository.TrainingPlanRepository
+	workoutRepo      repository.WorkoutRepository
+	assignmentRepo   repository.AssignmentRepository
+	workoutBlockRepo repository.WorkoutBlockRepository
+	exerciseRepo     repository.ExerciseRepository
+	planDraftRepo    repository.PlanDraftRepository
+	transactor       repository.Transactor
+	events           realtime.Publisher
+}
+
+// NewPlanPublishingService creates a new PlanPublishingService.
+func NewPlanPublishingService(
+	trainingPlanRepo repository.TrainingPlanRepository,
+	workoutRepo repository.WorkoutRepository,
+	assignmentRepo repository.AssignmentRepository,
+	workoutBlockRepo repository.WorkoutBlockRepository,
+	exerciseRepo repository.ExerciseRepository,
+	planDraftRepo repository.PlanDraftRepository,
+	transactor repository.Transactor,
+	events realtime.Publisher,
+) PlanPublishingService {
+	return &planPublishingService{
+		trainingPlanRepo: trainingPlanRepo,
+		workoutRepo:      workoutRepo,
+		assignmentRepo:   assignmentRepo,
+		workoutBlockRepo: workoutBlockRepo,
+		exerciseRepo:     exerciseRepo,
+		planDraftRepo:    planDraftRepo,
+		transactor:       transactor,
+		events:           events,
+	}
+}
+
+func (s *planPublishingService) ValidatePlan(ctx context.Context, trainerID, planID primitive.ObjectID) (*PlanValidationReport, error) {
+	content, draft, err := s.loadPlanContent(ctx, trainerID, planID)
+	if err != nil {
+		return nil, err
+	}
+	return validatePlan(withDraft(*content, draft), time.Now()), nil
+}
+
+func (s *planPublishingService) PublishPlan(ctx context.Context, trainerID, planID primitive.ObjectID) (*domain.TrainingPlan, *PlanValidationReport, error) {
+	// Validate, swap in the staged changes, clear the draft and record the publish together,
+	// all against the plan and draft as loaded inside the transaction, so the client sees
+	// either the old version or the new one and a concurrent edit is neither published
+	// unvalidated nor overwritten. The changes are described from the same load, so the
+	// summary matches what was applied.
+	var (
+		plan         *domain.TrainingPlan
+		report       *PlanValidationReport
+		changes      []PlanChange
+		firstPublish bool
+	)
+	err := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		current, draft, err := s.loadPlanContent(txCtx, trainerID, planID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		report = validatePlan(withDraft(*current, draft), now)
+		if !report.Valid {
+			return ErrPlanValidationFailed
+		}
+
+		plan = current.plan
+		firstPublish = !plan.IsPublished()
+		publishedAt := now.UTC()
+		plan.PublishedAt = &publishedAt
+		wasActive := plan.IsActive
+		if plan.EffectiveStatus() == domain.PlanStatusDraft {
+			plan.SetStatus(activationStatus(plan, now))
+		}
+
+		changes, draft, err = s.pendingChanges(txCtx, trainerID, current, draft)
+		if err != nil {
+			return err
+		}
+		if firstPublish {
+			changes = nil
+		}
+		plan.ChangeSummary = changeSummaryLines(changes)
+		if err := s.applyDraft(txCtx, current, draft); err != nil {
+			return err
+		}
+		if err := s.planDraftRepo.Delete(txCtx, planID); err != nil {
+			return err
+		}
+		if err := s.trainingPlanRepo.Update(txCtx, plan); err != nil {
+			return err
+		}
+		if plan.IsActive && !wasActive {
+			// Only one active plan per client: the published one replaces the current one.
+			return s.trainingPlanRepo.DeactivateOtherPlansForClient(txCtx, plan.ClientID, trainerID, planID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrPlanValidationFailed) {
+			return nil, report, err
+		}
+		if errors.Is(err, ErrTrainingPlanNotFound) || errors.Is(err, ErrTrainingPlanAccessDenied) {
+			return nil, nil, err
+		}
+		log.Printf("ERROR: Failed to publish plan %s: %v", planID.Hex(), err)
+		return nil, report, errors.New("failed to publish training plan")
+	}
+
+	if firstPublish {
+		s.events.Publish(ctx, realtime.NewEvent(realtime.EventPlan, trainerID, "New training plan: "+plan.Name, map[string]any{"planId": plan.ID}), plan.ClientID)
+	} else {
+		s.events.Publish(ctx, realtime.NewEvent(realtime.EventPlanUpdated, trainerID, planUpdatedSummary(plan.Name, changes), map[string]any{
+			"planId":  plan.ID,
+			"changes": len(changes),
+		}), plan.ClientID)
+	}
+	return plan, report, nil
+}
+
+func (s *planPublishingService) GetPendingChanges(ctx context.Context, trainerID, planID primitive.ObjectID) ([]PlanChange, error) {
+	content, draft, err := s.loadPlanContent(ctx, trainerID, planID)
+	if err != nil {
+		return nil, err
+	}
+	changes, _, err := s.pendingChanges(ctx, trainerID, content, draft)
+	return changes, err
+}
+
+func (s *planPublishingService) DiscardPendingChanges(ctx context.Context, trainerID, planID primitive.ObjectID) error {
+	if _, err := s.getOwnedPlan(ctx, trainerID, planID); err != nil {
+		return err
+	}
+	if err := s.planDraftRepo.Delete(ctx, planID); err != nil {
+		return errors.New("failed to discard unpublished changes")
+	}
+	return nil
+}
+
+// applyDraft writes a draft, as returned by applicableDraft for content, over the
+// published plan: added items are inserted, edits applied and removed items deleted.
+func (s *planPublishingService) applyDraft(ctx context.Context, content *planContent, draft *domain.PlanDraft) error {
+	if draft.IsEmpty() {
+		return nil
+	}
+	for _, id := range draft.Added {
+		if !draft.IsAdded(id) {
+			continue
+		}
+		var err error
+		if w, ok := draft.Workout(id); ok {
+			_, err = s.workoutRepo.Create(ctx, w)
+		} else if b, ok := draft.Block(id); ok {
+			_, err = s.workoutBlockRepo.Create(ctx, b)
+		} else if a, ok := draft.Assignment(id); ok {
+			_, err = s.assignmentRepo.Create(ctx, a)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, staged := range draft.Workouts {
+		if draft.IsAdded(staged.ID) {
+			continue
+		}
+		workout, err := s.workoutRepo.GetByID(ctx, staged.ID)
+		if err != nil {
+			return err
+		}
+		applyWorkoutEdits(workout, &staged)
+		if err := s.workoutRepo.Update(ctx, workout); err != nil {
+			return err
+		}
+	}
+	for _, staged := range draft.Blocks {
+		if draft.IsAdded(staged.ID) {
+			continue
+		}
+		block, err := s.workoutBlockRepo.GetByID(ctx, staged.ID)
+		if err != nil {
+			return err
+		}
+		applyBlockEdits(block, &staged)
+		if err := s.workoutBlockRepo.Update(ctx, block); err != nil {
+			return err
+		}
+	}
+	for _, staged := range draft.Assignments {
+		if draft.IsAdded(staged.ID) {
+			continue
+		}
+		assignment, err := s.assignmentRepo.GetByID(ctx, staged.ID)
+		if err != nil {
+			return err
+		}
+		applyAssignmentEdits(assignment, &staged) // Keeps the client's progress
+		if err := s.assignmentRepo.Update(ctx, assignment); err != nil {
+			return err
+		}
+	}
+
+	for _, workout := range content.workouts {
+		if draft.IsRemoved(workout.ID) {
+			if err := s.workoutRepo.Delete(ctx, workout.ID, workout.TrainerID); err != nil {
+				return err
+			}
+		}
+		for _, block := range content.blocks[workout.ID] {
+			if !draft.IsRemoved(block.ID) {
+				continue
+			}
+			if err := s.workoutBlockRepo.Delete(ctx, block.ID, workout.ID); err != nil {
+				return err
+			}
+			if _, err := s.assignmentRepo.ClearBlock(ctx, block.ID); err != nil {
+				return err
+			}
+		}
+		for _, assignment := range content.assignments[workout.ID] {
+			if draft.IsRemoved(assignment.ID) {
+				if err := s.assignmentRepo.Delete(ctx, assignment.ID, workout.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// pendingChanges describes what publishing would change for the client, naming exercises,
+// and returns the part of draft it would apply.
+func (s *planPublishingService) pendingChanges(ctx context.Context, trainerID primitive.ObjectID, content *planContent, draft *domain.PlanDraft) ([]PlanChange, *domain.PlanDraft, error) {
+	if draft.IsEmpty() {
+		return []PlanChange{}, draft, nil
+	}
+	exercises, err := s.exerciseRepo.GetByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, nil, errors.New("failed to retrieve exercises")
+	}
+	names := make(map[primitive.ObjectID]string, len(exercises))
+	for _, e := range exercises {
+		names[e.ID] = e.Name
+	}
+	draft = applicableDraft(content, draft, names)
+	return describePlanChanges(content, draft, names), draft, nil
+}
+
+// planUpdatedSummary is the notification line for a republished plan.
+func planUpdatedSummary(planName string, changes []PlanChange) string {
+	switch len(changes) {
+	case 0:
+		return "Your training plan was updated: " + planName
+	case 1:
+		return fmt.Sprintf("Your trainer updated %q: %s", planName, changes[0].Summary)
+	default:
+		return fmt.Sprintf("Your trainer updated %q: %s and %d more changes", planName, changes[0].Summary, len(changes)-1)
+	}
+}
+
+// withDraft returns content as it will be once the draft is published.
+func withDraft(content planContent, draft *domain.PlanDraft) planContent {
+	if draft.IsEmpty() {
+		return content
+	}
+	workouts := overlayWorkouts(content.workouts, draft)
+	assignments := make(map[primitive.ObjectID][]domain.Assignment, len(workouts))
+	blocks := make(map[primitive.ObjectID][]domain.WorkoutBlock, len(workouts))
+	for _, w := range workouts {
+		assignments[w.ID] = overlayAssignments(w.ID, content.assignments[w.ID], draft)
+		blocks[w.ID] = overlayBlocks(w.ID, content.blocks[w.ID], draft)
+	}
+	content.workouts = workouts
+	content.assignments = assignments
+	content.blocks = blocks
+	return content
+}
+
+func (s *planPublishingService) getOwnedPlan(ctx context.Context, trainerID, planID primitive.ObjectID) (*domain.TrainingPlan, error) {
+	plan, err := s.trainingPlanRepo.GetByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTrainingPlanNotFound
+		}
+		return nil, err
+	}
+	if plan.TrainerID != trainerID {
+		return nil, ErrTrainingPlanAccessDenied
+	}
+	return plan, nil
+}
+
+// loadPlanContent loads one of the trainer's plans as published, with its workouts,
+// their assignments and blocks, and the client's other plans, plus the changes staged on
+// it (nil if none).
+func (s *planPublishingService) loadPlanContent(ctx context.Context, trainerID, planID primitive.ObjectID) (*planContent, *domain.PlanDraft, error) {
+	plan, err := s.getOwnedPlan(ctx, trainerID, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workouts, err := s.workoutRepo.GetByPlanID(ctx, planID)
+	if err != nil {
+		return nil, nil, errors.New("failed to retrieve workouts for the plan")
+	}
+	assignments := make(map[primitive.ObjectID][]domain.Assignment, len(workouts))
+	blocks := make(map[primitive.ObjectID][]domain.WorkoutBlock, len(workouts))
+	for _, workout := range workouts {
+		list, err := s.assignmentRepo.GetByWorkoutID(ctx, workout.ID)
+		if err != nil {
+			return nil, nil, errors.New("failed to retrieve assignments for the plan")
+		}
+		assignments[workout.ID] = list
+		if blocks[workout.ID], err = s.workoutBlockRepo.GetByWorkoutID(ctx, workout.ID); err != nil {
+			return nil, nil, errors.New("failed to retrieve workout blocks for the plan")
+		}
+	}
+	otherPlans, err := s.trainingPlanRepo.GetByClientAndTrainerID(ctx, plan.ClientID, trainerID)
+	if err != nil {
+		return nil, nil, errors.New("failed to retrieve the client's training plans")
+	}
+	draft, err := s.planDraftRepo.GetByPlanID(ctx, planID)
+	if errors.Is(err, repository.ErrNotFound) {
+		draft = nil
+	} else if err != nil {
+		return nil, nil, errors.New("failed to retrieve unpublished changes")
+	}
+
+	return &planContent{plan: plan, workouts: workouts, assignments: assignments, blocks: blocks, otherPlans: otherPlans}, draft, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Codes of the problems plan validation reports.
+const (
+	// Errors: the plan cannot be published until they are fixed.
+	PlanIssueEndBeforeStart           = "end_before_start"
+	PlanIssueOverlappingPlan          = "overlapping_plan"
+	PlanIssueNoWorkouts               = "no_workouts"
+	PlanIssueWorkoutWithoutExercises  = "workout_without_assignments"
+	PlanIssueDuplicateDayOfWeek       = "duplicate_day_of_week"
+	PlanIssueInvalidDayOfWeek         = "invalid_day_of_week"
+	PlanIssueDuplicateWorkoutSequence = "duplicate_workout_sequence"
+
+	// Warnings: worth a look, but publishing goes ahead.
+	PlanIssueEndsInPast                  = "ends_in_past"
+	PlanIssueDuplicateAssignmentSequence = "duplicate_assignment_sequence"
+)
+
+// PlanIssue is one problem found in a training plan. WorkoutID, AssignmentID and
+// OtherPlanID point at what the issue is about, when it is about something specific.
+type PlanIssue struct {
+	Code         string              `json:"code"`
+	Message      string              `json:"message"`
+	WorkoutID    *primitive.ObjectID `json:"workoutId,omitempty"`
+	AssignmentID *primitive.ObjectID `json:"assignmentId,omitempty"`
+	OtherPlanID  *primitive.ObjectID `json:"otherPlanId,omitempty"`
+}
+
+// PlanValidationReport is the outcome of validating a training plan. A plan with
+// errors cannot be published; warnings are informational.
+type PlanValidationReport struct {
+	PlanID    primitive.ObjectID `json:"planId"`
+	Valid     bool               `json:"valid"` // No errors
+	Errors    []PlanIssue        `json:"errors"`
+	Warnings  []PlanIssue        `json:"warnings"`
+	CheckedAt time.Time          `json:"checkedAt"`
+}
+
+func (r *PlanValidationReport) addError(issue PlanIssue) {
+	r.Errors = append(r.Errors, issue)
+}
+
+func (r *PlanValidationReport) addWarning(issue PlanIssue) {
+	r.Warnings = append(r.Warnings, issue)
+}
+
+// planContent is a plan with everything validation looks at.
+type planContent struct {
+	plan        *domain.TrainingPlan
+	workouts    []domain.Workout                             // Ordered by sequence
+	assignments map[primitive.ObjectID][]domain.Assignment   // By workout ID
+	blocks      map[primitive.ObjectID][]domain.WorkoutBlock // By workout ID
+	otherPlans  []domain.TrainingPlan                        // The client's other plans from the same trainer
+}
+
+// validatePlan checks a plan's dates, its workouts and their exercises, and how it fits
+// with the client's other plans.
+func validatePlan(content planContent, now time.Time) *PlanValidationReport {
+	plan := content.plan
+	report := &PlanValidationReport{PlanID: plan.ID, Errors: []PlanIssue{}, Warnings: []PlanIssue{}, CheckedAt: now.UTC()}
+
+	// Dates
+	if plan.StartDate != nil && plan.EndDate != nil && plan.EndDate.Before(*plan.StartDate) {
+		report.addError(PlanIssue{
+			Code:    PlanIssueEndBeforeStart,
+			Message: fmt.Sprintf("The end date (%s) is before the start date (%s).", plan.EndDate.Format(reminderDateLayout), plan.StartDate.Format(reminderDateLayout)),
+		})
+	} else if plan.EndDate != nil && now.After(*plan.EndDate) {
+		report.addWarning(PlanIssue{
+			Code:    PlanIssueEndsInPast,
+			Message: fmt.Sprintf("The plan ended on %s.", plan.EndDate.Format(reminderDateLayout)),
+		})
+	}
+
+	// Only one plan runs at a time, so plans that are (or will become) current must not
+	// share days. Ended and archived plans are history and overlap nothing.
+	if runsAfterPublish(plan) {
+		for i := range content.otherPlans {
+			other := &content.otherPlans[i]
+			if other.ID == plan.ID || !other.IsPublished() {
+				continue
+			}
+			if status := other.EffectiveStatus(); status != domain.PlanStatusScheduled && status != domain.PlanStatusActive {
+				continue
+			}
+			if !datesOverlap(plan, other) {
+				continue
+			}
+			otherID := other.ID
+			report.addError(PlanIssue{
+				Code:        PlanIssueOverlappingPlan,
+				Message:     fmt.Sprintf("The dates overlap with the client's %s plan %q (%s). Change the dates or end that plan first.", other.EffectiveStatus(), other.Name, describeDates(other)),
+				OtherPlanID: &otherID,
+			})
+		}
+	}
+
+	// Workouts
+	if len(content.workouts) == 0 {
+		report.addError(PlanIssue{Code: PlanIssueNoWorkouts, Message: "The plan has no workouts."})
+	}
+	byDay := make(map[int]primitive.ObjectID)
+	bySequence := make(map[int]primitive.ObjectID)
+	for _, workout := range content.workouts {
+		workoutID := workout.ID
+		if workout.DayOfWeek != nil {
+			day := *workout.DayOfWeek
+			switch first, seen := byDay[day]; {
+			case day < 1 || day > 7:
+				report.addError(PlanIssue{
+					Code:      PlanIssueInvalidDayOfWeek,
+					Message:   fmt.Sprintf("Workout %q has day of week %d; expected 1 (Monday) to 7 (Sunday).", workout.Name, day),
+					WorkoutID: &workoutID,
+				})
+			case seen:
+				report.addError(PlanIssue{
+					Code:      PlanIssueDuplicateDayOfWeek,
+					Message:   fmt.Sprintf("Workout %q is on %s, like workout %q.", workout.Name, time.Weekday(day%7), workoutName(content.workouts, first)),
+					WorkoutID: &workoutID,
+				})
+			default:
+				byDay[day] = workoutID
+			}
+		}
+		if first, seen := bySequence[workout.Sequence]; seen {
+			report.addError(PlanIssue{
+				Code:      PlanIssueDuplicateWorkoutSequence,
+				Message:   fmt.Sprintf("Workout %q has position %d, like workout %q. Reorder the workouts.", workout.Name, workout.Sequence, workoutName(content.workouts, first)),
+				WorkoutID: &workoutID,
+			})
+		} else {
+			bySequence[workout.Sequence] = workoutID
+		}
+
+		assignments := content.assignments[workout.ID]
+		if len(assignments) == 0 {
+			report.addError(PlanIssue{
+				Code:      PlanIssueWorkoutWithoutExercises,
+				Message:   fmt.Sprintf("Workout %q has no exercises.", workout.Name),
+				WorkoutID: &workoutID,
+			})
+			continue
+		}
+		// Sequences are per block (ungrouped exercises share one), see domain.Assignment.
+		type slot struct {
+			block    primitive.ObjectID
+			sequence int
+		}
+		seen := make(map[slot]bool)
+		for _, assignment := range assignments {
+			key := slot{sequence: assignment.Sequence}
+			if assignment.BlockID != nil {
+				key.block = *assignment.BlockID
+			}
+			if !seen[key] {
+				seen[key] = true
+				continue
+			}
+			assignmentID := assignment.ID
+			report.addWarning(PlanIssue{
+				Code:         PlanIssueDuplicateAssignmentSequence,
+				Message:      fmt.Sprintf("Workout %q has two exercises at position %d; their order is undefined.", workout.Name, assignment.Sequence),
+				WorkoutID:    &workoutID,
+				AssignmentID: &assignmentID,
+			})
+		}
+	}
+
+	report.Valid = len(report.Errors) == 0
+	return report
+}
+
+// runsAfterPublish reports whether plan is, or will become, the client's current plan
+// once published: drafts, scheduled and active plans.
+func runsAfterPublish(plan *domain.TrainingPlan) bool {
+	switch plan.EffectiveStatus() {
+	case domain.PlanStatusDraft, domain.PlanStatusScheduled, domain.PlanStatusActive:
+		return true
+	}
+	return false
+}
+
+// datesOverlap reports whether two plans share at least one moment. Missing dates are
+// open-ended.
+func datesOverlap(a, b *domain.TrainingPlan) bool {
+	if a.EndDate != nil && b.StartDate != nil && a.EndDate.Before(*b.StartDate) {
+		return false
+	}
+	if b.EndDate != nil && a.StartDate != nil && b.EndDate.Before(*a.StartDate) {
+		return false
+	}
+	return true
+}
+
+// describeDates formats a plan's date range for messages.
+func describeDates(plan *domain.TrainingPlan) string {
+	start, end := "open start", "open end"
+	if plan.StartDate != nil {
+		start = plan.StartDate.Format(reminderDateLayout)
+	}
+	if plan.EndDate != nil {
+		end = plan.EndDate.Format(reminderDateLayout)
+	}
+	return start + " to " + end
+}
+
+func workoutName(workouts []domain.Workout, id primitive.ObjectID) string {
+	for _, w := range workouts {
+		if w.ID == id {
+			return w.Name
+		}
+	}
+	return id.Hex()
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/repository"
+	"alcyxob/fitness-app/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reconciledPrefixes are the storage prefixes whose objects belong to a database record:
+// client uploads, trainer exercise media and trainer feedback attachments.
+var reconciledPrefixes = []string{"uploads/", "exercises/", "feedback/"}
+
+// DefaultReconciliationGracePeriod protects objects whose upload may still be confirmed.
+// It comfortably exceeds the lifetime of single-PUT and multipart part URLs.
+const DefaultReconciliationGracePeriod = 24 * time.Hour
+
+var ErrReconciliationInProgress = errors.New("a storage reconciliation is already running")
+
+// OrphanedObject is a stored object with no Upload record.
+type OrphanedObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Deleted      bool      `json:"deleted"` // False in dry runs or when deletion failed
+}
+
+// StaleMultipartUpload is a multipart upload that was never completed or aborted.
+type StaleMultipartUpload struct {
+	Key       string    `json:"key"`
+	UploadID  string    `json:"uploadId"`
+	Initiated time.Time `json:"initiated"`
+	Aborted   bool      `json:"aborted"` // False in dry runs or when aborting failed
+}
+
+// MissingObjectUpload is an Upload record whose object is not in storage.
+type MissingObjectUpload struct {
+	UploadID     primitive.ObjectID `json:"uploadId"`
+	AssignmentID primitive.ObjectID `json:"assignmentId"`
+	ClientID     primitive.ObjectID `json:"clientId"`
+	Key          string             `json:"key"`
+	UploadedAt   time.Time          `json:"uploadedAt"`
+}
+
+// ReconciliationReport summarizes one comparison of storage with the records that reference it.
+type ReconciliationReport struct {
+	DryRun          bool                   `json:"dryRun"`
+	StartedAt       time.Time              `json:"startedAt"`
+	FinishedAt      time.Time              `json:"finishedAt"`
+	GracePeriod     string                 `json:"gracePeriod"`
+	ObjectsScanned  int                    `json:"objectsScanned"`
+	UploadsScanned  int                    `json:"uploadsScanned"`
+	RecordsScanned  int                    `json:"recordsScanned"` // Uploads, exercise media and feedback attachments
+	OrphanedObjects []OrphanedObject       `json:"orphanedObjects"`
+	OrphanedBytes   int64                  `json:"orphanedBytes"`
+	WithinGrace     int                    `json:"withinGrace"` // Unrecorded objects and multipart uploads too recent to remove
+	StaleMultipart  []StaleMultipartUpload `json:"staleMultipartUploads"`
+	MissingObjects  []MissingObjectUpload  `json:"missingObjects"`
+	Restored        int                    `json:"restored"` // Previously flagged uploads whose object is back
+	Errors          []string               `json:"errors,omitempty"`
+}
+
+// ReconciliationService keeps object storage and the records that reference it in step.
+// Periodic runs are registered with the scheduler, so only the lease holder reconciles.
+type ReconciliationService interface {
+	// Reconcile compares storage with the uploads, exercise media and feedback attachment
+	// records. Unless dryRun is set it deletes orphaned objects and aborts multipart uploads
+	// older than the grace period, and flags uploads whose object is missing.
+	Reconcile(ctx context.Context, dryRun bool) (*ReconciliationReport, error)
+}
+
+type reconciliationService struct {
+	uploadRepo        repository.UploadRepository
+	exerciseMediaRepo repository.ExerciseMediaRepository
+	assignmentRepo    repository.AssignmentRepository
+	fileStorage       storage.FileStorage
+	gracePeriod       time.Duration
+	running           sync.Mutex // Keeps an admin-triggered run from overlapping a scheduled one
+}
+
+// NewReconciliationService creates a new ReconciliationService.
+func NewReconciliationService(uploadRepo repository.UploadRepository, exerciseMediaRepo repository.ExerciseMediaRepository, assignmentRepo repository.AssignmentRepository, fileStorage storage.FileStorage, gracePeriod time.Duration) ReconciliationService {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultReconciliationGracePeriod
+	}
+	return &reconciliationService{
+		uploadRepo:        uploadRepo,
+		exerciseMediaRepo: exerciseMediaRepo,
+		assignmentRepo:    assignmentRepo,
+		fileStorage:       fileStorage,
+		gracePeriod:       gracePeriod,
+	}
+}
+
+func (s *reconciliationService) Reconcile(ctx context.Context, dryRun bool) (*ReconciliationReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrReconciliationInProgress
+	}
+	defer s.running.Unlock()
+
+	report := &ReconciliationReport{
+		DryRun:          dryRun,
+		StartedAt:       time.Now().UTC(),
+		GracePeriod:     s.gracePeriod.String(),
+		OrphanedObjects: []OrphanedObject{},
+		StaleMultipart:  []StaleMultipartUpload{},
+		MissingObjects:  []MissingObjectUpload{},
+	}
+
+	// 1. Load records BEFORE listing storage: a record always follows its object
+	// (confirmation checks the object exists), so this order never reports a fresh
+	// upload as missing. Fresh objects confirmed meanwhile are covered by the grace period.
+	uploads, err := s.uploadRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+	media, err := s.exerciseMediaRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exercise media: %w", err)
+	}
+	attachmentKeys, err := s.assignmentRepo.ListFeedbackAttachmentKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback attachments: %w", err)
+	}
+	var objects []storage.ObjectMetadata
+	var multipart []storage.MultipartUpload
+	for _, prefix := range reconciledPrefixes {
+		listed, err := s.fileStorage.ListObjects(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list storage objects under %s: %w", prefix, err)
+		}
+		objects = append(objects, listed...)
+		pending, err := s.fileStorage.ListMultipartUploads(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads under %s: %w", prefix, err)
+		}
+		multipart = append(multipart, pending...)
+	}
+	report.UploadsScanned = len(uploads)
+	report.RecordsScanned = len(uploads) + len(media) + len(attachmentKeys)
+	report.ObjectsScanned = len(objects)
+
+	recorded := make(map[string]bool, report.RecordsScanned)
+	for _, u := range uploads {
+		recorded[u.S3ObjectKey] = true
+	}
+	for _, m := range media {
+		recorded[m.S3ObjectKey] = true
+	}
+	for _, key := range attachmentKeys {
+		recorded[key] = true
+	}
+	stored := make(map[string]bool, len(objects))
+	for _, o := range objects {
+		stored[o.Key] = true
+	}
+
+	// 2. Objects without a record: delete once they are older than the grace period
+	cutoff := report.StartedAt.Add(-s.gracePeriod)
+	for _, o := range objects {
+		if recorded[o.Key] {
+			continue
+		}
+		if o.LastModified.After(cutoff) {
+			report.WithinGrace++
+			continue
+		}
+		orphan := OrphanedObject{Key: o.Key, Size: o.Size, LastModified: o.LastModified}
+		if !dryRun {
+			if err := s.fileStorage.DeleteObject(ctx, o.Key); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("delete %s: %v", o.Key, err))
+			} else {
+				orphan.Deleted = true
+			}
+		}
+		report.OrphanedObjects = append(report.OrphanedObjects, orphan)
+		report.OrphanedBytes += o.Size
+	}
+
+	// 3. Multipart uploads never completed or aborted: their part URLs have long expired
+	for _, u := range multipart {
+		if u.Initiated.After(cutoff) {
+			report.WithinGrace++
+			continue
+		}
+		stale := StaleMultipartUpload{Key: u.Key, UploadID: u.UploadID, Initiated: u.Initiated}
+		if !dryRun {
+			if err := s.fileStorage.AbortMultipartUpload(ctx, u.Key, u.UploadID); err != nil && !errors.Is(err, storage.ErrMultipartUploadNotFound) {
+				report.Errors = append(report.Errors, fmt.Sprintf("abort multipart upload %s of %s: %v", u.UploadID, u.Key, err))
+			} else {
+				stale.Aborted = true
+			}
+		}
+		report.StaleMultipart = append(report.StaleMultipart, stale)
+	}
+
+	// 4. Upload records without an object: flag them (and unflag ones whose object came back)
+	for i := range uploads {
+		u := &uploads[i]
+		if stored[u.S3ObjectKey] {
+			if u.ObjectMissingAt != nil {
+				report.Restored++
+				s.setObjectMissing(ctx, report, u, nil, dryRun)
+			}
+			continue
+		}
+		// The listing may be stale; confirm before flagging.
+		if _, err := s.fileStorage.GetObjectMetadata(ctx, u.S3ObjectKey); !errors.Is(err, storage.ErrObjectNotFound) {
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("check %s: %v", u.S3ObjectKey, err))
+			}
+			continue
+		}
+		report.MissingObjects = append(report.MissingObjects, MissingObjectUpload{
+			UploadID:     u.ID,
+			AssignmentID: u.AssignmentID,
+			ClientID:     u.ClientID,
+			Key:          u.S3ObjectKey,
+			UploadedAt:   u.UploadedAt,
+		})
+		if u.ObjectMissingAt == nil {
+			s.setObjectMissing(ctx, report, u, &report.StartedAt, dryRun)
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// setObjectMissing updates an upload's missing flag unless this is a dry run.
+func (s *reconciliationService) setObjectMissing(ctx context.Context, report *ReconciliationReport, upload *domain.Upload, missingAt *time.Time, dryRun bool) {
+	if dryRun {
+		return
+	}
+	if err := s.uploadRepo.SetObjectMissing(ctx, upload.ID, missingAt); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("flag upload %s: %v", upload.ID.Hex(), err))
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const reminderDateLayout = "2006-01-02"
+
+var ErrInvalidReminderSettings = errors.New("invalid reminder settings")
+
+// ReminderSettingsInput changes a client's reminder settings. Nil fields are left unchanged;
+// an empty time or time zone restores the default.
+type ReminderSettingsInput struct {
+	RemindersEnabled *bool
+	ReminderTime     *string // "HH:MM", 24-hour, in TimeZone
+	NudgesEnabled    *bool
+	NudgeTime        *string
+	TimeZone         *string // IANA name
+}
+
+// ReminderService reminds clients about their workouts: a reminder of the day's
+// workouts and a nudge the next day about exercises still "assigned". The Send methods
+// are meant for the scheduler; each client gets each at most once per local day.
+type ReminderService interface {
+	GetSettings(ctx context.Context, clientID primitive.ObjectID) (*domain.ReminderSettings, error)
+	UpdateSettings(ctx context.Context, clientID primitive.ObjectID, input ReminderSettingsInput) (*domain.ReminderSettings, error)
+
+	// SendDueReminders notifies every client whose reminder time has passed today (in
+	// their time zone) about the workouts GetMyCurrentWorkouts resolves for today.
+	SendDueReminders(ctx context.Context, now time.Time) (int, error)
+	// SendDueNudges notifies every client whose nudge time has passed today about
+	// exercises from yesterday's workouts that are still assigned.
+	SendDueNudges(ctx context.Context, now time.Time) (int, error)
+}
+
+type reminderService struct {
+	settingsRepo     repository.ReminderSettingsRepository
+	trainingPlanRepo repository.TrainingPlanRepository
+	assignmentRepo   repository.AssignmentRepository
+	clientService    ClientService
+	events           realtime.Publisher
+}
+
+// NewReminderService creates a new ReminderService. Workouts are resolved through
+// clientService so reminders match what the app shows under "today".
+func NewReminderService(
+	settingsRepo repository.ReminderSettingsRepository,
+	trainingPlanRepo repository.TrainingPlanRepository,
+	assignmentRepo repository.AssignmentRepository,
+	clientService ClientService,
+	events realtime.Publisher,
+) ReminderService {
+	return &reminderService{
+		settingsRepo:     settingsRepo,
+		trainingPlanRepo: trainingPlanRepo,
+		assignmentRepo:   assignmentRepo,
+		clientService:    clientService,
+		events:           events,
+	}
+}
+
+// getSettings loads a client's settings; clients who never saved any get the defaults.
+func (s *reminderService) getSettings(ctx context.Context, clientID primitive.ObjectID) (*domain.ReminderSettings, error) {
+	settings, err := s.settingsRepo.Get(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &domain.ReminderSettings{ClientID: clientID}, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *reminderService) GetSettings(ctx context.Context, clientID primitive.ObjectID) (*domain.ReminderSettings, error) {
+	if clientID == primitive.NilObjectID {
+		return nil, errors.New("client ID is required")
+	}
+	settings, err := s.getSettings(ctx, clientID)
+	if err != nil {
+		return nil, errors.New("failed to load reminder settings")
+	}
+	return settings, nil
+}
+
+func (s *reminderService) UpdateSettings(ctx context.Context, clientID primitive.ObjectID, input ReminderSettingsInput) (*domain.ReminderSettings, error) {
+	if clientID == primitive.NilObjectID {
+		return nil, errors.New("client ID is required")
+	}
+	settings, err := s.getSettings(ctx, clientID)
+	if err != nil {
+		return nil, errors.New("failed to load reminder settings")
+	}
+
+	if input.RemindersEnabled != nil {
+		settings.RemindersDisabled = !*input.RemindersEnabled
+	}
+	if input.NudgesEnabled != nil {
+		settings.NudgesDisabled = !*input.NudgesEnabled
+	}
+	if input.ReminderTime != nil {
+		t := strings.TrimSpace(*input.ReminderTime)
+		if _, err := parseClockTime(t); t != "" && err != nil {
+			return nil, fmt.Errorf("%w: reminderTime must be HH:MM", ErrInvalidReminderSettings)
+		}
+		settings.ReminderTime = t
+	}
+	if input.NudgeTime != nil {
+		t := strings.TrimSpace(*input.NudgeTime)
+		if _, err := parseClockTime(t); t != "" && err != nil {
+			return nil, fmt.Errorf("%w: nudgeTime must be HH:MM", ErrInvalidReminderSettings)
+		}
+		settings.NudgeTime = t
+	}
+	if input.TimeZone != nil {
+		tz := strings.TrimSpace(*input.TimeZone)
+		if _, err := time.LoadLocation(tz); tz != "" && err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidReminderSettings, tz)
+		}
+		settings.TimeZone = tz
+	}
+
+	if err := s.settingsRepo.Upsert(ctx, settings); err != nil {
+		return nil, errors.New("failed to save reminder settings")
+	}
+	return settings, nil
+}
+
+// parseClockTime parses "HH:MM" into minutes after midnight.
+func parseClockTime(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// reached reports whether local time is at or past the clock time (invalid = default).
+func reached(local time.Time, clock, fallback string) bool {
+	minutes, err := parseClockTime(clock)
+	if err != nil {
+		minutes, _ = parseClockTime(fallback)
+	}
+	return local.Hour()*60+local.Minute() >= minutes
+}
+
+func (s *reminderService) SendDueReminders(ctx context.Context, now time.Time) (int, error) {
+	clientIDs, err := s.trainingPlanRepo.GetActiveClientIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list clients with active plans: %w", err)
+	}
+	sent := 0
+	for _, clientID := range clientIDs {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		settings, err := s.getSettings(ctx, clientID)
+		if err != nil {
+			log.Printf("WARN: Failed to load reminder settings for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		local := now.In(settings.Location())
+		date := local.Format(reminderDateLayout)
+		if settings.RemindersDisabled || settings.LastReminderDate == date ||
+			!reached(local, settings.EffectiveReminderTime(), domain.DefaultReminderTime) {
+			continue
+		}
+
+		workouts, err := s.clientService.GetMyCurrentWorkouts(ctx, clientID, local)
+		if err != nil {
+			log.Printf("WARN: Failed to resolve today's workouts for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		// Claim the day before sending, so a concurrent or repeated run can't send twice.
+		claimed, err := s.settingsRepo.MarkReminderSent(ctx, clientID, date)
+		if err != nil {
+			log.Printf("WARN: Failed to record workout reminder for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		if !claimed || len(workouts) == 0 {
+			continue
+		}
+
+		summary := "Today's workout: " + workouts[0].Name
+		if len(workouts) > 1 {
+			summary = fmt.Sprintf("You have %d workouts today", len(workouts))
+		}
+		s.events.Publish(ctx, realtime.NewEvent(realtime.EventWorkoutReminder, primitive.NilObjectID, summary, map[string]any{
+			"workoutId":    workouts[0].ID,
+			"planId":       workouts[0].TrainingPlanID,
+			"workoutCount": len(workouts),
+			"date":         date,
+		}), clientID)
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *reminderService) SendDueNudges(ctx context.Context, now time.Time) (int, error) {
+	clientIDs, err := s.trainingPlanRepo.GetActiveClientIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list clients with active plans: %w", err)
+	}
+	sent := 0
+	for _, clientID := range clientIDs {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		settings, err := s.getSettings(ctx, clientID)
+		if err != nil {
+			log.Printf("WARN: Failed to load reminder settings for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		local := now.In(settings.Location())
+		date := local.Format(reminderDateLayout)
+		if settings.NudgesDisabled || settings.LastNudgeDate == date ||
+			!reached(local, settings.EffectiveNudgeTime(), domain.DefaultNudgeTime) {
+			continue
+		}
+
+		yesterday := local.AddDate(0, 0, -1)
+		workouts, err := s.clientService.GetMyCurrentWorkouts(ctx, clientID, yesterday)
+		if err != nil {
+			log.Printf("WARN: Failed to resolve yesterday's workouts for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		pending := 0
+		var firstWorkout *domain.Workout
+		for i := range workouts {
+			assignments, err := s.assignmentRepo.GetByWorkoutID(ctx, workouts[i].ID)
+			if err != nil {
+				log.Printf("WARN: Failed to load assignments of workout %s: %v", workouts[i].ID.Hex(), err)
+				continue
+			}
+			for _, a := range assignments {
+				if a.Status == domain.StatusAssigned {
+					pending++
+					if firstWorkout == nil {
+						firstWorkout = &workouts[i]
+					}
+				}
+			}
+		}
+
+		claimed, err := s.settingsRepo.MarkNudgeSent(ctx, clientID, date)
+		if err != nil {
+			log.Printf("WARN: Failed to record missed-workout nudge for %s: %v", clientID.Hex(), err)
+			continue
+		}
+		if !claimed || pending == 0 {
+			continue
+		}
+
+		summary := "You have an exercise left from yesterday's " + firstWorkout.Name
+		if pending > 1 {
+			summary = fmt.Sprintf("You have %d exercises left from yesterday's workout", pending)
+		}
+		s.events.Publish(ctx, realtime.NewEvent(realtime.EventWorkoutMissed, primitive.NilObjectID, summary, map[string]any{
+			"workoutId":    firstWorkout.ID,
+			"planId":       firstWorkout.TrainingPlanID,
+			"pendingCount": pending,
+			"date":         yesterday.Format(reminderDateLayout),
+		}), clientID)
+		sent++
+	}
+	return sent, nil
+}
@@ -2,13 +2,19 @@ package service
 
 import (
 	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
 	"alcyxob/fitness-app/internal/repository"
 	"alcyxob/fitness-app/internal/storage"
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -27,8 +33,23 @@ var (
 	ErrTrainingPlanAccessDenied = errors.New("access denied to this training plan")
 	ErrUploadNotFoundForAssignment = errors.New("no upload found for this assignment")
 	ErrS3URLGenerationFailed     = errors.New("failed to generate S3 download URL")
+	ErrWorkoutAccessDenied       = errors.New("access denied: trainer does not own this workout")
+	ErrWorkoutBlockNotFound      = errors.New("workout block not found")
+	ErrInvalidWorkoutBlock       = errors.New("invalid workout block")
+	ErrReorderMismatch           = errors.New("ordered IDs must list every item exactly once")
+	ErrDuplicateSequence         = errors.New("duplicate sequence number within the workout")
+	ErrInvalidBatchOperation     = errors.New("invalid batch operation")
+	ErrFeedbackAttachmentNotFound = errors.New("feedback attachment not found")
+	ErrInvalidFeedbackAttachment  = errors.New("invalid feedback attachment; expected a video or audio file")
+	ErrInvalidFeedbackStatus      = errors.New("invalid status transition for feedback")
+	ErrInvalidBulkFeedback        = errors.New("invalid bulk feedback request")
+	ErrInvalidPlanStatus          = errors.New("invalid training plan status")
+	ErrPlanNotPublished           = errors.New("training plan has not been published to the client yet")
 )
 
+// maxBulkFeedbackItems caps how many assignments one bulk feedback request may touch.
+const maxBulkFeedbackItems = 100
+
 // TrainerService Interface
 type TrainerService interface {
 	// Client Management
@@ -39,6 +60,10 @@ type TrainerService interface {
 	CreateTrainingPlan(ctx context.Context, trainerID, clientID primitive.ObjectID, name, description string, startDate, endDate *time.Time, isActive bool) (*domain.TrainingPlan, error)
 	GetTrainingPlansForClient(ctx context.Context, trainerID, clientID primitive.ObjectID) ([]domain.TrainingPlan, error)
 
+	// On a published plan, changes to its workouts, blocks and exercises (including adding,
+	// deleting and reordering them) are staged in the plan's draft; the client sees them
+	// when the plan is published again.
+
 	// --- NEW Workout Methods ---
 	CreateWorkout(ctx context.Context, trainerID, planID primitive.ObjectID, name string, dayOfWeek *int, notes string, sequence int) (*domain.Workout, error)
 	GetWorkoutsForPlan(ctx context.Context, trainerID, planID primitive.ObjectID) ([]domain.Workout, error)
@@ -48,12 +73,27 @@ type TrainerService interface {
 
 	// --- NEW: Get Video Download URL for an Assignment ---
 	GetAssignmentVideoDownloadURL(ctx context.Context, trainerID, assignmentID primitive.ObjectID) (string, error)
+	// All uploads of an assignment (retries, extra angles), primary marked
+	GetUploadsForAssignment(ctx context.Context, trainerID, assignmentID primitive.ObjectID) ([]UploadDetails, error)
+	// --- Video / voice replies attached to feedback ---
+	RequestFeedbackAttachmentUploadURL(ctx context.Context, trainerID, assignmentID primitive.ObjectID, kind domain.FeedbackAttachmentKind, contentType string) (*UploadURLResponse, error)
+	ConfirmFeedbackAttachment(ctx context.Context, trainerID, assignmentID primitive.ObjectID, kind domain.FeedbackAttachmentKind, objectKey, fileName string, fileSize int64, contentType string, durationMs *int64) (*domain.Assignment, error)
+	GetFeedbackAttachments(ctx context.Context, trainerID, assignmentID primitive.ObjectID) ([]FeedbackAttachmentDetails, error)
+	DeleteFeedbackAttachment(ctx context.Context, trainerID, assignmentID, attachmentID primitive.ObjectID) (*domain.Assignment, error)
+	// Video storage used by the trainer's clients, against the configured quotas
+	GetStorageUsage(ctx context.Context, trainerID primitive.ObjectID) (*TrainerStorageUsage, error)
+	// Submitted assignments awaiting review across clients, oldest first; clientID optional
+	GetReviewQueue(ctx context.Context, trainerID primitive.ObjectID, clientID *primitive.ObjectID) ([]ReviewQueueItem, error)
 	// Existing Assignment Management (will be adapted or removed)
 	//GetAssignmentsByTrainer(ctx context.Context, trainerID primitive.ObjectID) ([]domain.Assignment, error)
 	SubmitFeedback(ctx context.Context, trainerID, assignmentID primitive.ObjectID, feedback string, newStatus domain.AssignmentStatus) (*domain.Assignment, error)
+	// Feedback and/or status for many assignments; items fail independently
+	BulkSubmitFeedback(ctx context.Context, trainerID primitive.ObjectID, items []BulkFeedbackItem) ([]BulkFeedbackResult, error)
 
 	UpdateTrainingPlan(ctx context.Context, trainerID, planID primitive.ObjectID, updatedDetails domain.TrainingPlan) (*domain.TrainingPlan, error)
 	DeleteTrainingPlan(ctx context.Context, trainerID, planID primitive.ObjectID) error
+	// Moves a plan to draft, scheduled, active or archived; completed is set by the lifecycle job
+	SetTrainingPlanStatus(ctx context.Context, trainerID, planID primitive.ObjectID, status domain.PlanStatus) (*domain.TrainingPlan, error)
 
 	UpdateWorkout(ctx context.Context, trainerID, planID, workoutID primitive.ObjectID, updates domain.Workout) (*domain.Workout, error)
 	DeleteWorkout(ctx context.Context, trainerID, planID, workoutID primitive.ObjectID) error
@@ -61,6 +101,17 @@ type TrainerService interface {
 	UpdateAssignmentInWorkout(ctx context.Context, trainerID, workoutID, assignmentID primitive.ObjectID, updates domain.Assignment) (*domain.Assignment, error)
 	DeleteAssignmentFromWorkout(ctx context.Context, trainerID, workoutID, assignmentID primitive.ObjectID) error
 
+	// --- Workout Blocks (supersets, circuits, EMOM, AMRAP) ---
+	CreateWorkoutBlock(ctx context.Context, trainerID, workoutID primitive.ObjectID, block domain.WorkoutBlock) (*domain.WorkoutBlock, error)
+	UpdateWorkoutBlock(ctx context.Context, trainerID, workoutID, blockID primitive.ObjectID, updates domain.WorkoutBlock) (*domain.WorkoutBlock, error)
+	DeleteWorkoutBlock(ctx context.Context, trainerID, workoutID, blockID primitive.ObjectID) error
+	GetWorkoutStructure(ctx context.Context, trainerID, workoutID primitive.ObjectID) ([]WorkoutBlockDetails, error)
+
+	// --- Bulk Ordering & Editing (all-or-nothing) ---
+	ReorderAssignments(ctx context.Context, trainerID, workoutID primitive.ObjectID, orderedIDs []primitive.ObjectID) ([]domain.Assignment, error)
+	BatchEditAssignments(ctx context.Context, trainerID, workoutID primitive.ObjectID, ops []AssignmentBatchOperation) ([]AssignmentBatchResult, error)
+	ReorderWorkouts(ctx context.Context, trainerID, planID primitive.ObjectID, orderedIDs []primitive.ObjectID) ([]domain.Workout, error)
+
 }
 
 // --- Service Implementation ---
@@ -73,7 +124,12 @@ type trainerService struct {
 	trainingPlanRepo  repository.TrainingPlanRepository
   workoutRepo repository.WorkoutRepository
 	uploadRepo        repository.UploadRepository
+	workoutBlockRepo  repository.WorkoutBlockRepository
+	planDraftRepo     repository.PlanDraftRepository
+	transactor        repository.Transactor
 	fileStorage       storage.FileStorage
+	uploadLimits      UploadLimits
+	events            realtime.Publisher
 }
 
 // NewTrainerService creates a new instance of trainerService.
@@ -84,7 +140,12 @@ func NewTrainerService(
 	trainingPlanRepo repository.TrainingPlanRepository,
 	workoutRepo repository.WorkoutRepository,
 	uploadRepo repository.UploadRepository,
+	workoutBlockRepo repository.WorkoutBlockRepository,
+	planDraftRepo repository.PlanDraftRepository,
+	transactor repository.Transactor,
 	fileStorage storage.FileStorage, 
+	uploadLimits UploadLimits,
+	events realtime.Publisher,
 	) TrainerService {
 		return &trainerService{
 			userRepo:          userRepo,
@@ -93,7 +154,12 @@ func NewTrainerService(
 			trainingPlanRepo:  trainingPlanRepo,
 			workoutRepo:       workoutRepo,
 			uploadRepo:        uploadRepo,
+			workoutBlockRepo:  workoutBlockRepo,
+			planDraftRepo:     planDraftRepo,
+			transactor:        transactor,
 			fileStorage:       fileStorage,
+			uploadLimits:      uploadLimits,
+			events:            events,
 		}
 }
 
@@ -149,6 +215,12 @@ func (s *trainerService) AddClientByEmail(ctx context.Context, trainerID primiti
 
 	// Return the updated client object (refetch if needed to get updated fields)
 	client.TrainerID = &trainerID // Update in memory object for return
+
+	summary := "A trainer added you as a client"
+	if trainer, err := s.userRepo.GetByID(ctx, trainerID); err == nil && trainer.Name != "" {
+		summary = trainer.Name + " added you as a client"
+	}
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventClientLinked, trainerID, summary, map[string]any{"trainerId": trainerID, "clientId": client.ID}), client.ID)
 	return client, nil
 }
 
@@ -209,14 +281,28 @@ func (s *trainerService) CreateWorkout(ctx context.Context, trainerID, planID pr
 		// ID, CreatedAt, UpdatedAt set by repo
 	}
 
-	// 4. Call repository to save
+	// 4. The client keeps seeing a published plan as it was published; the new workout
+	//    waits in the plan's draft.
+	if plan.IsPublished() {
+		now := time.Now().UTC()
+		workout.ID = primitive.NewObjectID()
+		workout.CreatedAt = now
+		workout.UpdatedAt = now
+		edit := domain.PlanDraftEdit{Workouts: []domain.Workout{*workout}, Added: []primitive.ObjectID{workout.ID}}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, planID, edit); err != nil {
+			return nil, ErrWorkoutCreationFailed
+		}
+		return workout, nil
+	}
+
+	// 5. Call repository to save
 	workoutID, err := s.workoutRepo.Create(ctx, workout)
 	if err != nil {
 		// log.Printf("Error saving workout: %v", err)
 		return nil, ErrWorkoutCreationFailed
 	}
 
-	// 5. Fetch and return the full workout
+	// 6. Fetch and return the full workout
 	createdWorkout, err := s.workoutRepo.GetByID(ctx, workoutID)
 	if err != nil {
 		// log.Printf("Failed to fetch newly created workout %s: %v", workoutID.Hex(), err)
@@ -252,7 +338,12 @@ func (s *trainerService) GetWorkoutsForPlan(ctx context.Context, trainerID, plan
 		// log.Printf("Error fetching workouts for plan %s: %v", planID.Hex(), err)
 		return nil, errors.New("failed to retrieve workouts")
 	}
-	return workouts, nil
+	// 4. The trainer sees their unpublished edits
+	draft, err := s.planDraft(ctx, planID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workouts")
+	}
+	return overlayWorkouts(workouts, draft), nil
 }
 
 // SubmitFeedback updates an assignment with feedback and potentially a new status.
@@ -262,8 +353,8 @@ func (s *trainerService) SubmitFeedback(ctx context.Context, trainerID, assignme
 		return nil, errors.New("trainer ID and assignment ID are required")
 	}
 	// Validate feedback length, content? Validate status transition?
-	if newStatus != "" && !(newStatus == domain.StatusReviewed || newStatus == domain.StatusCompleted /*|| other valid transitions*/) {
-		return nil, errors.New("invalid status transition for feedback")
+	if !isValidFeedbackStatus(newStatus) {
+		return nil, ErrInvalidFeedbackStatus
 	}
 
 	// 2. Get the assignment
@@ -293,6 +384,7 @@ func (s *trainerService) SubmitFeedback(ctx context.Context, trainerID, assignme
 	}
 
 	// 4. Update fields
+	previousStatus := assignment.Status
 	assignment.Feedback = feedback
 	if newStatus != "" {
 		assignment.Status = newStatus
@@ -308,6 +400,7 @@ func (s *trainerService) SubmitFeedback(ctx context.Context, trainerID, assignme
 		return nil, err
 	}
 
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventFeedback, trainerID, "Your trainer left feedback on an exercise", feedbackEventData(assignment, previousStatus)), workout.ClientID)
 	return assignment, nil
 }
 
@@ -332,17 +425,9 @@ func (s *trainerService) CreateTrainingPlan(ctx context.Context, trainerID, clie
 		return nil, ErrClientNotManaged // Trainer does not manage this client
 	}
 
-    // 3. Optional: Logic for isActive flag
-    // If setting this plan to active, ensure no other plan for this client is active.
-    // This requires an extra repository method like `DeactivateAllPlansForClient` or `GetActivePlanForClient`.
-    // For simplicity now, we'll just set it as provided. Add this logic later if needed.
-    // if isActive {
-    //     err := s.trainingPlanRepo.DeactivateAllPlansForClient(ctx, clientID, trainerID)
-    //     // handle error
-    // }
-
-
-	// 4. Create domain object
+	// 3. Create domain object. New plans are drafts the client cannot see; publishing
+	//    validates them and activates (or schedules) them. isActive is therefore
+	//    ignored here.
 	plan := &domain.TrainingPlan{
 		TrainerID:   trainerID,
 		ClientID:    clientID,
@@ -350,9 +435,9 @@ func (s *trainerService) CreateTrainingPlan(ctx context.Context, trainerID, clie
 		Description: description,
 		StartDate:   startDate,
 		EndDate:     endDate,
-		IsActive:    isActive,
 		// ID, CreatedAt, UpdatedAt set by repo
 	}
+	plan.SetStatus(domain.PlanStatusDraft)
 
 	// 5. Call repository to save
 	planID, err := s.trainingPlanRepo.Create(ctx, plan)
@@ -394,7 +479,15 @@ func (s *trainerService) GetTrainingPlansForClient(ctx context.Context, trainerI
 	return plans, nil
 }
 
+// AssignExerciseToWorkout adds an exercise to a workout. Its sequence must not already be
+// used by another assignment of the same block (or by another ungrouped one).
 func (s *trainerService) AssignExerciseToWorkout(ctx context.Context, trainerID, workoutID, exerciseID primitive.ObjectID, assignmentDetails domain.Assignment) (*domain.Assignment, error) {
+	return s.assignExerciseToWorkout(ctx, trainerID, workoutID, exerciseID, assignmentDetails, true)
+}
+
+// assignExerciseToWorkout implements AssignExerciseToWorkout. BatchEditAssignments checks
+// sequences for the whole batch instead, as they may only be unique once it is complete.
+func (s *trainerService) assignExerciseToWorkout(ctx context.Context, trainerID, workoutID, exerciseID primitive.ObjectID, assignmentDetails domain.Assignment, uniqueSequence bool) (*domain.Assignment, error) {
 	// 1. Validate Inputs
 	if trainerID == primitive.NilObjectID || workoutID == primitive.NilObjectID || exerciseID == primitive.NilObjectID {
 			return nil, errors.New("trainer ID, workout ID, and exercise ID are required")
@@ -402,16 +495,9 @@ func (s *trainerService) AssignExerciseToWorkout(ctx context.Context, trainerID,
 	// TODO: Add validation for assignmentDetails fields (e.g., sets > 0, valid reps format?)
 
 	// 2. Validate Workout Access (Trainer owns the workout)
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	workout, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-					return nil, ErrWorkoutNotFound // Use specific error
-			}
-			return nil, err // Other repo errors
-	}
-	if workout.TrainerID != trainerID {
-			// Check if the trainer owns the workout they are assigning to
-			return nil, errors.New("access denied: trainer does not own this workout") // More specific error?
+			return nil, err
 	}
 
 	// 3. Validate Exercise Access (Trainer owns the exercise)
@@ -432,12 +518,47 @@ func (s *trainerService) AssignExerciseToWorkout(ctx context.Context, trainerID,
 	// We just need to ensure the core IDs and potentially sequence are set correctly.
 	assignmentDetails.WorkoutID = workoutID
 	assignmentDetails.ExerciseID = exerciseID
+	assignmentDetails.TrainerID = workout.TrainerID
+	assignmentDetails.ClientID = workout.ClientID
+	if assignmentDetails.BlockID != nil {
+			if _, err := s.getEditableBlock(ctx, workoutID, *assignmentDetails.BlockID, draft); err != nil {
+					return nil, err
+			}
+	}
+	// Pin the exercise content the client will see; later edits create new revisions.
+	// Unversioned (legacy) exercises report 0 and get pinned when first edited.
+	assignmentDetails.ExerciseRevision = exercise.CurrentRevision
+	if uniqueSequence {
+			siblings, err := s.workoutAssignments(ctx, workoutID, draft)
+			if err != nil {
+					return nil, errors.New("failed to retrieve assignments for the workout")
+			}
+			if err := checkSequenceFree(siblings, primitive.NilObjectID, assignmentDetails.BlockID, assignmentDetails.Sequence); err != nil {
+					return nil, err
+			}
+	}
 	// We could potentially fetch existing assignments for the workout to auto-increment sequence,
 	// or rely on the caller providing it. Let's assume caller provides it for now.
 	// if assignmentDetails.Sequence <= 0 { ... handle default sequence ... }
 
+	// 5. The client keeps seeing a published plan as it was published; the new exercise
+	//    waits in the plan's draft.
+	if plan.IsPublished() {
+			now := time.Now().UTC()
+			assignmentDetails.ID = primitive.NewObjectID()
+			assignmentDetails.AssignedAt = now
+			assignmentDetails.UpdatedAt = now
+			if assignmentDetails.Status == "" {
+					assignmentDetails.Status = domain.StatusAssigned
+			}
+			edit := domain.PlanDraftEdit{Assignments: []domain.Assignment{assignmentDetails}, Added: []primitive.ObjectID{assignmentDetails.ID}}
+			if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+					return nil, errors.New("failed to create assignment record")
+			}
+			return &assignmentDetails, nil
+	}
 
-	// 5. Call repository to save the assignment
+	// 6. Call repository to save the assignment
 	// Assuming assignmentRepo.Create takes the full assignment struct now
 	createdAssignmentID, err := s.assignmentRepo.Create(ctx, &assignmentDetails) // Pass pointer
 	if err != nil {
@@ -445,7 +566,7 @@ func (s *trainerService) AssignExerciseToWorkout(ctx context.Context, trainerID,
 			return nil, errors.New("failed to create assignment record")
 	}
 
-	// 6. Fetch and return the full assignment with generated fields
+	// 7. Fetch and return the full assignment with generated fields
 	fullAssignment, err := s.assignmentRepo.GetByID(ctx, createdAssignmentID)
 	if err != nil {
 			// log.Printf("Failed to fetch newly created assignment %s: %v", createdAssignmentID.Hex(), err)
@@ -464,19 +585,13 @@ func (s *trainerService) GetAssignmentsForWorkout(ctx context.Context, trainerID
 	}
 
 	// 2. Validate Workout Access (Trainer owns the workout)
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	_, _, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-					return nil, ErrWorkoutNotFound
-			}
 			return nil, err
 	}
-	if workout.TrainerID != trainerID {
-			return nil, errors.New("access denied: trainer does not own this workout")
-	}
 
-	// 3. Call repository to get assignments for this workout
-	assignments, err := s.assignmentRepo.GetByWorkoutID(ctx, workoutID)
+	// 3. Call repository to get assignments for this workout; the trainer sees their unpublished changes
+	assignments, err := s.workoutAssignments(ctx, workoutID, draft)
 	if err != nil {
 			// log.Printf("Error fetching assignments for workout %s: %v", workoutID.Hex(), err)
 			return nil, errors.New("failed to retrieve assignments for workout")
@@ -540,6 +655,443 @@ func (s *trainerService) GetAssignmentVideoDownloadURL(ctx context.Context, trai
 	return downloadURL, nil
 }
 
+// GetUploadsForAssignment lists every upload a client made for an assignment the trainer owns.
+func (s *trainerService) GetUploadsForAssignment(ctx context.Context, trainerID, assignmentID primitive.ObjectID) ([]UploadDetails, error) {
+	if trainerID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and assignment ID are required")
+	}
+
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.getOwnedWorkout(ctx, trainerID, assignment.WorkoutID); err != nil {
+		if errors.Is(err, ErrWorkoutAccessDenied) {
+			return nil, ErrAssignmentAccessDenied
+		}
+		return nil, err
+	}
+
+	return presignUploads(ctx, s.uploadRepo, s.fileStorage, assignment)
+}
+
+// === Feedback Attachments (trainer video / voice replies) ===
+
+// maxFeedbackAttachmentSize caps a trainer's reply; these are short clips, not workouts.
+const maxFeedbackAttachmentSize int64 = 512 << 20 // 512 MiB
+
+// FeedbackAttachmentDetails is an attachment with a short-lived download URL.
+type FeedbackAttachmentDetails struct {
+	domain.FeedbackAttachment
+	DownloadURL string `json:"downloadUrl"`
+}
+
+// feedbackAttachmentPrefix is where a trainer's replies to one assignment are stored.
+func feedbackAttachmentPrefix(trainerID, assignmentID primitive.ObjectID) string {
+	return path.Join("feedback", trainerID.Hex(), assignmentID.Hex()) + "/"
+}
+
+// validateFeedbackAttachmentType checks that the MIME type fits the attachment kind.
+func validateFeedbackAttachmentType(kind domain.FeedbackAttachmentKind, contentType string) error {
+	if !kind.IsValid() || !strings.HasPrefix(strings.ToLower(contentType), string(kind)+"/") {
+		return ErrInvalidFeedbackAttachment
+	}
+	return nil
+}
+
+// getOwnedAssignment fetches an assignment and verifies (via its workout) that the trainer owns it.
+func (s *trainerService) getOwnedAssignment(ctx context.Context, trainerID, assignmentID primitive.ObjectID) (*domain.Assignment, error) {
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.getOwnedWorkout(ctx, trainerID, assignment.WorkoutID); err != nil {
+		if errors.Is(err, ErrWorkoutAccessDenied) {
+			return nil, ErrAssignmentAccessDenied
+		}
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// RequestFeedbackAttachmentUploadURL issues a pre-signed URL for a trainer's reply to an assignment.
+func (s *trainerService) RequestFeedbackAttachmentUploadURL(ctx context.Context, trainerID, assignmentID primitive.ObjectID, kind domain.FeedbackAttachmentKind, contentType string) (*UploadURLResponse, error) {
+	if trainerID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and assignment ID are required")
+	}
+	if err := validateFeedbackAttachmentType(kind, contentType); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedAssignment(ctx, trainerID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	fileExtension := ""
+	parts := strings.Split(contentType, "/")
+	if len(parts) == 2 { fileExtension = parts[1] }
+	objectKey := feedbackAttachmentPrefix(trainerID, assignmentID) + fmt.Sprintf("%s.%s", uuid.NewString(), fileExtension)
+
+	uploadURL, err := s.fileStorage.GeneratePresignedUploadURL(ctx, objectKey, contentType, storage.DefaultPresignedURLExpiry)
+	if err != nil {
+		return nil, ErrUploadURLError
+	}
+	return &UploadURLResponse{UploadURL: uploadURL, ObjectKey: objectKey}, nil
+}
+
+// ConfirmFeedbackAttachment verifies the uploaded object and attaches it to the assignment.
+func (s *trainerService) ConfirmFeedbackAttachment(ctx context.Context, trainerID, assignmentID primitive.ObjectID, kind domain.FeedbackAttachmentKind, objectKey, fileName string, fileSize int64, contentType string, durationMs *int64) (*domain.Assignment, error) {
+	if trainerID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || objectKey == "" {
+		return nil, errors.New("trainer ID, assignment ID, and object key are required")
+	}
+	if err := validateFeedbackAttachmentType(kind, contentType); err != nil {
+		return nil, err
+	}
+	assignment, err := s.getOwnedAssignment(ctx, trainerID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key must be one we handed out for this assignment, and the object must match what was reported.
+	prefix := feedbackAttachmentPrefix(trainerID, assignmentID)
+	if path.Clean(objectKey) != objectKey || !strings.HasPrefix(objectKey, prefix) || len(objectKey) == len(prefix) {
+		return nil, ErrUploadObjectKeyInvalid
+	}
+	metadata, err := s.fileStorage.GetObjectMetadata(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrUploadObjectNotFound
+		}
+		return nil, fmt.Errorf("%w: could not read object metadata", ErrUploadConfirmationFailed)
+	}
+	if metadata.Size != fileSize || !sameMediaType(metadata.ContentType, contentType) {
+		return nil, fmt.Errorf("%w: stored %d bytes of %q", ErrUploadMetadataMismatch, metadata.Size, metadata.ContentType)
+	}
+	if metadata.Size > maxFeedbackAttachmentSize {
+		_ = s.fileStorage.DeleteObject(ctx, objectKey)
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrUploadTooLarge, metadata.Size, maxFeedbackAttachmentSize)
+	}
+
+	attachment := domain.FeedbackAttachment{
+		ID:          primitive.NewObjectID(),
+		Kind:        kind,
+		S3ObjectKey: objectKey,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        fileSize,
+		DurationMs:  durationMs,
+		UploadedAt:  time.Now().UTC(),
+	}
+	if err := s.assignmentRepo.AddFeedbackAttachment(ctx, assignmentID, attachment); err != nil {
+		return nil, ErrUploadConfirmationFailed
+	}
+	assignment.FeedbackAttachments = append(assignment.FeedbackAttachments, attachment)
+
+	summary := "Your trainer sent a video reply"
+	if kind == domain.FeedbackAttachmentAudio {
+		summary = "Your trainer sent a voice reply"
+	}
+	data := assignmentEventData(assignment)
+	data["attachmentId"] = attachment.ID
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventFeedback, trainerID, summary, data), assignment.ClientID)
+	return assignment, nil
+}
+
+// GetFeedbackAttachments lists the trainer's replies on an assignment with download URLs.
+func (s *trainerService) GetFeedbackAttachments(ctx context.Context, trainerID, assignmentID primitive.ObjectID) ([]FeedbackAttachmentDetails, error) {
+	if trainerID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and assignment ID are required")
+	}
+	assignment, err := s.getOwnedAssignment(ctx, trainerID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return presignFeedbackAttachments(ctx, s.fileStorage, assignment.FeedbackAttachments)
+}
+
+// DeleteFeedbackAttachment detaches a reply and removes its file.
+func (s *trainerService) DeleteFeedbackAttachment(ctx context.Context, trainerID, assignmentID, attachmentID primitive.ObjectID) (*domain.Assignment, error) {
+	if trainerID == primitive.NilObjectID || assignmentID == primitive.NilObjectID || attachmentID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID, assignment ID, and attachment ID are required")
+	}
+	assignment, err := s.getOwnedAssignment(ctx, trainerID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, a := range assignment.FeedbackAttachments {
+		if a.ID == attachmentID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrFeedbackAttachmentNotFound
+	}
+	removed := assignment.FeedbackAttachments[index]
+
+	if err := s.assignmentRepo.RemoveFeedbackAttachment(ctx, assignmentID, attachmentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFeedbackAttachmentNotFound
+		}
+		return nil, errors.New("failed to delete feedback attachment")
+	}
+	// Metadata is gone, so a failed object delete only leaves an unreferenced file behind.
+	if err := s.fileStorage.DeleteObject(ctx, removed.S3ObjectKey); err != nil {
+		log.Printf("WARN: Failed to delete feedback attachment object %s: %v", removed.S3ObjectKey, err)
+	}
+	assignment.FeedbackAttachments = append(assignment.FeedbackAttachments[:index], assignment.FeedbackAttachments[index+1:]...)
+	return assignment, nil
+}
+
+// presignFeedbackAttachments attaches a short-lived download URL to each attachment.
+func presignFeedbackAttachments(ctx context.Context, fileStorage storage.FileStorage, attachments []domain.FeedbackAttachment) ([]FeedbackAttachmentDetails, error) {
+	details := make([]FeedbackAttachmentDetails, 0, len(attachments))
+	for _, a := range attachments {
+		downloadURL, err := fileStorage.GeneratePresignedDownloadURL(ctx, a.S3ObjectKey, storage.DefaultPresignedURLExpiry)
+		if err != nil {
+			return nil, ErrDownloadURLError
+		}
+		details = append(details, FeedbackAttachmentDetails{FeedbackAttachment: a, DownloadURL: downloadURL})
+	}
+	return details, nil
+}
+
+// ClientStorageUsage is one client's share of a trainer's video storage.
+type ClientStorageUsage struct {
+	ClientID    primitive.ObjectID `json:"clientId"`
+	Name        string             `json:"name"`
+	Email       string             `json:"email"`
+	TotalBytes  int64              `json:"totalBytes"`
+	UploadCount int64              `json:"uploadCount"`
+	QuotaBytes  int64              `json:"quotaBytes"` // 0 = unlimited
+}
+
+// TrainerStorageUsage reports a trainer's video storage against the quotas.
+type TrainerStorageUsage struct {
+	TotalBytes  int64                `json:"totalBytes"`
+	UploadCount int64                `json:"uploadCount"`
+	QuotaBytes  int64                `json:"quotaBytes"`  // 0 = unlimited
+	MaxFileSize int64                `json:"maxFileSize"` // 0 = only the per-content-type limits apply
+	Clients     []ClientStorageUsage `json:"clients"`     // Largest first
+}
+
+// GetStorageUsage totals the trainer's uploads per client. Every managed client is
+// listed, including ones with no uploads yet.
+func (s *trainerService) GetStorageUsage(ctx context.Context, trainerID primitive.ObjectID) (*TrainerStorageUsage, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+
+	total, perClient, err := trainerStorageUsage(ctx, s.uploadRepo, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load storage usage")
+	}
+	clients, err := s.userRepo.GetClientsByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load clients")
+	}
+	clientsByID := make(map[primitive.ObjectID]domain.User, len(clients))
+	for _, c := range clients {
+		clientsByID[c.ID] = c
+	}
+
+	usage := &TrainerStorageUsage{
+		TotalBytes:  total,
+		QuotaBytes:  s.uploadLimits.TrainerQuota,
+		MaxFileSize: s.uploadLimits.MaxFileSize,
+		Clients:     make([]ClientStorageUsage, 0, len(clients)),
+	}
+	seen := make(map[primitive.ObjectID]bool, len(perClient))
+	for _, u := range perClient {
+		usage.UploadCount += u.UploadCount
+		seen[u.ClientID] = true
+		client := clientsByID[u.ClientID] // Former clients keep their uploads; names may be blank
+		usage.Clients = append(usage.Clients, ClientStorageUsage{
+			ClientID:    u.ClientID,
+			Name:        client.Name,
+			Email:       client.Email,
+			TotalBytes:  u.TotalBytes,
+			UploadCount: u.UploadCount,
+			QuotaBytes:  s.uploadLimits.ClientQuota,
+		})
+	}
+	for _, c := range clients {
+		if !seen[c.ID] {
+			usage.Clients = append(usage.Clients, ClientStorageUsage{
+				ClientID:   c.ID,
+				Name:       c.Name,
+				Email:      c.Email,
+				QuotaBytes: s.uploadLimits.ClientQuota,
+			})
+		}
+	}
+	return usage, nil
+}
+
+// ReviewQueueItem is one submitted assignment awaiting the trainer's review.
+type ReviewQueueItem struct {
+	Assignment    domain.Assignment
+	ClientName    string
+	ClientEmail   string
+	ExerciseName  string
+	WorkoutName   string
+	PrimaryUpload *domain.Upload // Nil if the primary upload's record is gone
+	UploadCount   int64
+}
+
+// GetReviewQueue lists the submitted assignments of all the trainer's clients (or just
+// clientID's), oldest submission first, with the names and upload details needed to triage.
+func (s *trainerService) GetReviewQueue(ctx context.Context, trainerID primitive.ObjectID, clientID *primitive.ObjectID) ([]ReviewQueueItem, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+
+	clients, err := s.userRepo.GetClientsByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to load clients")
+	}
+	clientsByID := make(map[primitive.ObjectID]domain.User, len(clients))
+	for _, c := range clients {
+		clientsByID[c.ID] = c
+	}
+	if clientID != nil {
+		if _, ok := clientsByID[*clientID]; !ok {
+			return nil, ErrClientNotManaged
+		}
+	}
+
+	assignments, err := s.assignmentRepo.GetSubmittedByTrainer(ctx, trainerID, clientID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve review queue")
+	}
+
+	exerciseNames := make(map[primitive.ObjectID]string)
+	workoutNames := make(map[primitive.ObjectID]string)
+	items := make([]ReviewQueueItem, 0, len(assignments))
+	for _, a := range assignments {
+		client := clientsByID[a.ClientID] // Former clients' submissions stay in the queue; names may be blank
+		item := ReviewQueueItem{
+			Assignment:  a,
+			ClientName:  client.Name,
+			ClientEmail: client.Email,
+		}
+
+		name, ok := exerciseNames[a.ExerciseID]
+		if !ok {
+			if exercise, err := s.exerciseRepo.GetByID(ctx, a.ExerciseID); err == nil {
+				name = exercise.Name
+			}
+			exerciseNames[a.ExerciseID] = name
+		}
+		item.ExerciseName = name
+
+		name, ok = workoutNames[a.WorkoutID]
+		if !ok {
+			if workout, err := s.workoutRepo.GetByID(ctx, a.WorkoutID); err == nil {
+				name = workout.Name
+			}
+			workoutNames[a.WorkoutID] = name
+		}
+		item.WorkoutName = name
+
+		if a.UploadID != nil {
+			if upload, err := s.uploadRepo.GetByID(ctx, *a.UploadID); err == nil {
+				item.PrimaryUpload = upload
+			}
+		}
+		if count, err := s.uploadRepo.CountByAssignmentID(ctx, a.ID); err == nil {
+			item.UploadCount = count
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// isValidFeedbackStatus reports whether a trainer may set status while giving feedback.
+// Empty means "leave the status unchanged".
+func isValidFeedbackStatus(status domain.AssignmentStatus) bool {
+	return status == "" || status == domain.StatusReviewed || status == domain.StatusCompleted /*|| other valid transitions*/
+}
+
+// BulkFeedbackItem is the feedback and/or status change for one assignment.
+// A nil Feedback leaves the existing feedback untouched; an empty Status leaves the status.
+type BulkFeedbackItem struct {
+	AssignmentID primitive.ObjectID
+	Feedback     *string
+	Status       domain.AssignmentStatus
+}
+
+// BulkFeedbackResult reports the outcome of one item of a bulk feedback request.
+// Err is nil on success, in which case Assignment holds the updated assignment.
+type BulkFeedbackResult struct {
+	Index        int
+	AssignmentID primitive.ObjectID
+	Assignment   *domain.Assignment
+	Err          error
+}
+
+// BulkSubmitFeedback applies feedback and/or a status change to each item in turn. Unlike
+// BatchEditAssignments it is not all-or-nothing: a missing, foreign or invalid item is
+// reported in its result and the rest are still applied. The returned error is only for
+// a malformed request as a whole.
+func (s *trainerService) BulkSubmitFeedback(ctx context.Context, trainerID primitive.ObjectID, items []BulkFeedbackItem) ([]BulkFeedbackResult, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no items", ErrInvalidBulkFeedback)
+	}
+	if len(items) > maxBulkFeedbackItems {
+		return nil, fmt.Errorf("%w: at most %d items per request", ErrInvalidBulkFeedback, maxBulkFeedbackItems)
+	}
+
+	results := make([]BulkFeedbackResult, len(items))
+	seen := make(map[primitive.ObjectID]bool, len(items))
+	for i, item := range items {
+		results[i] = BulkFeedbackResult{Index: i, AssignmentID: item.AssignmentID}
+		switch {
+		case item.AssignmentID == primitive.NilObjectID:
+			results[i].Err = fmt.Errorf("%w: assignment ID is required", ErrInvalidBulkFeedback)
+		case seen[item.AssignmentID]:
+			results[i].Err = fmt.Errorf("%w: assignment listed more than once", ErrInvalidBulkFeedback)
+		case item.Feedback == nil && item.Status == "":
+			results[i].Err = fmt.Errorf("%w: feedback or status is required", ErrInvalidBulkFeedback)
+		case !isValidFeedbackStatus(item.Status):
+			results[i].Err = ErrInvalidFeedbackStatus
+		default:
+			results[i].Assignment, results[i].Err = s.applyFeedback(ctx, trainerID, item)
+		}
+		seen[item.AssignmentID] = true
+	}
+	return results, nil
+}
+
+// applyFeedback updates one owned assignment for BulkSubmitFeedback.
+func (s *trainerService) applyFeedback(ctx context.Context, trainerID primitive.ObjectID, item BulkFeedbackItem) (*domain.Assignment, error) {
+	assignment, err := s.getOwnedAssignment(ctx, trainerID, item.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := assignment.Status
+	// Only feedback and status are written, so a set the client logs meanwhile survives.
+	assignment, err = s.assignmentRepo.UpdateFeedback(ctx, item.AssignmentID, item.Feedback, item.Status)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, errors.New("failed to update assignment")
+	}
+	s.events.Publish(ctx, realtime.NewEvent(realtime.EventFeedback, trainerID, "Your trainer left feedback on an exercise", feedbackEventData(assignment, previousStatus)), assignment.ClientID)
+	return assignment, nil
+}
+
 // === UpdateTrainingPlan Implementation ===
 func (s *trainerService) UpdateTrainingPlan(ctx context.Context, trainerID, planID primitive.ObjectID, updates domain.TrainingPlan) (*domain.TrainingPlan, error) {
     // 1. Validate Inputs
@@ -569,29 +1121,43 @@ func (s *trainerService) UpdateTrainingPlan(ctx context.Context, trainerID, plan
     }
 
 
-    //5. Optional: Logic for `isActive` flag
-    //If `updates.IsActive` is true and `existingPlan.IsActive` was false,
-    //you might want to deactivate other active plans for this client by this trainer.
-    if updates.IsActive && !existingPlan.IsActive {
-        err := s.trainingPlanRepo.DeactivateOtherPlansForClient(ctx, existingPlan.ClientID, trainerID, planID)
-        if err != nil {
-            // Log error but proceed with update? Or fail?
-            log.Printf("Warning: Failed to deactivate other plans for client %s: %v", existingPlan.ClientID.Hex(), err)
-        }
+    // 5. Apply updates to the fetched plan object
+    wasActive := existingPlan.IsActive
+    if !sameDate(existingPlan.EndDate, updates.EndDate) {
+        existingPlan.EndingNotifiedAt = nil // Warn again about the new end date
     }
-
-    // 6. Apply updates to the fetched plan object
     existingPlan.Name = updates.Name
     existingPlan.Description = updates.Description
     existingPlan.StartDate = updates.StartDate
     existingPlan.EndDate = updates.EndDate
-    existingPlan.IsActive = updates.IsActive
     // existingPlan.UpdatedAt will be set by repo.Update()
 
-    // 7. Call repository to save changes
-    err = s.trainingPlanRepo.Update(ctx, existingPlan)
+    // 6. `isActive` drives the lifecycle: true schedules or activates the plan (depending
+    //    on StartDate), false takes it back to draft. Ended and archived plans stay put
+    //    unless reactivated. Only published plans can be activated.
+    switch status := existingPlan.EffectiveStatus(); {
+    case updates.IsActive && !existingPlan.IsPublished():
+        return nil, ErrPlanNotPublished
+    case updates.IsActive:
+        existingPlan.SetStatus(activationStatus(existingPlan, time.Now()))
+    case status == domain.PlanStatusActive || status == domain.PlanStatusScheduled:
+        existingPlan.SetStatus(domain.PlanStatusDraft)
+    default:
+        existingPlan.SetStatus(status)
+    }
+    // 7. Save the changes and, if the plan just became active, deactivate the client's
+    //    other plans in the same transaction.
+    err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+        if err := s.trainingPlanRepo.Update(txCtx, existingPlan); err != nil {
+            return err
+        }
+        if existingPlan.IsActive && !wasActive {
+            return s.trainingPlanRepo.DeactivateOtherPlansForClient(txCtx, existingPlan.ClientID, trainerID, planID)
+        }
+        return nil
+    })
     if err != nil {
-        // log.Printf("Error updating training plan in service %s: %v", planID.Hex(), err)
+        log.Printf("ERROR: Failed to update training plan %s: %v", planID.Hex(), err)
         return nil, errors.New("failed to update training plan details")
     }
 
@@ -599,6 +1165,63 @@ func (s *trainerService) UpdateTrainingPlan(ctx context.Context, trainerID, plan
     return existingPlan, nil // Or return s.trainingPlanRepo.GetByID(ctx, planID)
 }
 
+// SetTrainingPlanStatus moves one of the trainer's plans to status. Scheduling needs a
+// future StartDate; activating needs the plan's dates to include today and replaces the
+// client's current plan. Both need the plan to be published.
+func (s *trainerService) SetTrainingPlanStatus(ctx context.Context, trainerID, planID primitive.ObjectID, status domain.PlanStatus) (*domain.TrainingPlan, error) {
+	plan, err := s.trainingPlanRepo.GetByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTrainingPlanNotFound
+		}
+		return nil, err
+	}
+	if plan.TrainerID != trainerID {
+		return nil, ErrTrainingPlanAccessDenied
+	}
+
+	now := time.Now()
+	if (status == domain.PlanStatusScheduled || status == domain.PlanStatusActive) && !plan.IsPublished() {
+		return nil, ErrPlanNotPublished
+	}
+	switch status {
+	case domain.PlanStatusDraft, domain.PlanStatusArchived:
+	case domain.PlanStatusScheduled:
+		if plan.StartDate == nil || !plan.StartDate.After(now) {
+			return nil, fmt.Errorf("%w: a scheduled plan needs a startDate in the future", ErrInvalidPlanStatus)
+		}
+	case domain.PlanStatusActive:
+		if plan.StartDate != nil && plan.StartDate.After(now) {
+			return nil, fmt.Errorf("%w: the plan starts in the future; schedule it instead", ErrInvalidPlanStatus)
+		}
+		if plan.EndDate != nil && now.After(*plan.EndDate) {
+			return nil, fmt.Errorf("%w: the plan's endDate has passed", ErrInvalidPlanStatus)
+		}
+	case domain.PlanStatusCompleted:
+		return nil, fmt.Errorf("%w: plans complete automatically after their endDate", ErrInvalidPlanStatus)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPlanStatus, status)
+	}
+
+	wasActive := plan.IsActive
+	plan.SetStatus(status)
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.trainingPlanRepo.Update(txCtx, plan); err != nil {
+			return err
+		}
+		if plan.IsActive && !wasActive {
+			// Only one active plan per client: this one replaces the current one.
+			return s.trainingPlanRepo.DeactivateOtherPlansForClient(txCtx, plan.ClientID, trainerID, planID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to set status of plan %s to %s: %v", planID.Hex(), status, err)
+		return nil, errors.New("failed to update training plan status")
+	}
+	return plan, nil
+}
+
 // === NEW DeleteTrainingPlan Implementation ===
 func (s *trainerService) DeleteTrainingPlan(ctx context.Context, trainerID, planID primitive.ObjectID) error {
     // 1. Validate Inputs
@@ -631,6 +1254,10 @@ func (s *trainerService) DeleteTrainingPlan(ctx context.Context, trainerID, plan
         return errors.New("failed to delete training plan")
     }
 
+    if err := s.planDraftRepo.Delete(ctx, planID); err != nil {
+        log.Printf("Warning: Failed to delete unpublished edits of plan %s: %v", planID.Hex(), err)
+    }
+
     // --- IMPORTANT: Business Logic for Cascading Deletes ---
     // What happens to Workouts and Assignments when a TrainingPlan is deleted?
     // Option A: Delete them all (cascading delete). Requires WorkoutRepo, AssignmentRepo.
@@ -687,10 +1314,10 @@ func (s *trainerService) UpdateWorkout(ctx context.Context, trainerID, planID, w
 			return nil, errors.New("trainer ID, plan ID, workout ID, and new workout name are required")
 	}
 
-	// 2. Fetch existing workout & verify ownership chain (Plan by Trainer, Workout by Plan & Trainer)
-	existingWorkout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	// 2. Fetch existing workout & verify ownership chain (Plan by Trainer, Workout by Plan & Trainer);
+	//    whether the plan is published decides where the edit goes
+	existingWorkout, plan, _, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return nil, ErrWorkoutNotFound }
 			return nil, err
 	}
 
@@ -698,14 +1325,6 @@ func (s *trainerService) UpdateWorkout(ctx context.Context, trainerID, planID, w
 	if existingWorkout.TrainingPlanID != planID {
 			return nil, errors.New("workout does not belong to the specified training plan")
 	}
-	// Verify trainer owns this workout (denormalized trainerId on workout should match)
-	if existingWorkout.TrainerID != trainerID {
-			return nil, errors.New("access denied: trainer does not own this workout")
-	}
-	// Optional: Verify plan also belongs to trainer (double check)
-	// plan, err := s.trainingPlanRepo.GetByID(ctx, planID)
-	// if err != nil { /* ... */ }
-	// if plan.TrainerID != trainerID { return nil, ErrTrainingPlanAccessDenied }
 
 
 	// 3. Apply updates to the fetched workout object
@@ -715,7 +1334,16 @@ func (s *trainerService) UpdateWorkout(ctx context.Context, trainerID, planID, w
 	existingWorkout.Sequence = updates.Sequence
 	// ClientID and TrainingPlanID on the workout should not be changed by this update.
 
-	// 4. Call repository to save
+	// 4. The client keeps seeing a published plan as it was published; stage the edit.
+	if plan.IsPublished() {
+			edit := domain.PlanDraftEdit{Workouts: []domain.Workout{*existingWorkout}}
+			if err := s.planDraftRepo.Stage(ctx, trainerID, planID, edit); err != nil {
+					return nil, errors.New("failed to update workout details")
+			}
+			return existingWorkout, nil
+	}
+
+	// 5. Call repository to save
 	err = s.workoutRepo.Update(ctx, existingWorkout)
 	if err != nil {
 			// log.Printf("Error updating workout %s in service: %v", workoutID.Hex(), err)
@@ -732,17 +1360,13 @@ func (s *trainerService) DeleteWorkout(ctx context.Context, trainerID, planID, w
 
 	// 2. Verify ownership and associations before deleting
 	// Fetch workout to check its planID and trainerID
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	workout, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return ErrWorkoutNotFound }
 			return err
 	}
 	if workout.TrainingPlanID != planID {
 			return errors.New("workout does not belong to the specified training plan")
 	}
-	if workout.TrainerID != trainerID {
-			return errors.New("access denied: trainer does not own this workout")
-	}
 	// The repo.Delete(ctx, workoutID, trainerID) will do the final ownership check on delete.
 
 	// 3. TODO: Business Logic - Cascade delete Assignments for this Workout
@@ -758,7 +1382,16 @@ func (s *trainerService) DeleteWorkout(ctx context.Context, trainerID, planID, w
 	// }
 
 
-	// 4. Call repository to delete the workout
+	// 4. The client keeps seeing a published plan as it was published; the workout is
+	//    removed from it on the next publish.
+	if plan.IsPublished() {
+			if err := s.planDraftRepo.Stage(ctx, trainerID, planID, workoutRemoval(draft, workoutID)); err != nil {
+					return errors.New("failed to delete workout")
+			}
+			return nil
+	}
+
+	// 5. Call repository to delete the workout
 	err = s.workoutRepo.Delete(ctx, workoutID, trainerID) // Repo delete includes trainerID check
 	if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
@@ -770,7 +1403,16 @@ func (s *trainerService) DeleteWorkout(ctx context.Context, trainerID, planID, w
 	return nil
 }
 
+// UpdateAssignmentInWorkout edits an assignment's exercise details. Moving it to a sequence
+// another assignment of the same block (or another ungrouped one) already has is rejected;
+// positions are swapped with ReorderAssignments or BatchEditAssignments.
 func (s *trainerService) UpdateAssignmentInWorkout(ctx context.Context, trainerID, workoutID, assignmentID primitive.ObjectID, updates domain.Assignment) (*domain.Assignment, error) {
+	return s.updateAssignmentInWorkout(ctx, trainerID, workoutID, assignmentID, updates, true)
+}
+
+// updateAssignmentInWorkout implements UpdateAssignmentInWorkout; see assignExerciseToWorkout
+// for uniqueSequence.
+func (s *trainerService) updateAssignmentInWorkout(ctx context.Context, trainerID, workoutID, assignmentID primitive.ObjectID, updates domain.Assignment, uniqueSequence bool) (*domain.Assignment, error) {
 	// 1. Validate IDs
 	if trainerID == primitive.NilObjectID || workoutID == primitive.NilObjectID || assignmentID == primitive.NilObjectID {
 			return nil, errors.New("trainer, workout, and assignment IDs are required")
@@ -781,26 +1423,25 @@ func (s *trainerService) UpdateAssignmentInWorkout(ctx context.Context, trainerI
 	}
 
 
-	// 2. Fetch existing assignment & verify ownership chain
-	existingAssignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	// 2. Verify trainer owns the workout, then fetch the assignment; edits of a published
+	//    plan build on the ones already staged
+	_, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return nil, ErrAssignmentNotFound }
 			return nil, err
 	}
-
-	// Verify assignment belongs to the specified workout
-	if existingAssignment.WorkoutID != workoutID {
-			return nil, errors.New("assignment does not belong to the specified workout")
-	}
-
-	// Verify trainer owns the workout
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID) // workoutID is existingAssignment.WorkoutID
+	existingAssignment, err := s.getEditableAssignment(ctx, workoutID, assignmentID, draft)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return nil, ErrWorkoutNotFound }
 			return nil, err
 	}
-	if workout.TrainerID != trainerID {
-			return nil, errors.New("access denied: trainer does not own this workout")
+	// Only a move is checked, so assignments already sharing a position can still be edited
+	if uniqueSequence && (updates.Sequence != existingAssignment.Sequence || !sameBlock(updates.BlockID, existingAssignment.BlockID)) {
+			siblings, err := s.workoutAssignments(ctx, workoutID, draft)
+			if err != nil {
+					return nil, errors.New("failed to retrieve assignments for the workout")
+			}
+			if err := checkSequenceFree(siblings, assignmentID, updates.BlockID, updates.Sequence); err != nil {
+					return nil, err
+			}
 	}
 
 	// 3. If ExerciseID is being changed in updates, verify trainer owns the new exercise
@@ -814,6 +1455,8 @@ func (s *trainerService) UpdateAssignmentInWorkout(ctx context.Context, trainerI
 					return nil, ErrExerciseAccessDenied // Trainer doesn't own the new exercise
 			}
 			existingAssignment.ExerciseID = newExercise.ID // Update if valid
+			existingAssignment.ExerciseRevision = newExercise.CurrentRevision
+			existingAssignment.RevisionExerciseID = nil
 	}
 
 
@@ -826,12 +1469,27 @@ func (s *trainerService) UpdateAssignmentInWorkout(ctx context.Context, trainerI
 	existingAssignment.Duration = updates.Duration
 	existingAssignment.Sequence = updates.Sequence
 	existingAssignment.TrainerNotes = updates.TrainerNotes
+	if updates.BlockID != nil {
+			if _, err := s.getEditableBlock(ctx, workoutID, *updates.BlockID, draft); err != nil {
+					return nil, err
+			}
+	}
+	existingAssignment.BlockID = updates.BlockID // nil moves the exercise out of its block
 	// Status, ClientNotes, UploadID, Feedback are usually updated via other specific flows
 	// but can be included here if the "edit assignment" form allows modifying them.
 	// For now, let's assume trainer edit focuses on parameters.
 	// If status changes are allowed: existingAssignment.Status = updates.Status
 
-	// 5. Call repository to save
+	// 5. The client keeps seeing a published plan as it was published; stage the edit.
+	if plan.IsPublished() {
+			edit := domain.PlanDraftEdit{Assignments: []domain.Assignment{*existingAssignment}}
+			if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+					return nil, errors.New("failed to update assignment details")
+			}
+			return existingAssignment, nil
+	}
+
+	// 6. Call repository to save
 	err = s.assignmentRepo.Update(ctx, existingAssignment)
 	if err != nil {
 			// log.Printf("Error updating assignment %s in service: %v", assignmentID.Hex(), err)
@@ -848,25 +1506,24 @@ func (s *trainerService) DeleteAssignmentFromWorkout(ctx context.Context, traine
 	}
 
 	// 2. Verify ownership and associations
-	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	_, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
 	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return ErrAssignmentNotFound }
 			return err
 	}
-	if assignment.WorkoutID != workoutID {
-			return errors.New("assignment does not belong to the specified workout")
-	}
-
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
-	if err != nil {
-			if errors.Is(err, repository.ErrNotFound) { return ErrWorkoutNotFound }
+	if _, err := s.getEditableAssignment(ctx, workoutID, assignmentID, draft); err != nil {
 			return err
 	}
-	if workout.TrainerID != trainerID {
-			return errors.New("access denied: trainer does not own this workout")
+
+	// 3. The client keeps seeing a published plan as it was published; the exercise is
+	//    removed from it on the next publish.
+	if plan.IsPublished() {
+			if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, draftRemoval(draft, assignmentID)); err != nil {
+					return errors.New("failed to delete assignment")
+			}
+			return nil
 	}
 
-	// 3. Call repository to delete. The repo Delete now takes workoutID for an extra check.
+	// 4. Call repository to delete. The repo Delete now takes workoutID for an extra check.
 	err = s.assignmentRepo.Delete(ctx, assignmentID, workoutID)
 	if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
@@ -876,4 +1533,754 @@ func (s *trainerService) DeleteAssignmentFromWorkout(ctx context.Context, traine
 			return errors.New("failed to delete assignment")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// === Workout Blocks ===
+
+// WorkoutBlockDetails is one block of a workout with its assignments in order.
+// Ungrouped assignments are returned as implicit single-exercise straight-set
+// blocks without an ID (a zero ID, left out of JSON), so clients can render every
+// workout the same way.
+type WorkoutBlockDetails struct {
+	domain.WorkoutBlock
+	Assignments []domain.Assignment `json:"assignments"`
+}
+
+// validateWorkoutBlock checks that a block's type and rounds make sense together.
+func validateWorkoutBlock(block *domain.WorkoutBlock) error {
+	if !block.Type.IsValid() {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidWorkoutBlock, block.Type)
+	}
+	if block.Rounds < 0 {
+		return fmt.Errorf("%w: rounds cannot be negative", ErrInvalidWorkoutBlock)
+	}
+	switch block.Type {
+	case domain.BlockTypeSuperset, domain.BlockTypeCircuit, domain.BlockTypeEMOM:
+		if block.Rounds < 1 {
+			return fmt.Errorf("%w: %s blocks need at least one round", ErrInvalidWorkoutBlock, block.Type)
+		}
+	case domain.BlockTypeAMRAP:
+		if block.TimeCap == nil || *block.TimeCap == "" {
+			return fmt.Errorf("%w: amrap blocks need a time cap", ErrInvalidWorkoutBlock)
+		}
+	}
+	return nil
+}
+
+// getOwnedWorkout fetches a workout and verifies the trainer owns it.
+func (s *trainerService) getOwnedWorkout(ctx context.Context, trainerID, workoutID primitive.ObjectID) (*domain.Workout, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutNotFound
+		}
+		return nil, err
+	}
+	if workout.TrainerID != trainerID {
+		return nil, ErrWorkoutAccessDenied
+	}
+	return workout, nil
+}
+
+// getOwnedExercise fetches an exercise the trainer wants to assign and verifies they own it.
+func (s *trainerService) getOwnedExercise(ctx context.Context, trainerID, exerciseID primitive.ObjectID) (*domain.Exercise, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExerciseNotFound
+		}
+		return nil, err
+	}
+	if exercise.TrainerID != trainerID {
+		return nil, ErrExerciseAccessDenied
+	}
+	return exercise, nil
+}
+
+// getEditableWorkout fetches a workout the trainer changes, with its plan and, for a
+// published plan, the draft the change goes to (nil if nothing is staged yet). The workout
+// is returned as the trainer sees it: with staged edits, and possibly only in the draft.
+func (s *trainerService) getEditableWorkout(ctx context.Context, trainerID, workoutID primitive.ObjectID) (*domain.Workout, *domain.TrainingPlan, *domain.PlanDraft, error) {
+	var draft *domain.PlanDraft
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Workouts added to a published plan only exist in its draft until it is published
+		draft, err = s.planDraftRepo.GetByWorkoutID(ctx, workoutID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil, ErrWorkoutNotFound
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		workout, _ = draft.Workout(workoutID)
+	} else if err != nil {
+		return nil, nil, nil, err
+	}
+	if workout.TrainerID != trainerID {
+		return nil, nil, nil, ErrWorkoutAccessDenied
+	}
+
+	plan, err := s.trainingPlanRepo.GetByID(ctx, workout.TrainingPlanID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil, ErrTrainingPlanNotFound
+		}
+		return nil, nil, nil, err
+	}
+	if plan.TrainerID != trainerID {
+		return nil, nil, nil, ErrTrainingPlanAccessDenied
+	}
+	if !plan.IsPublished() {
+		return workout, plan, nil, nil
+	}
+	if draft == nil {
+		if draft, err = s.planDraft(ctx, plan.ID); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if draft.IsRemoved(workoutID) {
+		return nil, nil, nil, ErrWorkoutNotFound
+	}
+	if staged, ok := draft.Workout(workoutID); ok && !draft.IsAdded(workoutID) {
+		applyWorkoutEdits(workout, staged)
+	}
+	return workout, plan, draft, nil
+}
+
+// getEditableAssignment fetches an assignment of workoutID as the trainer sees it, with
+// the edits staged in draft (nil for unpublished plans).
+func (s *trainerService) getEditableAssignment(ctx context.Context, workoutID, assignmentID primitive.ObjectID, draft *domain.PlanDraft) (*domain.Assignment, error) {
+	if draft.IsAdded(assignmentID) {
+		assignment, _ := draft.Assignment(assignmentID)
+		if assignment.WorkoutID != workoutID {
+			return nil, errors.New("assignment does not belong to the specified workout")
+		}
+		return assignment, nil
+	}
+	assignment, err := s.assignmentRepo.GetByID(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAssignmentNotFound
+		}
+		return nil, err
+	}
+	if draft.IsRemoved(assignmentID) {
+		return nil, ErrAssignmentNotFound
+	}
+	if assignment.WorkoutID != workoutID {
+		return nil, errors.New("assignment does not belong to the specified workout")
+	}
+	if staged, ok := draft.Assignment(assignmentID); ok {
+		applyAssignmentEdits(assignment, staged)
+	}
+	return assignment, nil
+}
+
+// getEditableBlock fetches a block of workoutID as the trainer sees it, with the edits
+// staged in draft. It also ensures assignments are only grouped into blocks of their own
+// workout.
+func (s *trainerService) getEditableBlock(ctx context.Context, workoutID, blockID primitive.ObjectID, draft *domain.PlanDraft) (*domain.WorkoutBlock, error) {
+	if draft.IsAdded(blockID) {
+		block, _ := draft.Block(blockID)
+		if block.WorkoutID != workoutID {
+			return nil, ErrWorkoutBlockNotFound
+		}
+		return block, nil
+	}
+	block, err := s.workoutBlockRepo.GetByID(ctx, blockID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutBlockNotFound
+		}
+		return nil, err
+	}
+	if block.WorkoutID != workoutID || draft.IsRemoved(blockID) {
+		return nil, ErrWorkoutBlockNotFound
+	}
+	if staged, ok := draft.Block(blockID); ok {
+		applyBlockEdits(block, staged)
+	}
+	return block, nil
+}
+
+// workoutAssignments returns a workout's assignments as the trainer sees them.
+func (s *trainerService) workoutAssignments(ctx context.Context, workoutID primitive.ObjectID, draft *domain.PlanDraft) ([]domain.Assignment, error) {
+	assignments, err := s.assignmentRepo.GetByWorkoutID(ctx, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	return overlayAssignments(workoutID, assignments, draft), nil
+}
+
+// workoutBlocks returns a workout's blocks as the trainer sees them.
+func (s *trainerService) workoutBlocks(ctx context.Context, workoutID primitive.ObjectID, draft *domain.PlanDraft) ([]domain.WorkoutBlock, error) {
+	blocks, err := s.workoutBlockRepo.GetByWorkoutID(ctx, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	return overlayBlocks(workoutID, blocks, draft), nil
+}
+
+// CreateWorkoutBlock adds a new block (superset, circuit, ...) to a workout owned by the trainer.
+func (s *trainerService) CreateWorkoutBlock(ctx context.Context, trainerID, workoutID primitive.ObjectID, block domain.WorkoutBlock) (*domain.WorkoutBlock, error) {
+	if trainerID == primitive.NilObjectID || workoutID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and workout ID are required")
+	}
+	_, plan, _, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWorkoutBlock(&block); err != nil {
+		return nil, err
+	}
+
+	block.WorkoutID = workoutID
+	block.TrainerID = trainerID
+	// The client keeps seeing a published plan as it was published; the new block waits
+	// in the plan's draft.
+	if plan.IsPublished() {
+		now := time.Now().UTC()
+		block.ID = primitive.NewObjectID()
+		block.CreatedAt = now
+		block.UpdatedAt = now
+		edit := domain.PlanDraftEdit{Blocks: []domain.WorkoutBlock{block}, Added: []primitive.ObjectID{block.ID}}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+			return nil, errors.New("failed to create workout block")
+		}
+		return &block, nil
+	}
+
+	blockID, err := s.workoutBlockRepo.Create(ctx, &block)
+	if err != nil {
+		return nil, errors.New("failed to create workout block")
+	}
+	block.ID = blockID
+	return &block, nil
+}
+
+// UpdateWorkoutBlock changes the type, rounds, rest or order of a block.
+func (s *trainerService) UpdateWorkoutBlock(ctx context.Context, trainerID, workoutID, blockID primitive.ObjectID, updates domain.WorkoutBlock) (*domain.WorkoutBlock, error) {
+	_, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.getEditableBlock(ctx, workoutID, blockID, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	block.Label = updates.Label
+	block.Type = updates.Type
+	block.Rounds = updates.Rounds
+	block.RestBetweenRounds = updates.RestBetweenRounds
+	block.TimeCap = updates.TimeCap
+	block.Sequence = updates.Sequence
+	block.Notes = updates.Notes
+	if err := validateWorkoutBlock(block); err != nil {
+		return nil, err
+	}
+
+	if plan.IsPublished() {
+		edit := domain.PlanDraftEdit{Blocks: []domain.WorkoutBlock{*block}}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+			return nil, errors.New("failed to update workout block")
+		}
+		return block, nil
+	}
+	if err := s.workoutBlockRepo.Update(ctx, block); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWorkoutBlockNotFound
+		}
+		return nil, errors.New("failed to update workout block")
+	}
+	return block, nil
+}
+
+// DeleteWorkoutBlock removes a block; its assignments stay in the workout as ungrouped exercises.
+func (s *trainerService) DeleteWorkoutBlock(ctx context.Context, trainerID, workoutID, blockID primitive.ObjectID) error {
+	_, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return err
+	}
+	if plan.IsPublished() {
+		if _, err := s.getEditableBlock(ctx, workoutID, blockID, draft); err != nil {
+			return err
+		}
+		assignments, err := s.workoutAssignments(ctx, workoutID, draft)
+		if err != nil {
+			return errors.New("failed to delete workout block")
+		}
+		edit := draftRemoval(draft, blockID)
+		for _, a := range assignments {
+			if a.BlockID != nil && *a.BlockID == blockID {
+				a.BlockID = nil
+				edit.Assignments = append(edit.Assignments, a)
+			}
+		}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+			return errors.New("failed to delete workout block")
+		}
+		return nil
+	}
+
+	// The block and its assignments' references to it go together, so no assignment is
+	// left pointing at a missing block.
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.workoutBlockRepo.Delete(txCtx, blockID, workoutID); err != nil {
+			return err
+		}
+		_, err := s.assignmentRepo.ClearBlock(txCtx, blockID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrWorkoutBlockNotFound
+		}
+		log.Printf("ERROR: Failed to delete workout block %s: %v", blockID.Hex(), err)
+		return errors.New("failed to delete workout block")
+	}
+	return nil
+}
+
+// GetWorkoutStructure returns the trainer's view of a workout as ordered blocks with nested assignments,
+// including changes not yet published.
+func (s *trainerService) GetWorkoutStructure(ctx context.Context, trainerID, workoutID primitive.ObjectID) ([]WorkoutBlockDetails, error) {
+	_, _, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.IsEmpty() {
+		return loadWorkoutStructure(ctx, s.workoutBlockRepo, s.assignmentRepo, workoutID)
+	}
+	// The trainer sees their unpublished changes
+	blocks, err := s.workoutBlocks(ctx, workoutID, draft)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workout blocks")
+	}
+	assignments, err := s.workoutAssignments(ctx, workoutID, draft)
+	if err != nil {
+		return nil, errors.New("failed to retrieve assignments for the workout")
+	}
+	return groupAssignmentsIntoBlocks(blocks, assignments), nil
+}
+
+// planDraft returns the edits staged on a published plan, or nil if there are none.
+func (s *trainerService) planDraft(ctx context.Context, planID primitive.ObjectID) (*domain.PlanDraft, error) {
+	draft, err := s.planDraftRepo.GetByPlanID(ctx, planID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return draft, err
+}
+
+// loadWorkoutStructure fetches a workout's blocks and assignments and nests them.
+func loadWorkoutStructure(ctx context.Context, blockRepo repository.WorkoutBlockRepository, assignmentRepo repository.AssignmentRepository, workoutID primitive.ObjectID) ([]WorkoutBlockDetails, error) {
+	blocks, err := blockRepo.GetByWorkoutID(ctx, workoutID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workout blocks")
+	}
+	assignments, err := assignmentRepo.GetByWorkoutID(ctx, workoutID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve assignments for the workout")
+	}
+	return groupAssignmentsIntoBlocks(blocks, assignments), nil
+}
+
+// groupAssignmentsIntoBlocks nests assignments under their blocks. Blocks and ungrouped
+// assignments share one sequence space and are interleaved by it; assignments within a
+// block keep their own sequence order. Assignments pointing at a missing block are
+// treated as ungrouped.
+func groupAssignmentsIntoBlocks(blocks []domain.WorkoutBlock, assignments []domain.Assignment) []WorkoutBlockDetails {
+	result := make([]WorkoutBlockDetails, 0, len(blocks)+len(assignments))
+	index := make(map[primitive.ObjectID]int, len(blocks))
+	for _, b := range blocks {
+		index[b.ID] = len(result)
+		result = append(result, WorkoutBlockDetails{WorkoutBlock: b, Assignments: []domain.Assignment{}})
+	}
+
+	for _, a := range assignments {
+		if a.BlockID != nil {
+			if i, ok := index[*a.BlockID]; ok {
+				result[i].Assignments = append(result[i].Assignments, a)
+				continue
+			}
+		}
+		sets := 0
+		if a.Sets != nil {
+			sets = *a.Sets
+		}
+		result = append(result, WorkoutBlockDetails{
+			WorkoutBlock: domain.WorkoutBlock{
+				WorkoutID: a.WorkoutID,
+				Type:      domain.BlockTypeStraightSets,
+				Rounds:    sets,
+				Sequence:  a.Sequence,
+			},
+			Assignments: []domain.Assignment{a},
+		})
+	}
+
+	for i := range result {
+		sort.SliceStable(result[i].Assignments, func(x, y int) bool {
+			return result[i].Assignments[x].Sequence < result[i].Assignments[y].Sequence
+		})
+	}
+	sort.SliceStable(result, func(x, y int) bool { return result[x].Sequence < result[y].Sequence })
+	return result
+}
+
+// === Bulk Ordering & Editing ===
+
+// AssignmentBatchOp is the kind of change in a batch edit of assignments.
+type AssignmentBatchOp string
+
+const (
+	AssignmentBatchCreate AssignmentBatchOp = "create"
+	AssignmentBatchUpdate AssignmentBatchOp = "update"
+	AssignmentBatchDelete AssignmentBatchOp = "delete"
+)
+
+// AssignmentBatchOperation is one create, update or delete in a batch edit.
+// AssignmentID is required for update/delete; ExerciseID for create.
+type AssignmentBatchOperation struct {
+	Op           AssignmentBatchOp
+	AssignmentID primitive.ObjectID
+	ExerciseID   primitive.ObjectID
+	Assignment   domain.Assignment // Details for create/update, as for AssignExerciseToWorkout
+}
+
+// AssignmentBatchResult reports the outcome of one operation of a successful batch.
+type AssignmentBatchResult struct {
+	Index        int
+	Op           AssignmentBatchOp
+	AssignmentID primitive.ObjectID
+	Assignment   *domain.Assignment // nil for deletes
+}
+
+// BatchOperationError identifies which operation made a batch fail.
+type BatchOperationError struct {
+	Index int
+	Op    AssignmentBatchOp
+	Err   error
+}
+
+func (e *BatchOperationError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *BatchOperationError) Unwrap() error { return e.Err }
+
+// sameIDSet reports whether orderedIDs is a permutation of existing.
+func sameIDSet(existing, orderedIDs []primitive.ObjectID) bool {
+	if len(existing) != len(orderedIDs) {
+		return false
+	}
+	remaining := make(map[primitive.ObjectID]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range orderedIDs {
+		if !remaining[id] {
+			return false // Unknown or listed twice
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+// ReorderAssignments sets the order of all assignments in a workout in one step. orderedIDs
+// must contain every assignment of the workout exactly once; sequence becomes the position
+// in the list. Blocks keep their own sequence and are reordered via UpdateWorkoutBlock.
+func (s *trainerService) ReorderAssignments(ctx context.Context, trainerID, workoutID primitive.ObjectID, orderedIDs []primitive.ObjectID) ([]domain.Assignment, error) {
+	_, plan, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.workoutAssignments(ctx, workoutID, draft)
+	if err != nil {
+		return nil, errors.New("failed to retrieve assignments for the workout")
+	}
+	currentIDs := make([]primitive.ObjectID, len(current))
+	for i, a := range current {
+		currentIDs[i] = a.ID
+	}
+	if !sameIDSet(currentIDs, orderedIDs) {
+		return nil, ErrReorderMismatch
+	}
+
+	// The client keeps seeing a published plan as it was published; stage the new order
+	if plan.IsPublished() {
+		position := make(map[primitive.ObjectID]int, len(orderedIDs))
+		for i, id := range orderedIDs {
+			position[id] = i
+		}
+		var edit domain.PlanDraftEdit
+		for i := range current {
+			if current[i].Sequence != position[current[i].ID] {
+				current[i].Sequence = position[current[i].ID]
+				edit.Assignments = append(edit.Assignments, current[i])
+			}
+		}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, plan.ID, edit); err != nil {
+			return nil, errors.New("failed to reorder assignments")
+		}
+		sort.SliceStable(current, func(i, j int) bool { return current[i].Sequence < current[j].Sequence })
+		return current, nil
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.assignmentRepo.UpdateSequences(txCtx, workoutID, orderedIDs)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrReorderMismatch // An assignment was removed concurrently
+		}
+		return nil, errors.New("failed to reorder assignments")
+	}
+	return s.assignmentRepo.GetByWorkoutID(ctx, workoutID)
+}
+
+// ReorderWorkouts sets the order of all workouts in a plan in one step. orderedIDs must
+// contain every workout of the plan exactly once; sequence becomes the position in the list.
+func (s *trainerService) ReorderWorkouts(ctx context.Context, trainerID, planID primitive.ObjectID, orderedIDs []primitive.ObjectID) ([]domain.Workout, error) {
+	plan, err := s.trainingPlanRepo.GetByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTrainingPlanNotFound
+		}
+		return nil, err
+	}
+	if plan.TrainerID != trainerID {
+		return nil, ErrTrainingPlanAccessDenied
+	}
+
+	current, err := s.workoutRepo.GetByPlanID(ctx, planID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workouts for the plan")
+	}
+	var draft *domain.PlanDraft
+	if plan.IsPublished() {
+		if draft, err = s.planDraft(ctx, planID); err != nil {
+			return nil, errors.New("failed to retrieve workouts for the plan")
+		}
+		current = overlayWorkouts(current, draft)
+	}
+	currentIDs := make([]primitive.ObjectID, len(current))
+	for i, w := range current {
+		currentIDs[i] = w.ID
+	}
+	if !sameIDSet(currentIDs, orderedIDs) {
+		return nil, ErrReorderMismatch
+	}
+
+	// The client keeps seeing a published plan as it was published; stage the new order
+	if plan.IsPublished() {
+		position := make(map[primitive.ObjectID]int, len(orderedIDs))
+		for i, id := range orderedIDs {
+			position[id] = i
+		}
+		var edit domain.PlanDraftEdit
+		for i := range current {
+			if current[i].Sequence != position[current[i].ID] {
+				current[i].Sequence = position[current[i].ID]
+				edit.Workouts = append(edit.Workouts, current[i])
+			}
+		}
+		if err := s.planDraftRepo.Stage(ctx, trainerID, planID, edit); err != nil {
+			return nil, errors.New("failed to reorder workouts")
+		}
+		sort.SliceStable(current, func(i, j int) bool { return current[i].Sequence < current[j].Sequence })
+		return current, nil
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.workoutRepo.UpdateSequences(txCtx, planID, orderedIDs)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrReorderMismatch
+		}
+		return nil, errors.New("failed to reorder workouts")
+	}
+	return s.workoutRepo.GetByPlanID(ctx, planID)
+}
+
+// BatchEditAssignments applies many creates, updates and deletes to one workout with
+// all-or-nothing semantics. The whole batch is validated up front (assignments, exercises,
+// blocks, and that no two assignments in the same block, or among ungrouped ones, end up
+// with the same sequence) and then written in a single transaction. Operations may pass
+// through duplicate positions on the way, e.g. to swap two exercises. On a published plan
+// the whole batch is staged in the plan's draft instead, in the same way.
+func (s *trainerService) BatchEditAssignments(ctx context.Context, trainerID, workoutID primitive.ObjectID, ops []AssignmentBatchOperation) ([]AssignmentBatchResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBatchOperation)
+	}
+	_, _, draft, err := s.getEditableWorkout(ctx, trainerID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.workoutAssignments(ctx, workoutID, draft)
+	if err != nil {
+		return nil, errors.New("failed to retrieve assignments for the workout")
+	}
+	if err := validateAssignmentBatch(current, ops); err != nil {
+		return nil, err
+	}
+	blocks, err := s.workoutBlocks(ctx, workoutID, draft)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workout blocks")
+	}
+	if err := s.verifyBatchReferences(ctx, trainerID, current, blocks, ops); err != nil {
+		return nil, err
+	}
+
+	results := make([]AssignmentBatchResult, len(ops))
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		for i, op := range ops {
+			result := AssignmentBatchResult{Index: i, Op: op.Op, AssignmentID: op.AssignmentID}
+			var opErr error
+			switch op.Op {
+			case AssignmentBatchCreate:
+				result.Assignment, opErr = s.assignExerciseToWorkout(txCtx, trainerID, workoutID, op.ExerciseID, op.Assignment, false)
+				if opErr == nil {
+					result.AssignmentID = result.Assignment.ID
+				}
+			case AssignmentBatchUpdate:
+				update := op.Assignment
+				update.ExerciseID = op.ExerciseID
+				result.Assignment, opErr = s.updateAssignmentInWorkout(txCtx, trainerID, workoutID, op.AssignmentID, update, false)
+			case AssignmentBatchDelete:
+				opErr = s.DeleteAssignmentFromWorkout(txCtx, trainerID, workoutID, op.AssignmentID)
+			}
+			if opErr != nil {
+				return &BatchOperationError{Index: i, Op: op.Op, Err: opErr}
+			}
+			results[i] = result
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// verifyBatchReferences checks that every exercise the batch assigns exists and belongs to
+// the trainer, and that every block it groups into is one of the workout's, so the batch
+// can't fail on them after earlier operations were written.
+func (s *trainerService) verifyBatchReferences(ctx context.Context, trainerID primitive.ObjectID, current []domain.Assignment, blocks []domain.WorkoutBlock, ops []AssignmentBatchOperation) error {
+	existing := make(map[primitive.ObjectID]domain.Assignment, len(current))
+	for _, a := range current {
+		existing[a.ID] = a
+	}
+	inWorkout := make(map[primitive.ObjectID]bool, len(blocks))
+	for _, b := range blocks {
+		inWorkout[b.ID] = true
+	}
+
+	checked := make(map[primitive.ObjectID]error)
+	for i, op := range ops {
+		if op.Op == AssignmentBatchDelete {
+			continue
+		}
+		exerciseID := op.ExerciseID
+		if op.Op == AssignmentBatchUpdate && exerciseID == existing[op.AssignmentID].ExerciseID {
+			exerciseID = primitive.NilObjectID // Unchanged; only a new exercise is checked
+		}
+		if exerciseID != primitive.NilObjectID {
+			err, seen := checked[exerciseID]
+			if !seen {
+				_, err = s.getOwnedExercise(ctx, trainerID, exerciseID)
+				checked[exerciseID] = err
+			}
+			if err != nil {
+				return &BatchOperationError{Index: i, Op: op.Op, Err: err}
+			}
+		}
+		if op.Assignment.BlockID != nil && !inWorkout[*op.Assignment.BlockID] {
+			return &BatchOperationError{Index: i, Op: op.Op, Err: ErrWorkoutBlockNotFound}
+		}
+	}
+	return nil
+}
+
+// checkSequenceFree rejects putting an assignment at a sequence that another assignment of
+// the same block (or another ungrouped one) already has. assignmentID is the one being
+// moved, NilObjectID for a new one.
+func checkSequenceFree(assignments []domain.Assignment, assignmentID primitive.ObjectID, blockID *primitive.ObjectID, sequence int) error {
+	for _, a := range assignments {
+		if a.ID != assignmentID && a.Sequence == sequence && sameBlock(a.BlockID, blockID) {
+			return fmt.Errorf("%w: sequence %d is already used", ErrDuplicateSequence, sequence)
+		}
+	}
+	return nil
+}
+
+// validateAssignmentBatch checks every operation against the workout's current assignments
+// and simulates the result to reject duplicate sequences before anything is written.
+func validateAssignmentBatch(current []domain.Assignment, ops []AssignmentBatchOperation) error {
+	final := make(map[primitive.ObjectID]domain.Assignment, len(current))
+	for _, a := range current {
+		final[a.ID] = a
+	}
+
+	touched := make(map[primitive.ObjectID]bool)
+	var created []domain.Assignment
+	for i, op := range ops {
+		switch op.Op {
+		case AssignmentBatchCreate:
+			if op.ExerciseID == primitive.NilObjectID {
+				return &BatchOperationError{Index: i, Op: op.Op, Err: fmt.Errorf("%w: exerciseId is required", ErrInvalidBatchOperation)}
+			}
+			created = append(created, op.Assignment)
+		case AssignmentBatchUpdate, AssignmentBatchDelete:
+			existing, ok := final[op.AssignmentID]
+			if !ok {
+				return &BatchOperationError{Index: i, Op: op.Op, Err: ErrAssignmentNotFound}
+			}
+			if touched[op.AssignmentID] {
+				return &BatchOperationError{Index: i, Op: op.Op, Err: fmt.Errorf("%w: assignment %s appears more than once", ErrInvalidBatchOperation, op.AssignmentID.Hex())}
+			}
+			touched[op.AssignmentID] = true
+			if op.Op == AssignmentBatchDelete {
+				delete(final, op.AssignmentID)
+			} else {
+				existing.Sequence = op.Assignment.Sequence
+				existing.BlockID = op.Assignment.BlockID
+				final[op.AssignmentID] = existing
+			}
+		default:
+			return &BatchOperationError{Index: i, Op: op.Op, Err: fmt.Errorf("%w: unknown op %q", ErrInvalidBatchOperation, op.Op)}
+		}
+	}
+
+	type slot struct {
+		block    primitive.ObjectID // NilObjectID for ungrouped
+		sequence int
+	}
+	seen := make(map[slot]bool)
+	check := func(a domain.Assignment) error {
+		key := slot{sequence: a.Sequence}
+		if a.BlockID != nil {
+			key.block = *a.BlockID
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: sequence %d is used more than once", ErrDuplicateSequence, a.Sequence)
+		}
+		seen[key] = true
+		return nil
+	}
+	for _, a := range final {
+		if err := check(a); err != nil {
+			return err
+		}
+	}
+	for _, a := range created {
+		if err := check(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
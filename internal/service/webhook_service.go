@@ -0,0 +1,634 @@
+package service
+
+import (
+	"alcyxob/fitness-app/internal/domain"
+	"alcyxob/fitness-app/internal/realtime"
+	"alcyxob/fitness-app/internal/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxWebhookEndpointsPerTrainer = 10
+	maxWebhookURLLength           = 2048
+	maxWebhookDescriptionLength   = 200
+
+	webhookAttemptTimeout   = 10 * time.Second
+	webhookMaxAttempts      = 8                // First try plus 7 retries, about an hour in total
+	webhookBaseBackoff      = 30 * time.Second // Doubled after each failed attempt
+	webhookMaxBackoff       = time.Hour
+	webhookClaimLease       = 2 * time.Minute // A claimed delivery is retried if its worker dies
+	webhookResponseBodySize = 1024            // Bytes of the endpoint's response kept in the log
+	webhookWorkers          = 4
+
+	defaultWebhookDeliveryPageSize = 30
+	maxWebhookDeliveryPageSize     = 100
+
+	// WebhookSignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>". The HMAC
+	// is computed with the endpoint secret over "<t>.<raw body>".
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhook          = errors.New("invalid webhook endpoint")
+	ErrWebhookLimitReached     = fmt.Errorf("a trainer can have at most %d webhook endpoints", maxWebhookEndpointsPerTrainer)
+	ErrWebhookInactive         = errors.New("webhook endpoint is inactive")
+)
+
+// WebhookEndpointInput is the editable part of a WebhookEndpoint.
+type WebhookEndpointInput struct {
+	URL         string
+	EventTypes  []domain.WebhookEventType
+	Description string
+	Active      *bool // nil: active on create, unchanged on update
+}
+
+// WebhookDeliveryQuery selects one page of an endpoint's delivery log.
+type WebhookDeliveryQuery struct {
+	BeforeID *primitive.ObjectID // Cursor: the oldest delivery of the previous page
+	Limit    int64               // 0 = default page size
+}
+
+// WebhookDeliveryPage is one page of the delivery log, newest first.
+type WebhookDeliveryPage struct {
+	Deliveries   []domain.WebhookDelivery
+	NextBeforeID *primitive.ObjectID // nil on the last page
+}
+
+// webhookPayload is the JSON body POSTed to endpoints.
+type webhookPayload struct {
+	ID        string                  `json:"id"` // Event ID; identical across redeliveries
+	Type      domain.WebhookEventType `json:"type"`
+	CreatedAt time.Time               `json:"createdAt"`
+	ActorID   *primitive.ObjectID     `json:"actorId,omitempty"`
+	Summary   string                  `json:"summary,omitempty"`
+	Data      map[string]any          `json:"data"`
+}
+
+// WebhookService lets trainers connect integrations (Zapier, CRMs) to their account.
+// It is also a realtime.Publisher: service events are translated to webhook events and
+// queued as deliveries, which RunDeliveries sends and retries in the background.
+type WebhookService interface {
+	realtime.Publisher
+
+	CreateEndpoint(ctx context.Context, trainerID primitive.ObjectID, input WebhookEndpointInput) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, trainerID primitive.ObjectID) ([]domain.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID) (*domain.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID, input WebhookEndpointInput) (*domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID) error
+
+	// Ping sends a signed test event right away, once, and returns the logged delivery.
+	Ping(ctx context.Context, trainerID, endpointID primitive.ObjectID) (*domain.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, trainerID, endpointID primitive.ObjectID, query WebhookDeliveryQuery) (*WebhookDeliveryPage, error)
+	// Redeliver queues a new delivery of a logged event with a fresh retry schedule.
+	Redeliver(ctx context.Context, trainerID, endpointID, deliveryID primitive.ObjectID) (*domain.WebhookDelivery, error)
+
+	// RunDeliveries sends due deliveries until ctx is cancelled, checking for retries every pollInterval.
+	RunDeliveries(ctx context.Context, pollInterval time.Duration)
+}
+
+type webhookService struct {
+	endpointRepo repository.WebhookEndpointRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	httpClient   *http.Client
+	wake         chan struct{}
+}
+
+// NewWebhookService creates a new WebhookService. Unless allowPrivateTargets is set
+// (local development), deliveries to loopback and private network addresses are refused.
+func NewWebhookService(endpointRepo repository.WebhookEndpointRepository, deliveryRepo repository.WebhookDeliveryRepository, allowPrivateTargets bool) WebhookService {
+	return &webhookService{
+		endpointRepo: endpointRepo,
+		deliveryRepo: deliveryRepo,
+		httpClient:   newWebhookHTTPClient(allowPrivateTargets),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// carrierGradeNAT is the shared address space (RFC 6598), internal like the private ranges.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// newWebhookHTTPClient returns a client that never follows redirects and, unless
+// allowPrivate, refuses to connect to internal addresses (whatever the URL's host resolves to).
+func newWebhookHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+				carrierGradeNAT.Contains(ip) {
+				return fmt.Errorf("webhook target %s is not a public address", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Connect directly: through a proxy, the dialer would only check the proxy's address.
+	transport.Proxy = nil
+	return &http.Client{
+		Transport: transport,
+		Timeout:   webhookAttemptTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// --- Endpoints ---
+
+// validateWebhookInput normalizes input in place.
+func validateWebhookInput(input *WebhookEndpointInput) error {
+	input.URL = strings.TrimSpace(input.URL)
+	if input.URL == "" || len(input.URL) > maxWebhookURLLength {
+		return fmt.Errorf("%w: url is required", ErrInvalidWebhook)
+	}
+	parsed, err := url.Parse(input.URL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if len(input.EventTypes) == 0 {
+		return fmt.Errorf("%w: subscribe to at least one event type", ErrInvalidWebhook)
+	}
+	seen := make(map[domain.WebhookEventType]bool, len(input.EventTypes))
+	eventTypes := make([]domain.WebhookEventType, 0, len(input.EventTypes))
+	for _, eventType := range input.EventTypes {
+		if !eventType.IsValid() {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	input.EventTypes = eventTypes
+	input.Description = strings.TrimSpace(input.Description)
+	if len(input.Description) > maxWebhookDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidWebhook, maxWebhookDescriptionLength)
+	}
+	return nil
+}
+
+// newWebhookSecret generates an endpoint's signing secret.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, trainerID primitive.ObjectID, input WebhookEndpointInput) (*domain.WebhookEndpoint, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+	if err := validateWebhookInput(&input); err != nil {
+		return nil, err
+	}
+	existing, err := s.endpointRepo.GetByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve webhook endpoints")
+	}
+	if len(existing) >= maxWebhookEndpointsPerTrainer {
+		return nil, ErrWebhookLimitReached
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate webhook secret")
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		TrainerID:   trainerID,
+		URL:         input.URL,
+		Secret:      secret,
+		EventTypes:  input.EventTypes,
+		Description: input.Description,
+		Active:      input.Active == nil || *input.Active,
+	}
+	if _, err := s.endpointRepo.Create(ctx, endpoint); err != nil {
+		return nil, errors.New("failed to create webhook endpoint")
+	}
+	return endpoint, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, trainerID primitive.ObjectID) ([]domain.WebhookEndpoint, error) {
+	if trainerID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID is required")
+	}
+	endpoints, err := s.endpointRepo.GetByTrainerID(ctx, trainerID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve webhook endpoints")
+	}
+	if endpoints == nil {
+		endpoints = []domain.WebhookEndpoint{}
+	}
+	return endpoints, nil
+}
+
+// getOwnedEndpoint loads an endpoint, hiding other trainers' endpoints as not found.
+func (s *webhookService) getOwnedEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID) (*domain.WebhookEndpoint, error) {
+	if trainerID == primitive.NilObjectID || endpointID == primitive.NilObjectID {
+		return nil, errors.New("trainer ID and webhook ID are required")
+	}
+	endpoint, err := s.endpointRepo.GetByID(ctx, endpointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, errors.New("failed to retrieve webhook endpoint")
+	}
+	if endpoint.TrainerID != trainerID {
+		return nil, ErrWebhookNotFound
+	}
+	return endpoint, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID) (*domain.WebhookEndpoint, error) {
+	return s.getOwnedEndpoint(ctx, trainerID, endpointID)
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID, input WebhookEndpointInput) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.getOwnedEndpoint(ctx, trainerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhookInput(&input); err != nil {
+		return nil, err
+	}
+	endpoint.URL = input.URL
+	endpoint.EventTypes = input.EventTypes
+	endpoint.Description = input.Description
+	if input.Active != nil {
+		endpoint.Active = *input.Active
+	}
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, errors.New("failed to update webhook endpoint")
+	}
+	return endpoint, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, trainerID, endpointID primitive.ObjectID) error {
+	if _, err := s.getOwnedEndpoint(ctx, trainerID, endpointID); err != nil {
+		return err
+	}
+	if err := s.endpointRepo.Delete(ctx, endpointID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrWebhookNotFound
+		}
+		return errors.New("failed to delete webhook endpoint")
+	}
+	if _, err := s.deliveryRepo.DeleteByEndpoint(ctx, endpointID); err != nil {
+		// Pending deliveries fail on their own once they find the endpoint gone.
+		log.Printf("WARN: Failed to delete delivery log of webhook %s: %v", endpointID.Hex(), err)
+	}
+	return nil
+}
+
+// --- Delivery log ---
+
+func (s *webhookService) ListDeliveries(ctx context.Context, trainerID, endpointID primitive.ObjectID, query WebhookDeliveryQuery) (*WebhookDeliveryPage, error) {
+	if _, err := s.getOwnedEndpoint(ctx, trainerID, endpointID); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryPageSize
+	}
+	if limit > maxWebhookDeliveryPageSize {
+		limit = maxWebhookDeliveryPageSize
+	}
+
+	// Fetch one extra to learn whether an older page exists.
+	deliveries, err := s.deliveryRepo.ListByEndpoint(ctx, endpointID, query.BeforeID, limit+1)
+	if err != nil {
+		return nil, errors.New("failed to retrieve webhook deliveries")
+	}
+	page := &WebhookDeliveryPage{Deliveries: deliveries}
+	if int64(len(deliveries)) > limit {
+		page.Deliveries = deliveries[:limit]
+		next := page.Deliveries[limit-1].ID
+		page.NextBeforeID = &next
+	}
+	if page.Deliveries == nil {
+		page.Deliveries = []domain.WebhookDelivery{}
+	}
+	return page, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, trainerID, endpointID, deliveryID primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	endpoint, err := s.getOwnedEndpoint(ctx, trainerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Active {
+		return nil, ErrWebhookInactive
+	}
+	original, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, errors.New("failed to retrieve webhook delivery")
+	}
+	if original.EndpointID != endpoint.ID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	now := time.Now().UTC()
+	delivery := &domain.WebhookDelivery{
+		EndpointID:    endpoint.ID,
+		TrainerID:     endpoint.TrainerID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if _, err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, errors.New("failed to queue webhook redelivery")
+	}
+	s.notifyWorkers()
+	return delivery, nil
+}
+
+func (s *webhookService) Ping(ctx context.Context, trainerID, endpointID primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	endpoint, err := s.getOwnedEndpoint(ctx, trainerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Active {
+		return nil, ErrWebhookInactive
+	}
+	eventID := primitive.NewObjectID().Hex()
+	payload, err := json.Marshal(webhookPayload{
+		ID:        eventID,
+		Type:      domain.WebhookPing,
+		CreatedAt: time.Now().UTC(),
+		Summary:   "Test delivery",
+		Data:      map[string]any{"webhookId": endpoint.ID},
+	})
+	if err != nil {
+		return nil, errors.New("failed to build webhook payload")
+	}
+	delivery := &domain.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		TrainerID:  endpoint.TrainerID,
+		EventID:    eventID,
+		EventType:  domain.WebhookPing,
+		Payload:    string(payload),
+		Status:     domain.WebhookDeliveryPending,
+	}
+	if _, err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, errors.New("failed to log webhook delivery")
+	}
+
+	// Pings are sent once, while the caller waits; they are not retried.
+	attempt := s.post(ctx, endpoint, delivery)
+	delivery.Status = domain.WebhookDeliveryFailed
+	if attempt.Error == "" {
+		delivery.Status = domain.WebhookDeliverySucceeded
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery.ID, attempt, delivery.Status, nil); err != nil {
+		log.Printf("WARN: Failed to record ping of webhook %s: %v", endpoint.ID.Hex(), err)
+	}
+	return delivery, nil
+}
+
+// --- Events ---
+
+// webhookEventFor translates a service event to the webhook event integrations see.
+func webhookEventFor(event realtime.Event) (domain.WebhookEventType, bool) {
+	switch event.Type {
+	case realtime.EventUpload:
+		return domain.WebhookAssignmentSubmitted, true
+	case realtime.EventFeedback:
+		// Feedback that moved the assignment to reviewed; comments and replies don't count.
+		if changed, _ := event.Data["statusChanged"].(bool); changed && fmt.Sprint(event.Data["status"]) == string(domain.StatusReviewed) {
+			return domain.WebhookAssignmentReviewed, true
+		}
+	case realtime.EventPlan:
+		return domain.WebhookPlanCreated, true
+	case realtime.EventClientLinked:
+		return domain.WebhookClientLinked, true
+	}
+	return "", false
+}
+
+// Publish queues a delivery to every subscribed endpoint of the trainer involved in the
+// event, who is either its actor or a recipient. Failures are logged, not returned.
+func (s *webhookService) Publish(ctx context.Context, event realtime.Event, recipients ...primitive.ObjectID) {
+	eventType, ok := webhookEventFor(event)
+	if !ok {
+		return
+	}
+	owners := make([]primitive.ObjectID, 0, len(recipients)+1)
+	if event.ActorID != primitive.NilObjectID {
+		owners = append(owners, event.ActorID)
+	}
+	owners = append(owners, recipients...)
+	endpoints, err := s.endpointRepo.GetSubscribed(ctx, owners, eventType)
+	if err != nil {
+		log.Printf("WARN: Failed to look up webhooks for %s: %v", eventType, err)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	body := webhookPayload{
+		ID:        event.ID,
+		Type:      eventType,
+		CreatedAt: event.CreatedAt,
+		Summary:   event.Summary,
+		Data:      event.Data,
+	}
+	if event.ActorID != primitive.NilObjectID {
+		actorID := event.ActorID
+		body.ActorID = &actorID
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("WARN: Failed to build %s webhook payload: %v", eventType, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, endpoint := range endpoints {
+		delivery := &domain.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			TrainerID:     endpoint.TrainerID,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if _, err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			log.Printf("WARN: Failed to queue %s webhook for %s: %v", eventType, endpoint.ID.Hex(), err)
+		}
+	}
+	s.notifyWorkers()
+}
+
+// notifyWorkers wakes an idle delivery worker without waiting for the next poll.
+func (s *webhookService) notifyWorkers() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// --- Delivery ---
+
+func (s *webhookService) RunDeliveries(ctx context.Context, pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		pollInterval = 15 * time.Second
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		go s.deliveryWorker(ctx, pollInterval)
+	}
+	<-ctx.Done()
+}
+
+func (s *webhookService) deliveryWorker(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		// Drain everything due, then wait for a new event or the next poll.
+		for ctx.Err() == nil {
+			now := time.Now().UTC()
+			delivery, err := s.deliveryRepo.ClaimDue(ctx, now, now.Add(webhookClaimLease))
+			if err != nil {
+				if !errors.Is(err, repository.ErrNotFound) && ctx.Err() == nil {
+					log.Printf("WARN: Failed to claim webhook delivery: %v", err)
+				}
+				break
+			}
+			s.attempt(ctx, delivery)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// attempt makes one attempt of a claimed delivery and schedules the next one if it failed.
+func (s *webhookService) attempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	var attempt domain.WebhookAttempt
+	endpoint, err := s.endpointRepo.GetByID(ctx, delivery.EndpointID)
+	switch {
+	case err == nil && endpoint.Active:
+		attempt = s.post(ctx, endpoint, delivery)
+	case err == nil:
+		attempt = domain.WebhookAttempt{At: time.Now().UTC(), Error: "endpoint is inactive"}
+	case errors.Is(err, repository.ErrNotFound):
+		attempt = domain.WebhookAttempt{At: time.Now().UTC(), Error: "endpoint was deleted"}
+	default:
+		// Database trouble; leave the delivery to be picked up again after its lease.
+		log.Printf("WARN: Failed to load webhook %s: %v", delivery.EndpointID.Hex(), err)
+		return
+	}
+
+	status := domain.WebhookDeliverySucceeded
+	var next *time.Time
+	if attempt.Error != "" {
+		status = domain.WebhookDeliveryFailed
+		attempts := len(delivery.Attempts) + 1
+		if endpoint != nil && endpoint.Active && attempts < webhookMaxAttempts {
+			status = domain.WebhookDeliveryPending
+			at := time.Now().UTC().Add(webhookBackoff(attempts))
+			next = &at
+		}
+	}
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery.ID, attempt, status, next); err != nil {
+		log.Printf("WARN: Failed to record attempt of webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
+
+// webhookBackoff is the wait after the given number of failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// post signs and sends the delivery's payload once. Any non-2xx answer is a failure.
+func (s *webhookService) post(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) domain.WebhookAttempt {
+	started := time.Now().UTC()
+	attempt := domain.WebhookAttempt{At: started}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.DurationMs = time.Since(started).Milliseconds()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FitnessApp-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Event-Id", delivery.EventID)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.Hex())
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, started, []byte(delivery.Payload)))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.DurationMs = time.Since(started).Milliseconds()
+		return attempt
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodySize))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseBody = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = "endpoint returned " + strconv.Itoa(resp.StatusCode)
+	}
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	return attempt
+}
+
+// SignWebhookPayload computes the X-Webhook-Signature value for body sent at t.
+// Receivers recompute the HMAC and should reject timestamps more than a few minutes old.
+func SignWebhookPayload(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NewLocalStorage creates a FileStorage that keeps objects on local disk under rootDir.
+// Presigned URLs are served by this server (see SignedObjectsRoute) and signed with signingKey.
+func NewLocalStorage(rootDir, publicBaseURL, signingKey string) (SignedURLBackend, error) {
+	if rootDir == "" {
+		return nil, errors.New("a root directory is required for local storage")
+	}
+	for _, dir := range []string{"objects", "meta"} {
+		if err := os.MkdirAll(filepath.Join(rootDir, dir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create local storage directory: %w", err)
+		}
+	}
+
+	log.Printf("Local Storage Service initialized at: %s", rootDir)
+	return newSignedURLStorage(&diskBlobStore{root: rootDir}, publicBaseURL, signingKey)
+}
+
+// diskBlobStore is a blobStore on the local filesystem. Object bytes live under
+// <root>/objects/<key>; content type and ETag live under <root>/meta/<key>.json.
+type diskBlobStore struct {
+	root string
+}
+
+// diskObjectMeta is the sidecar stored next to each object.
+type diskObjectMeta struct {
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag"`
+}
+
+// paths maps an object key to its data and sidecar files, rejecting keys that
+// would escape the storage root.
+func (d *diskBlobStore) paths(key string) (string, string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", "", fmt.Errorf("invalid object key %q", key)
+	}
+	rel := filepath.FromSlash(key)
+	return filepath.Join(d.root, "objects", rel), filepath.Join(d.root, "meta", rel+".json"), nil
+}
+
+func (d *diskBlobStore) put(key, contentType string, body io.Reader) (*ObjectMetadata, error) {
+	dataPath, metaPath, err := d.paths(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range []string{dataPath, metaPath} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write to a temp file and rename, so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(dataPath), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name()) // No-op after a successful rename
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	sidecar, err := json.Marshal(diskObjectMeta{
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(metaPath, sidecar, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), dataPath); err != nil {
+		return nil, err
+	}
+	return d.stat(key)
+}
+
+func (d *diskBlobStore) open(key string) (io.ReadSeekCloser, *ObjectMetadata, error) {
+	meta, err := d.stat(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataPath, _, _ := d.paths(key)
+	f, err := os.Open(dataPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, err
+	}
+	return f, meta, nil
+}
+
+func (d *diskBlobStore) stat(key string) (*ObjectMetadata, error) {
+	dataPath, metaPath, err := d.paths(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dataPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	var sidecar diskObjectMeta
+	if raw, err := os.ReadFile(metaPath); err == nil {
+		_ = json.Unmarshal(raw, &sidecar)
+	}
+	return &ObjectMetadata{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  sidecar.ContentType,
+		LastModified: info.ModTime().UTC(),
+		ETag:         sidecar.ETag,
+	}, nil
+}
+
+func (d *diskBlobStore) remove(key string) error {
+	dataPath, metaPath, err := d.paths(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{dataPath, metaPath} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *diskBlobStore) list(prefix string) ([]ObjectMetadata, error) {
+	objectsRoot := filepath.Join(d.root, "objects")
+	// Walk only the directory containing the prefix; filter the rest by key.
+	startDir := filepath.Join(objectsRoot, filepath.FromSlash(path.Dir(prefix)))
+	if strings.HasSuffix(prefix, "/") {
+		startDir = filepath.Join(objectsRoot, filepath.FromSlash(prefix))
+	}
+
+	var metas []ObjectMetadata
+	err := filepath.WalkDir(startDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(objectsRoot, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		meta, err := d.stat(key)
+		if err != nil {
+			return err
+		}
+		metas = append(metas, *meta)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Key < metas[j].Key })
+	return metas, nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMemoryStorage creates a FileStorage that keeps objects in memory, for tests and
+// local runs without MinIO. Presigned URLs are served by this server (see SignedObjectsRoute).
+func NewMemoryStorage(publicBaseURL, signingKey string) (SignedURLBackend, error) {
+	return newSignedURLStorage(&memoryBlobStore{objects: make(map[string]memoryObject)}, publicBaseURL, signingKey)
+}
+
+type memoryObject struct {
+	data []byte
+	meta ObjectMetadata
+}
+
+// memoryBlobStore is a blobStore backed by a map.
+type memoryBlobStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// readSeekNopCloser adds a no-op Close to an in-memory reader.
+type readSeekNopCloser struct{ *bytes.Reader }
+
+func (readSeekNopCloser) Close() error { return nil }
+
+func (m *memoryBlobStore) put(key, contentType string, body io.Reader) (*ObjectMetadata, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	sum := md5.Sum(data)
+	meta := ObjectMetadata{
+		Key:          key,
+		Size:         int64(len(data)),
+		ContentType:  contentType,
+		LastModified: time.Now().UTC(),
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+
+	m.mu.Lock()
+	m.objects[key] = memoryObject{data: data, meta: meta}
+	m.mu.Unlock()
+	return &meta, nil
+}
+
+func (m *memoryBlobStore) open(key string) (io.ReadSeekCloser, *ObjectMetadata, error) {
+	m.mu.RLock()
+	obj, ok := m.objects[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil, ErrObjectNotFound
+	}
+	meta := obj.meta
+	return readSeekNopCloser{bytes.NewReader(obj.data)}, &meta, nil
+}
+
+func (m *memoryBlobStore) stat(key string) (*ObjectMetadata, error) {
+	m.mu.RLock()
+	obj, ok := m.objects[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	meta := obj.meta
+	return &meta, nil
+}
+
+func (m *memoryBlobStore) remove(key string) error {
+	m.mu.Lock()
+	delete(m.objects, key)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryBlobStore) list(prefix string) ([]ObjectMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var metas []ObjectMetadata
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			metas = append(metas, obj.meta)
+		}
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Key < metas[j].Key })
+	return metas, nil
+}
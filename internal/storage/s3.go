@@ -3,13 +3,16 @@ package storage
 import (
 	"alcyxob/fitness-app/internal/config" // Import your config package
 	"context"
+	"errors"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsCfg "github.com/aws/aws-sdk-go-v2/config" // Alias config to avoid clash
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // s3Storage implements the FileStorage interface using an S3-compatible backend.
@@ -142,3 +145,210 @@ func (s *s3Storage) DeleteObject(ctx context.Context, objectKey string) error {
 	log.Printf("INFO: Deleted object '%s' from bucket '%s'", objectKey, s.bucketName)
 	return nil
 }
+
+// GetObjectMetadata fetches an object's size and content type with a HEAD request.
+func (s *s3Storage) GetObjectMetadata(ctx context.Context, objectKey string) (*ObjectMetadata, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		// HEAD responses carry no body, so a missing key surfaces as NotFound rather than NoSuchKey.
+		var notFound *types.NotFound
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &notFound) || errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		log.Printf("ERROR: Failed to get metadata for object '%s' in bucket '%s': %v", objectKey, s.bucketName, err)
+		return nil, err
+	}
+
+	metadata := &ObjectMetadata{
+		Key:          objectKey,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		LastModified: aws.ToTime(out.LastModified),
+		ETag:         aws.ToString(out.ETag),
+	}
+	return metadata, nil
+}
+
+// ListObjects pages through ListObjectsV2 and returns every object under prefix.
+func (s *s3Storage) ListObjects(ctx context.Context, prefix string) ([]ObjectMetadata, error) {
+	var objects []ObjectMetadata
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("ERROR: Failed to list objects under '%s' in bucket '%s': %v", prefix, s.bucketName, err)
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			// Listings carry no content type; use GetObjectMetadata when it matters.
+			objects = append(objects, ObjectMetadata{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+				ETag:         aws.ToString(obj.ETag),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// isNoSuchUpload reports whether err means the multipart upload ID is unknown.
+func isNoSuchUpload(err error) bool {
+	var noSuchUpload *types.NoSuchUpload
+	return errors.As(err, &noSuchUpload)
+}
+
+// CreateMultipartUpload starts a multipart upload for objectKey.
+func (s *s3Storage) CreateMultipartUpload(ctx context.Context, objectKey string, contentType string) (string, error) {
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(objectKey),
+		ContentType: aws.String(contentType), // Stored on the final object; parts carry no content type
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to create multipart upload for key '%s': %v", objectKey, err)
+		return "", err
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// GeneratePresignedUploadPartURL creates a temporary URL for uploading one part (PUT).
+func (s *s3Storage) GeneratePresignedUploadPartURL(ctx context.Context, objectKey, uploadID string, partNumber int32, expires time.Duration) (string, error) {
+	if expires <= 0 {
+		expires = DefaultPresignedURLExpiry
+	}
+
+	presignParams := &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        aws.String(objectKey),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(partNumber),
+	}
+
+	req, err := s.presignClient.PresignUploadPart(ctx, presignParams, s3.WithPresignExpires(expires))
+	if err != nil {
+		log.Printf("ERROR: Failed to generate presigned part URL for key '%s' part %d: %v", objectKey, partNumber, err)
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// ListUploadedParts pages through ListParts and returns every part received so far.
+func (s *s3Storage) ListUploadedParts(ctx context.Context, objectKey, uploadID string) ([]UploadedPart, error) {
+	var parts []UploadedPart
+	var marker *string
+	for {
+		out, err := s.client.ListParts(ctx, &s3.ListPartsInput{
+			Bucket:           aws.String(s.bucketName),
+			Key:              aws.String(objectKey),
+			UploadId:         aws.String(uploadID),
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			if isNoSuchUpload(err) {
+				return nil, ErrMultipartUploadNotFound
+			}
+			log.Printf("ERROR: Failed to list parts for key '%s': %v", objectKey, err)
+			return nil, err
+		}
+
+		for _, p := range out.Parts {
+			parts = append(parts, UploadedPart{
+				PartNumber:   aws.ToInt32(p.PartNumber),
+				ETag:         aws.ToString(p.ETag),
+				Size:         aws.ToInt64(p.Size),
+				LastModified: aws.ToTime(p.LastModified),
+			})
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		marker = out.NextPartNumberMarker
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+// CompleteMultipartUpload assembles the parts into the final object.
+func (s *s3Storage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []UploadedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int32(p.PartNumber),
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucketName),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		if isNoSuchUpload(err) {
+			return ErrMultipartUploadNotFound
+		}
+		log.Printf("ERROR: Failed to complete multipart upload for key '%s': %v", objectKey, err)
+		return err
+	}
+
+	log.Printf("INFO: Completed multipart upload of %d parts for key '%s'", len(parts), objectKey)
+	return nil
+}
+
+// ListMultipartUploads pages through ListMultipartUploads and returns every upload in progress under prefix.
+func (s *s3Storage) ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	var keyMarker, uploadIDMarker *string
+	for {
+		out, err := s.client.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{
+			Bucket:         aws.String(s.bucketName),
+			Prefix:         aws.String(prefix),
+			KeyMarker:      keyMarker,
+			UploadIdMarker: uploadIDMarker,
+		})
+		if err != nil {
+			log.Printf("ERROR: Failed to list multipart uploads under '%s' in bucket '%s': %v", prefix, s.bucketName, err)
+			return nil, err
+		}
+
+		for _, u := range out.Uploads {
+			uploads = append(uploads, MultipartUpload{
+				Key:       aws.ToString(u.Key),
+				UploadID:  aws.ToString(u.UploadId),
+				Initiated: aws.ToTime(u.Initiated),
+			})
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		keyMarker, uploadIDMarker = out.NextKeyMarker, out.NextUploadIdMarker
+	}
+	return uploads, nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts.
+func (s *s3Storage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		if isNoSuchUpload(err) {
+			return ErrMultipartUploadNotFound
+		}
+		log.Printf("ERROR: Failed to abort multipart upload for key '%s': %v", objectKey, err)
+		return err
+	}
+	return nil
+}
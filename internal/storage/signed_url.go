@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignedObjectsRoute is where the app serves objects for backends without their own
+// HTTP endpoint (local disk, in-memory). Presigned URLs point at this route.
+const SignedObjectsRoute = "/api/v1/storage/objects"
+
+// multipartPrefix holds in-progress multipart uploads inside the blob store.
+const multipartPrefix = ".multipart/"
+
+var (
+	ErrInvalidSignature = errors.New("invalid or missing URL signature")
+	ErrURLExpired       = errors.New("URL has expired")
+)
+
+// SignedURLBackend is a FileStorage whose presigned URLs are served by this server
+// (see SignedObjectsRoute) rather than by the storage provider itself.
+type SignedURLBackend interface {
+	FileStorage
+
+	// VerifySignedURL checks the signature and expiry of a request to SignedObjectsRoute.
+	VerifySignedURL(method, objectKey string, query url.Values) (*SignedURLRequest, error)
+
+	// PutObject stores an uploaded object (single PUT).
+	PutObject(ctx context.Context, objectKey, contentType string, body io.Reader) (*ObjectMetadata, error)
+
+	// PutPart stores one part of a multipart upload.
+	PutPart(ctx context.Context, objectKey, uploadID string, partNumber int32, body io.Reader) (*UploadedPart, error)
+
+	// OpenObject opens an object for reading; the reader supports seeking for range requests.
+	OpenObject(ctx context.Context, objectKey string) (io.ReadSeekCloser, *ObjectMetadata, error)
+}
+
+// SignedURLRequest is what a verified URL authorizes.
+type SignedURLRequest struct {
+	ContentType string // Required Content-Type header for PUTs of whole objects
+	UploadID    string // Set for multipart part uploads
+	PartNumber  int32
+}
+
+// blobStore is the raw object store behind signedURLStorage.
+type blobStore interface {
+	put(key, contentType string, body io.Reader) (*ObjectMetadata, error)
+	open(key string) (io.ReadSeekCloser, *ObjectMetadata, error)
+	stat(key string) (*ObjectMetadata, error)
+	remove(key string) error // Missing keys are not an error
+	list(prefix string) ([]ObjectMetadata, error)
+}
+
+// signedURLStorage implements SignedURLBackend on top of a blobStore, signing URLs
+// with HMAC-SHA256 so only URLs issued by this server are accepted.
+type signedURLStorage struct {
+	store      blobStore
+	baseURL    string // Public base URL of this server, e.g. "http://localhost:8080"
+	signingKey []byte
+}
+
+func newSignedURLStorage(store blobStore, baseURL, signingKey string) (*signedURLStorage, error) {
+	if signingKey == "" {
+		return nil, errors.New("a signing key is required for signed storage URLs")
+	}
+	return &signedURLStorage{
+		store:      store,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		signingKey: []byte(signingKey),
+	}, nil
+}
+
+// sign computes the signature over everything a URL authorizes.
+func (s *signedURLStorage) sign(method, objectKey string, expires int64, contentType, uploadID string, partNumber int32) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n%s\n%d", method, objectKey, expires, contentType, uploadID, partNumber)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedURL builds an expiring URL to SignedObjectsRoute for objectKey.
+func (s *signedURLStorage) signedURL(method, objectKey string, expires time.Duration, contentType, uploadID string, partNumber int32) string {
+	if expires <= 0 {
+		expires = DefaultPresignedURLExpiry
+	}
+	expiresAt := time.Now().Add(expires).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	if contentType != "" {
+		query.Set("contentType", contentType)
+	}
+	if uploadID != "" {
+		query.Set("uploadId", uploadID)
+		query.Set("partNumber", strconv.Itoa(int(partNumber)))
+	}
+	query.Set("signature", s.sign(method, objectKey, expiresAt, contentType, uploadID, partNumber))
+
+	segments := strings.Split(objectKey, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return s.baseURL + SignedObjectsRoute + "/" + strings.Join(segments, "/") + "?" + query.Encode()
+}
+
+// VerifySignedURL checks a request's signature and expiry.
+func (s *signedURLStorage) VerifySignedURL(method, objectKey string, query url.Values) (*SignedURLRequest, error) {
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	req := &SignedURLRequest{
+		ContentType: query.Get("contentType"),
+		UploadID:    query.Get("uploadId"),
+	}
+	if req.UploadID != "" {
+		n, err := strconv.ParseInt(query.Get("partNumber"), 10, 32)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		req.PartNumber = int32(n)
+	}
+
+	expected := s.sign(method, objectKey, expiresAt, req.ContentType, req.UploadID, req.PartNumber)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return nil, ErrInvalidSignature
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, ErrURLExpired
+	}
+	return req, nil
+}
+
+// --- FileStorage ---
+
+func (s *signedURLStorage) GeneratePresignedUploadURL(ctx context.Context, objectKey string, contentType string, expires time.Duration) (string, error) {
+	return s.signedURL("PUT", objectKey, expires, contentType, "", 0), nil
+}
+
+func (s *signedURLStorage) GeneratePresignedDownloadURL(ctx context.Context, objectKey string, expires time.Duration) (string, error) {
+	return s.signedURL("GET", objectKey, expires, "", "", 0), nil
+}
+
+func (s *signedURLStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	if err := s.store.remove(objectKey); err != nil {
+		log.Printf("ERROR: Failed to delete object '%s': %v", objectKey, err)
+		return err
+	}
+	return nil
+}
+
+func (s *signedURLStorage) GetObjectMetadata(ctx context.Context, objectKey string) (*ObjectMetadata, error) {
+	return s.store.stat(objectKey)
+}
+
+// ListObjects lists stored objects, hiding in-progress multipart parts.
+func (s *signedURLStorage) ListObjects(ctx context.Context, prefix string) ([]ObjectMetadata, error) {
+	blobs, err := s.store.list(prefix)
+	if err != nil {
+		return nil, err
+	}
+	objects := blobs[:0]
+	for _, b := range blobs {
+		if !strings.HasPrefix(b.Key, multipartPrefix) {
+			objects = append(objects, b)
+		}
+	}
+	return objects, nil
+}
+
+// --- SignedURLBackend ---
+
+func (s *signedURLStorage) PutObject(ctx context.Context, objectKey, contentType string, body io.Reader) (*ObjectMetadata, error) {
+	return s.store.put(objectKey, contentType, body)
+}
+
+func (s *signedURLStorage) OpenObject(ctx context.Context, objectKey string) (io.ReadSeekCloser, *ObjectMetadata, error) {
+	return s.store.open(objectKey)
+}
+
+// --- Multipart uploads ---
+// Each upload lives under .multipart/<uploadID>/: a "session" blob recording the
+// target key and content type, and one blob per part.
+
+func multipartSessionKey(uploadID string) string { return multipartPrefix + uploadID + "/session" }
+
+func multipartPartKey(uploadID string, partNumber int32) string {
+	return fmt.Sprintf("%s%s/part-%05d", multipartPrefix, uploadID, partNumber)
+}
+
+// loadMultipartSession returns the content type of an upload after checking it targets objectKey.
+func (s *signedURLStorage) loadMultipartSession(objectKey, uploadID string) (string, error) {
+	key, contentType, err := s.readMultipartSession(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if key != objectKey {
+		return "", ErrMultipartUploadNotFound
+	}
+	return contentType, nil
+}
+
+// readMultipartSession returns the target key and content type recorded for an upload.
+func (s *signedURLStorage) readMultipartSession(uploadID string) (string, string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", "", ErrMultipartUploadNotFound
+	}
+	r, _, err := s.store.open(multipartSessionKey(uploadID))
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return "", "", ErrMultipartUploadNotFound
+		}
+		return "", "", err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 {
+		return "", "", ErrMultipartUploadNotFound
+	}
+	return lines[0], lines[1], nil
+}
+
+func (s *signedURLStorage) CreateMultipartUpload(ctx context.Context, objectKey string, contentType string) (string, error) {
+	uploadID := uuid.NewString()
+	session := strings.NewReader(objectKey + "\n" + contentType + "\n")
+	if _, err := s.store.put(multipartSessionKey(uploadID), "text/plain", session); err != nil {
+		log.Printf("ERROR: Failed to create multipart upload for key '%s': %v", objectKey, err)
+		return "", err
+	}
+	return uploadID, nil
+}
+
+func (s *signedURLStorage) GeneratePresignedUploadPartURL(ctx context.Context, objectKey, uploadID string, partNumber int32, expires time.Duration) (string, error) {
+	return s.signedURL("PUT", objectKey, expires, "", uploadID, partNumber), nil
+}
+
+func (s *signedURLStorage) PutPart(ctx context.Context, objectKey, uploadID string, partNumber int32, body io.Reader) (*UploadedPart, error) {
+	if partNumber < 1 || partNumber > MaxMultipartParts {
+		return nil, fmt.Errorf("part number %d out of range", partNumber)
+	}
+	if _, err := s.loadMultipartSession(objectKey, uploadID); err != nil {
+		return nil, err
+	}
+	meta, err := s.store.put(multipartPartKey(uploadID, partNumber), "application/octet-stream", body)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadedPart{PartNumber: partNumber, ETag: meta.ETag, Size: meta.Size, LastModified: meta.LastModified}, nil
+}
+
+func (s *signedURLStorage) ListUploadedParts(ctx context.Context, objectKey, uploadID string) ([]UploadedPart, error) {
+	if _, err := s.loadMultipartSession(objectKey, uploadID); err != nil {
+		return nil, err
+	}
+	blobs, err := s.store.list(multipartPrefix + uploadID + "/part-")
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]UploadedPart, 0, len(blobs))
+	for _, b := range blobs {
+		n, err := strconv.ParseInt(strings.TrimPrefix(path.Base(b.Key), "part-"), 10, 32)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, UploadedPart{PartNumber: int32(n), ETag: b.ETag, Size: b.Size, LastModified: b.LastModified})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (s *signedURLStorage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []UploadedPart) error {
+	contentType, err := s.loadMultipartSession(objectKey, uploadID)
+	if err != nil {
+		return err
+	}
+
+	// Open every part first, checking it is the one the caller listed.
+	readers := make([]io.Reader, 0, len(parts))
+	for _, p := range parts {
+		r, meta, err := s.store.open(multipartPartKey(uploadID, p.PartNumber))
+		if err != nil {
+			return fmt.Errorf("part %d: %w", p.PartNumber, err)
+		}
+		defer r.Close()
+		if meta.ETag != p.ETag {
+			return fmt.Errorf("part %d: ETag mismatch", p.PartNumber)
+		}
+		readers = append(readers, r)
+	}
+
+	if _, err := s.store.put(objectKey, contentType, io.MultiReader(readers...)); err != nil {
+		log.Printf("ERROR: Failed to assemble multipart upload for key '%s': %v", objectKey, err)
+		return err
+	}
+	return s.removeMultipartUpload(uploadID)
+}
+
+func (s *signedURLStorage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	if _, err := s.loadMultipartSession(objectKey, uploadID); err != nil {
+		return err
+	}
+	return s.removeMultipartUpload(uploadID)
+}
+
+// ListMultipartUploads lists the sessions of uploads in progress whose target key starts with prefix.
+func (s *signedURLStorage) ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error) {
+	blobs, err := s.store.list(multipartPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var uploads []MultipartUpload
+	for _, b := range blobs {
+		if path.Base(b.Key) != "session" {
+			continue
+		}
+		uploadID := path.Base(path.Dir(b.Key))
+		key, _, err := s.readMultipartSession(uploadID)
+		if err != nil {
+			if errors.Is(err, ErrMultipartUploadNotFound) {
+				continue // Completed or aborted while listing
+			}
+			return nil, err
+		}
+		if strings.HasPrefix(key, prefix) {
+			uploads = append(uploads, MultipartUpload{Key: key, UploadID: uploadID, Initiated: b.LastModified})
+		}
+	}
+	return uploads, nil
+}
+
+// removeMultipartUpload deletes an upload's parts and session.
+func (s *signedURLStorage) removeMultipartUpload(uploadID string) error {
+	blobs, err := s.store.list(multipartPrefix + uploadID + "/")
+	if err != nil {
+		return err
+	}
+	for _, b := range blobs {
+		if err := s.store.remove(b.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
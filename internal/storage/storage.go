@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -21,19 +22,68 @@ type FileStorage interface {
 	// DeleteObject removes an object from the storage provider.
 	DeleteObject(ctx context.Context, objectKey string) error
 
-	// GetObjectMetadata (Optional) - could be useful to check existence or get details
-	// GetObjectMetadata(ctx context.Context, objectKey string) (*ObjectMetadata, error)
+	// GetObjectMetadata returns what the storage provider actually holds for an object.
+	// Returns ErrObjectNotFound if the object does not exist.
+	GetObjectMetadata(ctx context.Context, objectKey string) (*ObjectMetadata, error)
+
+	// ListObjects returns every object whose key starts with prefix.
+	ListObjects(ctx context.Context, prefix string) ([]ObjectMetadata, error)
+
+	// --- Multipart uploads (large files, resumable) ---
+
+	// CreateMultipartUpload starts a multipart upload and returns its provider upload ID.
+	CreateMultipartUpload(ctx context.Context, objectKey string, contentType string) (string, error)
+
+	// GeneratePresignedUploadPartURL creates a temporary URL for PUTting one part (1-based).
+	GeneratePresignedUploadPartURL(ctx context.Context, objectKey, uploadID string, partNumber int32, expires time.Duration) (string, error)
+
+	// ListUploadedParts returns the parts received so far, ordered by part number.
+	// Returns ErrMultipartUploadNotFound if the upload was completed, aborted or never existed.
+	ListUploadedParts(ctx context.Context, objectKey, uploadID string) ([]UploadedPart, error)
+
+	// CompleteMultipartUpload assembles the given parts into the final object.
+	CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []UploadedPart) error
+
+	// AbortMultipartUpload discards a multipart upload and any parts already uploaded.
+	AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error
+
+	// ListMultipartUploads returns the multipart uploads in progress (neither completed
+	// nor aborted) for object keys starting with prefix.
+	ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error)
 }
 
-// ObjectMetadata (Optional) - structure for metadata if needed
-// type ObjectMetadata struct {
-// 	Size         int64
-// 	ContentType  string
-// 	LastModified time.Time
-// 	ETag         string
-// }
+// MultipartUpload describes a multipart upload in progress.
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// UploadedPart describes one part of a multipart upload held by the storage provider.
+type UploadedPart struct {
+	PartNumber   int32
+	ETag         string
+	Size         int64
+	LastModified time.Time
+}
+
+// Multipart limits shared by S3-compatible providers.
+const (
+	MinMultipartPartSize = 5 << 20 // Every part except the last must be at least 5 MiB
+	MaxMultipartParts    = 10000
+)
+
+// ObjectMetadata describes a stored object as reported by the storage provider.
+type ObjectMetadata struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+}
 
-// Error constants for storage layer (optional)
+// Error constants for storage layer
 var (
-// ErrObjectNotFound = errors.New("object not found in storage")
+	ErrObjectNotFound = errors.New("object not found in storage")
+	ErrMultipartUploadNotFound = errors.New("multipart upload not found in storage")
 )